@@ -18,7 +18,9 @@ import (
 	"bytes"
 	"crypto/sha1"
 	"encoding/binary"
+	"encoding/json"
 	"fmt"
+	"reflect"
 	"sort"
 	"sync"
 	"time"
@@ -37,6 +39,7 @@ const (
 	AttrTypeInt    = 2
 	AttrTypeBool   = 3
 	AttrTypeFloat  = 4
+	AttrTypeList   = 5
 )
 
 // AttrStore represents a storage layer for attributes.
@@ -275,18 +278,11 @@ func txUpdateAttrs(tx *bolt.Tx, id uint64, m map[string]interface{}) (map[string
 			continue
 		}
 
-		switch v := v.(type) {
-		case int:
-			attr[k] = int64(v)
-		case uint:
-			attr[k] = int64(v)
-		case uint64:
-			attr[k] = int64(v)
-		case string, int64, bool, float64:
-			attr[k] = v
-		default:
-			return nil, fmt.Errorf("invalid attr type: %T", v)
+		v, err := normalizeAttrValue(v)
+		if err != nil {
+			return nil, err
 		}
+		attr[k] = v
 	}
 
 	// Marshal and save new values.
@@ -342,10 +338,106 @@ func encodeAttr(key string, value interface{}) *internal.Attr {
 	case bool:
 		pb.Type = AttrTypeBool
 		pb.BoolValue = value
+	case []interface{}:
+		pb.Type = AttrTypeList
+		pb.ListValue = make([]*internal.Attr, len(value))
+		for i := range value {
+			pb.ListValue[i] = encodeAttr("", value[i])
+		}
 	}
 	return pb
 }
 
+// normalizeAttrValue returns v converted to a type supported by the store.
+// Integers are converted to int64. Lists are copied and must only contain
+// elements of a single type, which may be another list.
+func normalizeAttrValue(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case int:
+		return int64(v), nil
+	case uint:
+		return int64(v), nil
+	case uint64:
+		return int64(v), nil
+	case string, int64, bool, float64:
+		return v, nil
+	case []interface{}:
+		other := make([]interface{}, len(v))
+		for i := range v {
+			elem, err := normalizeAttrValue(v[i])
+			if err != nil {
+				return nil, err
+			} else if i > 0 && attrValueKind(elem) != attrValueKind(other[0]) {
+				return nil, fmt.Errorf("invalid attr list: mixed types %T and %T", other[0], elem)
+			}
+			other[i] = elem
+		}
+		return other, nil
+	default:
+		return nil, fmt.Errorf("invalid attr type: %T", v)
+	}
+}
+
+// attrValueKind returns the attribute type of a normalized value.
+func attrValueKind(v interface{}) int {
+	switch v.(type) {
+	case string:
+		return AttrTypeString
+	case int64:
+		return AttrTypeInt
+	case bool:
+		return AttrTypeBool
+	case float64:
+		return AttrTypeFloat
+	case []interface{}:
+		return AttrTypeList
+	default:
+		return 0
+	}
+}
+
+// attrFilterSet represents a set of attribute values to filter by.
+type attrFilterSet map[interface{}]struct{}
+
+// newAttrFilterSet returns a set of filter values.
+// Unhashable values, such as lists, are ignored since no element can equal them.
+func newAttrFilterSet(values []interface{}) attrFilterSet {
+	s := make(attrFilterSet, len(values))
+	for _, v := range values {
+		if isHashable(v) {
+			s[v] = struct{}{}
+		}
+	}
+	return s
+}
+
+// matches returns true if v is in the set. A list value matches if any of
+// its elements are in the set.
+func (s attrFilterSet) matches(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case []interface{}:
+		for _, elem := range v {
+			if s.matches(elem) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if !isHashable(v) {
+		return false
+	}
+	_, ok := s[v]
+	return ok
+}
+
+// isHashable returns true if v can be used as a map key.
+func isHashable(v interface{}) bool {
+	return v != nil && reflect.TypeOf(v).Comparable()
+}
+
 // decodeAttr converts from an Attr internal representation to a key/value pair.
 func decodeAttr(attr *internal.Attr) (key string, value interface{}) {
 	switch attr.Type {
@@ -357,11 +449,39 @@ func decodeAttr(attr *internal.Attr) (key string, value interface{}) {
 		return attr.Key, attr.BoolValue
 	case AttrTypeFloat:
 		return attr.Key, attr.FloatValue
+	case AttrTypeList:
+		a := make([]interface{}, len(attr.ListValue))
+		for i := range attr.ListValue {
+			_, a[i] = decodeAttr(attr.ListValue[i])
+		}
+		return attr.Key, a
 	default:
 		return attr.Key, nil
 	}
 }
 
+// decodeJSONAttrValue converts a value decoded from JSON using
+// json.Decoder.UseNumber() to an attribute value. Integral numbers are
+// returned as int64 and other numbers as float64.
+func decodeJSONAttrValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case []interface{}:
+		a := make([]interface{}, len(v))
+		for i := range v {
+			a[i] = decodeJSONAttrValue(v[i])
+		}
+		return a
+	default:
+		return v
+	}
+}
+
 // cloneAttrs returns a shallow clone of m.
 func cloneAttrs(m map[string]interface{}) map[string]interface{} {
 	other := make(map[string]interface{}, len(m))
@@ -84,6 +84,35 @@ func TestAttrStore_Attrs_Unset(t *testing.T) {
 	}
 }
 
+// Ensure list attributes round trip through storage.
+func TestAttrStore_Attrs_List(t *testing.T) {
+	s := MustOpenAttrStore()
+	defer s.Close()
+
+	// Set attributes.
+	if err := s.SetAttrs(1, map[string]interface{}{
+		"tags":   []interface{}{"a", "b"},
+		"matrix": []interface{}{[]interface{}{1, uint64(2)}, []interface{}{int64(3)}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Read attributes back from storage rather than the cache.
+	if m, err := s.BlockData(0); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(m[1], map[string]interface{}{
+		"tags":   []interface{}{"a", "b"},
+		"matrix": []interface{}{[]interface{}{int64(1), int64(2)}, []interface{}{int64(3)}},
+	}) {
+		t.Fatalf("unexpected attrs: %#v", m[1])
+	}
+
+	// Mixed-type lists are rejected.
+	if err := s.SetAttrs(2, map[string]interface{}{"tags": []interface{}{"a", int64(1)}}); err == nil || err.Error() != "invalid attr list: mixed types string and int64" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 // Ensure attribute block checksums can be returned.
 func TestAttrStore_Blocks(t *testing.T) {
 	s := MustOpenAttrStore()
@@ -17,6 +17,7 @@ package pilosa
 // #cgo  CFLAGS:-mpopcnt
 
 import (
+	"bytes"
 	"encoding/json"
 	"sort"
 
@@ -30,6 +31,12 @@ type Bitmap struct {
 
 	// Attributes associated with the bitmap.
 	Attrs map[string]interface{}
+
+	// Attributes associated with the bitmap's columns, if requested.
+	ColumnAttrSets []*ColumnAttrSet
+
+	// Keys for each bit, in order, if requested.
+	Keys []string
 }
 
 // NewBitmap returns a new instance of Bitmap.
@@ -219,10 +226,14 @@ func (b *Bitmap) Count() uint64 {
 // MarshalJSON returns a JSON-encoded byte slice of b.
 func (b *Bitmap) MarshalJSON() ([]byte, error) {
 	var o struct {
-		Attrs map[string]interface{} `json:"attrs"`
-		Bits  []uint64               `json:"bits"`
+		Attrs          map[string]interface{} `json:"attrs"`
+		Bits           []uint64               `json:"bits"`
+		ColumnAttrSets []*ColumnAttrSet       `json:"columnAttrs,omitempty"`
+		Keys           []string               `json:"keys,omitempty"`
 	}
 	o.Bits = b.Bits()
+	o.ColumnAttrSets = b.ColumnAttrSets
+	o.Keys = b.Keys
 
 	o.Attrs = b.Attrs
 	if o.Attrs == nil {
@@ -241,6 +252,34 @@ func (b *Bitmap) Bits() []uint64 {
 	return a
 }
 
+// MarshalRoaring returns the columns of b as a single roaring bitmap in the
+// format written by the roaring package. Attributes and keys are not included.
+func (b *Bitmap) MarshalRoaring() ([]byte, error) {
+	a := make([]*roaring.Bitmap, len(b.segments))
+	for i := range b.segments {
+		a[i] = &b.segments[i].data
+	}
+
+	var buf bytes.Buffer
+	if _, err := unionRoarings(a).WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// unionRoarings returns the union of a, merging halves so that each
+// container is copied once per level instead of once per bitmap.
+func unionRoarings(a []*roaring.Bitmap) *roaring.Bitmap {
+	switch len(a) {
+	case 0:
+		return roaring.NewBitmap()
+	case 1:
+		return a[0]
+	default:
+		return unionRoarings(a[:len(a)/2]).Union(unionRoarings(a[len(a)/2:]))
+	}
+}
+
 // encodeBitmap converts b into its internal representation.
 func encodeBitmap(b *Bitmap) *internal.Bitmap {
 	if b == nil {
@@ -250,6 +289,7 @@ func encodeBitmap(b *Bitmap) *internal.Bitmap {
 	return &internal.Bitmap{
 		Bits:  b.Bits(),
 		Attrs: encodeAttrs(b.Attrs),
+		Keys:  b.Keys,
 	}
 }
 
@@ -261,6 +301,7 @@ func decodeBitmap(pb *internal.Bitmap) *Bitmap {
 
 	b := NewBitmap()
 	b.Attrs = decodeAttrs(pb.Attrs)
+	b.Keys = pb.Keys
 	for _, v := range pb.Bits {
 		b.SetBit(v)
 	}
@@ -276,6 +317,41 @@ func Union(bitmaps []*Bitmap) *Bitmap {
 	return other
 }
 
+// UnionCount returns the number of bits set in the union of bitmaps without
+// building the union.
+func UnionCount(bitmaps []*Bitmap) uint64 {
+	var n uint64
+	pos := make([]int, len(bitmaps))
+	data := make([]*roaring.Bitmap, 0, len(bitmaps))
+	for {
+		// Find the lowest remaining slice.
+		var seg *BitmapSegment
+		for i, bm := range bitmaps {
+			if pos[i] < len(bm.segments) && (seg == nil || bm.segments[pos[i]].slice < seg.slice) {
+				seg = &bm.segments[pos[i]]
+			}
+		}
+		if seg == nil {
+			return n
+		}
+
+		// Count segments of the slice together.
+		slice := seg.slice
+		data = data[:0]
+		for i, bm := range bitmaps {
+			if pos[i] < len(bm.segments) && bm.segments[pos[i]].slice == slice {
+				data = append(data, &bm.segments[pos[i]].data)
+				pos[i]++
+			}
+		}
+		if len(data) == 1 {
+			n += seg.Count()
+		} else {
+			n += roaring.UnionCount(data)
+		}
+	}
+}
+
 // BitmapSegment holds a subset of a bitmap.
 // This could point to a mmapped roaring bitmap or an in-memory bitmap. The
 // width of the segment will always match the slice width.
@@ -15,7 +15,11 @@
 package pilosa
 
 import (
+	"bufio"
 	"bytes"
+	"container/heap"
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 	"sort"
@@ -290,19 +294,22 @@ func (p BitmapPairs) Less(i, j int) bool { return p[i].Count > p[j].Count }
 // Pair holds an id/count pair.
 type Pair struct {
 	ID    uint64 `json:"id"`
+	Key   string `json:"key,omitempty"`
 	Count uint64 `json:"count"`
 }
 
 func encodePair(p Pair) *internal.Pair {
 	return &internal.Pair{
-		Key:   p.ID,
-		Count: p.Count,
+		Key:       p.ID,
+		Count:     p.Count,
+		StringKey: p.Key,
 	}
 }
 
 func decodePair(pb *internal.Pair) Pair {
 	return Pair{
 		ID:    pb.Key,
+		Key:   pb.StringKey,
 		Count: pb.Count,
 	}
 }
@@ -360,6 +367,44 @@ func (p Pairs) Add(other []Pair) []Pair {
 	return a
 }
 
+// Top returns the n pairs in p with the highest counts in no particular order.
+// Returns p if it has n or fewer pairs.
+func (p Pairs) Top(n int) []Pair {
+	if len(p) <= n {
+		return p
+	} else if n <= 0 {
+		return nil
+	}
+
+	// Keep the best n pairs in a min-heap so the lowest is replaced first.
+	h := &PairHeap{Pairs: make(Pairs, 0, n)}
+	for _, pair := range p {
+		if h.Len() < n {
+			heap.Push(h, pair)
+		} else if pair.Count > h.Pairs[0].Count {
+			h.Pairs[0] = pair
+			heap.Fix(h, 0)
+		}
+	}
+	return h.Pairs
+}
+
+// MinCount returns the pairs in p with a count of at least n.
+// Order is preserved. Returns p if n is zero.
+func (p Pairs) MinCount(n uint64) []Pair {
+	if n == 0 {
+		return p
+	}
+
+	a := make([]Pair, 0, len(p))
+	for _, pair := range p {
+		if pair.Count >= n {
+			a = append(a, pair)
+		}
+	}
+	return a
+}
+
 // Keys returns a slice of all keys in p.
 func (p Pairs) Keys() []uint64 {
 	a := make([]uint64, len(p))
@@ -398,6 +443,79 @@ func decodePairs(a []*internal.Pair) []Pair {
 	return other
 }
 
+// writePairStream writes a to w as a pair count followed by
+// length-delimited pair messages.
+func writePairStream(w io.Writer, a []Pair) error {
+	bw := bufio.NewWriter(w)
+
+	var hdr [binary.MaxVarintLen64]byte
+	if _, err := bw.Write(hdr[:binary.PutUvarint(hdr[:], uint64(len(a)))]); err != nil {
+		return err
+	}
+
+	var buf []byte
+	for _, p := range a {
+		pb := encodePair(p)
+		if sz := pb.Size(); cap(buf) < sz {
+			buf = make([]byte, sz)
+		} else {
+			buf = buf[:sz]
+		}
+		if _, err := pb.MarshalTo(buf); err != nil {
+			return err
+		}
+
+		if _, err := bw.Write(hdr[:binary.PutUvarint(hdr[:], uint64(len(buf)))]); err != nil {
+			return err
+		} else if _, err := bw.Write(buf); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// readPairStream reads pairs written by writePairStream from r.
+func readPairStream(r io.Reader) ([]Pair, error) {
+	br := bufio.NewReader(r)
+
+	n, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+
+	a := make([]Pair, 0, n)
+	var buf []byte
+	for i := uint64(0); i < n; i++ {
+		sz, err := binary.ReadUvarint(br)
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		} else if err != nil {
+			return nil, err
+		} else if sz > maxPairMessageSize {
+			return nil, errors.New("pair message too large")
+		}
+
+		if uint64(cap(buf)) < sz {
+			buf = make([]byte, sz)
+		} else {
+			buf = buf[:sz]
+		}
+		if _, err := io.ReadFull(br, buf); err != nil {
+			return nil, err
+		}
+
+		var pb internal.Pair
+		if err := pb.Unmarshal(buf); err != nil {
+			return nil, err
+		}
+		a = append(a, decodePair(&pb))
+	}
+	return a, nil
+}
+
+// maxPairMessageSize is the largest encoded pair accepted by readPairStream.
+const maxPairMessageSize = 64
+
 // uint64Slice represents a sortable slice of uint64 numbers.
 type uint64Slice []uint64
 
@@ -438,6 +556,7 @@ func (p uint64Slice) merge(other []uint64) []uint64 {
 type BitmapCache interface {
 	Fetch(id uint64) (*Bitmap, bool)
 	Add(id uint64, b *Bitmap)
+	Remove(id uint64)
 }
 
 // SimpleCache implements BitmapCache
@@ -459,3 +578,204 @@ func (s *SimpleCache) Fetch(id uint64) (*Bitmap, bool) {
 func (s *SimpleCache) Add(id uint64, b *Bitmap) {
 	s.cache[id] = b
 }
+
+// Remove removes the bitmap at the id from the cache.
+func (s *SimpleCache) Remove(id uint64) {
+	delete(s.cache, id)
+}
+
+// LRUBitmapCache implements BitmapCache with a bounded number of rows.
+// The least recently read rows are evicted first. It is meant for read-heavy
+// loads where a small number of hot rows are read repeatedly.
+//
+// Cached bitmaps are shared between readers so callers must remove a row
+// instead of modifying it in place.
+type LRUBitmapCache struct {
+	cache *lru.Cache
+
+	hitN  uint64
+	missN uint64
+}
+
+// NewLRUBitmapCache returns a new instance of LRUBitmapCache.
+func NewLRUBitmapCache(maxEntries int) *LRUBitmapCache {
+	return &LRUBitmapCache{cache: lru.New(maxEntries)}
+}
+
+// Fetch retrieves the bitmap at the id in the cache.
+func (c *LRUBitmapCache) Fetch(id uint64) (*Bitmap, bool) {
+	v, ok := c.cache.Get(id)
+	if !ok {
+		c.missN++
+		return nil, false
+	}
+	c.hitN++
+	return v.(*Bitmap), true
+}
+
+// Add adds the bitmap to the cache, keyed on the id.
+func (c *LRUBitmapCache) Add(id uint64, b *Bitmap) { c.cache.Add(id, b) }
+
+// Remove removes the bitmap at the id from the cache.
+func (c *LRUBitmapCache) Remove(id uint64) { c.cache.Remove(id) }
+
+// Len returns the number of rows in the cache.
+func (c *LRUBitmapCache) Len() int { return c.cache.Len() }
+
+// Stats returns the number of cache hits & misses.
+func (c *LRUBitmapCache) Stats() (hitN, missN uint64) { return c.hitN, c.missN }
+
+// Ensure caches implement BitmapCache.
+var _ BitmapCache = &SimpleCache{}
+var _ BitmapCache = &LRUBitmapCache{}
+
+// QueryCache is an LRU cache of read-only call results at the coordinator.
+//
+// Only writes executed or imported through the local node invalidate the
+// cache. Writes coordinated by other nodes are not seen, so the cache is only
+// suitable for clusters where a single node coordinates all queries.
+//
+// Entries are keyed by index and call string. Each index and each frame
+// within an index has a data version which is bumped on mutation. An entry
+// records the versions of the index and of the frames its call references
+// from before the call executed and is treated as a miss once any of them
+// change. Cached results are shared between callers and must not be modified.
+type QueryCache struct {
+	mu       sync.Mutex
+	cache    *lru.Cache
+	versions map[queryCacheKey]uint64
+
+	hitN  uint64
+	missN uint64
+}
+
+// NewQueryCache returns a new instance of QueryCache.
+func NewQueryCache(maxEntries int) *QueryCache {
+	return &QueryCache{
+		cache:    lru.New(maxEntries),
+		versions: make(map[queryCacheKey]uint64),
+	}
+}
+
+// Get returns the cached result for a call on an index.
+// Returns false if the entry does not exist or is stale.
+func (c *QueryCache) Get(index, key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	k := queryCacheKey{index: index, key: key}
+	v, ok := c.cache.Get(k)
+	if !ok {
+		c.missN++
+		return nil, false
+	}
+
+	entry := v.(*queryCacheEntry)
+	if !c.isCurrent(index, entry) {
+		c.cache.Remove(k)
+		c.missN++
+		return nil, false
+	}
+
+	c.hitN++
+	return entry.result, true
+}
+
+// isCurrent returns true if no version referenced by entry has changed.
+func (c *QueryCache) isCurrent(index string, entry *queryCacheEntry) bool {
+	if entry.version != c.versions[queryCacheKey{index: index}] {
+		return false
+	}
+	for i, frame := range entry.frames {
+		if entry.frameVersions[i] != c.versions[queryCacheKey{index: index, key: frame}] {
+			return false
+		}
+	}
+	return true
+}
+
+// Versions returns the current data versions of an index and a set of frames.
+// It must be called before executing the call whose result is passed to Add.
+func (c *QueryCache) Versions(index string, frames []string) QueryCacheVersions {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v := QueryCacheVersions{
+		version:       c.versions[queryCacheKey{index: index}],
+		frames:        frames,
+		frameVersions: make([]uint64, len(frames)),
+	}
+	for i, frame := range frames {
+		v.frameVersions[i] = c.versions[queryCacheKey{index: index, key: frame}]
+	}
+	return v
+}
+
+// Add adds a result for a call on an index which was executed at versions.
+// The result is not added if any of the versions changed during execution.
+func (c *QueryCache) Add(index, key string, versions QueryCacheVersions, result interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &queryCacheEntry{QueryCacheVersions: versions, result: result}
+	if !c.isCurrent(index, entry) {
+		return
+	}
+	c.cache.Add(queryCacheKey{index: index, key: key}, entry)
+}
+
+// Invalidate bumps the data version of an index.
+// All entries previously cached for the index become stale.
+func (c *QueryCache) Invalidate(index string) {
+	c.mu.Lock()
+	c.versions[queryCacheKey{index: index}]++
+	c.mu.Unlock()
+}
+
+// InvalidateFrame bumps the data version of a frame.
+// Only entries which reference the frame become stale.
+func (c *QueryCache) InvalidateFrame(index, frame string) {
+	c.mu.Lock()
+	c.versions[queryCacheKey{index: index, key: frame}]++
+	c.mu.Unlock()
+}
+
+// Len returns the number of entries in the cache, including stale entries.
+func (c *QueryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.Len()
+}
+
+// Stats returns the hit & miss counts for the cache.
+func (c *QueryCache) Stats() QueryCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return QueryCacheStats{Hits: c.hitN, Misses: c.missN}
+}
+
+// QueryCacheStats represents hit & miss counts for a QueryCache.
+type QueryCacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// queryCacheKey identifies a cache entry by index & call string.
+// It also identifies versions by index, or by index & frame name.
+type queryCacheKey struct {
+	index string
+	key   string
+}
+
+// QueryCacheVersions represents the data versions of an index and of the
+// frames read by a call.
+type QueryCacheVersions struct {
+	version       uint64
+	frames        []string
+	frameVersions []uint64
+}
+
+type queryCacheEntry struct {
+	QueryCacheVersions
+	result interface{}
+}
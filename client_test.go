@@ -204,6 +204,41 @@ func TestClient_Import(t *testing.T) {
 	}
 }
 
+// Ensure an import invalidates cached query results which read from the frame.
+func TestClient_Import_QueryCache(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(0, 1)
+
+	s := NewServer()
+	defer s.Close()
+	s.Handler.Holder = hldr.Holder
+
+	e := NewExecutor(hldr.Holder, s.Handler.Cluster)
+	e.QueryCache = pilosa.NewQueryCache(10)
+	s.Handler.Handler.Executor = e
+
+	// Cache the count before importing.
+	q := MustParse(`Count(Bitmap(rowID=0, frame=f))`)
+	if res, err := e.Execute(context.Background(), "i", q, nil, nil); err != nil {
+		t.Fatal(err)
+	} else if res[0] != uint64(1) {
+		t.Fatalf("unexpected n: %d", res[0])
+	}
+
+	c := MustNewClient(s.Host())
+	if err := c.Import(context.Background(), "i", "f", 0, []pilosa.Bit{{RowID: 0, ColumnID: 5}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The imported bit is counted rather than the cached result.
+	if res, err := e.Execute(context.Background(), "i", q, nil, nil); err != nil {
+		t.Fatal(err)
+	} else if res[0] != uint64(2) {
+		t.Fatalf("unexpected n after import: %d", res[0])
+	}
+}
+
 // Ensure client can bulk import data to an inverse frame.
 func TestClient_ImportInverseEnabled(t *testing.T) {
 	hldr := MustOpenHolder()
@@ -15,22 +15,36 @@
 package pilosa
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gogo/protobuf/proto"
 	"github.com/pilosa/pilosa/internal"
 	"github.com/pilosa/pilosa/pql"
+	"github.com/pilosa/pilosa/roaring"
 )
 
-// DefaultFrame is the frame used if one is not specified.
+// DefaultFrame is the frame used if one is not specified and the index
+// does not set its own default frame.
 const (
 	DefaultFrame = "general"
 
@@ -39,6 +53,39 @@ const (
 	MinThreshold = 1
 )
 
+// Serialization formats for requests to remote nodes.
+const (
+	RemoteFormatProtobuf = "protobuf"
+	RemoteFormatJSON     = "json"
+)
+
+// DefaultMaxColumnAttrsN is the default maximum number of result columns
+// to attach attributes to.
+const DefaultMaxColumnAttrsN = 10000
+
+// DefaultMaxTranslateIDsN is the default maximum number of result ids
+// to translate back to keys.
+const DefaultMaxTranslateIDsN = 10000
+
+// DefaultMaxColumnAttrFilterN is the default maximum number of column
+// attribute sets scanned to filter TopN() columns.
+const DefaultMaxColumnAttrFilterN = 1000000
+
+// DefaultMaxRowRangeN is the default maximum number of rows a RowRange()
+// call may union.
+const DefaultMaxRowRangeN = 1000
+
+// DefaultMaxCallDepth is the default maximum nesting depth of a call.
+const DefaultMaxCallDepth = 128
+
+// DefaultMaxExplainSlices is the default maximum number of slices
+// evaluated by Explain().
+const DefaultMaxExplainSlices = 16
+
+// DefaultIngestBatchN is the default number of bits IngestBits() reads
+// before writing them with a SetBits() call.
+const DefaultIngestBatchN = 65536
+
 // Executor recursively executes calls in a PQL query across all slices.
 type Executor struct {
 	Holder *Holder
@@ -49,17 +96,235 @@ type Executor struct {
 
 	// Client used for remote HTTP requests.
 	HTTPClient *http.Client
+
+	// Optional authorizer for frame access. Only used by the coordinator.
+	Authorizer Authorizer
+
+	// Optional translator of string row & column keys to ids.
+	// Only used by the coordinator.
+	KeyTranslator KeyTranslator
+
+	// Optional cache of read call results. Only used by the coordinator and
+	// only valid if the same node coordinates all writes. See QueryCache.
+	QueryCache *QueryCache
+
+	// Maximum number of concurrent queries per index originating on this
+	// node. Forwarded queries are not limited. Zero means unlimited.
+	MaxConcurrentQueries int
+
+	// If true, queries over the limit fail with ErrTooManyQueries.
+	// Otherwise they wait until a slot is available or the context is done.
+	RejectTooManyQueries bool
+
+	// Duration that a node is skipped in favor of replicas after a failed
+	// request. The node is retried once it expires. Zero disables skipping.
+	NodeDownTimeout time.Duration
+
+	// Number of consecutive failed requests after which a node's circuit
+	// opens. Requests to a node with an open circuit fail immediately and
+	// its slices are assigned to replicas. After NodeCircuitCooldown a single
+	// probe request is sent and the circuit closes if it succeeds.
+	// Zero disables the circuit breaker.
+	NodeFailureThreshold int
+
+	// Duration that a node's circuit stays open before it is probed.
+	NodeCircuitCooldown time.Duration
+
+	// Serialization format of requests to & responses from remote nodes.
+	// Either RemoteFormatProtobuf or RemoteFormatJSON. Defaults to protobuf.
+	RemoteFormat string
+
+	// Maximum duration of a single request to a remote node, independent of
+	// the query's deadline. Requests which time out are treated as transport
+	// errors so their slices are retried on replicas. Zero means no limit.
+	RemoteRequestTimeout time.Duration
+
+	// Maximum total duration spent merging node results into a call's
+	// result, independent of the time spent mapping slices and waiting on
+	// remote nodes. Checked after each merge, so a single slow merge is not
+	// interrupted. Calls over the limit fail with ErrReduceTimeout.
+	// Zero means no limit.
+	ReduceTimeout time.Duration
+
+	// If true, calls mapped across slices are executed entirely by the local
+	// node and slices are never assigned to or requested from other nodes.
+	// Calls which read a slice the local node does not own fail. Intended
+	// for embedded single node deployments. Mutations are not affected.
+	LocalOnly bool
+
+	// Maximum number of goroutines mapping local slices at once, shared by
+	// all calls of all queries on this node. Goroutines waiting on remote
+	// nodes are not counted since they could otherwise deadlock with the
+	// queries they forward. Zero means unlimited.
+	MaxMapGoroutines int
+
+	// Maximum number of slices sent to a remote node in a single request.
+	// Nodes owning more slices receive multiple requests. Zero means unlimited.
+	MaxSlicesPerRequest int
+
+	// Maximum number of time views a Range() call may expand to.
+	// Zero means unlimited.
+	MaxRangeViews int
+
+	// If true, writes to a slice which no node owns are dropped instead of
+	// returning ErrSliceUnowned. Intended for test clusters which
+	// intentionally leave gaps in ownership.
+	AllowUnownedWrites bool
+
+	// Maximum number of rows a RowRange() call may union. Each row is read
+	// from every slice. Zero means unlimited.
+	MaxRowRangeN int
+
+	// Maximum size in bytes of a single SetRowAttrs() attribute value and of
+	// all the attribute keys & values written to a row by a query. Strings
+	// count their length, lists the sum of their elements and other values
+	// eight bytes. Oversized writes fail before any attribute is stored.
+	// Zero means unlimited.
+	MaxRowAttrSize  int
+	MaxRowAttrsSize int
+
+	// Maximum nesting depth of a call, counting the call itself. Deeper
+	// queries are rejected before execution since calls are executed
+	// recursively. Zero means unlimited.
+	MaxCallDepth int
+
+	// Maximum number of slices Explain() evaluates. Larger slice sets are
+	// sampled evenly. Zero uses DefaultMaxExplainSlices.
+	MaxExplainSlices int
+
+	// Number of bits IngestBits() buffers before each write. Bounds the
+	// memory used while ingesting a stream. Zero uses DefaultIngestBatchN.
+	IngestBatchN int
+
+	// Returns the time relative Range() start & end times are resolved
+	// against. Read once per query by the coordinating node, which sends
+	// absolute times to remote nodes. Defaults to time.Now.
+	Now func() time.Time
+
+	// Maximum number of result columns to attach attributes to when
+	// ExecOptions.AttachColumnAttrs is set.
+	MaxColumnAttrsN int
+
+	// Maximum number of result ids to translate to keys when
+	// ExecOptions.TranslateKeys is set.
+	MaxTranslateIDsN int
+
+	// If positive, the first pass of a TopN() call with a limit only keeps
+	// the best n+TopNReduceMargin pairs while merging slice results instead
+	// of every pair, bounding memory. Rows dropped from the merge are not
+	// refetched, so a larger margin is closer to full accumulation.
+	// Zero keeps all pairs.
+	TopNReduceMargin int
+
+	// If positive, the first pass of a TopN() call with a limit visits
+	// slices in batches of this size and skips the remaining slices once the
+	// top rows lead by more than the remaining slices could contribute.
+	// Bounds are read from the local fragment caches so results are
+	// approximate: rows may be missed if the caches are incomplete.
+	// Only locally owned slices can be skipped. Slices owned by other nodes
+	// are all visited with the first batch. Zero visits all slices.
+	TopNEarlyTerminationBatchN int
+
+	// Maximum number of column attribute sets scanned to filter TopN()
+	// columns by attribute. The filter scans every column with attributes
+	// in the index on each node, so its cost grows with the attribute store
+	// rather than the result. Zero means unlimited.
+	MaxColumnAttrFilterN int
+
+	mu         sync.Mutex
+	querySlots map[string]chan struct{}
+
+	// Semaphore bounding local map goroutines to MaxMapGoroutines.
+	mapSlots chan struct{}
+
+	// Time of the last failed request, by host, for nodes marked down.
+	nodesDown map[string]time.Time
+
+	// Circuit breaker state, by host, for nodes with failed requests.
+	circuits map[string]*nodeCircuit
+
+	// Custom calls, by name, added by RegisterAggregation().
+	aggregations map[string]*Aggregation
+
+	// Results of recently applied forwarded mutations, by operation id.
+	operations   map[string][]interface{}
+	operationIDs []string
 }
 
 // NewExecutor returns a new instance of Executor.
 func NewExecutor() *Executor {
 	return &Executor{
-		HTTPClient: http.DefaultClient,
+		HTTPClient:           http.DefaultClient,
+		MaxColumnAttrsN:      DefaultMaxColumnAttrsN,
+		MaxTranslateIDsN:     DefaultMaxTranslateIDsN,
+		MaxColumnAttrFilterN: DefaultMaxColumnAttrFilterN,
+		MaxRowRangeN:         DefaultMaxRowRangeN,
+		IngestBatchN:         DefaultIngestBatchN,
+		MaxCallDepth:         DefaultMaxCallDepth,
 	}
 }
 
 // Execute executes a PQL query.
 func (e *Executor) Execute(ctx context.Context, index string, q *pql.Query, slices []uint64, opt *ExecOptions) ([]interface{}, error) {
+	return e.execute(ctx, index, q, slices, opt, nil)
+}
+
+// ExecuteWithStats executes a PQL query and also returns the slices
+// visited by each call.
+func (e *Executor) ExecuteWithStats(ctx context.Context, index string, q *pql.Query, slices []uint64, opt *ExecOptions) ([]interface{}, *ExecStats, error) {
+	stats := &ExecStats{}
+	results, err := e.execute(ctx, index, q, slices, opt, stats)
+	if err != nil {
+		return nil, nil, err
+	}
+	return results, stats, nil
+}
+
+// ExecuteWithWarnings executes a PQL query and also returns the warnings
+// raised for conditions which did not fail the query but may affect its
+// results. Warnings are only raised by the coordinator.
+func (e *Executor) ExecuteWithWarnings(ctx context.Context, index string, q *pql.Query, slices []uint64, opt *ExecOptions) (*ExecResult, error) {
+	other := ExecOptions{}
+	if opt != nil {
+		other = *opt
+	}
+	other.warnings = &warningList{}
+
+	results, err := e.execute(ctx, index, q, slices, &other, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ExecResult{Results: results, Warnings: other.warnings.list()}, nil
+}
+
+// ExecuteBatch executes multiple independent queries concurrently.
+// Results and errors are returned at the index of their request. A failed
+// request does not affect the execution of the other requests.
+func (e *Executor) ExecuteBatch(ctx context.Context, requests []QueryRequest) ([][]interface{}, []error) {
+	results := make([][]interface{}, len(requests))
+	errs := make([]error, len(requests))
+
+	var wg sync.WaitGroup
+	for i := range requests {
+		wg.Add(1)
+		go func(i int, req *QueryRequest) {
+			defer wg.Done()
+
+			q, err := pql.NewParser(strings.NewReader(req.Query)).Parse()
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			results[i], errs[i] = e.Execute(ctx, req.Index, q, req.Slices, req.execOptions())
+		}(i, &requests[i])
+	}
+	wg.Wait()
+
+	return results, errs
+}
+
+// execute executes a PQL query. Visited slices are recorded to stats, if not nil.
+func (e *Executor) execute(ctx context.Context, index string, q *pql.Query, slices []uint64, opt *ExecOptions, stats *ExecStats) ([]interface{}, error) {
 	// Verify that an index is set.
 	if index == "" {
 		return nil, ErrIndexRequired
@@ -69,6 +334,109 @@ func (e *Executor) Execute(ctx context.Context, index string, q *pql.Query, slic
 	if opt == nil {
 		opt = &ExecOptions{}
 	}
+	if opt.TranslateKeys && e.KeyTranslator == nil && !opt.Remote {
+		return nil, ErrKeyTranslatorRequired
+	}
+	if opt.PinNode != "" && !opt.Remote && e.Cluster.NodeByHost(opt.PinNode) == nil {
+		return nil, fmt.Errorf("pinned node not found in cluster: %s", opt.PinNode)
+	}
+
+	// Reject queries nested too deeply to walk safely. Checked on every node
+	// since forwarded queries are parsed and executed the same way.
+	if err := e.checkCallDepth(q); err != nil {
+		return nil, err
+	}
+
+	// Record the query & its latency under the caller's tags.
+	start := time.Now()
+	defer func() {
+		stats := e.queryStats(index, opt.Tags)
+		stats.Count("queryN", 1)
+		stats.Timing("query", time.Since(start))
+	}()
+
+	// Collect warnings even if the caller does not read them so that results
+	// with warnings are never cached.
+	if opt.warnings == nil {
+		other := *opt
+		other.warnings = &warningList{}
+		opt = &other
+	}
+
+	// Remember nodes which fail so later calls of the query avoid them.
+	if opt.failedHosts == nil && !opt.Remote {
+		other := *opt
+		other.failedHosts = &hostSet{}
+		opt = &other
+	}
+
+	// Count fragment reads against the query's budget. Exceeding the budget
+	// cancels the slices still being read.
+	if opt.MaxFragmentReads > 0 && opt.fragmentReads == nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+
+		other := *opt
+		other.fragmentReads = &fragmentReads{max: int64(opt.MaxFragmentReads), cancel: cancel}
+		opt = &other
+	}
+
+	// Pass frames set on parent calls down to children without one so every
+	// node and check below reads the same frames.
+	if !opt.Remote {
+		q = inheritQueryFrames(q)
+	}
+
+	// List the frames read by index-wide MaxColumn() & MinColumn() calls.
+	if !opt.Remote {
+		other, err := e.expandColumnBoundCalls(index, q)
+		if err != nil {
+			return nil, err
+		}
+		q = other
+	}
+
+	// Authorize access to all frames before executing any call.
+	// Forwarded queries have already been authorized by the coordinator.
+	if e.Authorizer != nil && !opt.Remote {
+		for _, call := range q.Calls {
+			if err := e.authorizeCall(ctx, index, call); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Report all missing frames at once before any call is executed.
+	if !opt.Remote {
+		if err := e.checkFramesExist(index, q.Calls, opt); err != nil {
+			return nil, err
+		}
+	}
+
+	// Return the original results of a forwarded mutation already applied.
+	if opt.Remote && opt.OperationID != "" {
+		if results, ok := e.operationResults(opt.OperationID); ok {
+			return results, nil
+		}
+	}
+
+	// Limit concurrent queries against the index.
+	if !opt.Remote {
+		release, err := e.acquireQuerySlot(ctx, index)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+
+	// Copy the local fragments read by the query so every call and slice
+	// reads the same data regardless of concurrent writes.
+	if opt.Snapshot {
+		other := *opt
+		other.snapshot = e.pinSnapshot(index, q.Calls)
+		opt = &other
+	}
 
 	// Don't bother calculating slices for query types that don't require it.
 	needsSlices := needsSlices(q.Calls)
@@ -76,16 +444,21 @@ func (e *Executor) Execute(ctx context.Context, index string, q *pql.Query, slic
 	// MaxSlice can differ between inverse and standard views, so we need
 	// to send queries to different slices based on orientation.
 	var inverseSlices []uint64
-	rowLabel := DefaultRowLabel
 	columnLabel := DefaultColumnLabel
 
 	// If slices aren't specified, then include all of them.
 	if len(slices) == 0 {
 		// Determine slices and inverseSlices for use in e.executeCall().
 		if needsSlices {
+			// Fetch index. This must occur before reading the slice counts.
+			idx := e.Holder.Index(index)
+			if idx == nil {
+				return nil, ErrIndexNotFound
+			}
+
 			// Round up the number of slices.
-			maxSlice := e.Holder.Index(index).MaxSlice()
-			maxInverseSlice := e.Holder.Index(index).MaxInverseSlice()
+			maxSlice := idx.MaxSlice()
+			maxInverseSlice := idx.MaxInverseSlice()
 
 			// Generate a slices of all slices.
 			slices = make([]uint64, maxSlice+1)
@@ -100,612 +473,4078 @@ func (e *Executor) Execute(ctx context.Context, index string, q *pql.Query, slic
 			}
 
 			// Fetch column label from index.
-			idx := e.Holder.Index(index)
-			if idx == nil {
-				return nil, ErrIndexNotFound
-			}
 			columnLabel = idx.ColumnLabel()
 		}
 	}
 
 	// Optimize handling for bulk attribute insertion.
 	if hasOnlySetRowAttrs(q.Calls) {
+		if stats != nil {
+			for range q.Calls {
+				stats.Calls = append(stats.Calls, &CallStats{})
+			}
+		}
 		return e.executeBulkSetRowAttrs(ctx, index, q.Calls, opt)
+	} else if hasOnlySetColumnAttrs(q.Calls) {
+		if stats != nil {
+			for range q.Calls {
+				stats.Calls = append(stats.Calls, &CallStats{})
+			}
+		}
+		return e.executeBulkSetColumnAttrs(ctx, index, q.Calls, opt)
 	}
 
 	// Execute each call serially.
 	results := make([]interface{}, 0, len(q.Calls))
-	for _, call := range q.Calls {
+	now := e.now()
+	var dedupResults map[string]interface{}
+	for i, call := range q.Calls {
+		// Attribute warnings raised from here on to this call.
+		opt.warnings.setCall(i)
+
+		// Simplify the call tree unless disabled.
+		if !opt.NoOptimize {
+			call = OptimizeCall(call)
+		}
+
+		// Resolve relative times so every slice and node reads the same views.
+		if !opt.Remote {
+			call = resolveRangeTimes(call, now)
+		}
 
-		if call.SupportsInverse() && needsSlices {
-			// Fetch frame & row label based on argument.
-			frame, _ := call.Args["frame"].(string)
-			if frame == "" {
-				frame = DefaultFrame
+		// Reject Range() calls which cannot match any view. Forwarded calls
+		// were checked by the coordinator.
+		if !opt.Remote && !opt.EmptyRangeWithoutQuantum {
+			if err := e.checkRangeQuantums(index, call); err != nil {
+				return nil, err
 			}
-			f := e.Holder.Frame(index, frame)
-			if f == nil {
-				return nil, ErrFrameNotFound
+		}
+
+		// Resolve string keys to ids before dispatching to remote nodes.
+		if e.KeyTranslator != nil && !opt.Remote {
+			other, err := e.translateCall(index, call)
+			if err != nil {
+				return nil, err
 			}
-			rowLabel = f.RowLabel()
+			call = other
+		}
 
-			// If this call is to an inverse frame send to a different list of slices.
-			if call.IsInverse(rowLabel, columnLabel) {
-				slices = inverseSlices
+		// If this call reads from inverse views then send to a different list of
+		// slices. Calls which read from both views use the longer list.
+		callSlices := slices
+		if inverseSlices != nil {
+			standard, inverse, err := e.callOrientation(index, call, columnLabel)
+			if err == ErrFrameNotFound && opt.TreatMissingAsEmpty {
+				standard, inverse, err = true, false, nil
+			}
+			if err != nil {
+				return nil, err
 			}
+			if inverse && (!standard || len(inverseSlices) > len(slices)) {
+				callSlices = inverseSlices
+			}
+		}
+		if len(opt.ExcludeSlices) > 0 && !opt.Remote {
+			callSlices = excludeSlices(callSlices, opt.ExcludeSlices)
 		}
 
-		v, err := e.executeCall(ctx, index, call, slices, opt)
-		if err != nil {
+		// Record visited slices on a per-call copy of the options.
+		callOpt := opt
+		if stats != nil {
+			cs := &CallStats{}
+			stats.Calls = append(stats.Calls, cs)
+
+			other := *opt
+			other.stats = cs
+			callOpt = &other
+		}
+
+		// Reuse the result of an identical earlier read call. Mutations may
+		// change the results of later calls so earlier results are dropped.
+		var dedupKey string
+		if opt.DedupCalls && !opt.Remote {
+			if !isReadCall(call) {
+				dedupResults = nil
+			} else if call.Name != "Warm" {
+				dedupKey = call.CanonicalString()
+				if v, ok := dedupResults[dedupKey]; ok {
+					results = append(results, v)
+					callOpt.stats.setResult(v)
+					continue
+				}
+			}
+		}
+
+		// Return cached results for read calls at the coordinator.
+		var cacheKey string
+		var cacheVersions QueryCacheVersions
+		if e.QueryCache != nil && !opt.Remote && !opt.Snapshot && isReadCall(call) && call.Name != "Warm" {
+			cacheKey = queryCacheKeyString(call, callSlices)
+			if opt.AttachColumnAttrs {
+				cacheKey += " columnAttrs"
+			}
+			if opt.ApproximateTopN {
+				cacheKey += " approximate"
+			}
+			if opt.IncludeCount {
+				cacheKey += " includeCount"
+			}
+			if opt.IncludeUniverse {
+				cacheKey += " includeUniverse"
+			}
+			if opt.RawRoaring {
+				cacheKey += " rawRoaring"
+			}
+			if opt.Checksum {
+				cacheKey += " checksum"
+			}
+			if opt.TranslateKeys {
+				cacheKey += " translateKeys"
+			}
+			if v, ok := e.QueryCache.Get(index, cacheKey); ok {
+				results = append(results, v)
+				callOpt.stats.setResult(v)
+				continue
+			}
+
+			// Capture versions before executing so concurrent writes are not missed.
+			cacheVersions = e.QueryCache.Versions(index, referencedFrames(call, e.defaultFrame(index)))
+		}
+
+		warningN := opt.warnings.len()
+		v, err := e.executeCall(ctx, index, call, callSlices, callOpt)
+		if opt.fragmentReads.exceeded() {
+			// Report the budget rather than the cancellation of other slices.
+			return nil, ErrFragmentReadBudgetExceeded
+		} else if err != nil {
 			return nil, err
 		}
+
+		// Translate result ids back to keys at the coordinator.
+		if opt.TranslateKeys && !opt.Remote {
+			if err := e.translateResult(index, call, v); err != nil {
+				return nil, err
+			}
+		}
+
+		// Hash the merged result at the coordinator.
+		if opt.Checksum && !opt.Remote {
+			v = checksumResult(v)
+		}
+
 		results = append(results, v)
+		callOpt.stats.setResult(v)
+		if dedupKey != "" {
+			if dedupResults == nil {
+				dedupResults = make(map[string]interface{})
+			}
+			dedupResults[dedupKey] = v
+		}
+
+		// Results with warnings are not cached since hits would not raise them.
+		if cacheKey != "" && opt.warnings.len() == warningN {
+			e.QueryCache.Add(index, cacheKey, cacheVersions, v)
+		}
+	}
+
+	// Record forwarded mutation results so duplicates can be skipped.
+	if opt.Remote && opt.OperationID != "" {
+		e.addOperationResults(opt.OperationID, results)
 	}
+
 	return results, nil
 }
 
-// executeCall executes a call.
-func (e *Executor) executeCall(ctx context.Context, index string, c *pql.Call, slices []uint64, opt *ExecOptions) (interface{}, error) {
-
-	if err := e.validateCallArgs(c); err != nil {
-		return nil, err
+// queryStats returns the stats client for queries on index with tags
+// attached as sorted "key:value" labels.
+func (e *Executor) queryStats(index string, tags map[string]string) StatsClient {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
 	}
+	sort.Strings(keys)
 
-	// Special handling for mutation and top-n calls.
-	switch c.Name {
-	case "ClearBit":
-		return e.executeClearBit(ctx, index, c, opt)
-	case "Count":
-		return e.executeCount(ctx, index, c, slices, opt)
-	case "SetBit":
-		return e.executeSetBit(ctx, index, c, opt)
-	case "SetRowAttrs":
-		return nil, e.executeSetRowAttrs(ctx, index, c, opt)
-	case "SetColumnAttrs":
-		return nil, e.executeSetColumnAttrs(ctx, index, c, opt)
-	case "TopN":
-		return e.executeTopN(ctx, index, c, slices, opt)
-	default:
-		return e.executeBitmapCall(ctx, index, c, slices, opt)
+	a := []string{fmt.Sprintf("index:%s", index)}
+	for _, k := range keys {
+		a = append(a, fmt.Sprintf("%s:%s", k, tags[k]))
 	}
+	return e.Holder.Stats.WithTags(a...)
 }
 
-// validateCallArgs ensures that the value types in call.Args are expected.
-func (e *Executor) validateCallArgs(c *pql.Call) error {
-	if _, ok := c.Args["ids"]; ok {
-		switch v := c.Args["ids"].(type) {
-		case []int64, []uint64:
-			// noop
-		case []interface{}:
-			b := make([]int64, len(v), len(v))
-			for i := range v {
-				b[i] = v[i].(int64)
-			}
-			c.Args["ids"] = b
-		default:
-			return fmt.Errorf("invalid call.Args[ids]: %s", v)
-		}
-	}
-	return nil
+// operationResults returns the results of a previously applied operation.
+func (e *Executor) operationResults(id string) ([]interface{}, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	results, ok := e.operations[id]
+	return results, ok
 }
 
-// executeBitmapCall executes a call that returns a bitmap.
-func (e *Executor) executeBitmapCall(ctx context.Context, index string, c *pql.Call, slices []uint64, opt *ExecOptions) (*Bitmap, error) {
-	// Execute calls in bulk on each remote node and merge.
-	mapFn := func(slice uint64) (interface{}, error) {
-		return e.executeBitmapCallSlice(ctx, index, c, slice)
+// addOperationResults records the results of an applied operation.
+// Only the most recent MaxOperationsN operations are retained.
+func (e *Executor) addOperationResults(id string, results []interface{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.operations == nil {
+		e.operations = make(map[string][]interface{})
 	}
+	if _, ok := e.operations[id]; ok {
+		return
+	}
+	e.operations[id] = results
+	e.operationIDs = append(e.operationIDs, id)
 
-	// Merge returned results at coordinating node.
-	reduceFn := func(prev, v interface{}) interface{} {
-		other, _ := prev.(*Bitmap)
-		if other == nil {
-			other = NewBitmap()
-		}
-		other.Merge(v.(*Bitmap))
-		return other
+	// Evict oldest operations over the limit.
+	for len(e.operationIDs) > MaxOperationsN {
+		delete(e.operations, e.operationIDs[0])
+		e.operationIDs = e.operationIDs[1:]
 	}
+}
 
-	other, err := e.mapReduce(ctx, index, slices, c, opt, mapFn, reduceFn)
-	if err != nil {
-		return nil, err
+// MaxOperationsN is the number of applied operation ids retained per executor.
+const MaxOperationsN = 10000
+
+// newOperationID returns a random id identifying a forwarded mutation.
+func newOperationID() string {
+	var buf [16]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		panic(err)
 	}
+	return hex.EncodeToString(buf[:])
+}
 
-	// Attach attributes for Bitmap() calls.
-	// If the column label is used then return column attributes.
-	// If the row label is used then return bitmap attributes.
-	bm, _ := other.(*Bitmap)
-	if c.Name == "Bitmap" {
+// checkFramesExist returns an error if any frame referenced by calls does not
+// exist. Returns ErrFrameNotFound if a single frame is missing or a
+// *FramesNotFoundError listing every missing frame if several are missing.
+// Read calls are skipped if opt.TreatMissingAsEmpty is set.
+func (e *Executor) checkFramesExist(index string, calls []*pql.Call, opt *ExecOptions) error {
+	// Missing indexes are reported during execution.
+	idx := e.Holder.Index(index)
+	if idx == nil {
+		return nil
+	}
 
-		idx := e.Holder.Index(index)
-		if idx != nil {
-			columnLabel := idx.ColumnLabel()
-			if columnID, ok, err := c.UintArg(columnLabel); ok && err == nil {
-				attrs, err := idx.ColumnAttrStore().Attrs(columnID)
-				if err != nil {
-					return nil, err
-				}
-				bm.Attrs = attrs
-			} else if err != nil {
-				return nil, err
-			} else {
-				frame, _ := c.Args["frame"].(string)
-				if fr := idx.Frame(frame); fr != nil {
-					rowLabel := fr.RowLabel()
-					rowID, _, err := c.UintArg(rowLabel)
-					if err != nil {
-						return nil, err
-					}
-					attrs, err := fr.RowAttrStore().Attrs(rowID)
-					if err != nil {
-						return nil, err
-					}
-					bm.Attrs = attrs
-				}
+	var missing []string
+	m := make(map[string]struct{})
+	for i, call := range calls {
+		opt.warnings.setCall(i)
+		skipMissing := opt.TreatMissingAsEmpty && isReadCall(call)
+		for _, frame := range referencedFrames(call, idx.DefaultFrame()) {
+			if idx.Frame(frame) != nil {
+				continue
+			}
+
+			// Missing frames read as empty are reported as warnings.
+			if skipMissing {
+				opt.warnings.add(WarningMissingFrame, fmt.Sprintf("frame not found, read as empty: %s", frame), map[string]interface{}{"frame": frame})
+				continue
 			}
+
+			if _, ok := m[frame]; ok {
+				continue
+			}
+			m[frame] = struct{}{}
+			missing = append(missing, frame)
 		}
 	}
 
-	return bm, nil
-}
-
-// executeBitmapCallSlice executes a bitmap call for a single slice.
-func (e *Executor) executeBitmapCallSlice(ctx context.Context, index string, c *pql.Call, slice uint64) (*Bitmap, error) {
-	switch c.Name {
-	case "Bitmap":
-		return e.executeBitmapSlice(ctx, index, c, slice)
-	case "Difference":
-		return e.executeDifferenceSlice(ctx, index, c, slice)
-	case "Intersect":
-		return e.executeIntersectSlice(ctx, index, c, slice)
-	case "Range":
-		return e.executeRangeSlice(ctx, index, c, slice)
-	case "Union":
-		return e.executeUnionSlice(ctx, index, c, slice)
+	switch len(missing) {
+	case 0:
+		return nil
+	case 1:
+		return ErrFrameNotFound
 	default:
-		return nil, fmt.Errorf("unknown call: %s", c.Name)
+		sort.Strings(missing)
+		return &FramesNotFoundError{Frames: missing}
 	}
 }
 
-// executeTopN executes a TopN() call.
-// This first performs the TopN() to determine the top results and then
-// requeries to retrieve the full counts for each of the top results.
-func (e *Executor) executeTopN(ctx context.Context, index string, c *pql.Call, slices []uint64, opt *ExecOptions) ([]Pair, error) {
-	rowIDs, _, err := c.UintSliceArg("ids")
-	if err != nil {
-		return nil, fmt.Errorf("executeTopN: %v", err)
-	}
-	n, _, err := c.UintArg("n")
-	if err != nil {
-		return nil, fmt.Errorf("executeTopN: %v", err)
-	}
+// FramesNotFoundError is returned when a query references multiple frames
+// which do not exist.
+type FramesNotFoundError struct {
+	Frames []string
+}
 
-	// Execute original query.
-	pairs, err := e.executeTopNSlices(ctx, index, c, slices, opt)
-	if err != nil {
-		return nil, err
-	}
+// Error returns the names of the missing frames.
+func (e *FramesNotFoundError) Error() string {
+	return fmt.Sprintf("frames not found: %s", strings.Join(e.Frames, ", "))
+}
 
-	// If this call is against specific ids, or we didn't get results,
-	// or we are part of a larger distributed query then don't refetch.
-	if len(pairs) == 0 || len(rowIDs) > 0 || opt.Remote {
-		return pairs, nil
+// authorizeCall authorizes access to every frame referenced by c.
+func (e *Executor) authorizeCall(ctx context.Context, index string, c *pql.Call) error {
+	op := AuthorizeRead
+	if !isReadCall(c) {
+		op = AuthorizeWrite
 	}
-	// Only the original caller should refetch the full counts.
-	other := c.Clone()
-
-	ids := Pairs(pairs).Keys()
-	sort.Sort(uint64Slice(ids))
-	other.Args["ids"] = ids
 
-	trimmedList, err := e.executeTopNSlices(ctx, index, other, slices, opt)
-	if err != nil {
-		return nil, err
+	for _, frame := range referencedFrames(c, e.defaultFrame(index)) {
+		if err := e.Authorizer.Authorize(ctx, index, frame, op); err != nil {
+			return &AuthorizationError{Index: index, Frame: frame, Op: op, Err: err}
+		}
 	}
+	return nil
+}
 
-	if n != 0 && int(n) < len(trimmedList) {
-		trimmedList = trimmedList[0:n]
-	}
-	return trimmedList, nil
+// Authorizer authorizes queries to access frames.
+type Authorizer interface {
+	// Authorize returns an error if the query with ctx may not perform op
+	// against the frame. Tenant information should be read from ctx.
+	Authorize(ctx context.Context, index, frame, op string) error
 }
 
-func (e *Executor) executeTopNSlices(ctx context.Context, index string, c *pql.Call, slices []uint64, opt *ExecOptions) ([]Pair, error) {
-	// Execute calls in bulk on each remote node and merge.
-	mapFn := func(slice uint64) (interface{}, error) {
-		return e.executeTopNSlice(ctx, index, c, slice)
-	}
+// Operations passed to Authorizer.Authorize().
+const (
+	AuthorizeRead  = "read"
+	AuthorizeWrite = "write"
+)
 
-	// Merge returned results at coordinating node.
-	reduceFn := func(prev, v interface{}) interface{} {
-		other, _ := prev.([]Pair)
-		return Pairs(other).Add(v.([]Pair))
-	}
+// AuthorizationError is returned when an Authorizer denies access to a frame.
+type AuthorizationError struct {
+	Index string
+	Frame string
+	Op    string
+	Err   error
+}
 
-	other, err := e.mapReduce(ctx, index, slices, c, opt, mapFn, reduceFn)
-	if err != nil {
-		return nil, err
-	}
-	results, _ := other.([]Pair)
+// Error returns the denied operation along with the authorizer's reason.
+func (e *AuthorizationError) Error() string {
+	return fmt.Sprintf("not authorized to %s frame %q in index %q: %s", e.Op, e.Frame, e.Index, e.Err)
+}
 
-	// Sort final merged results.
-	sort.Sort(Pairs(results))
+// KeyTranslator translates between string keys and row & column ids.
+// Row keys are translated within their frame and column keys are passed
+// with a blank frame.
+type KeyTranslator interface {
+	// TranslateKey returns the id for key.
+	TranslateKey(index, frame, key string) (uint64, error)
 
-	return results, nil
+	// TranslateID returns the key for id.
+	TranslateID(index, frame string, id uint64) (string, error)
 }
 
-// executeTopNSlice executes a TopN call for a single slice.
-func (e *Executor) executeTopNSlice(ctx context.Context, index string, c *pql.Call, slice uint64) ([]Pair, error) {
-	frame, _ := c.Args["frame"].(string)
-	n, _, err := c.UintArg("n")
-	if err != nil {
-		return nil, fmt.Errorf("executeTopNSlice: %v", err)
-	}
-	field, _ := c.Args["field"].(string)
-	rowIDs, _, err := c.UintSliceArg("ids")
-	if err != nil {
-		return nil, fmt.Errorf("executeTopNSlice: %v", err)
-	}
-	minThreshold, _, err := c.UintArg("threshold")
-	if err != nil {
-		return nil, fmt.Errorf("executeTopNSlice: %v", err)
-	}
-	filters, _ := c.Args["filters"].([]interface{})
-	tanimotoThreshold, _, err := c.UintArg("tanimotoThreshold")
-	if err != nil {
-		return nil, fmt.Errorf("executeTopNSlice: %v", err)
+// translateCall returns a copy of c with string row & column keys in
+// SetBit(), ClearBit() & Bitmap() calls replaced by their ids.
+func (e *Executor) translateCall(index string, c *pql.Call) (*pql.Call, error) {
+	other := c.Clone()
+	if err := e.translateCallKeys(index, other); err != nil {
+		return nil, err
 	}
+	return other, nil
+}
 
-	// Retrieve bitmap used to intersect.
-	var src *Bitmap
-	if len(c.Children) == 1 {
-		bm, err := e.executeBitmapCallSlice(ctx, index, c.Children[0], slice)
-		if err != nil {
-			return nil, err
+func (e *Executor) translateCallKeys(index string, c *pql.Call) error {
+	for _, child := range c.Children {
+		if err := e.translateCallKeys(index, child); err != nil {
+			return err
 		}
-		src = bm
-	} else if len(c.Children) > 1 {
-		return nil, errors.New("TopN() can only have one input bitmap")
 	}
 
-	// Set default frame.
-	if frame == "" {
-		frame = DefaultFrame
+	switch c.Name {
+	case "SetBit", "ClearBit", "Bitmap":
+	default:
+		return nil
 	}
 
-	f := e.Holder.Fragment(index, frame, ViewStandard, slice)
+	// Missing indexes & frames are reported during execution.
+	idx := e.Holder.Index(index)
+	if idx == nil {
+		return nil
+	}
+	frame, _ := c.Args["frame"].(string)
+	if frame == "" {
+		frame = idx.DefaultFrame()
+	}
+	f := idx.Frame(frame)
 	if f == nil {
-		return nil, nil
+		return nil
 	}
 
-	if minThreshold <= 0 {
-		minThreshold = MinThreshold
+	translate := func(label, frame string) error {
+		key, ok := c.Args[label].(string)
+		if !ok {
+			return nil
+		}
+		id, err := e.KeyTranslator.TranslateKey(index, frame, key)
+		if err != nil {
+			return fmt.Errorf("%s() cannot translate %s key %q: %v", c.Name, label, key, err)
+		}
+		c.Args[label] = id
+		return nil
 	}
 
-	if tanimotoThreshold > 100 {
-		return nil, errors.New("Tanimoto Threshold is from 1 to 100 only")
+	if err := translate(f.RowLabel(), frame); err != nil {
+		return err
 	}
-	return f.Top(TopOptions{
-		N:                 int(n),
-		Src:               src,
-		RowIDs:            rowIDs,
-		FilterField:       field,
-		FilterValues:      filters,
-		MinThreshold:      minThreshold,
-		TanimotoThreshold: tanimotoThreshold,
-	})
+	columnLabel := idx.ColumnLabel()
+	if label, ok := c.Args[columnLabelArg].(string); ok {
+		columnLabel = label
+	}
+	return translate(columnLabel, "")
 }
 
-// executeDifferenceSlice executes a difference() call for a local slice.
-func (e *Executor) executeDifferenceSlice(ctx context.Context, index string, c *pql.Call, slice uint64) (*Bitmap, error) {
-	var other *Bitmap
-	if len(c.Children) == 0 {
-		return nil, fmt.Errorf("empty Difference query is currently not supported")
-	}
-	for i, input := range c.Children {
-		bm, err := e.executeBitmapCallSlice(ctx, index, input, slice)
+// translateResult attaches keys for the ids in v, the result of c.
+// Bitmap results contain column ids unless c reads from the inverse view,
+// in which case they contain row ids of the frame c reads.
+func (e *Executor) translateResult(index string, c *pql.Call, v interface{}) error {
+	switch v := v.(type) {
+	case *BitmapResult:
+		return e.translateResult(index, c, v.Bitmap)
+	case *Bitmap:
+		if v.Count() > uint64(e.MaxTranslateIDsN) {
+			return ErrTooManyTranslateIDs
+		}
+
+		// Determine whether bits are column or row ids.
+		var frame string
+		if idx := e.Holder.Index(index); idx != nil {
+			standard, inverse, err := e.callOrientation(index, c, idx.ColumnLabel())
+			if err != nil {
+				return err
+			} else if inverse && !standard {
+				frames := referencedFrames(c, idx.DefaultFrame())
+				if len(frames) != 1 {
+					return fmt.Errorf("%s() cannot translate row ids from multiple frames", c.Name)
+				}
+				frame = frames[0]
+			}
+		}
+
+		bits := v.Bits()
+		keys := make([]string, len(bits))
+		for i, id := range bits {
+			key, err := e.KeyTranslator.TranslateID(index, frame, id)
+			if err != nil {
+				return fmt.Errorf("%s() cannot translate id %d: %v", c.Name, id, err)
+			}
+			keys[i] = key
+		}
+		v.Keys = keys
+	case *ColumnResult:
+		if v.Empty {
+			return nil
+		}
+		key, err := e.KeyTranslator.TranslateID(index, "", v.Column)
 		if err != nil {
-			return nil, err
+			return fmt.Errorf("%s() cannot translate id %d: %v", c.Name, v.Column, err)
+		}
+		v.Key = key
+	case []Pair:
+		if len(v) > e.MaxTranslateIDsN {
+			return ErrTooManyTranslateIDs
 		}
 
-		if i == 0 {
-			other = bm
-		} else {
-			other = other.Difference(bm)
+		// ColumnDegree(), TopColumns() and inverse TopN() pairs hold column ids.
+		var frame string
+		if c.Name != "ColumnDegree" && c.Name != "TopColumns" && c.Args["view"] != ViewInverse {
+			var err error
+			if frame, _, err = readTopNArgs(c, e.defaultFrame(index)); err != nil {
+				return err
+			}
+		}
+		for i := range v {
+			key, err := e.KeyTranslator.TranslateID(index, frame, v[i].ID)
+			if err != nil {
+				return fmt.Errorf("%s() cannot translate id %d: %v", c.Name, v[i].ID, err)
+			}
+			v[i].Key = key
 		}
 	}
-	other.InvalidateCount()
-	return other, nil
+	return nil
 }
 
-func (e *Executor) executeBitmapSlice(ctx context.Context, index string, c *pql.Call, slice uint64) (*Bitmap, error) {
-	// Fetch column label from index.
-	idx := e.Holder.Index(index)
-	if idx == nil {
-		return nil, ErrIndexNotFound
+// defaultFrame returns the frame used by calls against index which do not specify one.
+func (e *Executor) defaultFrame(index string) string {
+	if idx := e.Holder.Index(index); idx != nil {
+		return idx.DefaultFrame()
 	}
-	columnLabel := idx.ColumnLabel()
+	return DefaultFrame
+}
 
-	// Fetch frame & row label based on argument.
-	frame, _ := c.Args["frame"].(string)
-	if frame == "" {
-		frame = DefaultFrame
+// acquireQuerySlot reserves one of the index's concurrent query slots.
+// The returned function must be called to release the slot.
+func (e *Executor) acquireQuerySlot(ctx context.Context, index string) (func(), error) {
+	if e.MaxConcurrentQueries <= 0 {
+		return func() {}, nil
 	}
-	f := e.Holder.Frame(index, frame)
-	if f == nil {
-		return nil, ErrFrameNotFound
-	}
-	rowLabel := f.RowLabel()
 
-	// Return an error if both the row and column label are specified.
-	rowID, rowOK, rowErr := c.UintArg(rowLabel)
-	columnID, columnOK, columnErr := c.UintArg(columnLabel)
-	if rowErr != nil || columnErr != nil {
-		return nil, fmt.Errorf("Bitmap() error with arg for col: %v or row: %v", columnErr, rowErr)
+	// Lazily create the semaphore for the index.
+	e.mu.Lock()
+	if e.querySlots == nil {
+		e.querySlots = make(map[string]chan struct{})
 	}
-	if rowOK && columnOK {
-		return nil, fmt.Errorf("Bitmap() cannot specify both %s and %s values", rowLabel, columnLabel)
-	} else if !rowOK && !columnOK {
-		return nil, fmt.Errorf("Bitmap() must specify either %s or %s values", rowLabel, columnLabel)
+	slots := e.querySlots[index]
+	if slots == nil {
+		slots = make(chan struct{}, e.MaxConcurrentQueries)
+		e.querySlots[index] = slots
 	}
+	e.mu.Unlock()
 
-	// Determine row or column orientation.
-	view, id := ViewStandard, rowID
-	if columnOK {
-		view, id = ViewInverse, columnID
-		if !f.InverseEnabled() {
-			return nil, fmt.Errorf("Bitmap() cannot retrieve columns unless inverse storage enabled")
+	release := func() { <-slots }
+
+	if e.RejectTooManyQueries {
+		select {
+		case slots <- struct{}{}:
+			return release, nil
+		default:
+			return nil, ErrTooManyQueries
 		}
 	}
 
-	frag := e.Holder.Fragment(index, frame, view, slice)
-	if frag == nil {
-		return NewBitmap(), nil
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case slots <- struct{}{}:
+		return release, nil
 	}
-	return frag.Row(id), nil
 }
 
-// executeIntersectSlice executes a intersect() call for a local slice.
-func (e *Executor) executeIntersectSlice(ctx context.Context, index string, c *pql.Call, slice uint64) (*Bitmap, error) {
-	var other *Bitmap
-	if len(c.Children) == 0 {
-		return nil, fmt.Errorf("empty Intersect query is currently not supported")
-	}
-	for i, input := range c.Children {
-		bm, err := e.executeBitmapCallSlice(ctx, index, input, slice)
-		if err != nil {
-			return nil, err
+// callOrientation returns whether c and its children read from standard or
+// inverse views. Bitmap() calls using the column label read from the inverse
+// view and all other calls read from the standard view.
+func (e *Executor) callOrientation(index string, c *pql.Call, columnLabel string) (standard, inverse bool, err error) {
+	if c.SupportsInverse() {
+		// Fetch frame & row label based on argument.
+		frame, _ := c.Args["frame"].(string)
+		if frame == "" {
+			frame = e.defaultFrame(index)
 		}
-
-		if i == 0 {
-			other = bm
-		} else {
-			other = other.Intersect(bm)
+		f := e.Holder.Frame(index, frame)
+		if f == nil {
+			return false, false, ErrFrameNotFound
 		}
-	}
-	other.InvalidateCount()
-	return other, nil
-}
 
-// executeRangeSlice executes a range() call for a local slice.
-func (e *Executor) executeRangeSlice(ctx context.Context, index string, c *pql.Call, slice uint64) (*Bitmap, error) {
-	// Parse frame, use default if unset.
-	frame, _ := c.Args["frame"].(string)
-	if frame == "" {
-		frame = DefaultFrame
+		if c.IsInverse(f.RowLabel(), columnLabel) {
+			return false, true, nil
+		}
+		return true, false, nil
 	}
 
-	// Retrieve base frame.
-	f := e.Holder.Frame(index, frame)
-	if f == nil {
-		return nil, ErrFrameNotFound
+	// Range() calls read from the inverse view only if it is targeted.
+	if c.Name == "Range" {
+		if view, _ := c.Args["view"].(string); view == ViewInverse {
+			return false, true, nil
+		}
+		return true, false, nil
 	}
-	rowLabel := f.RowLabel()
 
-	// Read row id.
-	rowID, _, err := c.UintArg(rowLabel) // TODO: why are we ignoring missing rowID?
-	if err != nil {
-		return nil, fmt.Errorf("executeRangeSlice - reading row: %v", err)
+	// Calls without bitmap inputs and TopN() read from standard views,
+	// unless TopN() ranks the inverse view.
+	if c.Name == "TopN" && c.Args["view"] == ViewInverse {
+		inverse = true
+	} else if len(c.Children) == 0 || c.Name == "TopN" {
+		standard = true
 	}
 
-	// Parse start time.
-	startTimeStr, ok := c.Args["start"].(string)
-	if !ok {
-		return nil, errors.New("Range() start time required")
-	}
-	startTime, err := time.Parse(TimeFormat, startTimeStr)
-	if err != nil {
-		return nil, errors.New("cannot parse Range() start time")
+	for _, child := range c.Children {
+		s, i, err := e.callOrientation(index, child, columnLabel)
+		if err != nil {
+			return false, false, err
+		}
+		standard, inverse = standard || s, inverse || i
 	}
+	return standard, inverse, nil
+}
 
-	// Parse end time.
-	endTimeStr, _ := c.Args["end"].(string)
-	if !ok {
-		return nil, errors.New("Range() end time required")
-	}
-	endTime, err := time.Parse(TimeFormat, endTimeStr)
-	if err != nil {
-		return nil, errors.New("cannot parse Range() end time")
-	}
+// OptimizeCall returns a simplified copy of c which produces the same result.
+// Nested Union() and Intersect() calls are merged into a parent of the same
+// type, as is a Difference() nested as the first input of a Difference().
+// Empty Union() inputs are removed from unions and from the subtracted
+// inputs of a difference.
+func OptimizeCall(c *pql.Call) *pql.Call {
+	other := c.Clone()
+	optimizeCall(other)
+	return other
+}
 
-	// If no quantum exists then return an empty bitmap.
-	q := f.TimeQuantum()
-	if q == "" {
-		return &Bitmap{}, nil
+// optimizeCall simplifies c and its children in place.
+func optimizeCall(c *pql.Call) {
+	for _, child := range c.Children {
+		optimizeCall(child)
 	}
 
-	// Union bitmaps across all time-based subframes.
-	bm := &Bitmap{}
-	for _, view := range ViewsByTimeRange(ViewStandard, startTime, endTime, q) {
-		f := e.Holder.Fragment(index, frame, view, slice)
-		if f == nil {
-			continue
+	switch c.Name {
+	case "Union", "Intersect":
+		children := make([]*pql.Call, 0, len(c.Children))
+		for _, child := range c.Children {
+			if c.Name == "Union" && isEmptyUnion(child) {
+				continue
+			} else if child.Name == c.Name && len(child.Args) == 0 && len(child.Children) > 0 {
+				children = append(children, child.Children...)
+				continue
+			}
+			children = append(children, child)
 		}
-		bm = bm.Union(f.Row(rowID))
-	}
-	return bm, nil
-}
+		c.Children = children
 
-// executeUnionSlice executes a union() call for a local slice.
-func (e *Executor) executeUnionSlice(ctx context.Context, index string, c *pql.Call, slice uint64) (*Bitmap, error) {
-	other := NewBitmap()
-	for i, input := range c.Children {
-		bm, err := e.executeBitmapCallSlice(ctx, index, input, slice)
-		if err != nil {
-			return nil, err
+	case "Difference":
+		if len(c.Children) == 0 {
+			return
 		}
 
-		if i == 0 {
-			other = bm
+		// Merge a nested difference in the first input.
+		var children []*pql.Call
+		if first := c.Children[0]; first.Name == "Difference" && len(first.Args) == 0 && len(first.Children) > 0 {
+			children = append(children, first.Children...)
 		} else {
-			other = other.Union(bm)
+			children = append(children, first)
+		}
+
+		// Subtracting an empty bitmap is a no-op.
+		for _, child := range c.Children[1:] {
+			if !isEmptyUnion(child) {
+				children = append(children, child)
+			}
 		}
+		c.Children = children
 	}
-	other.InvalidateCount()
-	return other, nil
 }
 
-// executeCount executes a count() call.
-func (e *Executor) executeCount(ctx context.Context, index string, c *pql.Call, slices []uint64, opt *ExecOptions) (uint64, error) {
-	if len(c.Children) == 0 {
-		return 0, errors.New("Count() requires an input bitmap")
-	} else if len(c.Children) > 1 {
-		return 0, errors.New("Count() only accepts a single bitmap input")
+// isEmptyUnion returns true if c is a Union() with no inputs.
+func isEmptyUnion(c *pql.Call) bool {
+	return c.Name == "Union" && len(c.Args) == 0 && len(c.Children) == 0
+}
+
+// now returns the current time from e.Now, if set, in UTC.
+func (e *Executor) now() time.Time {
+	if e.Now != nil {
+		return e.Now().UTC()
 	}
+	return time.Now().UTC()
+}
 
-	// Execute calls in bulk on each remote node and merge.
-	mapFn := func(slice uint64) (interface{}, error) {
-		bm, err := e.executeBitmapCallSlice(ctx, index, c.Children[0], slice)
-		if err != nil {
-			return 0, err
-		}
-		return bm.Count(), nil
+// checkCallDepth returns an error if any call in q is nested deeper than
+// MaxCallDepth. The walk stops at the limit so it is bounded regardless of
+// the query's depth.
+func (e *Executor) checkCallDepth(q *pql.Query) error {
+	if e.MaxCallDepth <= 0 {
+		return nil
+	}
+
+	var walk func(c *pql.Call, depth int) bool
+	walk = func(c *pql.Call, depth int) bool {
+		if depth > e.MaxCallDepth {
+			return false
+		}
+		for _, child := range c.Children {
+			if !walk(child, depth+1) {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, call := range q.Calls {
+		if !walk(call, 1) {
+			return fmt.Errorf("%s() call exceeds the maximum nesting depth of %d", call.Name, e.MaxCallDepth)
+		}
+	}
+	return nil
+}
+
+// frameParentCalls are the calls whose frame argument is inherited by their
+// children instead of being read by the call itself.
+var frameParentCalls = map[string]struct{}{
+	"Difference": {},
+	"Intersect":  {},
+	"Shift":      {},
+	"Union":      {},
+}
+
+// frameLeafCalls are the calls which read a row from their frame argument.
+var frameLeafCalls = map[string]struct{}{
+	"Bitmap":   {},
+	"Range":    {},
+	"RowRange": {},
+}
+
+// inheritFrames returns a copy of c where the frame set on a Union(),
+// Intersect(), Difference() or Shift() call is moved to the children which
+// do not set their own, recursively. Children may still override the frame.
+// Returns c if no call sets an inheritable frame.
+func inheritFrames(c *pql.Call) *pql.Call {
+	var inherit func(c *pql.Call, frame string) bool
+	inherit = func(c *pql.Call, frame string) bool {
+		var changed bool
+		if _, ok := frameParentCalls[c.Name]; ok {
+			if v, ok := c.Args["frame"].(string); ok {
+				frame = v
+				delete(c.Args, "frame")
+				changed = true
+			}
+		} else {
+			if _, ok := frameLeafCalls[c.Name]; ok && frame != "" {
+				if _, ok := c.Args["frame"]; !ok {
+					if c.Args == nil {
+						c.Args = make(map[string]interface{})
+					}
+					c.Args["frame"] = frame
+				}
+			}
+
+			// Other calls, such as TopN() filters, do not inherit.
+			frame = ""
+		}
+
+		for _, child := range c.Children {
+			if inherit(child, frame) {
+				changed = true
+			}
+		}
+		return changed
+	}
+
+	other := c.Clone()
+	if !inherit(other, "") {
+		return c
+	}
+	return other
+}
+
+// inheritQueryFrames returns q with inheritFrames() applied to every call.
+// Returns q if no call changed.
+func inheritQueryFrames(q *pql.Query) *pql.Query {
+	var other *pql.Query
+	for i, call := range q.Calls {
+		v := inheritFrames(call)
+		if v == call {
+			continue
+		}
+		if other == nil {
+			other = &pql.Query{Calls: make([]*pql.Call, len(q.Calls))}
+			copy(other.Calls, q.Calls)
+		}
+		other.Calls[i] = v
+	}
+
+	if other == nil {
+		return q
+	}
+	return other
+}
+
+// resolveRangeTimes returns a copy of c with the relative start & end times
+// of Range() calls replaced by absolute times relative to now. Returns c if
+// it has no relative times.
+func resolveRangeTimes(c *pql.Call, now time.Time) *pql.Call {
+	var resolve func(c *pql.Call) bool
+	resolve = func(c *pql.Call) bool {
+		var changed bool
+		if c.Name == "Range" {
+			for _, key := range []string{"start", "end"} {
+				s, _ := c.Args[key].(string)
+				if t, ok := ParseRelativeTime(s, now); ok {
+					c.Args[key] = t.Format(TimeFormat)
+					changed = true
+				}
+			}
+		}
+		for _, child := range c.Children {
+			if resolve(child) {
+				changed = true
+			}
+		}
+		return changed
+	}
+
+	other := c.Clone()
+	if !resolve(other) {
+		return c
+	}
+	return other
+}
+
+// checkRangeQuantums returns an error if a Range() call within c reads a
+// frame without a time quantum, since no time views exist to match. Missing
+// frames are left to be handled during execution.
+func (e *Executor) checkRangeQuantums(index string, c *pql.Call) error {
+	if c.Name == "Range" {
+		args, err := e.readRangeArgs(index, c)
+		if err == ErrFrameNotFound {
+			return nil
+		} else if err != nil {
+			return err
+		} else if args.frame.TimeQuantum() == "" {
+			return fmt.Errorf("Range() requires a time quantum on frame %s", args.frame.Name())
+		}
+	}
+	for _, child := range c.Children {
+		if err := e.checkRangeQuantums(index, child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// executeCall executes a call.
+func (e *Executor) executeCall(ctx context.Context, index string, c *pql.Call, slices []uint64, opt *ExecOptions) (interface{}, error) {
+
+	if err := e.validateCallArgs(c); err != nil {
+		return nil, err
+	} else if err := validateShiftInputs(c); err != nil {
+		return nil, err
+	}
+
+	// Special handling for mutation and top-n calls.
+	switch c.Name {
+	case "ClearBit":
+		return e.executeClearBit(ctx, index, c, opt)
+	case "ClearBits", "SetBits":
+		return e.executeBits(ctx, index, c, opt)
+	case "ClearMatching":
+		return e.executeClearMatching(ctx, index, c, slices, opt)
+	case "ColumnDegree":
+		return e.executeColumnDegree(ctx, index, c, slices, opt)
+	case "TopColumns":
+		return e.executeTopColumns(ctx, index, c, slices, opt)
+	case "Count":
+		n, err := e.executeCount(ctx, index, c, slices, opt)
+		if err != nil {
+			return nil, err
+		} else if opt.IncludeUniverse && !opt.Remote {
+			return e.executeCountUniverse(ctx, index, c, n, slices, opt)
+		}
+		return n, nil
+	case "CountColumns":
+		return e.executeCountColumns(ctx, index, c, slices, opt)
+	case "MaxColumn", "MinColumn":
+		return e.executeColumnBound(ctx, index, c, slices, opt)
+	case "Page":
+		return e.executePage(ctx, index, c, slices, opt)
+	case "Rows":
+		return e.executeRows(ctx, index, c, slices, opt)
+	case "SetBit":
+		ret, err := e.executeSetBit(ctx, index, c, opt)
+		if err != nil {
+			return false, err
+		} else if opt.ChangedByView {
+			return ret, nil
+		}
+		return ret.Changed(), nil
+	case "SetRowAttrs":
+		return nil, e.executeSetRowAttrs(ctx, index, c, opt)
+	case "SetRowFromRoaring":
+		return nil, e.executeSetRowFromRoaring(ctx, index, c, opt)
+	case "SetColumnAttrs":
+		return nil, e.executeSetColumnAttrs(ctx, index, c, opt)
+	case "TopN":
+		return e.executeTopN(ctx, index, c, slices, opt)
+	case "Warm":
+		return nil, e.executeWarm(ctx, index, c, slices, opt)
+	default:
+		if agg := e.aggregation(c.Name); agg != nil {
+			return e.executeAggregation(ctx, index, c, agg, slices, opt)
+		}
+
+		bm, err := e.executeBitmapCall(ctx, index, c, slices, opt)
+		if err != nil {
+			return nil, err
+		} else if opt.RawRoaring && !opt.Remote {
+			return bm.MarshalRoaring()
+		} else if opt.IncludeCount && !opt.Remote {
+			return &BitmapResult{Bitmap: bm, Count: bm.Count()}, nil
+		}
+		return bm, nil
+	}
+}
+
+// BitmapResult represents a bitmap call result along with its column count.
+type BitmapResult struct {
+	Bitmap *Bitmap
+	Count  uint64
+}
+
+// MarshalJSON returns a JSON-encoded byte slice of r.
+func (r *BitmapResult) MarshalJSON() ([]byte, error) {
+	var o struct {
+		Attrs          map[string]interface{} `json:"attrs"`
+		Bits           []uint64               `json:"bits"`
+		ColumnAttrSets []*ColumnAttrSet       `json:"columnAttrs,omitempty"`
+		Keys           []string               `json:"keys,omitempty"`
+		Count          uint64                 `json:"count"`
+	}
+	o.Bits = r.Bitmap.Bits()
+	o.ColumnAttrSets = r.Bitmap.ColumnAttrSets
+	o.Keys = r.Bitmap.Keys
+	o.Count = r.Count
+
+	o.Attrs = r.Bitmap.Attrs
+	if o.Attrs == nil {
+		o.Attrs = make(map[string]interface{})
+	}
+
+	return json.Marshal(&o)
+}
+
+// ChecksumResult represents a call result along with a hash of its contents.
+type ChecksumResult struct {
+	Result   interface{}
+	Checksum string
+}
+
+// MarshalJSON returns a JSON-encoded byte slice of r.
+func (r *ChecksumResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Result   interface{} `json:"result"`
+		Checksum string      `json:"checksum"`
+	}{Result: r.Result, Checksum: r.Checksum})
+}
+
+// checksumResult wraps bitmap and pair results in a *ChecksumResult. The
+// hash covers the sorted columns of a bitmap, or the pairs sorted by id, so
+// it does not depend on the order slices were merged in.
+func checksumResult(v interface{}) interface{} {
+	var bm *Bitmap
+	var pairs []Pair
+	switch v := v.(type) {
+	case *Bitmap:
+		bm = v
+	case *BitmapResult:
+		bm = v.Bitmap
+	case []Pair:
+		pairs = make([]Pair, len(v))
+		copy(pairs, v)
+		sort.Sort(pairsByID(pairs))
+	default:
+		return v
+	}
+
+	h := sha1.New()
+	var buf [16]byte
+	if bm != nil {
+		for _, col := range bm.Bits() {
+			binary.BigEndian.PutUint64(buf[:8], col)
+			h.Write(buf[:8])
+		}
+	} else {
+		for _, p := range pairs {
+			binary.BigEndian.PutUint64(buf[:8], p.ID)
+			binary.BigEndian.PutUint64(buf[8:], p.Count)
+			h.Write(buf[:])
+		}
+	}
+	return &ChecksumResult{Result: v, Checksum: hex.EncodeToString(h.Sum(nil))}
+}
+
+// Validate checks every call in q without executing it. Frames and labels
+// are resolved and required arguments are parsed using the same logic as
+// execution. Returns the first invalid call as a *ValidationError.
+func (e *Executor) Validate(ctx context.Context, index string, q *pql.Query) error {
+	if e.Holder.Index(index) == nil {
+		return ErrIndexNotFound
+	} else if err := e.checkCallDepth(q); err != nil {
+		return err
+	}
+	for _, call := range q.Calls {
+		call = inheritFrames(call)
+		if err := e.validateCall(index, call, false); err != nil {
+			return err
+		} else if err := validateShiftInputs(call); err != nil {
+			return &ValidationError{Call: call, Err: err}
+		}
+	}
+	return nil
+}
+
+// EstimateCost returns the estimated work to execute q without executing it.
+// Each call costs one unit per row read from each slice it touches, plus
+// the ranked rows scanned by TopN() calls. Ranked row counts are read from
+// local fragment caches, falling back to the frame's cache size. The estimate
+// is not exact but grows with the slices and rows a query reads.
+func (e *Executor) EstimateCost(ctx context.Context, index string, q *pql.Query, slices []uint64) (*QueryCost, error) {
+	idx := e.Holder.Index(index)
+	if idx == nil {
+		return nil, ErrIndexNotFound
+	}
+	if err := e.Validate(ctx, index, q); err != nil {
+		return nil, err
+	}
+
+	var cost QueryCost
+	for _, call := range q.Calls {
+		call = inheritFrames(call)
+
+		// Mutations only touch the slice of their column.
+		var callCost CallCost
+		if !isReadCall(call) {
+			callCost = CallCost{SliceN: 1, Cost: 1}
+		} else {
+			callSlices := slices
+			if len(callSlices) == 0 {
+				var err error
+				if callSlices, err = e.callSlices(idx, call); err != nil {
+					return nil, err
+				}
+			}
+
+			callCost.SliceN = len(callSlices)
+			for _, slice := range callSlices {
+				n, err := e.estimateCallSliceCost(index, call, slice)
+				if err != nil {
+					return nil, err
+				}
+				callCost.Cost += n
+			}
+		}
+
+		cost.Calls = append(cost.Calls, callCost)
+		cost.Total += callCost.Cost
+	}
+	return &cost, nil
+}
+
+// callSlices returns every slice a read call touches: all standard slices of
+// idx, or all inverse slices if the call reads from inverse views and the
+// index has more of them.
+func (e *Executor) callSlices(idx *Index, call *pql.Call) ([]uint64, error) {
+	n := idx.MaxSlice()
+	if _, inverse, err := e.callOrientation(idx.Name(), call, idx.ColumnLabel()); err != nil {
+		return nil, err
+	} else if inverse && idx.MaxInverseSlice() > n {
+		n = idx.MaxInverseSlice()
+	}
+
+	slices := make([]uint64, n+1)
+	for i := range slices {
+		slices[i] = uint64(i)
+	}
+	return slices, nil
+}
+
+// Warm loads the local fragments read by q into memory on the nodes owning
+// them without executing q, so that a later execution of q does not wait on
+// disk. Each read call is planned as it would be executed: its frames are
+// authorized and its slices are mapped to their owners, which warm their
+// slices concurrently. If slices is empty then each call's slices are used.
+// Mutations are skipped.
+func (e *Executor) Warm(ctx context.Context, index string, q *pql.Query, slices []uint64) error {
+	idx := e.Holder.Index(index)
+	if idx == nil {
+		return ErrIndexNotFound
+	}
+	q, err := e.expandColumnBoundCalls(index, q)
+	if err != nil {
+		return err
+	}
+
+	for _, call := range q.Calls {
+		if !isReadCall(call) {
+			continue
+		}
+
+		frames := referencedFrames(call, idx.DefaultFrame())
+		if len(frames) == 0 {
+			continue
+		}
+		names := make([]interface{}, len(frames))
+		for i, frame := range frames {
+			names[i] = frame
+		}
+
+		callSlices := slices
+		if len(callSlices) == 0 {
+			if callSlices, err = e.callSlices(idx, call); err != nil {
+				return err
+			}
+		}
+
+		warm := &pql.Call{Name: "Warm", Args: map[string]interface{}{"frames": names}}
+		if _, err := e.Execute(ctx, index, &pql.Query{Calls: []*pql.Call{warm}}, callSlices, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// executeWarm executes a Warm() call. Every local fragment of the frames
+// in each slice is loaded into memory. Nothing is returned.
+func (e *Executor) executeWarm(ctx context.Context, index string, c *pql.Call, slices []uint64, opt *ExecOptions) error {
+	frames, err := readFrameListArgs(c)
+	if err != nil {
+		return err
+	}
+
+	mapFn := func(slice uint64) (interface{}, error) {
+		for _, frame := range frames {
+			f := e.Holder.Frame(index, frame)
+			if f == nil {
+				continue
+			}
+			for _, view := range f.Views() {
+				if frag := view.Fragment(slice); frag != nil {
+					if err := frag.Warm(); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+		return nil, nil
+	}
+
+	reduceFn := func(prev, v interface{}) interface{} { return nil }
+
+	_, err = e.mapReduce(ctx, index, slices, c, opt, mapFn, reduceFn)
+	return err
+}
+
+// readFrameListArgs returns the frames of a Warm() or CountColumns() call.
+func readFrameListArgs(c *pql.Call) ([]string, error) {
+	a, ok := c.Args["frames"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s() frames required", c.Name)
+	}
+	frames := make([]string, len(a))
+	for i, name := range a {
+		name, ok := name.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid %s() frame: %v", c.Name, a[i])
+		}
+		frames[i] = name
+	}
+	return frames, nil
+}
+
+// estimateCallSliceCost returns the estimated work for c on a single slice.
+func (e *Executor) estimateCallSliceCost(index string, c *pql.Call, slice uint64) (uint64, error) {
+	var n uint64
+	for _, child := range c.Children {
+		v, err := e.estimateCallSliceCost(index, child, slice)
+		if err != nil {
+			return 0, err
+		}
+		n += v
+	}
+
+	switch c.Name {
+	case "Bitmap":
+		n++
+	case "UnionRows", "CountColumns":
+		frames, _ := c.Args["frames"].([]interface{})
+		n += uint64(len(frames))
+	case "MaxColumn", "MinColumn":
+		frames, err := e.readColumnBoundArgs(index, c)
+		if err != nil {
+			return 0, err
+		}
+		n += uint64(len(frames))
+	case "Range":
+		args, err := e.readRangeArgs(index, c)
+		if err != nil {
+			return 0, err
+		}
+		n += uint64(len(args.views))
+	case "RowRange":
+		args, err := e.readRowRangeArgs(index, c)
+		if err != nil {
+			return 0, err
+		}
+		n += args.to - args.from + 1
+	case "TopColumns":
+		args, err := e.readTopColumnsArgs(index, c)
+		if err != nil {
+			return 0, err
+		}
+		n += uint64(len(args.rowIDs))
+	case "Rows":
+		args, err := e.readRowsArgs(index, c)
+		if err != nil {
+			return 0, err
+		} else if frag := e.Holder.Fragment(index, args.frame.Name(), ViewStandard, slice); frag != nil {
+			n += uint64(frag.Cache().Len())
+		}
+	case "TopN", "ColumnDegree":
+		frame, err := e.rankedFrame(index, c)
+		if err != nil {
+			return 0, err
+		}
+		view := ViewStandard
+		if c.Name == "TopN" {
+			if view, err = e.readTopNView(index, frame, c); err != nil {
+				return 0, err
+			}
+		}
+		if frag := e.Holder.Fragment(index, frame, view, slice); frag != nil {
+			n += uint64(frag.Cache().Len())
+		} else if f := e.Holder.Frame(index, frame); f != nil {
+			n += uint64(f.CacheSize())
+		}
+	}
+	return n, nil
+}
+
+// rankedFrame returns the frame whose rows are scanned by a TopN() or
+// ColumnDegree() call.
+func (e *Executor) rankedFrame(index string, c *pql.Call) (string, error) {
+	if c.Name == "ColumnDegree" {
+		frame, _, _, err := e.readColumnDegreeArgs(index, c)
+		return frame, err
+	}
+	frame, _, err := readTopNArgs(c, e.defaultFrame(index))
+	return frame, err
+}
+
+// QueryCost represents the estimated work to execute a query.
+type QueryCost struct {
+	// Estimates for each call, in query order.
+	Calls []CallCost
+
+	// Sum of all call estimates.
+	Total uint64
+}
+
+// CallCost represents the estimated work to execute a single call.
+type CallCost struct {
+	// Number of slices the call touches.
+	SliceN int
+
+	// Estimated units of work across all slices.
+	Cost uint64
+}
+
+// Explain evaluates every bitmap call of q and its children against a sample
+// of local slices and returns the number of columns each one matches, so the
+// call where a query's result diverges can be found. Calls are traced as
+// written, without simplifying the call tree. If slices is empty then the
+// slices owned by this node are used. At most MaxExplainSlices slices
+// are evaluated. Only local fragments are read. Mutations are rejected.
+func (e *Executor) Explain(ctx context.Context, index string, q *pql.Query, slices []uint64) (*QueryExplain, error) {
+	idx := e.Holder.Index(index)
+	if idx == nil {
+		return nil, ErrIndexNotFound
+	}
+	if err := e.Validate(ctx, index, q); err != nil {
+		return nil, err
+	}
+	q, err := e.expandColumnBoundCalls(index, inheritQueryFrames(q))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(slices) == 0 {
+		maxSlice := idx.MaxSlice()
+		if idx.MaxInverseSlice() > maxSlice {
+			maxSlice = idx.MaxInverseSlice()
+		}
+		slices = e.Cluster.OwnsSlices(index, maxSlice, e.Host)
+	}
+
+	max := e.MaxExplainSlices
+	if max <= 0 {
+		max = DefaultMaxExplainSlices
+	}
+	explain := &QueryExplain{Slices: sampleSlices(slices, max)}
+
+	opt := &ExecOptions{}
+	now := e.now()
+	for _, call := range q.Calls {
+		if !isReadCall(call) {
+			return nil, fmt.Errorf("cannot explain %s() call", call.Name)
+		}
+		call = resolveRangeTimes(call, now)
+
+		ce := newCallExplain(call)
+		for _, slice := range explain.Slices {
+			if err := e.explainCallSlice(ctx, index, call, ce, slice, opt); err != nil {
+				return nil, err
+			}
+		}
+		explain.Calls = append(explain.Calls, ce)
+	}
+	return explain, nil
+}
+
+// explainCallSlice adds the columns matched by c and its children in a local
+// slice to ce, which must have been built from c by newCallExplain().
+func (e *Executor) explainCallSlice(ctx context.Context, index string, c *pql.Call, ce *CallExplain, slice uint64, opt *ExecOptions) error {
+	if _, ok := bitmapCalls[c.Name]; ok {
+		bm, err := e.executeBitmapCallSlice(ctx, index, c, slice, opt)
+		if err != nil {
+			return err
+		}
+		ce.Count += bm.Count()
+	}
+
+	for i, child := range c.Children {
+		if err := e.explainCallSlice(ctx, index, child, ce.Children[i], slice, opt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sampleSlices returns at most n slices picked at even intervals.
+func sampleSlices(slices []uint64, n int) []uint64 {
+	if len(slices) <= n {
+		return slices
+	}
+	other := make([]uint64, n)
+	for i := range other {
+		other[i] = slices[i*len(slices)/n]
+	}
+	return other
+}
+
+// bitmapCalls are the calls executed by executeBitmapCallSlice().
+var bitmapCalls = map[string]struct{}{
+	"Bitmap":     {},
+	"Difference": {},
+	"Intersect":  {},
+	"Range":      {},
+	"RowRange":   {},
+	"Shift":      {},
+	"Union":      {},
+	"UnionRows":  {},
+}
+
+// QueryExplain represents the columns matched by each call of a query and
+// its children in a sample of slices.
+type QueryExplain struct {
+	// Slices evaluated.
+	Slices []uint64
+
+	// Trace of each call, in query order.
+	Calls []*CallExplain
+}
+
+// CallExplain represents the columns matched by a call and its children.
+type CallExplain struct {
+	// The call, excluding its children.
+	Call string
+
+	// Number of columns matched in the evaluated slices. Zero for calls
+	// which do not return a bitmap, though their children are still traced.
+	Count uint64
+
+	// Traces of the call's children, in argument order.
+	Children []*CallExplain
+}
+
+// newCallExplain returns an empty trace of c and its children.
+func newCallExplain(c *pql.Call) *CallExplain {
+	ce := &CallExplain{Call: (&pql.Call{Name: c.Name, Args: c.Args}).String()}
+	for _, child := range c.Children {
+		ce.Children = append(ce.Children, newCallExplain(child))
+	}
+	return ce
+}
+
+// validateCall validates c and its children. If bitmapOnly is true then c
+// must be a call which returns a bitmap.
+func (e *Executor) validateCall(index string, c *pql.Call, bitmapOnly bool) error {
+	if err := e.validateCallArgs(c); err != nil {
+		return &ValidationError{Call: c, Err: err}
+	}
+
+	var err error
+	switch c.Name {
+	case "Bitmap":
+		_, _, _, err = e.readBitmapArgs(index, c)
+	case "Range":
+		_, err = e.readRangeArgs(index, c)
+	case "Difference", "Intersect":
+		if len(c.Children) == 0 {
+			err = fmt.Errorf("empty %s query is currently not supported", c.Name)
+		}
+	case "Union":
+	case "RowRange":
+		_, err = e.readRowRangeArgs(index, c)
+	case "UnionRows":
+		_, _, err = e.readUnionRowsArgs(index, c, false)
+	case "Shift":
+		_, err = readShiftArgs(c)
+	case "ColumnDegree", "Count", "CountColumns", "MaxColumn", "MinColumn", "Page", "Rows", "TopColumns", "TopN", "SetBit", "ClearBit", "ClearBits", "SetBits", "ClearMatching", "SetRowAttrs", "SetRowFromRoaring", "SetColumnAttrs", "Warm":
+		if bitmapOnly {
+			return &ValidationError{Call: c, Err: fmt.Errorf("unknown call: %s", c.Name)}
+		}
+		switch c.Name {
+		case "ColumnDegree":
+			_, _, _, err = e.readColumnDegreeArgs(index, c)
+		case "Count":
+			if len(c.Children) == 0 {
+				err = errors.New("Count() requires an input bitmap")
+			} else if len(c.Children) > 1 {
+				err = errors.New("Count() only accepts a single bitmap input")
+			}
+		case "MaxColumn", "MinColumn":
+			_, err = e.readColumnBoundArgs(index, c)
+		case "Page":
+			_, _, err = readPageArgs(c)
+		case "Rows":
+			_, err = e.readRowsArgs(index, c)
+		case "TopColumns":
+			_, err = e.readTopColumnsArgs(index, c)
+		case "TopN":
+			var frame string
+			if frame, _, err = readTopNArgs(c, e.defaultFrame(index)); err == nil {
+				_, err = e.readTopNView(index, frame, c)
+			}
+		case "SetBit", "ClearBit":
+			_, err = e.readBitArgs(index, c)
+		case "ClearBits", "SetBits":
+			_, _, _, _, err = e.readBitsArgs(index, c, false)
+		case "SetRowFromRoaring":
+			_, err = e.readSetRowFromRoaringArgs(index, c)
+		case "ClearMatching":
+			_, _, err = e.readClearMatchingArgs(index, c)
+		case "SetRowAttrs":
+			_, _, _, err = e.readSetRowAttrsArgs(index, c)
+		case "SetColumnAttrs":
+			_, _, _, err = e.readSetColumnAttrsArgs(index, c)
+		case "CountColumns", "Warm":
+			_, err = readFrameListArgs(c)
+		}
+	default:
+		if bitmapOnly || e.aggregation(c.Name) == nil {
+			err = fmt.Errorf("unknown call: %s", c.Name)
+		}
+	}
+	if err != nil {
+		return &ValidationError{Call: c, Err: err}
+	}
+
+	// All child calls must return bitmaps.
+	for _, child := range c.Children {
+		if err := e.validateCall(index, child, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateCallArgs ensures that the value types in call.Args are expected.
+func (e *Executor) validateCallArgs(c *pql.Call) error {
+	if _, ok := c.Args["ids"]; ok {
+		switch v := c.Args["ids"].(type) {
+		case []int64, []uint64:
+			// noop
+		case []interface{}:
+			b := make([]int64, len(v), len(v))
+			for i := range v {
+				b[i] = v[i].(int64)
+			}
+			c.Args["ids"] = b
+		default:
+			return fmt.Errorf("invalid call.Args[ids]: %s", v)
+		}
+	}
+	return nil
+}
+
+// executeBitmapCall executes a call that returns a bitmap.
+func (e *Executor) executeBitmapCall(ctx context.Context, index string, c *pql.Call, slices []uint64, opt *ExecOptions) (*Bitmap, error) {
+	// Execute calls in bulk on each remote node and merge.
+	mapFn := func(slice uint64) (interface{}, error) {
+		return e.executeBitmapCallSlice(ctx, index, c, slice, opt)
+	}
+
+	// Merge returned results at coordinating node.
+	reduceFn := func(prev, v interface{}) interface{} {
+		other, _ := prev.(*Bitmap)
+		if other == nil {
+			other = NewBitmap()
+		}
+		other.Merge(v.(*Bitmap))
+		return other
+	}
+
+	other, err := e.mapReduce(ctx, index, slices, c, opt, mapFn, reduceFn)
+	if err != nil {
+		return nil, err
+	}
+
+	// Attach attributes for Bitmap() calls.
+	// If the column label is used then return column attributes.
+	// If the row label is used then return bitmap attributes.
+	bm, _ := other.(*Bitmap)
+	if bm == nil {
+		bm = NewBitmap()
+	}
+
+	// Attach attributes for all result columns, if requested.
+	if opt.AttachColumnAttrs && !opt.Remote {
+		if err := e.attachColumnAttrs(index, bm); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.Name == "Bitmap" {
+
+		idx := e.Holder.Index(index)
+		if idx != nil {
+			columnLabel := idx.ColumnLabel()
+			if columnID, _, ok, err := readColumnID(c, false, columnLabel); ok && err == nil {
+				attrs, err := idx.ColumnAttrStore().Attrs(columnID)
+				if err != nil {
+					return nil, err
+				}
+				bm.Attrs = attrs
+			} else if err != nil {
+				return nil, err
+			} else {
+				frame, _ := c.Args["frame"].(string)
+				if fr := idx.Frame(frame); fr != nil {
+					rowLabel := fr.RowLabel()
+					rowID, _, err := c.UintArg(rowLabel)
+					if err != nil {
+						return nil, err
+					}
+					attrs, err := fr.RowAttrStore().Attrs(rowID)
+					if err != nil {
+						return nil, err
+					}
+					bm.Attrs = attrs
+				}
+			}
+		}
+	}
+
+	return bm, nil
+}
+
+// attachColumnAttrs sets the attributes for each column in bm.
+// Returns ErrTooManyColumnAttrs if bm has more than MaxColumnAttrsN columns.
+func (e *Executor) attachColumnAttrs(index string, bm *Bitmap) error {
+	if bm.Count() > uint64(e.MaxColumnAttrsN) {
+		return ErrTooManyColumnAttrs
+	}
+
+	columnAttrSets, err := readColumnAttrSets(e.Holder.Index(index), bm.Bits())
+	if err != nil {
+		return err
+	}
+	bm.ColumnAttrSets = columnAttrSets
+	return nil
+}
+
+// executeBitmapCallSlice executes a bitmap call for a single slice.
+func (e *Executor) executeBitmapCallSlice(ctx context.Context, index string, c *pql.Call, slice uint64, opt *ExecOptions) (*Bitmap, error) {
+	switch c.Name {
+	case "Bitmap":
+		return e.executeBitmapSlice(ctx, index, c, slice, opt)
+	case "Difference":
+		return e.executeDifferenceSlice(ctx, index, c, slice, opt)
+	case "Intersect":
+		return e.executeIntersectSlice(ctx, index, c, slice, opt)
+	case "Range":
+		return e.executeRangeSlice(ctx, index, c, slice, opt)
+	case "RowRange":
+		return e.executeRowRangeSlice(ctx, index, c, slice, opt)
+	case "Shift":
+		return e.executeShiftSlice(ctx, index, c, slice, opt)
+	case "Union":
+		return e.executeUnionSlice(ctx, index, c, slice, opt)
+	case "UnionRows":
+		return e.executeUnionRowsSlice(ctx, index, c, slice, opt)
+	default:
+		return nil, fmt.Errorf("unknown call: %s", c.Name)
+	}
+}
+
+// executeTopN executes a TopN() call.
+// This first performs the TopN() to determine the top results and then
+// requeries to retrieve the full counts for each of the top results.
+func (e *Executor) executeTopN(ctx context.Context, index string, c *pql.Call, slices []uint64, opt *ExecOptions) ([]Pair, error) {
+	rowIDs, _, err := c.UintSliceArg("ids")
+	if err != nil {
+		return nil, fmt.Errorf("executeTopN: %v", err)
+	}
+	n, _, err := c.UintArg("n")
+	if err != nil {
+		return nil, fmt.Errorf("executeTopN: %v", err)
+	}
+
+	// The minimum count is applied to the final merged counts, unlike the
+	// per-slice threshold which only filters rows within each slice.
+	minCount, _, err := c.UintArg("minCount")
+	if err != nil {
+		return nil, fmt.Errorf("executeTopN: %v", err)
+	}
+
+	// Execute original query.
+	pairs, err := e.executeTopNSlices(ctx, index, c, slices, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Filtering only occurs once counts are merged on the original caller.
+	if opt.Remote {
+		return pairs, nil
+	}
+
+	// If this call is against specific ids or we didn't get results then don't refetch.
+	if len(pairs) == 0 || len(rowIDs) > 0 {
+		return Pairs(pairs).MinCount(minCount), nil
+	}
+
+	// Return the first pass counts if approximate results are acceptable.
+	if opt.ApproximateTopN {
+		pairs = Pairs(pairs).MinCount(minCount)
+		if n != 0 && int(n) < len(pairs) {
+			pairs = pairs[0:n]
+		}
+		return pairs, nil
+	}
+
+	// Only the original caller should refetch the full counts.
+	other := c.Clone()
+
+	ids := Pairs(pairs).Keys()
+	sort.Sort(uint64Slice(ids))
+	other.Args["ids"] = ids
+
+	trimmedList, err := e.executeTopNSlices(ctx, index, other, slices, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	trimmedList = Pairs(trimmedList).MinCount(minCount)
+	if n != 0 && int(n) < len(trimmedList) {
+		trimmedList = trimmedList[0:n]
+	}
+	return trimmedList, nil
+}
+
+func (e *Executor) executeTopNSlices(ctx context.Context, index string, c *pql.Call, slices []uint64, opt *ExecOptions) ([]Pair, error) {
+	// Materialize columns matching a column attribute filter once per node.
+	var attrs *Bitmap
+	if field, values := readTopNColumnFilter(c); field != "" {
+		bm, err := e.columnAttrBitmap(index, field, values)
+		if err != nil {
+			return nil, err
+		}
+		attrs = bm
+	}
+
+	// Execute calls in bulk on each remote node and merge.
+	mapFn := func(slice uint64) (interface{}, error) {
+		return e.executeTopNSlice(ctx, index, c, slice, attrs, opt)
+	}
+
+	// Bound the pairs kept while merging a first pass with a limit.
+	n, _, err := c.UintArg("n")
+	if err != nil {
+		return nil, fmt.Errorf("executeTopNSlices: %v", err)
+	}
+	_, hasIDs := c.Args["ids"]
+	var limit int
+	if n > 0 && !hasIDs && e.TopNReduceMargin > 0 {
+		limit = int(n) + e.TopNReduceMargin
+	}
+
+	// Merge returned results at coordinating node.
+	reduceFn := func(prev, v interface{}) interface{} {
+		other, _ := prev.([]Pair)
+		pairs := Pairs(other).Add(v.([]Pair))
+		if limit > 0 {
+			pairs = Pairs(pairs).Top(limit)
+		}
+		return pairs
+	}
+
+	// Visit batches of slices on a first pass with a limit, if enabled, so
+	// that slices can be skipped once the top rows are decided.
+	var other interface{}
+	if e.TopNEarlyTerminationBatchN > 0 && n > 0 && !hasIDs && !opt.Remote {
+		other, err = e.mapReduceTopNBatches(ctx, index, c, slices, int(n), opt, mapFn, reduceFn)
+	} else {
+		other, err = e.mapReduce(ctx, index, slices, c, opt, mapFn, reduceFn)
+	}
+	if err != nil {
+		return nil, err
+	}
+	results, _ := other.([]Pair)
+	if results == nil {
+		results = []Pair{}
+	}
+
+	// Sort final merged results.
+	sort.Sort(Pairs(results))
+
+	return results, nil
+}
+
+// mapReduceTopNBatches maps TopN() over slices in batches of
+// TopNEarlyTerminationBatchN and stops once the top n rows lead the next row
+// by more than the most any row could gain from the remaining slices.
+// Remote slices cannot be bounded so they are mapped with the first batch.
+func (e *Executor) mapReduceTopNBatches(ctx context.Context, index string, c *pql.Call, slices []uint64, n int, opt *ExecOptions, mapFn mapFunc, reduceFn reduceFunc) (interface{}, error) {
+	frame, _, err := readTopNArgs(c, e.defaultFrame(index))
+	if err != nil {
+		return nil, err
+	}
+	view, err := e.readTopNView(index, frame, c)
+	if err != nil {
+		return nil, err
+	}
+
+	var local, remote []uint64
+	for _, slice := range slices {
+		if e.Cluster.OwnsFragment(e.Host, index, slice) {
+			local = append(local, slice)
+		} else {
+			remote = append(remote, slice)
+		}
+	}
+
+	var result interface{}
+	for i := 0; i == 0 || i < len(local); i += e.TopNEarlyTerminationBatchN {
+		j := i + e.TopNEarlyTerminationBatchN
+		if j > len(local) {
+			j = len(local)
+		}
+
+		batch := local[i:j]
+		if i == 0 {
+			batch = append(remote, batch...)
+		}
+		other, err := e.mapReduce(ctx, index, batch, c, opt, mapFn, reduceFn)
+		if err != nil {
+			return nil, err
+		}
+		result = reduceFn(result, other)
+
+		if j < len(local) && topNDecided(result.([]Pair), n, e.maxSliceRowCount(index, frame, view, local[j:])) {
+			opt.warnings.add(WarningTopNSlicesSkipped, fmt.Sprintf("TopN() ranked rows from %d of %d slices", len(remote)+j, len(slices)), map[string]interface{}{
+				"slices":        len(slices),
+				"skippedSlices": len(local) - j,
+			})
+			break
+		}
+	}
+	return result, nil
+}
+
+// topNDecided returns true if the n highest pairs cannot be overtaken by
+// any other row gaining up to bound.
+func topNDecided(pairs []Pair, n int, bound uint64) bool {
+	if len(pairs) < n {
+		return false
+	}
+
+	a := make([]Pair, len(pairs))
+	copy(a, pairs)
+	sort.Sort(Pairs(a))
+
+	var next uint64
+	if len(a) > n {
+		next = a[n].Count
+	}
+	return a[n-1].Count > next+bound
+}
+
+// maxSliceRowCount returns an upper bound on the count a single row can
+// gain across local slices. Each fragment is bounded by the highest row
+// count in its cache. Counts are approximate for caches which have evicted rows.
+func (e *Executor) maxSliceRowCount(index, frame, view string, slices []uint64) uint64 {
+	var bound uint64
+	for _, slice := range slices {
+		frag := e.Holder.Fragment(index, frame, view, slice)
+		if frag == nil {
+			continue
+		}
+		cache := frag.Cache()
+		var max uint64
+		for _, id := range cache.IDs() {
+			if v := cache.Get(id); v > max {
+				max = v
+			}
+		}
+		bound += max
+	}
+	return bound
+}
+
+// executeTopNSlice executes a TopN call for a single slice.
+// If attrs is not nil then only columns in attrs are counted.
+func (e *Executor) executeTopNSlice(ctx context.Context, index string, c *pql.Call, slice uint64, attrs *Bitmap, opt *ExecOptions) ([]Pair, error) {
+	frame, topOpt, err := readTopNArgs(c, e.defaultFrame(index))
+	if err != nil {
+		return nil, err
+	}
+	view, err := e.readTopNView(index, frame, c)
+	if err != nil {
+		return nil, err
+	}
+
+	// Retrieve bitmap used to intersect. Multiple inputs are intersected
+	// within the slice so that ranking only counts columns matching all inputs.
+	if len(c.Children) == 1 {
+		bm, err := e.executeBitmapCallSlice(ctx, index, c.Children[0], slice, opt)
+		if err != nil {
+			return nil, err
+		}
+		topOpt.Src = bm
+	} else if len(c.Children) > 1 {
+		bm, err := e.executeIntersectSlice(ctx, index, c, slice, opt)
+		if err != nil {
+			return nil, err
+		}
+		topOpt.Src = bm
+	}
+
+	// Restrict to columns matching the column attribute filter.
+	if attrs != nil {
+		if topOpt.Src == nil {
+			topOpt.Src = attrs
+		} else {
+			topOpt.Src = topOpt.Src.Intersect(attrs)
+		}
+	}
+
+	f := e.Holder.Fragment(index, frame, view, slice)
+	if f == nil {
+		return nil, nil
+	} else if err := opt.fragmentReads.add(); err != nil {
+		return nil, err
+	}
+	return f.Top(topOpt)
+}
+
+// readTopNColumnFilter returns the column attribute & values used to filter
+// the columns counted by a TopN() call. Returns a blank field if unset.
+func readTopNColumnFilter(c *pql.Call) (field string, values []interface{}) {
+	field, _ = c.Args["columnField"].(string)
+	values, _ = c.Args["columnFilters"].([]interface{})
+	if len(values) == 0 {
+		return "", nil
+	}
+	return field, values
+}
+
+// columnAttrBitmap returns a bitmap of columns in index where the attribute
+// field matches one of values, or is a list containing one of values. This scans all column attributes and returns
+// ErrTooManyFilterColumns if more than MaxColumnAttrFilterN sets are scanned.
+func (e *Executor) columnAttrBitmap(index, field string, values []interface{}) (*Bitmap, error) {
+	idx := e.Holder.Index(index)
+	if idx == nil {
+		return nil, ErrIndexNotFound
+	}
+	store := idx.ColumnAttrStore()
+
+	// Create a fast lookup of filter values.
+	filters := newAttrFilterSet(values)
+
+	blocks, err := store.Blocks()
+	if err != nil {
+		return nil, err
+	}
+
+	bm := NewBitmap()
+	var scanN int
+	for _, block := range blocks {
+		m, err := store.BlockData(block.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		scanN += len(m)
+		if e.MaxColumnAttrFilterN > 0 && scanN > e.MaxColumnAttrFilterN {
+			return nil, ErrTooManyFilterColumns
+		}
+
+		for id, attrs := range m {
+			if filters.matches(attrs[field]) {
+				bm.SetBit(id)
+			}
+		}
+	}
+	return bm, nil
+}
+
+// readTopNArgs returns the frame name & top options for a TopN() call.
+// The source bitmap is not set on the returned options.
+func readTopNArgs(c *pql.Call, defaultFrame string) (string, TopOptions, error) {
+	frame, _ := c.Args["frame"].(string)
+	n, _, err := c.UintArg("n")
+	if err != nil {
+		return "", TopOptions{}, fmt.Errorf("executeTopNSlice: %v", err)
+	}
+	field, _ := c.Args["field"].(string)
+	rowIDs, _, err := c.UintSliceArg("ids")
+	if err != nil {
+		return "", TopOptions{}, fmt.Errorf("executeTopNSlice: %v", err)
+	}
+	minThreshold, _, err := c.UintArg("threshold")
+	if err != nil {
+		return "", TopOptions{}, fmt.Errorf("executeTopNSlice: %v", err)
+	}
+	filters, _ := c.Args["filters"].([]interface{})
+	tanimotoThreshold, _, err := c.UintArg("tanimotoThreshold")
+	if err != nil {
+		return "", TopOptions{}, fmt.Errorf("executeTopNSlice: %v", err)
+	}
+
+	// Set default frame.
+	if frame == "" {
+		frame = defaultFrame
+	}
+
+	if minThreshold <= 0 {
+		minThreshold = MinThreshold
+	}
+
+	if tanimotoThreshold > 100 {
+		return "", TopOptions{}, errors.New("Tanimoto Threshold is from 1 to 100 only")
+	}
+
+	return frame, TopOptions{
+		N:                 int(n),
+		RowIDs:            rowIDs,
+		FilterField:       field,
+		FilterValues:      filters,
+		MinThreshold:      minThreshold,
+		TanimotoThreshold: tanimotoThreshold,
+	}, nil
+}
+
+// readTopNView returns the view ranked by a TopN() call, standard if unset.
+// Rows of the inverse view are the frame's columns so an inverse TopN() ranks
+// columns by the number of rows they are set in. Attribute filters are only
+// supported on the standard view.
+func (e *Executor) readTopNView(index, frame string, c *pql.Call) (string, error) {
+	v, ok := c.Args["view"]
+	if !ok {
+		return ViewStandard, nil
+	}
+	view, ok := v.(string)
+	if !ok || !IsValidView(view) {
+		return "", fmt.Errorf("invalid TopN() view: %v", v)
+	} else if view == ViewStandard {
+		return view, nil
+	}
+
+	if f := e.Holder.Frame(index, frame); f == nil {
+		return "", ErrFrameNotFound
+	} else if !f.InverseEnabled() {
+		return "", ErrFrameInverseDisabled
+	}
+	if field, _ := readTopNColumnFilter(c); field != "" || c.Args["field"] != nil {
+		return "", errors.New("TopN() attribute filters require the standard view")
+	}
+	return view, nil
+}
+
+// executeDifferenceSlice executes a difference() call for a local slice.
+func (e *Executor) executeDifferenceSlice(ctx context.Context, index string, c *pql.Call, slice uint64, opt *ExecOptions) (*Bitmap, error) {
+	var other *Bitmap
+	if len(c.Children) == 0 {
+		return nil, fmt.Errorf("empty Difference query is currently not supported")
+	}
+
+	// Subtract the union of all other inputs at once when there are several.
+	// This is equivalent to subtracting each input in turn.
+	if len(c.Children) > 2 && !opt.NoOptimize {
+		bm, err := e.executeBitmapCallSlice(ctx, index, c.Children[0], slice, opt)
+		if err != nil {
+			return nil, err
+		}
+		subtrahend, err := e.executeUnionSlice(ctx, index, &pql.Call{Name: "Union", Children: c.Children[1:]}, slice, opt)
+		if err != nil {
+			return nil, err
+		}
+		other = bm.Difference(subtrahend)
+		other.InvalidateCount()
+		return other, nil
+	}
+
+	for i, input := range c.Children {
+		bm, err := e.executeBitmapCallSlice(ctx, index, input, slice, opt)
+		if err != nil {
+			return nil, err
+		}
+
+		if i == 0 {
+			other = bm
+		} else {
+			other = other.Difference(bm)
+		}
+	}
+	other.InvalidateCount()
+	return other, nil
+}
+
+func (e *Executor) executeBitmapSlice(ctx context.Context, index string, c *pql.Call, slice uint64, opt *ExecOptions) (*Bitmap, error) {
+	f, view, id, err := e.readBitmapArgs(index, c)
+	if err == ErrFrameNotFound && opt.TreatMissingAsEmpty {
+		return NewBitmap(), nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	row, err := e.fragmentRow(index, f.Name(), view, slice, id, opt)
+	if err != nil {
+		return nil, err
+	} else if row == nil {
+		opt.stats.addMissingSlice()
+		return NewBitmap(), nil
+	}
+	return row, nil
+}
+
+// fragmentRow returns a row of a local fragment or nil if the fragment does
+// not exist. Rows are read from the query's snapshot, if pinned. Returns an
+// error if the read exceeds the query's fragment read budget.
+func (e *Executor) fragmentRow(index, frame, view string, slice, rowID uint64, opt *ExecOptions) (*Bitmap, error) {
+	if opt.snapshot != nil {
+		row := opt.snapshot.row(frame, view, slice, rowID)
+		if row == nil {
+			return nil, nil
+		}
+		return row, opt.fragmentReads.add()
+	}
+
+	frag := e.Holder.Fragment(index, frame, view, slice)
+	if frag == nil {
+		return nil, nil
+	} else if err := opt.fragmentReads.add(); err != nil {
+		return nil, err
+	}
+	return frag.Row(rowID), nil
+}
+
+// fragmentRowIDs returns the ids of the non-empty rows of a local fragment or
+// nil if the fragment does not exist. Rows are read from the query's
+// snapshot, if pinned.
+func (e *Executor) fragmentRowIDs(index, frame, view string, slice uint64, opt *ExecOptions) ([]uint64, error) {
+	if opt.snapshot != nil {
+		rowIDs := opt.snapshot.rows(frame, view, slice)
+		if rowIDs == nil {
+			return nil, nil
+		}
+		return rowIDs, opt.fragmentReads.add()
+	}
+
+	frag := e.Holder.Fragment(index, frame, view, slice)
+	if frag == nil {
+		return nil, nil
+	} else if err := opt.fragmentReads.add(); err != nil {
+		return nil, err
+	}
+	return frag.Rows(), nil
+}
+
+// fragmentColumns returns the columns set in any row of a local fragment or
+// nil if the fragment does not exist. Columns are read from the query's
+// snapshot, if pinned.
+func (e *Executor) fragmentColumns(index, frame, view string, slice uint64, opt *ExecOptions) (*Bitmap, error) {
+	if opt.snapshot != nil {
+		cols := opt.snapshot.columns(frame, view, slice)
+		if cols == nil {
+			return nil, nil
+		}
+		return cols, opt.fragmentReads.add()
+	}
+
+	frag := e.Holder.Fragment(index, frame, view, slice)
+	if frag == nil {
+		return nil, nil
+	} else if err := opt.fragmentReads.add(); err != nil {
+		return nil, err
+	}
+	return frag.Columns(), nil
+}
+
+// fragmentColumnBound returns the highest column id set in a local fragment,
+// or the lowest if min is true. Returns false if the fragment is empty or does
+// not exist. The column is read from the query's snapshot, if pinned.
+func (e *Executor) fragmentColumnBound(index, frame, view string, slice uint64, min bool, opt *ExecOptions) (uint64, bool, error) {
+	if opt.snapshot != nil {
+		col, ok := opt.snapshot.columnBound(frame, view, slice, min)
+		if !ok {
+			return 0, false, nil
+		}
+		return col, ok, opt.fragmentReads.add()
+	}
+
+	frag := e.Holder.Fragment(index, frame, view, slice)
+	if frag == nil {
+		return 0, false, nil
+	} else if err := opt.fragmentReads.add(); err != nil {
+		return 0, false, err
+	}
+
+	col, ok := frag.MaxColumn()
+	if min {
+		col, ok = frag.MinColumn()
+	}
+	return col, ok, nil
+}
+
+// readBitmapArgs returns the frame, view & id referenced by a Bitmap() call.
+func (e *Executor) readBitmapArgs(index string, c *pql.Call) (*Frame, string, uint64, error) {
+	// Fetch column label from index.
+	idx := e.Holder.Index(index)
+	if idx == nil {
+		return nil, "", 0, ErrIndexNotFound
+	}
+	columnLabel := idx.ColumnLabel()
+
+	// Fetch frame & row label based on argument.
+	frame, _ := c.Args["frame"].(string)
+	if frame == "" {
+		frame = idx.DefaultFrame()
+	}
+	f := idx.Frame(frame)
+	if f == nil {
+		return nil, "", 0, ErrFrameNotFound
+	}
+	rowLabel := f.RowLabel()
+
+	// Return an error if both the row and column label are specified.
+	rowID, rowOK, rowErr := c.UintArg(rowLabel)
+	columnID, _, columnOK, columnErr := readColumnID(c, false, columnLabel)
+	if rowErr != nil || columnErr != nil {
+		return nil, "", 0, fmt.Errorf("Bitmap() error with arg for col: %v or row: %v", columnErr, rowErr)
+	}
+	if rowOK && columnOK {
+		return nil, "", 0, fmt.Errorf("Bitmap() cannot specify both %s and %s values", rowLabel, columnLabel)
+	} else if !rowOK && !columnOK {
+		return nil, "", 0, fmt.Errorf("Bitmap() must specify either %s or %s values", rowLabel, columnLabel)
+	}
+
+	// Determine row or column orientation.
+	if columnOK {
+		if !f.InverseEnabled() {
+			return nil, "", 0, fmt.Errorf("Bitmap() cannot retrieve columns unless inverse storage enabled")
+		}
+		return f, ViewInverse, columnID, nil
+	}
+	return f, ViewStandard, rowID, nil
+}
+
+// executeIntersectSlice executes a intersect() call for a local slice.
+func (e *Executor) executeIntersectSlice(ctx context.Context, index string, c *pql.Call, slice uint64, opt *ExecOptions) (*Bitmap, error) {
+	var other *Bitmap
+	if len(c.Children) == 0 {
+		return nil, fmt.Errorf("empty Intersect query is currently not supported")
+	}
+	bms, err := e.executeChildrenSlice(ctx, index, c, slice, opt)
+	if err != nil {
+		return nil, err
+	}
+	for i, bm := range bms {
+		if i == 0 {
+			other = bm
+		} else {
+			other = other.Intersect(bm)
+		}
+	}
+	other.InvalidateCount()
+	return other, nil
+}
+
+// executeChildrenSlice executes the children of c for a local slice and
+// returns their bitmaps in argument order.
+func (e *Executor) executeChildrenSlice(ctx context.Context, index string, c *pql.Call, slice uint64, opt *ExecOptions) ([]*Bitmap, error) {
+	return e.mapChildren(len(c.Children), opt.ParallelChildren, func(i int) (*Bitmap, error) {
+		return e.executeBitmapCallSlice(ctx, index, c.Children[i], slice, opt)
+	})
+}
+
+// mapChildren calls fn for each of n children and returns the results in
+// order. If parallel is set, children are evaluated concurrently while spare
+// map slots are available and inline otherwise. Slots are never waited on
+// since the caller may already hold one. The first error by index is returned.
+func (e *Executor) mapChildren(n int, parallel bool, fn func(i int) (*Bitmap, error)) ([]*Bitmap, error) {
+	bms := make([]*Bitmap, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		// The last child runs inline as the caller would otherwise be idle.
+		if parallel && i < n-1 {
+			if release, ok := e.tryAcquireMapSlot(); ok {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					defer release()
+					bms[i], errs[i] = fn(i)
+				}(i)
+				continue
+			}
+		}
+		bms[i], errs[i] = fn(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return bms, nil
+}
+
+// executeRangeSlice executes a range() call for a local slice.
+func (e *Executor) executeRangeSlice(ctx context.Context, index string, c *pql.Call, slice uint64, opt *ExecOptions) (*Bitmap, error) {
+	args, err := e.readRangeArgs(index, c)
+	if err == ErrFrameNotFound && opt.TreatMissingAsEmpty {
+		return NewBitmap(), nil
+	} else if err != nil {
+		return nil, err
+	}
+	f := args.frame
+
+	// Union bitmaps across all time-based subframes.
+	// If no quantum exists then this returns an empty bitmap.
+	bm := &Bitmap{}
+	for _, view := range args.views {
+		row, err := e.fragmentRow(index, f.Name(), view, slice, args.rowID, opt)
+		if err != nil {
+			return nil, err
+		} else if row == nil {
+			continue
+		}
+		bm = bm.Union(row)
+	}
+	return bm, nil
+}
+
+// rangeArgs represents the parsed arguments of a Range() call.
+type rangeArgs struct {
+	frame      *Frame
+	view       string
+	rowID      uint64
+	start, end time.Time
+	views      []string // time views to union, empty if no quantum
+}
+
+// readRangeArgs returns the frame, view, row id & time range referenced by a Range() call.
+// The view defaults to the standard view and must be enabled on the frame.
+func (e *Executor) readRangeArgs(index string, c *pql.Call) (*rangeArgs, error) {
+	var args rangeArgs
+
+	// Parse frame, use default if unset.
+	frame, _ := c.Args["frame"].(string)
+	if frame == "" {
+		frame = e.defaultFrame(index)
+	}
+
+	// Retrieve base frame.
+	if args.frame = e.Holder.Frame(index, frame); args.frame == nil {
+		return nil, ErrFrameNotFound
+	}
+	rowLabel := args.frame.RowLabel()
+
+	// Parse view, use standard if unset.
+	args.view = ViewStandard
+	if v, ok := c.Args["view"]; ok {
+		view, ok := v.(string)
+		if !ok || !IsValidView(view) {
+			return nil, fmt.Errorf("invalid Range() view: %v", v)
+		} else if view == ViewInverse && !args.frame.InverseEnabled() {
+			return nil, ErrFrameInverseDisabled
+		}
+		args.view = view
+	}
+
+	// Read row id.
+	rowID, _, err := c.UintArg(rowLabel) // TODO: why are we ignoring missing rowID?
+	if err != nil {
+		return nil, fmt.Errorf("executeRangeSlice - reading row: %v", err)
+	}
+	args.rowID = rowID
+
+	// Parse start time. Relative times are normally resolved by the
+	// coordinator but are accepted for calls validated without executing.
+	startTimeStr, ok := c.Args["start"].(string)
+	if !ok {
+		return nil, errors.New("Range() start time required")
+	}
+	if t, ok := ParseRelativeTime(startTimeStr, e.now()); ok {
+		args.start = t.Truncate(time.Minute)
+	} else if args.start, err = time.Parse(TimeFormat, startTimeStr); err != nil {
+		return nil, errors.New("cannot parse Range() start time")
+	}
+
+	// Parse end time.
+	endTimeStr, ok := c.Args["end"].(string)
+	if !ok {
+		return nil, errors.New("Range() end time required")
+	}
+	if t, ok := ParseRelativeTime(endTimeStr, e.now()); ok {
+		args.end = t.Truncate(time.Minute)
+	} else if args.end, err = time.Parse(TimeFormat, endTimeStr); err != nil {
+		return nil, errors.New("cannot parse Range() end time")
+	}
+
+	// Expand the time views unless the frame has no quantum.
+	if q := args.frame.TimeQuantum(); q != "" {
+		args.views = ViewsByTimeRange(args.view, args.start, args.end, q)
+		if e.MaxRangeViews > 0 && len(args.views) > e.MaxRangeViews {
+			return nil, fmt.Errorf("Range() spans %d views, exceeding the limit of %d: narrow the time range or use a coarser quantum", len(args.views), e.MaxRangeViews)
+		}
+	}
+
+	return &args, nil
+}
+
+// executeUnionSlice executes a union() call for a local slice.
+func (e *Executor) executeUnionSlice(ctx context.Context, index string, c *pql.Call, slice uint64, opt *ExecOptions) (*Bitmap, error) {
+	other := NewBitmap()
+	bms, err := e.executeChildrenSlice(ctx, index, c, slice, opt)
+	if err != nil {
+		return nil, err
+	}
+	for i, bm := range bms {
+		if i == 0 {
+			other = bm
+		} else {
+			other = other.Union(bm)
+		}
+	}
+	other.InvalidateCount()
+	return other, nil
+}
+
+// executeUnionRowsSlice executes a UnionRows() call for a local slice.
+// The row is read from the standard view of each frame and unioned.
+func (e *Executor) executeUnionRowsSlice(ctx context.Context, index string, c *pql.Call, slice uint64, opt *ExecOptions) (*Bitmap, error) {
+	frames, rowID, err := e.readUnionRowsArgs(index, c, opt.TreatMissingAsEmpty)
+	if err != nil {
+		return nil, err
+	}
+
+	bm := NewBitmap()
+	for _, f := range frames {
+		row, err := e.fragmentRow(index, f.Name(), ViewStandard, slice, rowID, opt)
+		if err != nil {
+			return nil, err
+		} else if row != nil {
+			bm = bm.Union(row)
+		}
+	}
+	return bm, nil
+}
+
+// executeRowRangeSlice executes a RowRange() call for a local slice.
+// The rows between the bounds are read from the standard view and unioned.
+func (e *Executor) executeRowRangeSlice(ctx context.Context, index string, c *pql.Call, slice uint64, opt *ExecOptions) (*Bitmap, error) {
+	args, err := e.readRowRangeArgs(index, c)
+	if err == ErrFrameNotFound && opt.TreatMissingAsEmpty {
+		return NewBitmap(), nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	bm := NewBitmap()
+	for rowID := args.from; ; rowID++ {
+		row, err := e.fragmentRow(index, args.frame.Name(), ViewStandard, slice, rowID, opt)
+		if err != nil {
+			return nil, err
+		} else if row != nil {
+			bm = bm.Union(row)
+		}
+
+		// Checked before incrementing so a bound of MaxUint64 terminates.
+		if rowID == args.to {
+			break
+		}
+	}
+	return bm, nil
+}
+
+// rowRangeArgs are the arguments of a RowRange() call.
+type rowRangeArgs struct {
+	frame    *Frame
+	from, to uint64
+}
+
+// readRowRangeArgs returns the frame & inclusive row bounds of a RowRange()
+// call. Returns an error if the bounds span more than MaxRowRangeN rows.
+func (e *Executor) readRowRangeArgs(index string, c *pql.Call) (*rowRangeArgs, error) {
+	var args rowRangeArgs
+	if len(c.Children) > 0 {
+		return nil, errors.New("RowRange() does not accept bitmap inputs")
+	}
+
+	// Parse frame, use default if unset.
+	frame, _ := c.Args["frame"].(string)
+	if frame == "" {
+		frame = e.defaultFrame(index)
+	}
+	if args.frame = e.Holder.Frame(index, frame); args.frame == nil {
+		return nil, ErrFrameNotFound
+	}
+
+	// Read bounds.
+	from, ok, err := c.UintArg("from")
+	if err != nil {
+		return nil, fmt.Errorf("reading RowRange() from: %v", err)
+	} else if !ok {
+		return nil, errors.New("RowRange() from required")
+	}
+	to, ok, err := c.UintArg("to")
+	if err != nil {
+		return nil, fmt.Errorf("reading RowRange() to: %v", err)
+	} else if !ok {
+		return nil, errors.New("RowRange() to required")
+	} else if from > to {
+		return nil, fmt.Errorf("invalid RowRange() bounds: from %d is after to %d", from, to)
+	}
+	args.from, args.to = from, to
+
+	// Bound the rows read from each slice. The span is compared without
+	// adding one so that the full uint64 range cannot overflow.
+	if e.MaxRowRangeN > 0 && to-from >= uint64(e.MaxRowRangeN) {
+		return nil, fmt.Errorf("RowRange() exceeds the limit of %d rows", e.MaxRowRangeN)
+	}
+	return &args, nil
+}
+
+// readUnionRowsArgs returns the frames and row of a UnionRows() call.
+// All frames must use the same row label. Missing frames are skipped if
+// skipMissing is true.
+func (e *Executor) readUnionRowsArgs(index string, c *pql.Call, skipMissing bool) ([]*Frame, uint64, error) {
+	names, ok := c.Args["frames"].([]interface{})
+	if !ok || len(names) == 0 {
+		return nil, 0, errors.New("UnionRows() frames required")
+	} else if len(c.Children) > 0 {
+		return nil, 0, errors.New("UnionRows() does not accept bitmap inputs")
+	}
+
+	// Retrieve frames.
+	frames := make([]*Frame, 0, len(names))
+	for _, name := range names {
+		name, ok := name.(string)
+		if !ok {
+			return nil, 0, fmt.Errorf("invalid UnionRows() frame: %v", name)
+		}
+
+		f := e.Holder.Frame(index, name)
+		if f == nil {
+			if skipMissing {
+				continue
+			}
+			return nil, 0, ErrFrameNotFound
+		} else if len(frames) > 0 && f.RowLabel() != frames[0].RowLabel() {
+			return nil, 0, fmt.Errorf("UnionRows() frames must share a row label: %s uses %q, %s uses %q", frames[0].Name(), frames[0].RowLabel(), f.Name(), f.RowLabel())
+		}
+		frames = append(frames, f)
+	}
+
+	// All frames being missing reads an empty row.
+	if len(frames) == 0 {
+		return nil, 0, nil
+	}
+
+	rowLabel := frames[0].RowLabel()
+	rowID, ok, err := c.UintArg(rowLabel)
+	if err != nil {
+		return nil, 0, fmt.Errorf("reading UnionRows() row: %v", err)
+	} else if !ok {
+		return nil, 0, fmt.Errorf("UnionRows() row field '%v' required", rowLabel)
+	}
+	return frames, rowID, nil
+}
+
+// executeShiftSlice executes a Shift() call for a local slice.
+// Columns are shifted by n and may move into an adjacent slice. The columns
+// carried out of the slice are combined with the other slices when results
+// are reduced, which is why Shift() may only be merged by Union() or counted.
+func (e *Executor) executeShiftSlice(ctx context.Context, index string, c *pql.Call, slice uint64, opt *ExecOptions) (*Bitmap, error) {
+	n, err := readShiftArgs(c)
+	if err != nil {
+		return nil, err
+	}
+
+	bm, err := e.executeBitmapCallSlice(ctx, index, c.Children[0], slice, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Columns shifted below zero are dropped.
+	other := NewBitmap()
+	for _, col := range bm.Bits() {
+		if n < 0 && col < uint64(-n) {
+			continue
+		}
+		other.SetBit(uint64(int64(col) + n))
+	}
+	return other, nil
+}
+
+// readShiftArgs returns the offset of a Shift() call.
+func readShiftArgs(c *pql.Call) (int64, error) {
+	if len(c.Children) != 1 {
+		return 0, errors.New("Shift() requires a single bitmap input")
+	}
+
+	switch v := c.Args["n"].(type) {
+	case int64:
+		return v, nil
+	case uint64:
+		return int64(v), nil
+	case nil:
+		return 0, errors.New("Shift() n required")
+	default:
+		return 0, fmt.Errorf("invalid Shift() n: %v", v)
+	}
+}
+
+// validateShiftInputs returns an error if a Shift() call is nested within a
+// call that combines inputs slice by slice. Shifted columns only reach their
+// slice when results are reduced across slices.
+//
+// Count() and Page() sum per-slice counts so a Shift() may only be their
+// direct input. Combining it with other bitmaps first would count columns
+// carried into a slice once for each slice they appear in.
+func validateShiftInputs(c *pql.Call) error {
+	switch c.Name {
+	case "Difference", "Intersect", "TopN", "ClearMatching":
+		for _, child := range c.Children {
+			if hasShift(child) {
+				return fmt.Errorf("Shift() cannot be nested in %s()", c.Name)
+			}
+		}
+	case "Count", "Page":
+		for _, child := range c.Children {
+			if hasCombinedShift(child) {
+				return fmt.Errorf("Shift() must be the direct input of %s()", c.Name)
+			}
+		}
+	}
+
+	for _, child := range c.Children {
+		if err := validateShiftInputs(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasCombinedShift returns true if c combines a Shift() call with other
+// inputs. Chains of Shift() calls are not combined.
+func hasCombinedShift(c *pql.Call) bool {
+	if c.Name != "Shift" {
+		return hasShift(c)
+	}
+	for _, child := range c.Children {
+		if hasCombinedShift(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasShift returns true if c or any of its children is a Shift() call.
+func hasShift(c *pql.Call) bool {
+	if c.Name == "Shift" {
+		return true
+	}
+	for _, child := range c.Children {
+		if hasShift(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// executeCount executes a count() call.
+func (e *Executor) executeCount(ctx context.Context, index string, c *pql.Call, slices []uint64, opt *ExecOptions) (uint64, error) {
+	if len(c.Children) == 0 {
+		return 0, errors.New("Count() requires an input bitmap")
+	} else if len(c.Children) > 1 {
+		return 0, errors.New("Count() only accepts a single bitmap input")
+	}
+
+	// Execute calls in bulk on each remote node and merge.
+	mapFn := func(slice uint64) (interface{}, error) {
+		if c.Children[0].Name == "Range" {
+			return e.executeCountRangeSlice(ctx, index, c.Children[0], slice, opt)
+		} else if c.Children[0].Name == "Difference" && !opt.NoOptimize {
+			return e.executeCountDifferenceSlice(ctx, index, c.Children[0], slice, opt)
+		}
+
+		bm, err := e.executeBitmapCallSlice(ctx, index, c.Children[0], slice, opt)
+		if err != nil {
+			return 0, err
+		}
+		return bm.Count(), nil
 	}
 
 	// Merge returned results at coordinating node.
 	reduceFn := func(prev, v interface{}) interface{} {
-		other, _ := prev.(uint64)
-		return other + v.(uint64)
+		other, _ := prev.(uint64)
+		return other + v.(uint64)
+	}
+
+	result, err := e.mapReduce(ctx, index, slices, c, opt, mapFn, reduceFn)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := result.(uint64)
+
+	return n, nil
+}
+
+// executeCountUniverse returns the count n of a Count() call along with the
+// number of columns set in the frames read by its input.
+func (e *Executor) executeCountUniverse(ctx context.Context, index string, c *pql.Call, n uint64, slices []uint64, opt *ExecOptions) (*CountResult, error) {
+	frames := referencedFrames(c, e.defaultFrame(index))
+	names := make([]interface{}, len(frames))
+	for i, frame := range frames {
+		names[i] = frame
+	}
+
+	universe, err := e.executeCountColumns(ctx, index, &pql.Call{
+		Name: "CountColumns",
+		Args: map[string]interface{}{"frames": names},
+	}, slices, opt)
+	if err != nil {
+		return nil, err
+	}
+	return &CountResult{Count: n, Universe: universe}, nil
+}
+
+// CountResult represents a Count() result along with the number of columns
+// set in the frames counted.
+type CountResult struct {
+	Count    uint64 `json:"count"`
+	Universe uint64 `json:"universe"`
+}
+
+// executeCountColumns executes a CountColumns() call.
+// Returns the number of columns set in any row of the standard view of the
+// frames. Missing frames are skipped.
+func (e *Executor) executeCountColumns(ctx context.Context, index string, c *pql.Call, slices []uint64, opt *ExecOptions) (uint64, error) {
+	frames, err := readFrameListArgs(c)
+	if err != nil {
+		return 0, err
+	}
+
+	mapFn := func(slice uint64) (interface{}, error) {
+		bm := NewBitmap()
+		for _, frame := range frames {
+			cols, err := e.fragmentColumns(index, frame, ViewStandard, slice, opt)
+			if err != nil {
+				return nil, err
+			} else if cols != nil {
+				bm = bm.Union(cols)
+			}
+		}
+		return bm.Count(), nil
+	}
+
+	reduceFn := func(prev, v interface{}) interface{} {
+		other, _ := prev.(uint64)
+		return other + v.(uint64)
+	}
+
+	result, err := e.mapReduce(ctx, index, slices, c, opt, mapFn, reduceFn)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := result.(uint64)
+	return n, nil
+}
+
+// executeRows executes a Rows() call. Each slice returns its lowest n rows
+// which intersect the filter, if any, and slices are merged keeping the
+// lowest n distinct rows overall.
+func (e *Executor) executeRows(ctx context.Context, index string, c *pql.Call, slices []uint64, opt *ExecOptions) ([]uint64, error) {
+	args, err := e.readRowsArgs(index, c)
+	if err == ErrFrameNotFound && opt.TreatMissingAsEmpty {
+		return []uint64{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	mapFn := func(slice uint64) (interface{}, error) {
+		var filter *Bitmap
+		if len(c.Children) == 1 {
+			bm, err := e.executeBitmapCallSlice(ctx, index, c.Children[0], slice, opt)
+			if err != nil {
+				return nil, err
+			}
+			filter = bm
+		}
+		return e.executeRowsSlice(index, args, slice, filter, opt)
+	}
+
+	reduceFn := func(prev, v interface{}) interface{} {
+		other, _ := prev.([]uint64)
+		return mergeRowIDs(other, v.([]uint64), args.n)
+	}
+
+	result, err := e.mapReduce(ctx, index, slices, c, opt, mapFn, reduceFn)
+	if err != nil {
+		return nil, err
+	}
+	rowIDs, _ := result.([]uint64)
+	if rowIDs == nil {
+		rowIDs = []uint64{}
+	}
+	return rowIDs, nil
+}
+
+// executeRowsSlice returns the lowest n rows of a local slice which have a
+// bit set, or which intersect filter if it is not nil.
+func (e *Executor) executeRowsSlice(index string, args *rowsArgs, slice uint64, filter *Bitmap, opt *ExecOptions) ([]uint64, error) {
+	candidates, err := e.fragmentRowIDs(index, args.frame.Name(), ViewStandard, slice, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	rowIDs := []uint64{}
+	for _, rowID := range candidates {
+		if args.n > 0 && uint64(len(rowIDs)) >= args.n {
+			break
+		}
+
+		if filter != nil {
+			row, err := e.fragmentRow(index, args.frame.Name(), ViewStandard, slice, rowID, opt)
+			if err != nil {
+				return nil, err
+			} else if row == nil || row.IntersectionCount(filter) == 0 {
+				continue
+			}
+		}
+		rowIDs = append(rowIDs, rowID)
+	}
+	return rowIDs, nil
+}
+
+// mergeRowIDs returns the distinct ids of two sorted lists, in order, keeping
+// the lowest n if n is non-zero.
+func mergeRowIDs(a, b []uint64, n uint64) []uint64 {
+	other := make([]uint64, 0, len(a)+len(b))
+	for len(a) > 0 || len(b) > 0 {
+		if n > 0 && uint64(len(other)) >= n {
+			break
+		}
+
+		var v uint64
+		switch {
+		case len(b) == 0 || (len(a) > 0 && a[0] < b[0]):
+			v, a = a[0], a[1:]
+		case len(a) == 0 || b[0] < a[0]:
+			v, b = b[0], b[1:]
+		default:
+			v, a, b = a[0], a[1:], b[1:]
+		}
+		other = append(other, v)
+	}
+	return other
+}
+
+// rowsArgs are the arguments of a Rows() call.
+type rowsArgs struct {
+	frame *Frame
+	n     uint64
+}
+
+// readRowsArgs returns the frame & limit of a Rows() call. The call accepts
+// at most one bitmap input to filter rows by.
+func (e *Executor) readRowsArgs(index string, c *pql.Call) (*rowsArgs, error) {
+	var args rowsArgs
+	if len(c.Children) > 1 {
+		return nil, errors.New("Rows() only accepts a single bitmap input")
+	}
+
+	// Parse frame, use default if unset.
+	frame, _ := c.Args["frame"].(string)
+	if frame == "" {
+		frame = e.defaultFrame(index)
+	}
+	if args.frame = e.Holder.Frame(index, frame); args.frame == nil {
+		return nil, ErrFrameNotFound
+	}
+
+	n, _, err := c.UintArg("n")
+	if err != nil {
+		return nil, fmt.Errorf("invalid Rows() n: %v", c.Args["n"])
+	}
+	args.n = n
+	return &args, nil
+}
+
+// executePage executes a Page() call.
+// The total count and the page are collected in the same reduce. Each slice
+// returns its count and its first offset+limit columns since no other columns
+// can fall within the page.
+func (e *Executor) executePage(ctx context.Context, index string, c *pql.Call, slices []uint64, opt *ExecOptions) (*PageResult, error) {
+	offset, limit, err := readPageArgs(c)
+	if err != nil {
+		return nil, err
+	}
+	n := offset + limit
+
+	mapFn := func(slice uint64) (interface{}, error) {
+		bm, err := e.executeBitmapCallSlice(ctx, index, c.Children[0], slice, opt)
+		if err != nil {
+			return nil, err
+		}
+		columns := bm.Bits()
+		if uint64(len(columns)) > n {
+			columns = columns[:n]
+		}
+		return &PageResult{Count: bm.Count(), Columns: columns}, nil
+	}
+
+	reduceFn := func(prev, v interface{}) interface{} {
+		other, _ := prev.(*PageResult)
+		if other == nil {
+			other = &PageResult{}
+		}
+		result := v.(*PageResult)
+
+		other.Count += result.Count
+		other.Columns = uint64Slice(other.Columns).merge(result.Columns)
+		if uint64(len(other.Columns)) > n {
+			other.Columns = other.Columns[:n]
+		}
+		return other
+	}
+
+	result, err := e.mapReduce(ctx, index, slices, c, opt, mapFn, reduceFn)
+	if err != nil {
+		return nil, err
+	}
+	page, _ := result.(*PageResult)
+	if page == nil {
+		page = &PageResult{}
+	}
+
+	// Remote results are paged by the coordinating node.
+	if opt.Remote {
+		return page, nil
+	}
+	if uint64(len(page.Columns)) > offset {
+		page.Columns = page.Columns[offset:]
+	} else {
+		page.Columns = []uint64{}
+	}
+	return page, nil
+}
+
+// executeColumnDegree executes a ColumnDegree() call. Each filtered column
+// is paired with the number of rows it belongs to in the frame's standard
+// view. Pairs are ordered by degree, then column, and paged by offset & n.
+//
+// Every bit of the frame's local fragments is scanned so n is required.
+func (e *Executor) executeColumnDegree(ctx context.Context, index string, c *pql.Call, slices []uint64, opt *ExecOptions) ([]Pair, error) {
+	frame, offset, n, err := e.readColumnDegreeArgs(index, c)
+	if err != nil {
+		return nil, err
+	}
+	limit := int(offset + n)
+
+	mapFn := func(slice uint64) (interface{}, error) {
+		filter, err := e.executeBitmapCallSlice(ctx, index, c.Children[0], slice, opt)
+		if err != nil {
+			return nil, err
+		}
+
+		// Count the rows of each filtered column. Columns only exist in a
+		// single slice so the slice's best pairs are final.
+		m := make(map[uint64]uint64)
+		for _, col := range filter.Bits() {
+			m[col] = 0
+		}
+		if frag := e.Holder.Fragment(index, frame, ViewStandard, slice); frag != nil && len(m) > 0 {
+			if err := opt.fragmentReads.add(); err != nil {
+				return nil, err
+			}
+			if err := frag.ForEachBit(func(rowID, columnID uint64) error {
+				if n, ok := m[columnID]; ok {
+					m[columnID] = n + 1
+				}
+				return nil
+			}); err != nil {
+				return nil, err
+			}
+		}
+
+		pairs := make([]Pair, 0, len(m))
+		for col, n := range m {
+			pairs = append(pairs, Pair{ID: col, Count: n})
+		}
+		return topColumnDegrees(pairs, limit), nil
+	}
+
+	reduceFn := func(prev, v interface{}) interface{} {
+		other, _ := prev.([]Pair)
+		return topColumnDegrees(append(other, v.([]Pair)...), limit)
+	}
+
+	result, err := e.mapReduce(ctx, index, slices, c, opt, mapFn, reduceFn)
+	if err != nil {
+		return nil, err
+	}
+	pairs, _ := result.([]Pair)
+
+	// Remote results are paged by the coordinating node.
+	if opt.Remote {
+		return pairs, nil
+	}
+	if uint64(len(pairs)) > offset {
+		return pairs[offset:], nil
+	}
+	return []Pair{}, nil
+}
+
+// topColumnDegrees sorts pairs by descending count, then by ascending id,
+// and returns at most the first n.
+func topColumnDegrees(pairs []Pair, n int) []Pair {
+	sort.Sort(pairsByID(pairs))
+	sort.Stable(Pairs(pairs))
+	if len(pairs) > n {
+		pairs = pairs[:n]
+	}
+	return pairs
+}
+
+// pairsByID sorts pairs by ascending id.
+type pairsByID []Pair
+
+func (p pairsByID) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+func (p pairsByID) Len() int           { return len(p) }
+func (p pairsByID) Less(i, j int) bool { return p[i].ID < p[j].ID }
+
+// readColumnDegreeArgs returns the frame, offset & n of a ColumnDegree() call.
+func (e *Executor) readColumnDegreeArgs(index string, c *pql.Call) (frame string, offset, n uint64, err error) {
+	if len(c.Children) != 1 {
+		return "", 0, 0, errors.New("ColumnDegree() requires a single filter bitmap")
+	}
+
+	frame, _ = c.Args["frame"].(string)
+	if frame == "" {
+		frame = e.defaultFrame(index)
+	}
+
+	offset, _, err = c.UintArg("offset")
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("invalid ColumnDegree() offset: %v", c.Args["offset"])
+	}
+
+	n, ok, err := c.UintArg("n")
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("invalid ColumnDegree() n: %v", c.Args["n"])
+	} else if !ok || n == 0 {
+		return "", 0, 0, errors.New("ColumnDegree() n required")
+	} else if offset > uint64(maxInt) || n > uint64(maxInt)-offset {
+		return "", 0, 0, fmt.Errorf("ColumnDegree() offset + n exceeds %d", uint64(maxInt))
+	}
+	return frame, offset, n, nil
+}
+
+// maxInt is the largest value of an int.
+const maxInt = int(^uint(0) >> 1)
+
+// executeTopColumns executes a TopColumns() call. Each column set in any of
+// the given rows is paired with the number of those rows it belongs to.
+// Pairs are ordered by count, then column, and at most n are returned.
+func (e *Executor) executeTopColumns(ctx context.Context, index string, c *pql.Call, slices []uint64, opt *ExecOptions) ([]Pair, error) {
+	args, err := e.readTopColumnsArgs(index, c)
+	if err != nil {
+		return nil, err
+	}
+
+	mapFn := func(slice uint64) (interface{}, error) {
+		// Count the rows of each column. Columns only exist in a single
+		// slice so the slice's best pairs are final.
+		m := make(map[uint64]uint64)
+		for _, rowID := range args.rowIDs {
+			row, err := e.fragmentRow(index, args.frame, ViewStandard, slice, rowID, opt)
+			if err != nil {
+				return nil, err
+			} else if row == nil {
+				break
+			}
+			for _, col := range row.Bits() {
+				m[col]++
+			}
+		}
+
+		pairs := make([]Pair, 0, len(m))
+		for col, n := range m {
+			pairs = append(pairs, Pair{ID: col, Count: n})
+		}
+		return topColumnDegrees(pairs, args.n), nil
+	}
+
+	reduceFn := func(prev, v interface{}) interface{} {
+		other, _ := prev.([]Pair)
+		return topColumnDegrees(append(other, v.([]Pair)...), args.n)
+	}
+
+	result, err := e.mapReduce(ctx, index, slices, c, opt, mapFn, reduceFn)
+	if err != nil {
+		return nil, err
+	}
+	pairs, _ := result.([]Pair)
+	if pairs == nil {
+		pairs = []Pair{}
+	}
+	return pairs, nil
+}
+
+// topColumnsArgs are the arguments of a TopColumns() call.
+type topColumnsArgs struct {
+	frame  string
+	rowIDs []uint64
+	n      int
+}
+
+// readTopColumnsArgs returns the arguments of a TopColumns() call.
+func (e *Executor) readTopColumnsArgs(index string, c *pql.Call) (topColumnsArgs, error) {
+	if len(c.Children) > 0 {
+		return topColumnsArgs{}, errors.New("TopColumns() does not accept bitmap inputs")
+	}
+
+	frame, _ := c.Args["frame"].(string)
+	if frame == "" {
+		frame = e.defaultFrame(index)
+	}
+	if e.Holder.Frame(index, frame) == nil {
+		return topColumnsArgs{}, ErrFrameNotFound
+	}
+
+	rowIDs, ok, err := c.UintSliceArg("ids")
+	if err != nil {
+		return topColumnsArgs{}, fmt.Errorf("invalid TopColumns() ids: %v", err)
+	} else if !ok || len(rowIDs) == 0 {
+		return topColumnsArgs{}, errors.New("TopColumns() ids required")
+	}
+
+	n, ok, err := c.UintArg("n")
+	if err != nil {
+		return topColumnsArgs{}, fmt.Errorf("invalid TopColumns() n: %v", c.Args["n"])
+	} else if !ok || n == 0 {
+		return topColumnsArgs{}, errors.New("TopColumns() n required")
+	} else if n > uint64(maxInt) {
+		return topColumnsArgs{}, fmt.Errorf("TopColumns() n exceeds %d", uint64(maxInt))
+	}
+	return topColumnsArgs{frame: frame, rowIDs: rowIDs, n: int(n)}, nil
+}
+
+// executeColumnBound executes a MaxColumn() or MinColumn() call. The highest
+// or lowest column id set is found in each slice of the frames read and
+// reduced with max or min.
+func (e *Executor) executeColumnBound(ctx context.Context, index string, c *pql.Call, slices []uint64, opt *ExecOptions) (*ColumnResult, error) {
+	frames, err := e.readColumnBoundArgs(index, c)
+	if err != nil {
+		return nil, err
+	}
+	min := c.Name == "MinColumn"
+
+	mapFn := func(slice uint64) (interface{}, error) {
+		result := &ColumnResult{Empty: true}
+		for _, frame := range frames {
+			col, ok, err := e.fragmentColumnBound(index, frame, ViewStandard, slice, min, opt)
+			if err != nil {
+				return nil, err
+			} else if ok {
+				result = result.merge(&ColumnResult{Column: col}, min)
+			}
+		}
+		return result, nil
+	}
+
+	reduceFn := func(prev, v interface{}) interface{} {
+		other, _ := prev.(*ColumnResult)
+		return other.merge(v.(*ColumnResult), min)
+	}
+
+	result, err := e.mapReduce(ctx, index, slices, c, opt, mapFn, reduceFn)
+	if err != nil {
+		return nil, err
+	}
+	bound, _ := result.(*ColumnResult)
+	if bound == nil {
+		bound = &ColumnResult{Empty: true}
+	}
+	return bound, nil
+}
+
+// isColumnBoundCall returns true if c is a MaxColumn() or MinColumn() call.
+func isColumnBoundCall(c *pql.Call) bool {
+	return c.Name == "MaxColumn" || c.Name == "MinColumn"
+}
+
+// readColumnBoundArgs returns the frames read by a MaxColumn() or
+// MinColumn() call. Calls without a frame or frames argument read every
+// frame in the index.
+func (e *Executor) readColumnBoundArgs(index string, c *pql.Call) ([]string, error) {
+	if len(c.Children) > 0 {
+		return nil, fmt.Errorf("%s() does not accept bitmap inputs", c.Name)
+	}
+
+	if frame, ok := c.Args["frame"]; ok {
+		name, ok := frame.(string)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid %s() frame: %v", c.Name, frame)
+		}
+		return []string{name}, nil
+	}
+
+	if names, ok := c.Args["frames"]; ok {
+		a, ok := names.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid %s() frames: %v", c.Name, names)
+		}
+		frames := make([]string, len(a))
+		for i, name := range a {
+			name, ok := name.(string)
+			if !ok || name == "" {
+				return nil, fmt.Errorf("invalid %s() frame: %v", c.Name, a[i])
+			}
+			frames[i] = name
+		}
+		return frames, nil
+	}
+
+	var frames []string
+	if idx := e.Holder.Index(index); idx != nil {
+		for _, f := range idx.Frames() {
+			frames = append(frames, f.Name())
+		}
+	}
+	return frames, nil
+}
+
+// expandColumnBoundCalls returns q with the frames of the index listed on
+// every index-wide MaxColumn() and MinColumn() call so that the frames are
+// authorized, cached and forwarded like those of any other read.
+// q is not modified.
+func (e *Executor) expandColumnBoundCalls(index string, q *pql.Query) (*pql.Query, error) {
+	var other *pql.Query
+	for i, call := range q.Calls {
+		if !isColumnBoundCall(call) || call.Args["frame"] != nil || call.Args["frames"] != nil {
+			continue
+		}
+
+		frames, err := e.readColumnBoundArgs(index, call)
+		if err != nil {
+			return nil, err
+		}
+		names := make([]interface{}, len(frames))
+		for j, frame := range frames {
+			names[j] = frame
+		}
+
+		if other == nil {
+			other = &pql.Query{Calls: make([]*pql.Call, len(q.Calls))}
+			copy(other.Calls, q.Calls)
+		}
+		call = call.Clone()
+		if call.Args == nil {
+			call.Args = make(map[string]interface{})
+		}
+		call.Args["frames"] = names
+		other.Calls[i] = call
+	}
+
+	if other == nil {
+		return q, nil
+	}
+	return other, nil
+}
+
+// ColumnResult represents the result of a MaxColumn() or MinColumn() call.
+type ColumnResult struct {
+	// Highest or lowest column id set. Zero if Empty is true.
+	Column uint64 `json:"column"`
+
+	// Key of the column, if keys are translated.
+	Key string `json:"key,omitempty"`
+
+	// True if no columns are set. Distinguishes an empty result from
+	// column zero.
+	Empty bool `json:"empty"`
+}
+
+// merge returns the greater of r and other, or the lesser if min is true.
+// Either may be nil.
+func (r *ColumnResult) merge(other *ColumnResult, min bool) *ColumnResult {
+	if r == nil || r.Empty {
+		return other
+	} else if other == nil || other.Empty || other.Column == r.Column {
+		return r
+	} else if (other.Column < r.Column) == min {
+		return other
+	}
+	return r
+}
+
+// readPageArgs returns the offset and limit of a Page() call.
+func readPageArgs(c *pql.Call) (offset, limit uint64, err error) {
+	if len(c.Children) != 1 {
+		return 0, 0, errors.New("Page() requires a single bitmap input")
+	}
+
+	offset, _, err = c.UintArg("offset")
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid Page() offset: %v", c.Args["offset"])
+	}
+
+	limit, ok, err := c.UintArg("limit")
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid Page() limit: %v", c.Args["limit"])
+	} else if !ok {
+		return 0, 0, errors.New("Page() limit required")
+	}
+	return offset, limit, nil
+}
+
+// PageResult represents the result of a Page() call.
+type PageResult struct {
+	// Total number of columns in the input bitmap.
+	Count uint64 `json:"count"`
+
+	// Column ids within the requested page.
+	Columns []uint64 `json:"columns"`
+}
+
+// Aggregation represents a custom call executed across slices with the same
+// map & reduce steps as the built-in calls. Remote nodes must register the
+// same aggregation under the same name.
+type Aggregation struct {
+	// Returns the result of the call for a single local slice. The inputs
+	// are the results of the call's child bitmap calls for the slice.
+	Map func(ctx context.Context, index string, c *pql.Call, slice uint64, inputs []*Bitmap) (interface{}, error)
+
+	// Merges a slice or node result, v, into the previous result.
+	// prev is nil for the first result.
+	Reduce func(prev, v interface{}) interface{}
+
+	// Decodes a JSON-encoded result returned by a remote node.
+	// If nil, the result is decoded into an interface{}.
+	Decode func(data []byte) (interface{}, error)
+}
+
+// decode returns the result decoded from a remote node's response.
+func (a *Aggregation) decode(data []byte) (interface{}, error) {
+	if len(data) == 0 {
+		return nil, nil
+	} else if a.Decode != nil {
+		return a.Decode(data)
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// builtinCalls are the names of calls executed by the executor itself.
+var builtinCalls = map[string]struct{}{
+	"Bitmap":            {},
+	"ClearBit":          {},
+	"ClearBits":         {},
+	"ClearMatching":     {},
+	"Count":             {},
+	"CountColumns":      {},
+	"Difference":        {},
+	"Intersect":         {},
+	"MaxColumn":         {},
+	"MinColumn":         {},
+	"Page":              {},
+	"ColumnDegree":      {},
+	"Range":             {},
+	"RowRange":          {},
+	"Rows":              {},
+	"SetBit":            {},
+	"SetBits":           {},
+	"SetColumnAttrs":    {},
+	"SetRowAttrs":       {},
+	"SetRowFromRoaring": {},
+	"Shift":             {},
+	"TopColumns":        {},
+	"TopN":              {},
+	"Union":             {},
+	"UnionRows":         {},
+	"Warm":              {},
+}
+
+// RegisterAggregation adds a custom call named name. Calls with the name
+// are executed by mapping agg.Map over each slice and merging with agg.Reduce.
+// Returns an error if name is a built-in call or is already registered.
+func (e *Executor) RegisterAggregation(name string, agg Aggregation) error {
+	if name == "" {
+		return errors.New("aggregation name required")
+	} else if _, ok := builtinCalls[name]; ok {
+		return fmt.Errorf("cannot register built-in call: %s", name)
+	} else if agg.Map == nil || agg.Reduce == nil {
+		return fmt.Errorf("aggregation map & reduce functions required: %s", name)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.aggregations[name]; ok {
+		return fmt.Errorf("aggregation already registered: %s", name)
+	}
+	if e.aggregations == nil {
+		e.aggregations = make(map[string]*Aggregation)
+	}
+	e.aggregations[name] = &agg
+	return nil
+}
+
+// aggregation returns the aggregation registered as name, if any.
+func (e *Executor) aggregation(name string) *Aggregation {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.aggregations[name]
+}
+
+// executeAggregation executes a registered custom call.
+func (e *Executor) executeAggregation(ctx context.Context, index string, c *pql.Call, agg *Aggregation, slices []uint64, opt *ExecOptions) (interface{}, error) {
+	mapFn := func(slice uint64) (interface{}, error) {
+		inputs := make([]*Bitmap, len(c.Children))
+		for i, child := range c.Children {
+			bm, err := e.executeBitmapCallSlice(ctx, index, child, slice, opt)
+			if err != nil {
+				return nil, err
+			}
+			inputs[i] = bm
+		}
+		return agg.Map(ctx, index, c, slice, inputs)
+	}
+
+	// Nodes without slices for the call return nil.
+	reduceFn := func(prev, v interface{}) interface{} {
+		if v == nil {
+			return prev
+		}
+		return agg.Reduce(prev, v)
+	}
+
+	return e.mapReduce(ctx, index, slices, c, opt, mapFn, reduceFn)
+}
+
+// executeCountDifferenceSlice counts the columns matching a Difference() call
+// for a local slice without building the difference. The columns of the first
+// input which are also set in any other input are subtracted from its count.
+func (e *Executor) executeCountDifferenceSlice(ctx context.Context, index string, c *pql.Call, slice uint64, opt *ExecOptions) (uint64, error) {
+	if len(c.Children) == 0 {
+		return 0, fmt.Errorf("empty Difference query is currently not supported")
+	}
+
+	bm, err := e.executeBitmapCallSlice(ctx, index, c.Children[0], slice, opt)
+	if err != nil {
+		return 0, err
 	}
 
-	result, err := e.mapReduce(ctx, index, slices, c, opt, mapFn, reduceFn)
+	var subtrahend *Bitmap
+	switch len(c.Children) {
+	case 1:
+		return bm.Count(), nil
+	case 2:
+		subtrahend, err = e.executeBitmapCallSlice(ctx, index, c.Children[1], slice, opt)
+	default:
+		subtrahend, err = e.executeUnionSlice(ctx, index, &pql.Call{Name: "Union", Children: c.Children[1:]}, slice, opt)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return bm.Count() - bm.IntersectionCount(subtrahend), nil
+}
+
+// executeCountRangeSlice counts the columns matching a Range() call for a local slice.
+// Empty views are skipped and a single matching view is counted directly.
+// Columns may be set in more than one view so rows from multiple views are
+// counted with UnionCount, which counts each column once without building
+// the union.
+func (e *Executor) executeCountRangeSlice(ctx context.Context, index string, c *pql.Call, slice uint64, opt *ExecOptions) (uint64, error) {
+	args, err := e.readRangeArgs(index, c)
+	if err == ErrFrameNotFound && opt.TreatMissingAsEmpty {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	f := args.frame
+
+	// Collect non-empty rows across all time-based subframes.
+	// If no quantum exists then there are no matching columns.
+	var rows []*Bitmap
+	for _, view := range args.views {
+		row, err := e.fragmentRow(index, f.Name(), view, slice, args.rowID, opt)
+		if err != nil {
+			return 0, err
+		} else if row == nil {
+			continue
+		}
+		if row.Count() > 0 {
+			rows = append(rows, row)
+		}
+	}
+
+	switch len(rows) {
+	case 0:
+		return 0, nil
+	case 1:
+		return rows[0].Count(), nil
+	default:
+		return UnionCount(rows), nil
+	}
+}
+
+// executeClearBit executes a ClearBit() call.
+func (e *Executor) executeClearBit(ctx context.Context, index string, c *pql.Call, opt *ExecOptions) (bool, error) {
+	args, err := e.readBitArgs(index, c)
+	if err != nil {
+		return false, err
+	}
+	f, rowID, colID, timestamp := args.frame, args.rowID, args.colID, args.timestamp
+
+	// Invalidate cached results once the write completes.
+	defer e.InvalidateQueryCacheFrame(index, f.Name())
+
+	// Clear bits for each view.
+	switch args.view {
+	case ViewStandard:
+		return e.executeClearBitView(ctx, index, c, f, ViewStandard, colID, rowID, timestamp, opt)
+	case ViewInverse:
+		return e.executeClearBitView(ctx, index, c, f, ViewInverse, rowID, colID, timestamp, opt)
+	default:
+		var ret bool
+		if changed, err := e.executeClearBitView(ctx, index, c, f, ViewStandard, colID, rowID, timestamp, opt); err != nil {
+			return ret, err
+		} else if changed {
+			ret = true
+		}
+
+		if f.InverseEnabled() && !opt.SkipInverse {
+			if changed, err := e.executeClearBitView(ctx, index, c, f, ViewInverse, rowID, colID, timestamp, opt); err != nil {
+				return ret, err
+			} else if changed {
+				ret = true
+			}
+		}
+		return ret, nil
+	}
+}
+
+// executeClearBitView executes a ClearBit() call for a single view.
+func (e *Executor) executeClearBitView(ctx context.Context, index string, c *pql.Call, f *Frame, view string, colID, rowID uint64, timestamp *time.Time, opt *ExecOptions) (bool, error) {
+	slice := colID / SliceWidth
+	ret := false
+
+	// Identify forwarded calls so that duplicate requests are only applied once.
+	fopt := opt
+	if !opt.Remote {
+		other := *opt
+		other.OperationID = newOperationID()
+		fopt = &other
+	}
+
+	nodes := e.Cluster.FragmentNodes(index, slice)
+	if len(nodes) == 0 && !e.AllowUnownedWrites {
+		return false, ErrSliceUnowned
+	}
+
+	for _, node := range nodes {
+		// Update locally if host matches.
+		if node.Host == e.Host {
+			val, err := f.ClearBit(view, rowID, colID, timestamp)
+			if err != nil {
+				return false, err
+			} else if val {
+				ret = true
+			}
+			continue
+		}
+		// Do not forward call if this is already being forwarded.
+		if opt.Remote {
+			continue
+		}
+
+		// Forward call to remote node otherwise.
+		if res, err := e.exec(ctx, node, index, &pql.Query{Calls: []*pql.Call{c}}, nil, fopt); err != nil {
+			return false, err
+		} else {
+			ret = res[0].(bool)
+		}
+	}
+	return ret, nil
+}
+
+// executeBits executes a SetBits() or ClearBits() call.
+// Returns the number of bits changed in the first view, as reported by
+// each slice's primary owner.
+func (e *Executor) executeBits(ctx context.Context, index string, c *pql.Call, opt *ExecOptions) (uint64, error) {
+	set := c.Name == "SetBits"
+	f, views, rowIDs, columnIDs, err := e.readBitsArgs(index, c, opt.SkipInverse)
+	if err != nil {
+		return 0, err
+	}
+
+	// Reject the batch before writing any bits if a slice has no owners.
+	if !e.AllowUnownedWrites {
+		for _, view := range views {
+			for j := range rowIDs {
+				slice := columnIDs[j] / SliceWidth
+				if view == ViewInverse {
+					slice = rowIDs[j] / SliceWidth
+				}
+				if len(e.Cluster.FragmentNodes(index, slice)) == 0 {
+					return 0, ErrSliceUnowned
+				}
+			}
+		}
+	}
+
+	// Invalidate cached results once the write completes.
+	defer e.InvalidateQueryCacheFrame(index, f.Name())
+
+	// Clear local bits and group remote bits by node & view.
+	type batchKey struct {
+		node *Node
+		view string
+	}
+	batches := make(map[batchKey]*pql.Call)
+
+	var n uint64
+	for i, view := range views {
+		for j := range rowIDs {
+			rowID, colID := rowIDs[j], columnIDs[j]
+
+			// Inverse views are stored with rows & columns swapped.
+			slice := colID / SliceWidth
+			if view == ViewInverse {
+				slice, rowID, colID = rowID/SliceWidth, colID, rowID
+			}
+
+			for k, node := range e.Cluster.FragmentNodes(index, slice) {
+				// Update locally if host matches.
+				if node.Host == e.Host {
+					var changed bool
+					if set {
+						changed, err = f.SetBit(view, rowID, colID, nil)
+					} else {
+						changed, err = f.ClearBit(view, rowID, colID, nil)
+					}
+					if err != nil {
+						return 0, err
+					} else if changed && i == 0 && k == 0 {
+						n++
+					}
+					continue
+				}
+
+				// Do not forward call if this is already being forwarded.
+				if opt.Remote {
+					continue
+				}
+
+				// Add the original ids to the node's batch otherwise.
+				key := batchKey{node: node, view: view}
+				batch := batches[key]
+				if batch == nil {
+					batch = &pql.Call{
+						Name: c.Name,
+						Args: map[string]interface{}{
+							"frame":     f.Name(),
+							"view":      view,
+							"rowIDs":    []uint64{},
+							"columnIDs": []uint64{},
+						},
+					}
+					batches[key] = batch
+				}
+				batch.Args["rowIDs"] = append(batch.Args["rowIDs"].([]uint64), rowIDs[j])
+				batch.Args["columnIDs"] = append(batch.Args["columnIDs"].([]uint64), columnIDs[j])
+			}
+		}
+	}
+
+	// Forward batches to remote nodes in parallel. Each node only counts bits
+	// for slices it is the primary owner of.
+	type batchResponse struct {
+		n   uint64
+		err error
+	}
+	resp := make(chan batchResponse, len(batches))
+	for key, batch := range batches {
+		// Identify each batch so that duplicate requests are only applied once.
+		bopt := *opt
+		bopt.OperationID = newOperationID()
+
+		go func(key batchKey, batch *pql.Call, opt *ExecOptions) {
+			res, err := e.exec(ctx, key.node, index, &pql.Query{Calls: []*pql.Call{batch}}, nil, opt)
+			if err != nil {
+				resp <- batchResponse{err: err}
+				return
+			}
+
+			var r batchResponse
+			if key.view == views[0] {
+				r.n, _ = res[0].(uint64)
+			}
+			resp <- r
+		}(key, batch, &bopt)
+	}
+
+	// Return first error.
+	for range batches {
+		r := <-resp
+		if r.err != nil {
+			return 0, r.err
+		}
+		n += r.n
+	}
+
+	return n, nil
+}
+
+// executeClearMatching executes a ClearMatching() call.
+// The bits of the row which are set for columns in the filter bitmap are
+// read across all slices and then cleared with ClearBits() so that they are
+// cleared on every node owning the fragment. Returns the number of bits
+// cleared.
+func (e *Executor) executeClearMatching(ctx context.Context, index string, c *pql.Call, slices []uint64, opt *ExecOptions) (uint64, error) {
+	f, rowID, err := e.readClearMatchingArgs(index, c)
+	if err != nil {
+		return 0, err
+	}
+
+	// Find the set columns of the row within the filter.
+	bm, err := e.executeBitmapCall(ctx, index, &pql.Call{
+		Name: "Intersect",
+		Children: []*pql.Call{
+			{Name: "Bitmap", Args: map[string]interface{}{"frame": f.Name(), f.RowLabel(): rowID}},
+			c.Children[0],
+		},
+	}, slices, opt)
 	if err != nil {
 		return 0, err
 	}
-	n, _ := result.(uint64)
 
-	return n, nil
+	columnIDs := bm.Bits()
+	if len(columnIDs) == 0 {
+		return 0, nil
+	}
+	rowIDs := make([]uint64, len(columnIDs))
+	for i := range rowIDs {
+		rowIDs[i] = rowID
+	}
+
+	return e.executeBits(ctx, index, &pql.Call{
+		Name: "ClearBits",
+		Args: map[string]interface{}{
+			"frame":     f.Name(),
+			"rowIDs":    rowIDs,
+			"columnIDs": columnIDs,
+		},
+	}, opt)
+}
+
+// readClearMatchingArgs parses the arguments of a ClearMatching() call.
+// Returns the frame and the row to clear.
+func (e *Executor) readClearMatchingArgs(index string, c *pql.Call) (*Frame, uint64, error) {
+	if len(c.Children) != 1 {
+		return nil, 0, errors.New("ClearMatching() requires a single filter bitmap")
+	}
+
+	frame, ok := c.Args["frame"].(string)
+	if !ok {
+		return nil, 0, errors.New("ClearMatching() frame required")
+	}
+
+	// Retrieve frame.
+	f := e.Holder.Frame(index, frame)
+	if f == nil {
+		return nil, 0, ErrFrameNotFound
+	}
+
+	rowLabel := f.RowLabel()
+	rowID, ok, err := c.UintArg(rowLabel)
+	if err != nil {
+		return nil, 0, fmt.Errorf("reading ClearMatching() row: %v", err)
+	} else if !ok {
+		return nil, 0, fmt.Errorf("ClearMatching() row field '%v' required", rowLabel)
+	}
+
+	return f, rowID, nil
+}
+
+// readBitsArgs parses the arguments of a SetBits() or ClearBits() call.
+// Returns the frame, views to write and the row & column ids of each bit.
+// Calls without a view only write the standard view if skipInverse is set.
+func (e *Executor) readBitsArgs(index string, c *pql.Call, skipInverse bool) (*Frame, []string, []uint64, []uint64, error) {
+	view, _ := c.Args["view"].(string)
+	frame, ok := c.Args["frame"].(string)
+	if !ok {
+		return nil, nil, nil, nil, fmt.Errorf("%s() frame required", c.Name)
+	}
+
+	// Retrieve frame.
+	f := e.Holder.Frame(index, frame)
+	if f == nil {
+		return nil, nil, nil, nil, ErrFrameNotFound
+	}
+
+	// Determine views to clear.
+	var views []string
+	switch view {
+	case ViewStandard, ViewInverse:
+		views = []string{view}
+	case "":
+		views = []string{ViewStandard}
+		if f.InverseEnabled() && !skipInverse {
+			views = append(views, ViewInverse)
+		}
+	default:
+		return nil, nil, nil, nil, fmt.Errorf("invalid view: %s", view)
+	}
+
+	// Read parallel lists of row & column ids.
+	rowIDs, _, err := c.UintSliceArg("rowIDs")
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("reading %s() rowIDs: %v", c.Name, err)
+	}
+	columnIDs, _, err := c.UintSliceArg("columnIDs")
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("reading %s() columnIDs: %v", c.Name, err)
+	} else if len(rowIDs) != len(columnIDs) {
+		return nil, nil, nil, nil, fmt.Errorf("%s() rowIDs and columnIDs length mismatch: %d != %d", c.Name, len(rowIDs), len(columnIDs))
+	}
+
+	return f, views, rowIDs, columnIDs, nil
+}
+
+// IngestBits sets the bits read from r in the standard and, if enabled,
+// inverse views of a frame. The input is a sequence of row & column id
+// pairs, each encoded as two uvarints; see AppendIngestBit(). Bits are
+// written with a SetBits() call for every IngestBatchN pairs read so the
+// input is never held in memory. Returns the number of bits changed in the
+// standard view. Batches written before an error are not rolled back.
+func (e *Executor) IngestBits(ctx context.Context, index, frame string, r io.Reader) (uint64, error) {
+	batchN := e.IngestBatchN
+	if batchN <= 0 {
+		batchN = DefaultIngestBatchN
+	}
+
+	br := bufio.NewReader(r)
+	rowIDs := make([]uint64, 0, batchN)
+	columnIDs := make([]uint64, 0, batchN)
+
+	var n uint64
+	flush := func() error {
+		if len(rowIDs) == 0 {
+			return nil
+		}
+		results, err := e.Execute(ctx, index, &pql.Query{Calls: []*pql.Call{{
+			Name: "SetBits",
+			Args: map[string]interface{}{
+				"frame":     frame,
+				"rowIDs":    rowIDs,
+				"columnIDs": columnIDs,
+			},
+		}}}, nil, nil)
+		if err != nil {
+			return err
+		}
+		v, _ := results[0].(uint64)
+		n += v
+
+		// The call is done with the ids so their buffers can be reused.
+		rowIDs, columnIDs = rowIDs[:0], columnIDs[:0]
+		return nil
+	}
+
+	for {
+		rowID, err := binary.ReadUvarint(br)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return n, fmt.Errorf("reading ingest row: %v", err)
+		}
+		columnID, err := binary.ReadUvarint(br)
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return n, fmt.Errorf("reading ingest column: %v", err)
+		}
+
+		rowIDs, columnIDs = append(rowIDs, rowID), append(columnIDs, columnID)
+		if len(rowIDs) >= batchN {
+			if err := flush(); err != nil {
+				return n, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return n, err
+	}
+	return n, nil
+}
+
+// AppendIngestBit appends a row & column id pair encoded for IngestBits()
+// to dst and returns the extended buffer.
+func AppendIngestBit(dst []byte, rowID, columnID uint64) []byte {
+	var buf [2 * binary.MaxVarintLen64]byte
+	i := binary.PutUvarint(buf[:], rowID)
+	i += binary.PutUvarint(buf[i:], columnID)
+	return append(dst, buf[:i]...)
+}
+
+// executeSetRowFromRoaring executes a SetRowFromRoaring() call.
+// The columns of the roaring bitmap are split by slice and imported in bulk
+// into the row's fragments. Each remote node receives a single call holding
+// the columns of every slice it owns.
+func (e *Executor) executeSetRowFromRoaring(ctx context.Context, index string, c *pql.Call, opt *ExecOptions) error {
+	args, err := e.readSetRowFromRoaringArgs(index, c)
+	if err != nil {
+		return err
+	}
+	f, rowID := args.frame, args.rowID
+
+	// Invalidate cached results once the write completes.
+	defer e.InvalidateQueryCacheFrame(index, f.Name())
+
+	// Determine views to set.
+	var views []string
+	if args.view == ViewStandard || args.view == "" {
+		views = append(views, ViewStandard)
+	}
+	if args.view == ViewInverse || (args.view == "" && f.InverseEnabled() && !opt.SkipInverse) {
+		views = append(views, ViewInverse)
+	}
+
+	// Split columns by slice. Inverse views store the row as a column so the
+	// entire row is stored in the row's slice.
+	columnIDs := args.bitmap.Slice()
+
+	// Reject the row before importing any columns if a slice has no owners.
+	if !e.AllowUnownedWrites {
+		for _, view := range views {
+			if view == ViewInverse {
+				if len(columnIDs) > 0 && len(e.Cluster.FragmentNodes(index, rowID/SliceWidth)) == 0 {
+					return ErrSliceUnowned
+				}
+				continue
+			}
+			for i, id := range columnIDs {
+				if i > 0 && id/SliceWidth == columnIDs[i-1]/SliceWidth {
+					continue
+				}
+				if len(e.Cluster.FragmentNodes(index, id/SliceWidth)) == 0 {
+					return ErrSliceUnowned
+				}
+			}
+		}
+	}
+	type batchKey struct {
+		node *Node
+		view string
+	}
+	batches := make(map[batchKey][]uint64)
+	for _, view := range views {
+		for i := 0; i < len(columnIDs); {
+			slice := columnIDs[i] / SliceWidth
+			j := len(columnIDs)
+			if view == ViewStandard {
+				j = i + sort.Search(len(columnIDs)-i, func(k int) bool { return columnIDs[i+k] >= (slice+1)*SliceWidth })
+			} else {
+				slice = rowID / SliceWidth
+			}
+
+			for _, node := range e.Cluster.FragmentNodes(index, slice) {
+				// Import locally if host matches.
+				if node.Host == e.Host {
+					if err := importRowColumns(f, view, slice, rowID, columnIDs[i:j]); err != nil {
+						return err
+					}
+					continue
+				}
+
+				// Do not forward call if this is already being forwarded.
+				if opt.Remote {
+					continue
+				}
+
+				key := batchKey{node: node, view: view}
+				batches[key] = append(batches[key], columnIDs[i:j]...)
+			}
+			i = j
+		}
+	}
+
+	// Forward batches to remote nodes in parallel.
+	resp := make(chan error, len(batches))
+	for key, ids := range batches {
+		go func(key batchKey, ids []uint64) {
+			var buf bytes.Buffer
+			if _, err := roaring.NewBitmap(ids...).WriteTo(&buf); err != nil {
+				resp <- err
+				return
+			}
+
+			_, err := e.exec(ctx, key.node, index, &pql.Query{Calls: []*pql.Call{{
+				Name: "SetRowFromRoaring",
+				Args: map[string]interface{}{
+					"frame":      f.Name(),
+					f.RowLabel(): rowID,
+					"view":       key.view,
+					"data":       base64.StdEncoding.EncodeToString(buf.Bytes()),
+				},
+			}}}, nil, opt)
+			resp <- err
+		}(key, ids)
+	}
+
+	// Return first error.
+	for range batches {
+		if err := <-resp; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importRowColumns bulk imports the columns of a row into a single fragment.
+// The columns must be sorted and belong to the fragment's slice.
+func importRowColumns(f *Frame, view string, slice, rowID uint64, columnIDs []uint64) error {
+	v, err := f.CreateViewIfNotExists(view)
+	if err != nil {
+		return err
+	}
+	frag, err := v.CreateFragmentIfNotExists(slice)
+	if err != nil {
+		return err
+	}
+
+	rowIDs := make([]uint64, len(columnIDs))
+	for i := range rowIDs {
+		rowIDs[i] = rowID
+	}
+
+	// Inverse views are stored with rows & columns swapped.
+	if view == ViewInverse {
+		return frag.Import(columnIDs, rowIDs)
+	}
+	return frag.Import(rowIDs, columnIDs)
+}
+
+// setRowFromRoaringArgs represents the parsed arguments of a SetRowFromRoaring() call.
+type setRowFromRoaringArgs struct {
+	frame  *Frame
+	view   string // blank for all views
+	rowID  uint64
+	bitmap *roaring.Bitmap
 }
 
-// executeClearBit executes a ClearBit() call.
-func (e *Executor) executeClearBit(ctx context.Context, index string, c *pql.Call, opt *ExecOptions) (bool, error) {
+// readSetRowFromRoaringArgs parses the arguments of a SetRowFromRoaring() call.
+// The data argument holds the base64 encoded roaring bitmap of the row's columns.
+func (e *Executor) readSetRowFromRoaringArgs(index string, c *pql.Call) (*setRowFromRoaringArgs, error) {
 	view, _ := c.Args["view"].(string)
 	frame, ok := c.Args["frame"].(string)
 	if !ok {
-		return false, errors.New("ClearBit() frame required")
+		return nil, errors.New("SetRowFromRoaring() frame required")
 	}
 
-	// Retrieve frame.
-	idx := e.Holder.Index(index)
-	if idx == nil {
-		return false, ErrIndexNotFound
+	switch view {
+	case "", ViewStandard, ViewInverse:
+	default:
+		return nil, fmt.Errorf("invalid view: %s", view)
 	}
-	f := idx.Frame(frame)
+
+	// Retrieve frame.
+	f := e.Holder.Frame(index, frame)
 	if f == nil {
-		return false, ErrFrameNotFound
+		return nil, ErrFrameNotFound
 	}
 
-	// Retrieve labels.
-	columnLabel := idx.ColumnLabel()
 	rowLabel := f.RowLabel()
-
-	// Read fields using labels.
 	rowID, ok, err := c.UintArg(rowLabel)
 	if err != nil {
-		return false, fmt.Errorf("reading ClearBit() row: %v", err)
+		return nil, fmt.Errorf("reading SetRowFromRoaring() row: %v", err)
 	} else if !ok {
-		return false, fmt.Errorf("ClearBit() row field '%v' required", rowLabel)
+		return nil, fmt.Errorf("SetRowFromRoaring() row field '%v' required", rowLabel)
 	}
 
-	colID, ok, err := c.UintArg(columnLabel)
+	s, ok := c.Args["data"].(string)
+	if !ok {
+		return nil, errors.New("SetRowFromRoaring() data required")
+	}
+	data, err := base64.StdEncoding.DecodeString(s)
 	if err != nil {
-		return false, fmt.Errorf("reading ClearBit() column: %v", err)
-	} else if !ok {
-		return false, fmt.Errorf("ClearBit col field '%v' required", columnLabel)
+		return nil, fmt.Errorf("decoding SetRowFromRoaring() data: %v", err)
+	}
+	bm := roaring.NewBitmap()
+	if err := bm.UnmarshalBinary(data); err != nil {
+		return nil, fmt.Errorf("invalid SetRowFromRoaring() roaring data: %v", err)
 	}
 
-	// Clear bits for each view.
-	switch view {
-	case ViewStandard:
-		return e.executeClearBitView(ctx, index, c, f, view, colID, rowID, opt)
-	case ViewInverse:
-		return e.executeClearBitView(ctx, index, c, f, view, rowID, colID, opt)
-	case "":
-		var ret bool
-		if changed, err := e.executeClearBitView(ctx, index, c, f, ViewStandard, colID, rowID, opt); err != nil {
-			return ret, err
-		} else if changed {
-			ret = true
-		}
+	return &setRowFromRoaringArgs{
+		frame:  f,
+		view:   view,
+		rowID:  rowID,
+		bitmap: bm,
+	}, nil
+}
 
-		if f.InverseEnabled() {
-			if changed, err := e.executeClearBitView(ctx, index, c, f, ViewInverse, rowID, colID, opt); err != nil {
-				return ret, err
-			} else if changed {
-				ret = true
-			}
-		}
-		return ret, nil
-	default:
-		return false, fmt.Errorf("invalid view: %s", view)
+// executeSetBit executes a SetBit() call.
+func (e *Executor) executeSetBit(ctx context.Context, index string, c *pql.Call, opt *ExecOptions) (SetBitResult, error) {
+	var ret SetBitResult
+
+	args, err := e.readBitArgs(index, c)
+	if err != nil {
+		return ret, err
 	}
-}
+	f, rowID, colID, timestamp := args.frame, args.rowID, args.colID, args.timestamp
 
-// executeClearBitView executes a ClearBit() call for a single view.
-func (e *Executor) executeClearBitView(ctx context.Context, index string, c *pql.Call, f *Frame, view string, colID, rowID uint64, opt *ExecOptions) (bool, error) {
-	slice := colID / SliceWidth
-	ret := false
-	for _, node := range e.Cluster.FragmentNodes(index, slice) {
-		// Update locally if host matches.
-		if node.Host == e.Host {
-			val, err := f.ClearBit(view, rowID, colID, nil)
-			if err != nil {
-				return false, err
-			} else if val {
-				ret = true
-			}
-			continue
-		}
-		// Do not forward call if this is already being forwarded.
-		if opt.Remote {
-			continue
-		}
+	// Invalidate cached results once the write completes.
+	defer e.InvalidateQueryCacheFrame(index, f.Name())
 
-		// Forward call to remote node otherwise.
-		if res, err := e.exec(ctx, node, index, &pql.Query{Calls: []*pql.Call{c}}, nil, opt); err != nil {
-			return false, err
-		} else {
-			ret = res[0].(bool)
+	// Set bits for each view.
+	if args.view == ViewStandard || args.view == "" {
+		if ret.Standard, err = e.executeSetBitView(ctx, index, c, f, ViewStandard, colID, rowID, timestamp, opt); err != nil {
+			return ret, err
+		}
+	}
+	if args.view == ViewInverse || (args.view == "" && f.InverseEnabled() && !opt.SkipInverse) {
+		if ret.Inverse, err = e.executeSetBitView(ctx, index, c, f, ViewInverse, rowID, colID, timestamp, opt); err != nil {
+			return ret, err
 		}
 	}
 	return ret, nil
 }
 
-// executeSetBit executes a SetBit() call.
-func (e *Executor) executeSetBit(ctx context.Context, index string, c *pql.Call, opt *ExecOptions) (bool, error) {
+// SetBitResult represents the views changed by a SetBit() call.
+type SetBitResult struct {
+	Standard bool `json:"standard"`
+	Inverse  bool `json:"inverse"`
+}
+
+// Changed returns true if any view was changed.
+func (r SetBitResult) Changed() bool { return r.Standard || r.Inverse }
+
+// bitArgs represents the parsed arguments of a SetBit() or ClearBit() call.
+type bitArgs struct {
+	frame     *Frame
+	view      string // blank for all views
+	rowID     uint64
+	colID     uint64
+	timestamp *time.Time
+}
+
+// readBitArgs parses the arguments of a SetBit() or ClearBit() call.
+// Returns an error if the frame does not exist or an argument is missing or invalid.
+func (e *Executor) readBitArgs(index string, c *pql.Call) (*bitArgs, error) {
 	view, _ := c.Args["view"].(string)
 	frame, ok := c.Args["frame"].(string)
 	if !ok {
-		return false, errors.New("SetBit() field required: frame")
+		return nil, fmt.Errorf("%s() frame required", c.Name)
+	}
+
+	switch view {
+	case "", ViewStandard, ViewInverse:
+	default:
+		return nil, fmt.Errorf("invalid view: %s", view)
 	}
 
 	// Retrieve frame.
 	idx := e.Holder.Index(index)
 	if idx == nil {
-		return false, ErrIndexNotFound
+		return nil, ErrIndexNotFound
 	}
 	f := idx.Frame(frame)
 	if f == nil {
-		return false, ErrFrameNotFound
+		return nil, ErrFrameNotFound
 	}
 
 	// Retrieve labels.
@@ -715,53 +4554,32 @@ func (e *Executor) executeSetBit(ctx context.Context, index string, c *pql.Call,
 	// Read fields using labels.
 	rowID, ok, err := c.UintArg(rowLabel)
 	if err != nil {
-		return false, fmt.Errorf("reading SetBit() row: %v", err)
+		return nil, fmt.Errorf("reading %s() row: %v", c.Name, err)
 	} else if !ok {
-		return false, fmt.Errorf("SetBit() row field '%v' required", rowLabel)
+		return nil, fmt.Errorf("%s() row field '%v' required", c.Name, rowLabel)
 	}
 
-	colID, ok, err := c.UintArg(columnLabel)
+	colID, _, _, err := readColumnID(c, true, columnLabel)
 	if err != nil {
-		return false, fmt.Errorf("reading SetBit() column: %v", err)
-	} else if !ok {
-		return false, fmt.Errorf("SetBit() column field '%v' required", columnLabel)
+		return nil, err
 	}
 
 	var timestamp *time.Time
-	sTimestamp, ok := c.Args["timestamp"].(string)
-	if ok {
+	if sTimestamp, ok := c.Args["timestamp"].(string); ok {
 		t, err := time.Parse(TimeFormat, sTimestamp)
 		if err != nil {
-			return false, fmt.Errorf("invalid date: %s", sTimestamp)
+			return nil, fmt.Errorf("invalid date: %s", sTimestamp)
 		}
 		timestamp = &t
 	}
 
-	// Set bits for each view.
-	switch view {
-	case ViewStandard:
-		return e.executeSetBitView(ctx, index, c, f, view, colID, rowID, timestamp, opt)
-	case ViewInverse:
-		return e.executeSetBitView(ctx, index, c, f, view, rowID, colID, timestamp, opt)
-	case "":
-		var ret bool
-		if changed, err := e.executeSetBitView(ctx, index, c, f, ViewStandard, colID, rowID, timestamp, opt); err != nil {
-			return ret, err
-		} else if changed {
-			ret = true
-		}
-
-		if f.InverseEnabled() {
-			if changed, err := e.executeSetBitView(ctx, index, c, f, ViewInverse, rowID, colID, timestamp, opt); err != nil {
-				return ret, err
-			} else if changed {
-				ret = true
-			}
-		}
-		return ret, nil
-	default:
-		return false, fmt.Errorf("invalid view: %s", view)
-	}
+	return &bitArgs{
+		frame:     f,
+		view:      view,
+		rowID:     rowID,
+		colID:     colID,
+		timestamp: timestamp,
+	}, nil
 }
 
 // executeSetBitView executes a SetBit() call for a specific view.
@@ -769,7 +4587,20 @@ func (e *Executor) executeSetBitView(ctx context.Context, index string, c *pql.C
 	slice := colID / SliceWidth
 	ret := false
 
-	for _, node := range e.Cluster.FragmentNodes(index, slice) {
+	// Identify forwarded calls so that duplicate requests are only applied once.
+	fopt := opt
+	if !opt.Remote {
+		other := *opt
+		other.OperationID = newOperationID()
+		fopt = &other
+	}
+
+	nodes := e.Cluster.FragmentNodes(index, slice)
+	if len(nodes) == 0 && !e.AllowUnownedWrites {
+		return false, ErrSliceUnowned
+	}
+
+	for _, node := range nodes {
 		// Update locally if host matches.
 		if node.Host == e.Host {
 			val, err := f.SetBit(view, rowID, colID, timestamp)
@@ -787,7 +4618,7 @@ func (e *Executor) executeSetBitView(ctx context.Context, index string, c *pql.C
 		}
 
 		// Forward call to remote node otherwise.
-		if res, err := e.exec(ctx, node, index, &pql.Query{Calls: []*pql.Call{c}}, nil, opt); err != nil {
+		if res, err := e.exec(ctx, node, index, &pql.Query{Calls: []*pql.Call{c}}, nil, fopt); err != nil {
 			return false, err
 		} else {
 			ret = res[0].(bool)
@@ -798,30 +4629,13 @@ func (e *Executor) executeSetBitView(ctx context.Context, index string, c *pql.C
 
 // executeSetRowAttrs executes a SetRowAttrs() call.
 func (e *Executor) executeSetRowAttrs(ctx context.Context, index string, c *pql.Call, opt *ExecOptions) error {
-	frameName, ok := c.Args["frame"].(string)
-	if !ok {
-		return errors.New("SetRowAttrs() frame required")
-	}
-
-	// Retrieve frame.
-	frame := e.Holder.Frame(index, frameName)
-	if frame == nil {
-		return ErrFrameNotFound
-	}
-	rowLabel := frame.RowLabel()
-
-	// Parse labels.
-	rowID, ok, err := c.UintArg(rowLabel)
+	frame, rowID, attrs, err := e.readSetRowAttrsArgs(index, c)
 	if err != nil {
-		return fmt.Errorf("reading SetRowAttrs() row: %v", err)
-	} else if !ok {
-		return fmt.Errorf("SetRowAttrs() row field '%v' required", rowLabel)
+		return err
 	}
 
-	// Copy args and remove reserved fields.
-	attrs := pql.CopyArgs(c.Args)
-	delete(attrs, "frame")
-	delete(attrs, rowLabel)
+	// Invalidate cached results once the write completes.
+	defer e.InvalidateQueryCacheFrame(index, frame.Name())
 
 	// Set attributes.
 	if err := frame.RowAttrStore().SetAttrs(rowID, attrs); err != nil {
@@ -853,34 +4667,90 @@ func (e *Executor) executeSetRowAttrs(ctx context.Context, index string, c *pql.
 	return nil
 }
 
+// readSetRowAttrsArgs returns the frame, row id & attributes of a SetRowAttrs() call.
+func (e *Executor) readSetRowAttrsArgs(index string, c *pql.Call) (*Frame, uint64, map[string]interface{}, error) {
+	frameName, ok := c.Args["frame"].(string)
+	if !ok {
+		return nil, 0, nil, errors.New("SetRowAttrs() frame required")
+	}
+
+	// Retrieve frame.
+	frame := e.Holder.Frame(index, frameName)
+	if frame == nil {
+		return nil, 0, nil, ErrFrameNotFound
+	}
+	rowLabel := frame.RowLabel()
+
+	// Parse labels.
+	rowID, ok, err := c.UintArg(rowLabel)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("reading SetRowAttrs() row: %v", err)
+	} else if !ok {
+		return nil, 0, nil, fmt.Errorf("SetRowAttrs() row field '%v' required", rowLabel)
+	}
+
+	// Copy args and remove reserved fields.
+	attrs := pql.CopyArgs(c.Args)
+	delete(attrs, "frame")
+	delete(attrs, rowLabel)
+	if err := validateAttrs(c.Name, attrs); err != nil {
+		return nil, 0, nil, err
+	} else if err := e.checkRowAttrsSize(rowID, attrs); err != nil {
+		return nil, 0, nil, err
+	}
+
+	return frame, rowID, attrs, nil
+}
+
+// checkRowAttrsSize returns an error if an attribute value or the attributes
+// written to a row exceed MaxRowAttrSize or MaxRowAttrsSize.
+func (e *Executor) checkRowAttrsSize(rowID uint64, attrs map[string]interface{}) error {
+	if e.MaxRowAttrSize <= 0 && e.MaxRowAttrsSize <= 0 {
+		return nil
+	}
+
+	var total int
+	for k, v := range attrs {
+		n := attrValueSize(v)
+		if e.MaxRowAttrSize > 0 && n > e.MaxRowAttrSize {
+			return fmt.Errorf("SetRowAttrs() attribute %s exceeds the maximum size of %d bytes", k, e.MaxRowAttrSize)
+		}
+		total += len(k) + n
+	}
+	if e.MaxRowAttrsSize > 0 && total > e.MaxRowAttrsSize {
+		return fmt.Errorf("SetRowAttrs() attributes of row %d exceed the maximum size of %d bytes", rowID, e.MaxRowAttrsSize)
+	}
+	return nil
+}
+
+// attrValueSize returns the size in bytes counted for an attribute value.
+func attrValueSize(v interface{}) int {
+	switch v := v.(type) {
+	case nil:
+		return 0
+	case string:
+		return len(v)
+	case []interface{}:
+		var n int
+		for _, elem := range v {
+			n += attrValueSize(elem)
+		}
+		return n
+	default:
+		return 8
+	}
+}
+
 // executeBulkSetRowAttrs executes a set of SetRowAttrs() calls.
 func (e *Executor) executeBulkSetRowAttrs(ctx context.Context, index string, calls []*pql.Call, opt *ExecOptions) ([]interface{}, error) {
 	// Collect attributes by frame/id.
 	m := make(map[string]map[uint64]map[string]interface{})
 	for _, c := range calls {
-		frame, ok := c.Args["frame"].(string)
-		if !ok {
-			return nil, errors.New("SetRowAttrs() frame required")
-		}
-
-		// Retrieve frame.
-		f := e.Holder.Frame(index, frame)
-		if f == nil {
-			return nil, ErrFrameNotFound
-		}
-		rowLabel := f.RowLabel()
-
-		rowID, ok, err := c.UintArg(rowLabel)
+		f, rowID, attrs, err := e.readSetRowAttrsArgs(index, c)
 		if err != nil {
-			return nil, fmt.Errorf("reading SetRowAttrs() row: %v", rowLabel)
-		} else if !ok {
-			return nil, fmt.Errorf("SetRowAttrs row field '%v' required", rowLabel)
+			return nil, err
 		}
-
-		// Copy args and remove reserved fields.
-		attrs := pql.CopyArgs(c.Args)
-		delete(attrs, "frame")
-		delete(attrs, rowLabel)
+		frame := f.Name()
 
 		// Create frame group, if not exists.
 		frameMap := m[frame]
@@ -897,6 +4767,9 @@ func (e *Executor) executeBulkSetRowAttrs(ctx context.Context, index string, cal
 			for k, v := range attrs {
 				attr[k] = v
 			}
+			if err := e.checkRowAttrsSize(rowID, attr); err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -912,11 +4785,112 @@ func (e *Executor) executeBulkSetRowAttrs(ctx context.Context, index string, cal
 		if err := frame.RowAttrStore().SetBulkAttrs(frameMap); err != nil {
 			return nil, err
 		}
+		e.InvalidateQueryCacheFrame(index, name)
+	}
+
+	// Do not forward call if this is already being forwarded.
+	if opt.Remote {
+		return make([]interface{}, len(calls)), nil
+	}
+
+	// Execute on remote nodes in parallel.
+	if err := e.forwardBulkAttrs(ctx, index, calls, opt); err != nil {
+		return nil, err
+	}
+
+	// Return a set of nil responses to match the non-optimized return.
+	return make([]interface{}, len(calls)), nil
+}
+
+// executeBulkSetColumnAttrs executes a set of SetColumnAttrs() calls.
+func (e *Executor) executeBulkSetColumnAttrs(ctx context.Context, index string, calls []*pql.Call, opt *ExecOptions) ([]interface{}, error) {
+	// Collect attributes by id.
+	var idx *Index
+	m := make(map[uint64]map[string]interface{})
+	for _, c := range calls {
+		i, id, attrs, err := e.readSetColumnAttrsArgs(index, c)
+		if err != nil {
+			return nil, err
+		}
+		idx = i
+
+		// Set or merge attributes.
+		attr := m[id]
+		if attr == nil {
+			m[id] = cloneAttrs(attrs)
+		} else {
+			for k, v := range attrs {
+				attr[k] = v
+			}
+		}
+	}
+
+	// Invalidate cached results once the write completes.
+	defer e.invalidateQueryCache(index)
+
+	// Bulk insert attributes.
+	if err := idx.ColumnAttrStore().SetBulkAttrs(m); err != nil {
+		return nil, err
+	}
+
+	// Do not forward call if this is already being forwarded.
+	if opt.Remote {
+		return make([]interface{}, len(calls)), nil
+	}
+
+	// Execute on remote nodes in parallel.
+	if err := e.forwardBulkAttrs(ctx, index, calls, opt); err != nil {
+		return nil, err
+	}
+
+	// Return a set of nil responses to match the non-optimized return.
+	return make([]interface{}, len(calls)), nil
+}
+
+// forwardBulkAttrs executes bulk attribute calls on all remote nodes in
+// parallel and returns the first error, which cancels the other forwards.
+func (e *Executor) forwardBulkAttrs(ctx context.Context, index string, calls []*pql.Call, opt *ExecOptions) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The channel is buffered for every node so forwards still running
+	// after an error do not block on send.
+	nodes := Nodes(e.Cluster.Nodes).FilterHost(e.Host)
+	resp := make(chan error, len(nodes))
+	for _, node := range nodes {
+		go func(node *Node) {
+			_, err := e.exec(ctx, node, index, &pql.Query{Calls: calls}, nil, opt)
+			resp <- err
+		}(node)
+	}
+
+	// Return first error.
+	for range nodes {
+		if err := <-resp; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// executeSetColumnAttrs executes a SetColumnAttrs() call.
+func (e *Executor) executeSetColumnAttrs(ctx context.Context, index string, c *pql.Call, opt *ExecOptions) error {
+	idx, id, attrs, err := e.readSetColumnAttrsArgs(index, c)
+	if err != nil {
+		return err
+	}
+
+	// Invalidate cached results once the write completes.
+	defer e.invalidateQueryCache(index)
+
+	// Set attributes.
+	if err := idx.ColumnAttrStore().SetAttrs(id, attrs); err != nil {
+		return err
 	}
 
 	// Do not forward call if this is already being forwarded.
 	if opt.Remote {
-		return make([]interface{}, len(calls)), nil
+		return nil
 	}
 
 	// Execute on remote nodes in parallel.
@@ -924,7 +4898,7 @@ func (e *Executor) executeBulkSetRowAttrs(ctx context.Context, index string, cal
 	resp := make(chan error, len(nodes))
 	for _, node := range nodes {
 		go func(node *Node) {
-			_, err := e.exec(ctx, node, index, &pql.Query{Calls: calls}, nil, opt)
+			_, err := e.exec(ctx, node, index, &pql.Query{Calls: []*pql.Call{c}}, nil, opt)
 			resp <- err
 		}(node)
 	}
@@ -932,149 +4906,370 @@ func (e *Executor) executeBulkSetRowAttrs(ctx context.Context, index string, cal
 	// Return first error.
 	for range nodes {
 		if err := <-resp; err != nil {
-			return nil, err
+			return err
 		}
 	}
 
-	// Return a set of nil responses to match the non-optimized return.
-	return make([]interface{}, len(calls)), nil
+	return nil
 }
 
-// executeSetColumnAttrs executes a SetColumnAttrs() call.
-func (e *Executor) executeSetColumnAttrs(ctx context.Context, index string, c *pql.Call, opt *ExecOptions) error {
+// readSetColumnAttrsArgs returns the index, column id & attributes of a SetColumnAttrs() call.
+func (e *Executor) readSetColumnAttrsArgs(index string, c *pql.Call) (*Index, uint64, map[string]interface{}, error) {
 	// Retrieve index.
 	idx := e.Holder.Index(index)
 	if idx == nil {
-		return ErrIndexNotFound
+		return nil, 0, nil, ErrIndexNotFound
 	}
 
-	var colName string
-	id, okID, errID := c.UintArg("id")
-	if errID != nil || !okID {
-		// Retrieve columnLabel
-		columnLabel := idx.columnLabel
-		col, okCol, errCol := c.UintArg(columnLabel)
-		if errCol != nil || !okCol {
-			return fmt.Errorf("reading SetColumnAttrs() id/columnLabel errs: %v/%v found %v/%v", errID, errCol, okID, okCol)
+	// Columns may be specified by "id" or by the index's column label.
+	// An invalid "id" falls back to the column label, if present.
+	id, label, _, err := readColumnID(c, true, "id", idx.ColumnLabel())
+	if cerr, ok := err.(*ColumnIDError); ok && cerr.Label == "id" && c.Args[columnLabelArg] == nil {
+		if colID, colLabel, ok, _ := readColumnID(c, false, idx.ColumnLabel()); ok {
+			id, label, err = colID, colLabel, nil
 		}
-		id = col
-		colName = columnLabel
-	} else {
-		colName = "id"
+	}
+	if err != nil {
+		return nil, 0, nil, err
 	}
 
 	// Copy args and remove reserved fields.
 	attrs := pql.CopyArgs(c.Args)
-	delete(attrs, colName)
-
-	// Set attributes.
-	if err := idx.ColumnAttrStore().SetAttrs(id, attrs); err != nil {
-		return err
+	delete(attrs, label)
+	delete(attrs, columnLabelArg)
+	if err := validateAttrs(c.Name, attrs); err != nil {
+		return nil, 0, nil, err
 	}
 
-	// Do not forward call if this is already being forwarded.
-	if opt.Remote {
-		return nil
+	return idx, id, attrs, nil
+}
+
+// validateAttrs returns an error if any attribute value cannot be stored.
+// Lists are validated before execution so that no attributes are written.
+func validateAttrs(name string, attrs map[string]interface{}) error {
+	for k, v := range attrs {
+		if v == nil {
+			continue
+		} else if _, err := normalizeAttrValue(v); err != nil {
+			return fmt.Errorf("%s() attribute %s: %v", name, k, err)
+		}
 	}
+	return nil
+}
 
-	// Execute on remote nodes in parallel.
-	nodes := Nodes(e.Cluster.Nodes).FilterHost(e.Host)
-	resp := make(chan error, len(nodes))
-	for _, node := range nodes {
-		go func(node *Node) {
-			_, err := e.exec(ctx, node, index, &pql.Query{Calls: []*pql.Call{c}}, nil, opt)
-			resp <- err
-		}(node)
+// columnLabelArg is the argument used to name the label a column id is read from.
+const columnLabelArg = "columnLabel"
+
+// readColumnID reads a column id from the first of labels present in c.Args
+// and returns the label that was used. An explicit "columnLabel" argument
+// forces the id to be read from that label, which must be one of labels.
+//
+// If no label is present then ok is false. If required is also true then a
+// *ColumnIDError is returned. Invalid ids always return a *ColumnIDError.
+func readColumnID(c *pql.Call, required bool, labels ...string) (id uint64, label string, ok bool, err error) {
+	if v, exists := c.Args[columnLabelArg]; exists {
+		s, _ := v.(string)
+		var found bool
+		for _, label := range labels {
+			found = found || s == label
+		}
+		if !found {
+			return 0, "", false, &ColumnIDError{Call: c.Name, Labels: labels, Label: columnLabelArg, Err: fmt.Errorf("unknown label: %v", v)}
+		}
+		labels, required = []string{s}, true
 	}
 
-	// Return first error.
-	for range nodes {
-		if err := <-resp; err != nil {
-			return err
+	for _, label := range labels {
+		id, ok, err := c.UintArg(label)
+		if err != nil {
+			return 0, "", false, &ColumnIDError{Call: c.Name, Labels: labels, Label: label, Err: err}
+		} else if ok {
+			return id, label, true, nil
 		}
 	}
 
-	return nil
+	if required {
+		return 0, "", false, &ColumnIDError{Call: c.Name, Labels: labels}
+	}
+	return 0, "", false, nil
+}
+
+// ColumnIDError is returned when a call does not have a valid column id.
+type ColumnIDError struct {
+	Call   string   // name of the call
+	Labels []string // labels the id could be read from
+	Label  string   // label with an invalid value, if any
+	Err    error    // reason the label's value is invalid, if any
+}
+
+// Error returns a message naming the expected or invalid label.
+func (e *ColumnIDError) Error() string {
+	if e.Label != "" {
+		return fmt.Sprintf("%s() invalid column id %q: %s", e.Call, e.Label, e.Err)
+	}
+
+	a := make([]string, len(e.Labels))
+	for i := range e.Labels {
+		a[i] = strconv.Quote(e.Labels[i])
+	}
+	return fmt.Sprintf("%s() column id required: expected %s", e.Call, strings.Join(a, " or "))
+}
+
+// ExecuteOnNode executes a PQL query on a single node for a set of slices.
+// The query is not fanned out across the cluster so only data local to the
+// node is used.
+func (e *Executor) ExecuteOnNode(ctx context.Context, node *Node, index string, q *pql.Query, slices []uint64, opt *ExecOptions) ([]interface{}, error) {
+	if index == "" {
+		return nil, ErrIndexRequired
+	} else if node == nil {
+		return nil, errors.New("node required")
+	}
+
+	// Default options.
+	if opt == nil {
+		opt = &ExecOptions{}
+	}
+
+	return e.exec(ctx, node, index, q, slices, opt)
 }
 
 // exec executes a PQL query remotely for a set of slices on a node.
 func (e *Executor) exec(ctx context.Context, node *Node, index string, q *pql.Query, slices []uint64, opt *ExecOptions) (results []interface{}, err error) {
-	// Encode request object.
-	pbreq := &internal.QueryRequest{
-		Query:  q.String(),
-		Slices: slices,
-		Remote: true,
+	// Fail fast if the node's circuit is open so its slices are resplit.
+	if !e.allowRequest(node.Host) {
+		return nil, &RemoteTransportError{Host: node.Host, Err: ErrCircuitOpen}
 	}
-	buf, err := proto.Marshal(pbreq)
-	if err != nil {
-		return nil, err
+	defer func() {
+		// Requests canceled by the caller say nothing about the node but
+		// must still release a probe so that the node can be probed again.
+		if ctx.Err() == nil {
+			e.recordRequest(node.Host, err)
+		} else {
+			e.releaseProbe(node.Host)
+		}
+	}()
+
+	// Abandon the request if the node does not respond in time so that its
+	// slices can be retried on replicas. The query itself continues.
+	reqCtx := ctx
+	if e.RemoteRequestTimeout > 0 {
+		var cancel context.CancelFunc
+		reqCtx, cancel = context.WithTimeout(ctx, e.RemoteRequestTimeout)
+		defer cancel()
 	}
 
-	// Create HTTP request.
-	req, err := http.NewRequest("POST", (&url.URL{
-		Scheme: "http",
-		Host:   node.Host,
-		Path:   fmt.Sprintf("/index/%s/query", index),
-	}).String(), bytes.NewReader(buf))
+	// Encode request in the remote format.
+	req, err := e.newRemoteQueryRequest(reqCtx, node, index, q, slices, opt)
 	if err != nil {
 		return nil, err
 	}
 
-	// Require protobuf encoding.
-	req.Header.Set("Accept", "application/x-protobuf")
-	req.Header.Set("Content-Type", "application/x-protobuf")
+	// Request a pair stream for single TopN() calls. Servers which do not
+	// support streaming ignore the header and return a normal response.
+	if len(q.Calls) == 1 && q.Calls[0].Name == "TopN" && e.remoteFormat() == RemoteFormatProtobuf {
+		req.Header.Set(StreamPairsHeader, "1")
+	}
 
 	// Send request to remote node.
 	resp, err := e.HTTPClient.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, &RemoteTransportError{Host: node.Host, Err: err}
 	}
 	defer resp.Body.Close()
 
+	// Decode streamed TopN() pairs directly from the body.
+	if resp.StatusCode == http.StatusOK && resp.Header.Get(StreamPairsHeader) != "" {
+		pairs, err := readPairStream(resp.Body)
+		if err != nil {
+			return nil, &RemoteTransportError{Host: node.Host, Err: err}
+		}
+		return []interface{}{pairs}, nil
+	}
+
 	// Read response into buffer.
 	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &RemoteTransportError{Host: node.Host, Err: err}
+	}
+
+	if e.remoteFormat() == RemoteFormatJSON {
+		return e.decodeJSONQueryResponse(node.Host, q, resp.StatusCode, body)
+	}
+	return e.decodeProtobufQueryResponse(node.Host, q, resp.StatusCode, body)
+}
+
+// remoteFormat returns the serialization format used for remote requests.
+func (e *Executor) remoteFormat() string {
+	if e.RemoteFormat == "" {
+		return RemoteFormatProtobuf
+	}
+	return e.RemoteFormat
+}
+
+// newRemoteQueryRequest returns an HTTP request forwarding q to node.
+// Protobuf requests encode all options in the body. JSON requests send the
+// query as the body and options as URL parameters.
+func (e *Executor) newRemoteQueryRequest(ctx context.Context, node *Node, index string, q *pql.Query, slices []uint64, opt *ExecOptions) (*http.Request, error) {
+	u := &url.URL{
+		Scheme: "http",
+		Host:   node.Host,
+		Path:   fmt.Sprintf("/index/%s/query", index),
+	}
+
+	// Forward the deadline so the remote node can stop when it expires.
+	deadline, hasDeadline := ctx.Deadline()
+
+	var body []byte
+	var contentType, accept string
+	switch e.remoteFormat() {
+	case RemoteFormatProtobuf:
+		pbreq := &internal.QueryRequest{
+			Query:               q.String(),
+			Slices:              slices,
+			Remote:              true,
+			OperationID:         opt.OperationID,
+			TreatMissingAsEmpty: opt.TreatMissingAsEmpty,
+			Snapshot:            opt.Snapshot,
+			SkipInverse:         opt.SkipInverse,
+			MaxFragmentReads:    int64(opt.MaxFragmentReads),
+			ParallelChildren:    opt.ParallelChildren,
+		}
+		if hasDeadline {
+			pbreq.Deadline = deadline.UnixNano()
+		}
+
+		buf, err := proto.Marshal(pbreq)
+		if err != nil {
+			return nil, err
+		}
+		body, contentType, accept = buf, "application/x-protobuf", "application/x-protobuf"
+
+	case RemoteFormatJSON:
+		params := url.Values{}
+		params.Set("remote", "true")
+		if len(slices) > 0 {
+			a := make([]string, len(slices))
+			for i, slice := range slices {
+				a[i] = strconv.FormatUint(slice, 10)
+			}
+			params.Set("slices", strings.Join(a, ","))
+		}
+		if opt.OperationID != "" {
+			params.Set("operationID", opt.OperationID)
+		}
+		if opt.TreatMissingAsEmpty {
+			params.Set("treatMissingAsEmpty", "true")
+		}
+		if opt.Snapshot {
+			params.Set("snapshot", "true")
+		}
+		if opt.SkipInverse {
+			params.Set("skipInverse", "true")
+		}
+		if opt.MaxFragmentReads > 0 {
+			params.Set("maxFragmentReads", strconv.Itoa(opt.MaxFragmentReads))
+		}
+		if opt.ParallelChildren {
+			params.Set("parallelChildren", "true")
+		}
+		u.RawQuery = params.Encode()
+		body, contentType, accept = []byte(q.String()), "text/plain", "application/json"
+
+	default:
+		return nil, fmt.Errorf("unknown remote format: %s", e.RemoteFormat)
+	}
+
+	// Create HTTP request.
+	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
+	req = req.WithContext(ctx)
 
-	// Check status code.
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("invalid status: code=%d, err=%s", resp.StatusCode, body)
+	// Require the response in the same format as the request.
+	req.Header.Set("Accept", accept)
+	req.Header.Set("Content-Type", contentType)
+	if hasDeadline {
+		req.Header.Set(QueryDeadlineHeader, deadline.UTC().Format(time.RFC3339Nano))
 	}
+	return req, nil
+}
 
-	// Decode response object.
+// decodeProtobufQueryResponse returns the results of q from a protobuf
+// encoded response from host.
+func (e *Executor) decodeProtobufQueryResponse(host string, q *pql.Query, statusCode int, body []byte) ([]interface{}, error) {
+	// Decode response object. Query errors are returned with an error status
+	// so only an undecodable body is considered an invalid response.
 	var pb internal.QueryResponse
-	if err := proto.Unmarshal(body, &pb); err != nil {
-		return nil, err
+	if err := proto.Unmarshal(body, &pb); err != nil || (statusCode != http.StatusOK && pb.Err == "") {
+		if statusCode != http.StatusOK {
+			err = fmt.Errorf("invalid status: code=%d, err=%s", statusCode, body)
+		}
+		return nil, &RemoteTransportError{Host: host, Err: err}
 	}
 
 	// Return an error, if specified on response.
 	if err := decodeError(pb.Err); err != nil {
-		return nil, err
+		return nil, &RemoteQueryError{Host: host, Err: err}
+	}
+
+	// Ensure there is a result for each call. A mismatch means the response
+	// is malformed, possibly by a node running a different version, so the
+	// same request may succeed on another node.
+	if len(pb.Results) != len(q.Calls) {
+		return nil, &RemoteTransportError{Host: host, Err: fmt.Errorf("unexpected result count: %d, expected %d", len(pb.Results), len(q.Calls))}
 	}
 
 	// Return appropriate data for the query.
-	results = make([]interface{}, len(q.Calls))
+	results := make([]interface{}, len(q.Calls))
 	for i, call := range q.Calls {
+		result := pb.Results[i]
+		if result == nil {
+			return nil, &RemoteTransportError{Host: host, Err: fmt.Errorf("missing %s() result", call.Name)}
+		}
+
 		var v interface{}
 		var err error
 
 		switch call.Name {
-		case "TopN":
-			v, err = decodePairs(pb.Results[i].GetPairs()), nil
+		case "TopN", "ColumnDegree", "TopColumns":
+			v, err = decodePairs(result.GetPairs()), nil
 		case "Count":
-			v, err = pb.Results[i].N, nil
+			v, err = result.N, nil
+		case "MaxColumn", "MinColumn":
+			var bm *Bitmap
+			if bm, err = decodeResultBitmap(result); err == nil {
+				v = decodeColumnResult(bm)
+			}
+		case "Page":
+			var bm *Bitmap
+			if bm, err = decodeResultBitmap(result); err == nil {
+				v = &PageResult{Count: result.N, Columns: bm.Bits()}
+			}
+		case "Rows":
+			var bm *Bitmap
+			if bm, err = decodeResultBitmap(result); err == nil {
+				v = bm.Bits()
+			}
 		case "SetBit":
-			v, err = pb.Results[i].Changed, nil
+			v, err = result.Changed, nil
 		case "ClearBit":
-			v, err = pb.Results[i].Changed, nil
+			v, err = result.Changed, nil
+		case "ClearBits", "CountColumns", "SetBits":
+			v, err = result.N, nil
 		case "SetRowAttrs":
+		case "SetRowFromRoaring":
 		case "SetColumnAttrs":
+		case "Warm":
 		default:
-			v, err = decodeBitmap(pb.Results[i].GetBitmap()), nil
+			if agg := e.aggregation(call.Name); agg != nil {
+				v, err = agg.decode(result.Value)
+			} else {
+				v, err = decodeResultBitmap(result)
+			}
 		}
 		if err != nil {
-			return nil, err
+			return nil, &RemoteTransportError{Host: host, Err: fmt.Errorf("invalid %s() result: %s", call.Name, err)}
 		}
 
 		results[i] = v
@@ -1082,22 +5277,342 @@ func (e *Executor) exec(ctx context.Context, node *Node, index string, q *pql.Qu
 	return results, nil
 }
 
+// decodeJSONQueryResponse returns the results of q from a JSON encoded
+// response from host. Results decode to the same types as protobuf except
+// that JSON does not distinguish integral float attributes from integers.
+func (e *Executor) decodeJSONQueryResponse(host string, q *pql.Query, statusCode int, body []byte) ([]interface{}, error) {
+	var resp struct {
+		Results []json.RawMessage `json:"results"`
+		Err     string            `json:"error"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || (statusCode != http.StatusOK && resp.Err == "") {
+		if statusCode != http.StatusOK {
+			err = fmt.Errorf("invalid status: code=%d, err=%s", statusCode, body)
+		}
+		return nil, &RemoteTransportError{Host: host, Err: err}
+	}
+
+	// Return an error, if specified on response.
+	if err := decodeError(resp.Err); err != nil {
+		return nil, &RemoteQueryError{Host: host, Err: err}
+	}
+
+	// Ensure there is a result for each call.
+	if len(resp.Results) != len(q.Calls) {
+		return nil, &RemoteTransportError{Host: host, Err: fmt.Errorf("unexpected result count: %d, expected %d", len(resp.Results), len(q.Calls))}
+	}
+
+	results := make([]interface{}, len(q.Calls))
+	for i, call := range q.Calls {
+		v, err := e.decodeJSONResult(call, resp.Results[i])
+		if err != nil {
+			return nil, &RemoteTransportError{Host: host, Err: fmt.Errorf("invalid %s() result: %s", call.Name, err)}
+		}
+		results[i] = v
+	}
+	return results, nil
+}
+
+// decodeJSONResult returns the result of c from its JSON encoding.
+func (e *Executor) decodeJSONResult(c *pql.Call, data json.RawMessage) (interface{}, error) {
+	// Null results are only valid for calls without a return value.
+	if string(data) == "null" {
+		data = nil
+	}
+
+	switch c.Name {
+	case "TopN", "ColumnDegree", "TopColumns":
+		pairs := []Pair{}
+		if err := json.Unmarshal(data, &pairs); err != nil {
+			return nil, err
+		}
+		return pairs, nil
+	case "Count", "ClearBits", "CountColumns", "SetBits":
+		var n uint64
+		if err := json.Unmarshal(data, &n); err != nil {
+			return nil, err
+		}
+		return n, nil
+	case "MaxColumn", "MinColumn":
+		var v ColumnResult
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+		return &v, nil
+	case "Page":
+		var v PageResult
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, err
+		} else if v.Columns == nil {
+			v.Columns = []uint64{}
+		}
+		return &v, nil
+	case "Rows":
+		rowIDs := []uint64{}
+		if err := json.Unmarshal(data, &rowIDs); err != nil {
+			return nil, err
+		}
+		return rowIDs, nil
+	case "SetBit", "ClearBit":
+		var v bool
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	case "SetRowAttrs", "SetRowFromRoaring", "SetColumnAttrs", "Warm":
+		return nil, nil
+	default:
+		if agg := e.aggregation(c.Name); agg != nil {
+			return agg.decode(data)
+		}
+		return decodeJSONBitmap(data)
+	}
+}
+
+// decodeJSONBitmap returns a bitmap from its JSON encoding.
+func decodeJSONBitmap(data []byte) (*Bitmap, error) {
+	if data == nil {
+		return nil, errors.New("bitmap missing")
+	}
+
+	var o struct {
+		Attrs map[string]interface{} `json:"attrs"`
+		Bits  []uint64               `json:"bits"`
+		Keys  []string               `json:"keys"`
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&o); err != nil {
+		return nil, err
+	}
+
+	b := NewBitmap()
+	b.Attrs = make(map[string]interface{}, len(o.Attrs))
+	for k, v := range o.Attrs {
+		b.Attrs[k] = decodeJSONAttrValue(v)
+	}
+	b.Keys = o.Keys
+	for _, v := range o.Bits {
+		b.SetBit(v)
+	}
+	return b, nil
+}
+
+// decodeColumnResult returns a MaxColumn() or MinColumn() result from the
+// bitmap a remote node encodes it as. An empty bitmap means no columns are set.
+func decodeColumnResult(bm *Bitmap) *ColumnResult {
+	bits := bm.Bits()
+	if len(bits) == 0 {
+		return &ColumnResult{Empty: true}
+	}
+	return &ColumnResult{Column: bits[0]}
+}
+
+// decodeResultBitmap returns the bitmap of a remote result.
+// Returns an error if the result does not contain a bitmap.
+func decodeResultBitmap(pb *internal.QueryResult) (*Bitmap, error) {
+	bm := decodeBitmap(pb.Bitmap)
+	if bm == nil {
+		return nil, errors.New("bitmap missing")
+	}
+	return bm, nil
+}
+
+// RemoteTransportError is returned when a remote node cannot be reached or
+// returns an invalid response. The same request may succeed on another node.
+type RemoteTransportError struct {
+	Host string
+	Err  error
+}
+
+// Error returns the underlying error along with the remote host.
+func (e *RemoteTransportError) Error() string {
+	return fmt.Sprintf("remote transport error: host=%s, err=%s", e.Host, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *RemoteTransportError) Unwrap() error { return e.Err }
+
+// RemoteQueryError is returned when a remote node fails to execute a query.
+// These errors are deterministic and will fail the same on any node.
+type RemoteQueryError struct {
+	Host string
+	Err  error
+}
+
+// Error returns the error message from the remote node.
+func (e *RemoteQueryError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the underlying error.
+func (e *RemoteQueryError) Unwrap() error { return e.Err }
+
 // slicesByNode returns a mapping of nodes to slices.
+// Nodes recently marked down, with an open circuit or in failed are only
+// used if no other owner is available.
 // Returns errSliceUnavailable if a slice cannot be allocated to a node.
-func (e *Executor) slicesByNode(nodes []*Node, index string, slices []uint64) (map[*Node][]uint64, error) {
+func (e *Executor) slicesByNode(nodes []*Node, index string, slices []uint64, failed *hostSet) (map[*Node][]uint64, error) {
 	m := make(map[*Node][]uint64)
 
-loop:
-	for _, slice := range slices {
-		for _, node := range e.Cluster.FragmentNodes(index, slice) {
-			if Nodes(nodes).Contains(node) {
-				m[node] = append(m[node], slice)
-				continue loop
-			}
-		}
-		return nil, errSliceUnavailable
+loop:
+	for _, slice := range slices {
+		var down *Node
+		for _, node := range e.Cluster.FragmentNodes(index, slice) {
+			if !Nodes(nodes).Contains(node) {
+				continue
+			} else if e.isNodeDown(node.Host) || e.isCircuitOpen(node.Host) || failed.contains(node.Host) {
+				if down == nil {
+					down = node
+				}
+				continue
+			}
+			m[node] = append(m[node], slice)
+			continue loop
+		}
+
+		if down == nil {
+			return nil, errSliceUnavailable
+		}
+		m[down] = append(m[down], slice)
+	}
+	return m, nil
+}
+
+// pinnedSlicesByNode assigns all slices to the node with host. Returns
+// errSliceUnavailable if the node is not in nodes, such as after it failed.
+func pinnedSlicesByNode(nodes []*Node, host string, slices []uint64) (map[*Node][]uint64, error) {
+	for _, node := range nodes {
+		if node.Host == host {
+			return map[*Node][]uint64{node: slices}, nil
+		}
+	}
+	return nil, errSliceUnavailable
+}
+
+// markNodeDown records a failed request to host. The host is skipped in
+// favor of replicas until NodeDownTimeout has elapsed. No-op if the timeout is not set.
+func (e *Executor) markNodeDown(host string) {
+	if e.NodeDownTimeout <= 0 {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.nodesDown == nil {
+		e.nodesDown = make(map[string]time.Time)
+	}
+	e.nodesDown[host] = time.Now()
+}
+
+// markNodeUp records a successful request to host.
+func (e *Executor) markNodeUp(host string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.nodesDown, host)
+}
+
+// isNodeDown returns true if host failed within the last NodeDownTimeout.
+// Expired hosts are treated as up so the next request probes them again.
+func (e *Executor) isNodeDown(host string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	t, ok := e.nodesDown[host]
+	if !ok {
+		return false
+	} else if time.Since(t) >= e.NodeDownTimeout {
+		delete(e.nodesDown, host)
+		return false
+	}
+	return true
+}
+
+// nodeCircuit represents the circuit breaker state of a node.
+type nodeCircuit struct {
+	failureN int       // consecutive failed requests
+	openedAt time.Time // zero if closed
+	probing  bool      // true if a half-open probe is in flight
+}
+
+// allowRequest returns true if a request may be sent to host. Once an open
+// circuit's cooldown elapses a single probe request is allowed.
+func (e *Executor) allowRequest(host string) bool {
+	if e.NodeFailureThreshold <= 0 {
+		return true
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	c := e.circuits[host]
+	if c == nil || c.openedAt.IsZero() {
+		return true
+	} else if c.probing || time.Since(c.openedAt) < e.NodeCircuitCooldown {
+		return false
+	}
+	c.probing = true
+	return true
+}
+
+// isCircuitOpen returns true if requests to host are short-circuited.
+// Nodes ready for a probe are not considered open.
+func (e *Executor) isCircuitOpen(host string) bool {
+	if e.NodeFailureThreshold <= 0 {
+		return false
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	c := e.circuits[host]
+	if c == nil || c.openedAt.IsZero() {
+		return false
+	}
+	return c.probing || time.Since(c.openedAt) < e.NodeCircuitCooldown
+}
+
+// recordRequest updates the circuit for host with the error of a request.
+// Transport errors count as failures and reopen a probed circuit. Any other
+// outcome means the node is reachable and closes the circuit.
+func (e *Executor) recordRequest(host string, err error) {
+	if e.NodeFailureThreshold <= 0 {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, ok := err.(*RemoteTransportError); !ok {
+		delete(e.circuits, host)
+		return
+	}
+
+	if e.circuits == nil {
+		e.circuits = make(map[string]*nodeCircuit)
+	}
+	c := e.circuits[host]
+	if c == nil {
+		c = &nodeCircuit{}
+		e.circuits[host] = c
+	}
+	c.failureN++
+	c.probing = false
+	if c.failureN >= e.NodeFailureThreshold {
+		c.openedAt = time.Now()
+	}
+}
+
+// releaseProbe marks a half-open probe of host as no longer in flight
+// without recording its outcome.
+func (e *Executor) releaseProbe(host string) {
+	if e.NodeFailureThreshold <= 0 {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if c := e.circuits[host]; c != nil {
+		c.probing = false
 	}
-	return m, nil
 }
 
 // mapReduce maps and reduces data across the cluster.
@@ -1105,12 +5620,44 @@ loop:
 // If a mapping of slices to a node fails then the slices are resplit across
 // secondary nodes and retried. This continues to occur until all nodes are exhausted.
 func (e *Executor) mapReduce(ctx context.Context, index string, slices []uint64, c *pql.Call, opt *ExecOptions, mapFn mapFunc, reduceFn reduceFunc) (interface{}, error) {
-	ch := make(chan mapResponse, 0)
-
 	// Wrap context with a cancel to kill goroutines on exit.
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
+	// No slices produce no map responses so there is nothing to reduce.
+	// Callers construct the empty result for their call type.
+	if len(slices) == 0 {
+		return nil, nil
+	}
+
+	// Record the largest result of a single local slice.
+	if opt.stats != nil {
+		fn := mapFn
+		mapFn = func(slice uint64) (interface{}, error) {
+			v, err := fn(slice)
+			if err == nil {
+				opt.stats.addSliceResult(v)
+			}
+			return v, err
+		}
+	}
+
+	// Map every slice on the local node without assigning slices to nodes.
+	if e.LocalOnly {
+		for _, slice := range slices {
+			if !e.Cluster.OwnsFragment(e.Host, index, slice) {
+				return nil, fmt.Errorf("slice %d is not owned by the local node: %s", slice, e.Host)
+			}
+		}
+		result, err := e.mapLocal(ctx, slices, opt, mapFn, reduceFn)
+		if err != nil {
+			return nil, err
+		}
+		opt.stats.add(e.Host, true, slices)
+		opt.reportSlicesDone(slices, e.Host)
+		return result, nil
+	}
+
 	// If this is the coordinating node then start with all nodes in the cluster.
 	//
 	// However, if this request is being sent from the coordinator then all
@@ -1119,9 +5666,18 @@ func (e *Executor) mapReduce(ctx context.Context, index string, slices []uint64,
 	if !opt.Remote {
 		nodes = Nodes(e.Cluster.Nodes).Clone()
 	} else {
-		nodes = []*Node{e.Cluster.NodeByHost(e.Host)}
+		node := e.Cluster.NodeByHost(e.Host)
+		if node == nil {
+			return nil, fmt.Errorf("executor host not found in cluster: %s", e.Host)
+		}
+		nodes = []*Node{node}
 	}
 
+	// Buffer a response per node so mappers can exit without waiting on the
+	// reducer. Mappers started on retry fall back to blocking until received
+	// or until the context is canceled.
+	ch := make(chan mapResponse, len(nodes))
+
 	// Start mapping across all primary owners.
 	if err := e.mapper(ctx, ch, nodes, index, slices, c, opt, mapFn, reduceFn); err != nil {
 		return nil, err
@@ -1130,14 +5686,23 @@ func (e *Executor) mapReduce(ctx context.Context, index string, slices []uint64,
 	// Iterate over all map responses and reduce.
 	var result interface{}
 	var maxSlice int
+	var reduceTime time.Duration
 	for {
 		select {
 		case <-ctx.Done():
 			return nil, ctx.Err()
 		case resp := <-ch:
-			// On error retry against remaining nodes. If an error returns then
-			// the context will cancel and cause all open goroutines to return.
+			// On transport error retry against remaining nodes. Other errors
+			// are deterministic and would fail the same on every node. If an
+			// error returns then the context will cancel and cause all open
+			// goroutines to return.
 			if resp.err != nil {
+				if _, ok := resp.err.(*RemoteTransportError); !ok {
+					return nil, resp.err
+				}
+				e.markNodeDown(resp.node.Host)
+				opt.failedHosts.add(resp.node.Host)
+
 				// Filter out unavailable nodes.
 				nodes = Nodes(nodes).Filter(resp.node)
 
@@ -1151,7 +5716,17 @@ func (e *Executor) mapReduce(ctx context.Context, index string, slices []uint64,
 			}
 
 			// Reduce value.
+			start := time.Now()
 			result = reduceFn(result, resp.result)
+			if reduceTime += time.Since(start); e.ReduceTimeout > 0 && reduceTime > e.ReduceTimeout {
+				return nil, ErrReduceTimeout
+			}
+			opt.stats.add(resp.node.Host, resp.node.Host == e.Host, resp.slices)
+			if resp.node.Host != e.Host {
+				e.markNodeUp(resp.node.Host)
+			}
+
+			opt.reportSlicesDone(resp.slices, resp.node.Host)
 
 			// If all slices have been processed then return.
 			maxSlice += len(resp.slices)
@@ -1163,8 +5738,14 @@ func (e *Executor) mapReduce(ctx context.Context, index string, slices []uint64,
 }
 
 func (e *Executor) mapper(ctx context.Context, ch chan mapResponse, nodes []*Node, index string, slices []uint64, c *pql.Call, opt *ExecOptions, mapFn mapFunc, reduceFn reduceFunc) error {
-	// Group slices together by nodes.
-	m, err := e.slicesByNode(nodes, index, slices)
+	// Group slices together by nodes, unless all slices are sent to a single node.
+	var m map[*Node][]uint64
+	var err error
+	if opt.PinNode != "" && !opt.Remote {
+		m, err = pinnedSlicesByNode(nodes, opt.PinNode, slices)
+	} else {
+		m, err = e.slicesByNode(nodes, index, slices, opt.failedHosts)
+	}
 	if err != nil {
 		return err
 	}
@@ -1176,14 +5757,9 @@ func (e *Executor) mapper(ctx context.Context, ch chan mapResponse, nodes []*Nod
 
 			// Send local slices to mapper, otherwise remote exec.
 			if n.Host == e.Host {
-				resp.result, resp.err = e.mapperLocal(ctx, nodeSlices, mapFn, reduceFn)
+				resp.result, resp.err = e.mapLocal(ctx, nodeSlices, opt, mapFn, reduceFn)
 			} else if !opt.Remote {
-
-				results, err := e.exec(ctx, n, index, &pql.Query{Calls: []*pql.Call{c}}, nodeSlices, opt)
-				if len(results) > 0 {
-					resp.result = results[0]
-				}
-				resp.err = err
+				resp.result, resp.err = e.mapperRemote(ctx, n, index, c, nodeSlices, opt, reduceFn)
 			}
 
 			// Return response to the channel.
@@ -1197,18 +5773,74 @@ func (e *Executor) mapper(ctx context.Context, ch chan mapResponse, nodes []*Nod
 	return nil
 }
 
+// mapperRemote executes a call on a remote node and reduces the results.
+// Slices are sent in requests of up to MaxSlicesPerRequest slices, if set.
+func (e *Executor) mapperRemote(ctx context.Context, node *Node, index string, c *pql.Call, slices []uint64, opt *ExecOptions, reduceFn reduceFunc) (interface{}, error) {
+	q := &pql.Query{Calls: []*pql.Call{c}}
+
+	// Send all slices in a single request if there is no limit.
+	if e.MaxSlicesPerRequest <= 0 || len(slices) <= e.MaxSlicesPerRequest {
+		results, err := e.exec(ctx, node, index, q, slices, opt)
+		if err != nil {
+			return nil, err
+		} else if len(results) == 0 {
+			return nil, nil
+		}
+		return results[0], nil
+	}
+
+	// Otherwise execute each chunk of slices in order and reduce.
+	var result interface{}
+	for len(slices) > 0 {
+		chunk := slices
+		if len(chunk) > e.MaxSlicesPerRequest {
+			chunk = chunk[:e.MaxSlicesPerRequest]
+		}
+		slices = slices[len(chunk):]
+
+		results, err := e.exec(ctx, node, index, q, chunk, opt)
+		if err != nil {
+			return nil, err
+		} else if len(results) > 0 {
+			result = reduceFn(result, results[0])
+		}
+	}
+	return result, nil
+}
+
+// mapLocal maps & reduces slices on the local node. Slices skipped under
+// opt.AllowPartial are reported as a WarningSlicesFailed warning.
+func (e *Executor) mapLocal(ctx context.Context, slices []uint64, opt *ExecOptions, mapFn mapFunc, reduceFn reduceFunc) (interface{}, error) {
+	result, failed, err := e.mapperLocal(ctx, slices, opt, mapFn, reduceFn)
+	if len(failed) > 0 {
+		opt.warnings.add(WarningSlicesFailed, fmt.Sprintf("%d of %d local slices failed and were skipped", len(failed), len(slices)), map[string]interface{}{
+			"slices": failed,
+		})
+	}
+	return result, err
+}
+
 // mapperLocal performs map & reduce entirely on the local node.
-func (e *Executor) mapperLocal(ctx context.Context, slices []uint64, mapFn mapFunc, reduceFn reduceFunc) (interface{}, error) {
+// If opt.AllowPartial is set then slices which fail to map are skipped and
+// returned as failed instead of failing the reduce.
+func (e *Executor) mapperLocal(ctx context.Context, slices []uint64, opt *ExecOptions, mapFn mapFunc, reduceFn reduceFunc) (interface{}, []uint64, error) {
 	ch := make(chan mapResponse, len(slices))
 
 	for _, slice := range slices {
+		// Wait for a slot before starting the goroutine.
+		release, err := e.acquireMapSlot(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+
 		go func(slice uint64) {
 			result, err := mapFn(slice)
+			release()
 
 			// Return response to the channel.
 			select {
 			case <-ctx.Done():
-			case ch <- mapResponse{result: result, err: err}:
+			case ch <- mapResponse{slices: []uint64{slice}, result: result, err: err}:
 			}
 		}(slice)
 	}
@@ -1216,25 +5848,78 @@ func (e *Executor) mapperLocal(ctx context.Context, slices []uint64, mapFn mapFu
 	// Reduce results
 	var maxSlice int
 	var result interface{}
+	var failed []uint64
 	for {
 		select {
 		case <-ctx.Done():
-			return nil, ctx.Err()
+			return nil, nil, ctx.Err()
 		case resp := <-ch:
 			if resp.err != nil {
-				return nil, resp.err
+				// Cancellation fails the call regardless of AllowPartial.
+				if !opt.AllowPartial || ctx.Err() != nil {
+					return nil, nil, resp.err
+				}
+				failed = append(failed, resp.slices...)
+			} else {
+				result = reduceFn(result, resp.result)
 			}
-			result = reduceFn(result, resp.result)
 			maxSlice++
 		}
 
 		// Exit once all slices are processed.
 		if maxSlice == len(slices) {
-			return result, nil
+			sort.Sort(uint64Slice(failed))
+			return result, failed, nil
 		}
 	}
 }
 
+// acquireMapSlot reserves one of the executor's local map goroutine slots.
+// The returned function must be called to release the slot.
+func (e *Executor) acquireMapSlot(ctx context.Context) (func(), error) {
+	if e.MaxMapGoroutines <= 0 {
+		return func() {}, nil
+	}
+
+	// Lazily create the semaphore.
+	e.mu.Lock()
+	if e.mapSlots == nil {
+		e.mapSlots = make(chan struct{}, e.MaxMapGoroutines)
+	}
+	slots := e.mapSlots
+	e.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	}
+}
+
+// tryAcquireMapSlot reserves a local map goroutine slot if one is free.
+// The returned function must be called to release the slot.
+func (e *Executor) tryAcquireMapSlot() (func(), bool) {
+	if e.MaxMapGoroutines <= 0 {
+		return func() {}, true
+	}
+
+	// Lazily create the semaphore.
+	e.mu.Lock()
+	if e.mapSlots == nil {
+		e.mapSlots = make(chan struct{}, e.MaxMapGoroutines)
+	}
+	slots := e.mapSlots
+	e.mu.Unlock()
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, true
+	default:
+		return nil, false
+	}
+}
+
 // errSliceUnavailable is a marker error if no nodes are available.
 var errSliceUnavailable = errors.New("slice unavailable")
 
@@ -1253,6 +5938,538 @@ type mapResponse struct {
 // ExecOptions represents an execution context for a single Execute() call.
 type ExecOptions struct {
 	Remote bool
+
+	// If true, attributes for the result columns are attached to bitmap results.
+	AttachColumnAttrs bool
+
+	// If true, TopN() skips refetching exact counts for the top rows and
+	// returns the merged counts from the first pass. These may undercount
+	// rows which fell below a slice's local threshold on some slices.
+	ApproximateTopN bool
+
+	// If true, bitmap calls return a BitmapResult with the number of columns
+	// in the bitmap instead of a *Bitmap.
+	IncludeCount bool
+
+	// If true, Range() calls on frames without a time quantum return an empty
+	// bitmap instead of an error, as no time views exist to match.
+	EmptyRangeWithoutQuantum bool
+
+	// If true, bitmap and TopN() results are returned as a *ChecksumResult
+	// holding a hash of the merged result. The hash only depends on the
+	// result's columns or pairs, so it matches across clusters holding the
+	// same data regardless of slice placement. Other results are unchanged.
+	Checksum bool
+
+	// If true, Count() calls return a *CountResult holding the number of
+	// columns set in any row of the frames read by the input, so that clients
+	// can compute the fraction of columns matched in a single query.
+	IncludeUniverse bool
+
+	// If true, bitmap calls return the merged result as a []byte holding a
+	// single roaring bitmap of every column across slices, as written by the
+	// roaring package, instead of a *Bitmap. Takes precedence over
+	// IncludeCount. Column attributes and keys are not returned.
+	RawRoaring bool
+
+	// If true, result column ids of bitmap calls and row ids of TopN() calls
+	// are translated to keys by the executor's KeyTranslator.
+	TranslateKeys bool
+
+	// If true, SetBit() returns a SetBitResult with the changes to each view
+	// instead of a single bool.
+	ChangedByView bool
+
+	// If true, calls are executed as written without simplifying the call
+	// tree, and Difference() subtracts each input in turn instead of their union.
+	NoOptimize bool
+
+	// If true, read calls treat frames which do not exist as empty
+	// instead of returning ErrFrameNotFound.
+	TreatMissingAsEmpty bool
+
+	// Identifies a forwarded mutation. Nodes skip operations they have already
+	// applied and return the original results. Set by the coordinator.
+	OperationID string
+
+	// Host of a node which executes all slices of calls mapped across
+	// slices instead of distributing them to their owners. Remote nodes
+	// fail for slices they do not own. Intended for load testing a single
+	// node. Mutations are not affected.
+	PinNode string
+
+	// If true, row reads of all calls use a copy of the local fragments
+	// taken when the query starts, so concurrent writes are not observed.
+	// The copy holds every fragment of the frames read by the query in
+	// memory until the query completes. Each node copies its fragments when
+	// it receives the query so reads are only consistent within a node.
+	// TopN() ranks are read from the live fragment caches.
+	Snapshot bool
+
+	// If true, SetBit(), SetBits(), ClearBit() and ClearBits() calls without a
+	// view only write the standard view, even if the frame has inverse enabled. Intended for bulk
+	// ingest: the inverse view is stale until it is rebuilt. Calls which
+	// target the inverse view are still applied.
+	SkipInverse bool
+
+	// If true, local slices which fail to map, such as those of a corrupted
+	// fragment, are skipped instead of failing the query. Skipped slices are
+	// reported as WarningSlicesFailed warnings. The option is not forwarded
+	// so errors on remote nodes still fail the query.
+	AllowPartial bool
+
+	// Maximum number of fragments the query may read on each node, if
+	// non-zero. The query is cancelled and fails with
+	// ErrFragmentReadBudgetExceeded once the budget is exceeded. Guards
+	// against queries whose cost estimate is far below their actual cost.
+	MaxFragmentReads int
+
+	// If true, the children of Intersect() and Union() calls are evaluated
+	// concurrently within each slice. Each extra goroutine takes a spare
+	// MaxMapGoroutines slot; children are evaluated inline when none is
+	// free. Results are still folded in argument order.
+	ParallelChildren bool
+
+	// If true, read calls identical to an earlier call of the query, ignoring
+	// argument order, are executed once and share the earlier result. Calls
+	// after a mutation are executed again. Applied by the coordinating node.
+	DedupCalls bool
+
+	// Slices skipped by read calls, even if listed in the slices passed to
+	// Execute(). Applied by the coordinating node before slices are mapped
+	// to nodes, so excluded slices are neither read nor counted towards a
+	// call's completion. Not forwarded to remote nodes.
+	ExcludeSlices []uint64
+
+	// Called with each slice and the host of the node which served it as
+	// each node's results are merged. Intended for reporting progress. Each
+	// node's slices are reported from a separate goroutine so a slow callback
+	// does not delay the query; calls may be concurrent and may continue
+	// after the query returns. Calls which map slices more than once, such
+	// as TopN(), report each slice once per pass and cached results report
+	// no slices. Not forwarded to remote nodes.
+	OnSliceDone func(slice uint64, node string)
+
+	// Labels attached to the query's stats, such as the client issuing it.
+	// Tags are only recorded by the executing node and are not forwarded.
+	Tags map[string]string
+
+	// Visited slices for the current call. Set by ExecuteWithStats().
+	stats *CallStats
+
+	// Fragment copies read when Snapshot is set.
+	snapshot *querySnapshot
+
+	// Warnings raised by the query. Set by execute() if not already set.
+	warnings *warningList
+
+	// Fragments read by the query. Set by execute() if MaxFragmentReads is set.
+	fragmentReads *fragmentReads
+
+	// Hosts which failed a request during the query. Set by execute().
+	failedHosts *hostSet
+}
+
+// reportSlicesDone passes slices merged from host to OnSliceDone, if set,
+// without waiting on the callback.
+func (opt *ExecOptions) reportSlicesDone(slices []uint64, host string) {
+	fn := opt.OnSliceDone
+	if fn == nil {
+		return
+	}
+	go func() {
+		for _, slice := range slices {
+			fn(slice, host)
+		}
+	}()
+}
+
+// fragmentReads counts the fragments read by a query against its budget.
+type fragmentReads struct {
+	max    int64
+	n      int64
+	cancel context.CancelFunc
+}
+
+// add counts a fragment read. Returns ErrFragmentReadBudgetExceeded and
+// cancels the query if the read exceeds the budget. A nil budget is unlimited.
+func (r *fragmentReads) add() error {
+	if r == nil {
+		return nil
+	}
+	if atomic.AddInt64(&r.n, 1) > r.max {
+		r.cancel()
+		return ErrFragmentReadBudgetExceeded
+	}
+	return nil
+}
+
+// exceeded returns true if more fragments were read than the budget allows.
+func (r *fragmentReads) exceeded() bool {
+	return r != nil && atomic.LoadInt64(&r.n) > r.max
+}
+
+// hostSet is a set of node hosts which is safe for concurrent use.
+// Methods on a nil set are no-ops.
+type hostSet struct {
+	mu    sync.Mutex
+	hosts map[string]struct{}
+}
+
+// add inserts host into the set.
+func (s *hostSet) add(host string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.hosts == nil {
+		s.hosts = make(map[string]struct{})
+	}
+	s.hosts[host] = struct{}{}
+}
+
+// contains returns true if host is in the set.
+func (s *hostSet) contains(host string) bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.hosts[host]
+	return ok
+}
+
+// querySnapshot holds copies of the local fragments read by a query.
+type querySnapshot struct {
+	fragments map[snapshotKey]*FragmentSnapshot
+}
+
+// snapshotKey identifies a fragment within an index.
+type snapshotKey struct {
+	frame string
+	view  string
+	slice uint64
+}
+
+// row returns a row from the fragment copy or nil if the fragment did not exist.
+func (s *querySnapshot) row(frame, view string, slice, rowID uint64) *Bitmap {
+	fs := s.fragments[snapshotKey{frame: frame, view: view, slice: slice}]
+	if fs == nil {
+		return nil
+	}
+	return fs.Row(rowID)
+}
+
+// rows returns the non-empty rows of the fragment copy or nil if the fragment
+// did not exist.
+func (s *querySnapshot) rows(frame, view string, slice uint64) []uint64 {
+	fs := s.fragments[snapshotKey{frame: frame, view: view, slice: slice}]
+	if fs == nil {
+		return nil
+	}
+	return fs.Rows()
+}
+
+// columns returns the columns set in the fragment copy or nil if the fragment
+// did not exist.
+func (s *querySnapshot) columns(frame, view string, slice uint64) *Bitmap {
+	fs := s.fragments[snapshotKey{frame: frame, view: view, slice: slice}]
+	if fs == nil {
+		return nil
+	}
+	return fs.Columns()
+}
+
+// columnBound returns the highest column id set in the fragment copy, or the
+// lowest if min is true. Returns false if the fragment was empty or did not exist.
+func (s *querySnapshot) columnBound(frame, view string, slice uint64, min bool) (uint64, bool) {
+	fs := s.fragments[snapshotKey{frame: frame, view: view, slice: slice}]
+	if fs == nil {
+		return 0, false
+	} else if min {
+		return fs.MinColumn()
+	}
+	return fs.MaxColumn()
+}
+
+// pinSnapshot copies all local fragments of the frames read by calls.
+// Every fragment is locked before copying so that no write lands between
+// the copies of two fragments. Fragments are locked in frame, view & slice
+// order so that concurrent pins cannot deadlock.
+func (e *Executor) pinSnapshot(index string, calls []*pql.Call) *querySnapshot {
+	m := make(map[string]struct{})
+	for _, call := range calls {
+		for _, name := range referencedFrames(call, e.defaultFrame(index)) {
+			m[name] = struct{}{}
+		}
+	}
+
+	var frags []*Fragment
+	for name := range m {
+		f := e.Holder.Frame(index, name)
+		if f == nil {
+			continue
+		}
+		for _, view := range f.Views() {
+			frags = append(frags, view.Fragments()...)
+		}
+	}
+	sort.Sort(fragmentsByKey(frags))
+
+	for _, frag := range frags {
+		frag.mu.Lock()
+	}
+	s := &querySnapshot{fragments: make(map[snapshotKey]*FragmentSnapshot, len(frags))}
+	for _, frag := range frags {
+		s.fragments[snapshotKey{frame: frag.Frame(), view: frag.View(), slice: frag.Slice()}] = frag.readSnapshot()
+	}
+	for _, frag := range frags {
+		frag.mu.Unlock()
+	}
+	return s
+}
+
+// fragmentsByKey sorts fragments of an index by frame, view & slice.
+type fragmentsByKey []*Fragment
+
+func (p fragmentsByKey) Len() int      { return len(p) }
+func (p fragmentsByKey) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
+func (p fragmentsByKey) Less(i, j int) bool {
+	if p[i].Frame() != p[j].Frame() {
+		return p[i].Frame() < p[j].Frame()
+	} else if p[i].View() != p[j].View() {
+		return p[i].View() < p[j].View()
+	}
+	return p[i].Slice() < p[j].Slice()
+}
+
+// ExecResult represents the results of a query along with its warnings.
+type ExecResult struct {
+	// Result for each top-level query call.
+	Results []interface{}
+
+	// Warnings raised by all calls, in the order they were raised.
+	Warnings []Warning
+}
+
+// Warning codes.
+const (
+	// A frame referenced by a read call does not exist and was read as empty
+	// because ExecOptions.TreatMissingAsEmpty is set.
+	WarningMissingFrame = "missing_frame"
+
+	// A TopN() call stopped ranking rows before visiting every slice because
+	// Executor.TopNEarlyTerminationBatchN is set. Rows missing from the local
+	// fragment caches may have been omitted.
+	WarningTopNSlicesSkipped = "topn_slices_skipped"
+
+	// Local slices failed to map and were left out of a call's result
+	// because ExecOptions.AllowPartial is set.
+	WarningSlicesFailed = "slices_failed"
+)
+
+// Warning represents a condition which did not fail a query but may affect
+// its results.
+type Warning struct {
+	// Identifies the condition. One of the Warning* codes.
+	Code string `json:"code"`
+
+	// Human readable description of the condition.
+	Message string `json:"message"`
+
+	// Index of the top-level call which raised the warning.
+	Call int `json:"call"`
+
+	// Additional values describing the condition, by name.
+	Context map[string]interface{} `json:"context,omitempty"`
+}
+
+// warningList accumulates the warnings raised by a query. Warnings are
+// attributed to the current call. It is safe for concurrent use and a nil
+// list discards warnings.
+type warningList struct {
+	mu       sync.Mutex
+	call     int
+	warnings []Warning
+}
+
+// setCall sets the index of the call that subsequent warnings belong to.
+func (l *warningList) setCall(i int) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	l.call = i
+	l.mu.Unlock()
+}
+
+// add appends a warning for the current call.
+func (l *warningList) add(code, msg string, context map[string]interface{}) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	l.warnings = append(l.warnings, Warning{Code: code, Message: msg, Call: l.call, Context: context})
+	l.mu.Unlock()
+}
+
+// len returns the number of warnings raised so far.
+func (l *warningList) len() int {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.warnings)
+}
+
+// list returns a copy of the warnings raised so far.
+func (l *warningList) list() []Warning {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.warnings) == 0 {
+		return nil
+	}
+	return append([]Warning(nil), l.warnings...)
+}
+
+// ExecStats represents the slices visited while executing a query.
+type ExecStats struct {
+	// Stats for each call, in query order.
+	Calls []*CallStats
+}
+
+// CallStats represents the slices visited by a single call.
+type CallStats struct {
+	// Slices processed by the local node.
+	LocalSlices []uint64
+
+	// Slices processed by remote nodes, by host.
+	RemoteSlices map[string][]uint64
+
+	// Host of the node which served each slice, including the local node.
+	SliceHosts map[uint64]string
+
+	// Size of the final result: the number of columns for bitmap calls,
+	// the number of pairs for TopN() and the value for Count().
+	ResultN uint64
+
+	// Largest size of a single slice's result processed by the local node.
+	MaxSliceResultN uint64
+
+	// Number of local slices read by Bitmap() calls which had no fragment
+	// and were treated as empty. Slices whose fragment exists but whose row
+	// is empty are not counted. Counted once per Bitmap() call so a slice
+	// is counted again for each missing Bitmap() child of a call.
+	MissingSliceN uint64
+}
+
+// setResult records the size of the final result. No-op if s is nil.
+func (s *CallStats) setResult(v interface{}) {
+	if s == nil {
+		return
+	}
+	s.ResultN = resultSize(v)
+}
+
+// addSliceResult records the size of a local slice result. No-op if s is nil.
+// Safe to call from multiple goroutines.
+func (s *CallStats) addSliceResult(v interface{}) {
+	if s == nil {
+		return
+	}
+
+	n := resultSize(v)
+	for {
+		max := atomic.LoadUint64(&s.MaxSliceResultN)
+		if n <= max || atomic.CompareAndSwapUint64(&s.MaxSliceResultN, max, n) {
+			return
+		}
+	}
+}
+
+// addMissingSlice records a slice read without a fragment. No-op if s is nil.
+// Safe to call from multiple goroutines.
+func (s *CallStats) addMissingSlice() {
+	if s == nil {
+		return
+	}
+	atomic.AddUint64(&s.MissingSliceN, 1)
+}
+
+// resultSize returns the size of a call result. Returns zero for other types.
+func resultSize(v interface{}) uint64 {
+	switch v := v.(type) {
+	case *Bitmap:
+		if v == nil {
+			return 0
+		}
+		return v.Count()
+	case *BitmapResult:
+		return v.Count
+	case []Pair:
+		return uint64(len(v))
+	case *ChecksumResult:
+		return resultSize(v.Result)
+	case uint64:
+		return v
+	default:
+		return 0
+	}
+}
+
+// add records slices as visited by host. No-op if s is nil.
+func (s *CallStats) add(host string, local bool, slices []uint64) {
+	if s == nil {
+		return
+	}
+
+	a := make([]uint64, len(slices))
+	copy(a, slices)
+	sort.Sort(uint64Slice(a))
+
+	if s.SliceHosts == nil {
+		s.SliceHosts = make(map[uint64]string)
+	}
+	for _, slice := range a {
+		s.SliceHosts[slice] = host
+	}
+
+	if local {
+		s.LocalSlices = uint64Slice(s.LocalSlices).merge(a)
+		return
+	}
+	if s.RemoteSlices == nil {
+		s.RemoteSlices = make(map[string][]uint64)
+	}
+	s.RemoteSlices[host] = uint64Slice(s.RemoteSlices[host]).merge(a)
+}
+
+// SlicesByHost returns the slices served by each node, by host.
+func (s *CallStats) SlicesByHost() map[string][]uint64 {
+	m := make(map[string][]uint64)
+	for slice, host := range s.SliceHosts {
+		m[host] = append(m[host], slice)
+	}
+	for _, slices := range m {
+		sort.Sort(uint64Slice(slices))
+	}
+	return m
+}
+
+// ValidationError is returned by Validate() when a call is invalid.
+type ValidationError struct {
+	Call *pql.Call
+	Err  error
+}
+
+// Error returns the error message along with the offending call.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Call.String(), e.Err)
 }
 
 // decodeError returns an error representation of s if s is non-blank.
@@ -1264,6 +6481,80 @@ func decodeError(s string) error {
 	return errors.New(s)
 }
 
+// invalidateQueryCache marks all cached results for an index as stale.
+func (e *Executor) invalidateQueryCache(index string) {
+	if e.QueryCache != nil {
+		e.QueryCache.Invalidate(index)
+	}
+}
+
+// InvalidateQueryCacheFrame marks cached results which read from a frame as stale.
+// Writes which bypass query execution, such as imports, must call it.
+func (e *Executor) InvalidateQueryCacheFrame(index, frame string) {
+	if e.QueryCache != nil {
+		e.QueryCache.InvalidateFrame(index, frame)
+	}
+}
+
+// isReadCall returns true if c does not mutate data.
+func isReadCall(c *pql.Call) bool {
+	switch c.Name {
+	case "ClearBit", "ClearBits", "ClearMatching", "SetBit", "SetBits", "SetRowAttrs", "SetRowFromRoaring", "SetColumnAttrs":
+		return false
+	default:
+		return true
+	}
+}
+
+// referencedFrames returns the names of all frames read by c and its children.
+// Calls which default to a frame reference defaultFrame.
+func referencedFrames(c *pql.Call, defaultFrame string) []string {
+	m := make(map[string]struct{})
+	var walk func(c *pql.Call)
+	walk = func(c *pql.Call) {
+		if frames, ok := c.Args["frames"].([]interface{}); ok {
+			for _, frame := range frames {
+				if frame, ok := frame.(string); ok && frame != "" {
+					m[frame] = struct{}{}
+				}
+			}
+		}
+		if frame, ok := c.Args["frame"].(string); ok && frame != "" {
+			m[frame] = struct{}{}
+		} else {
+			switch c.Name {
+			case "Bitmap", "ColumnDegree", "Range", "RowRange", "Rows", "TopColumns", "TopN":
+				m[defaultFrame] = struct{}{}
+			}
+		}
+		for _, child := range c.Children {
+			walk(child)
+		}
+	}
+	walk(c)
+
+	a := make([]string, 0, len(m))
+	for frame := range m {
+		a = append(a, frame)
+	}
+	sort.Strings(a)
+	return a
+}
+
+// queryCacheKeyString returns the result cache key for a call over a set of slices.
+func queryCacheKeyString(c *pql.Call, slices []uint64) string {
+	var buf bytes.Buffer
+	buf.WriteString(c.CanonicalString())
+	buf.WriteString(" slices=")
+	for i, slice := range slices {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(strconv.FormatUint(slice, 10))
+	}
+	return buf.String()
+}
+
 // hasOnlySetRowAttrs returns true if calls only contains SetRowAttrs() calls.
 func hasOnlySetRowAttrs(calls []*pql.Call) bool {
 	if len(calls) == 0 {
@@ -1278,13 +6569,43 @@ func hasOnlySetRowAttrs(calls []*pql.Call) bool {
 	return true
 }
 
+// hasOnlySetColumnAttrs returns true if calls only contains SetColumnAttrs() calls.
+func hasOnlySetColumnAttrs(calls []*pql.Call) bool {
+	if len(calls) == 0 {
+		return false
+	}
+
+	for _, call := range calls {
+		if call.Name != "SetColumnAttrs" {
+			return false
+		}
+	}
+	return true
+}
+
+// excludeSlices returns the slices not in exclude, in their original order.
+func excludeSlices(slices, exclude []uint64) []uint64 {
+	m := make(map[uint64]struct{}, len(exclude))
+	for _, slice := range exclude {
+		m[slice] = struct{}{}
+	}
+
+	other := make([]uint64, 0, len(slices))
+	for _, slice := range slices {
+		if _, ok := m[slice]; !ok {
+			other = append(other, slice)
+		}
+	}
+	return other
+}
+
 func needsSlices(calls []*pql.Call) bool {
 	if len(calls) == 0 {
 		return false
 	}
 	for _, call := range calls {
 		switch call.Name {
-		case "ClearBit", "SetBit", "SetRowAttrs", "SetColumnAttrs":
+		case "ClearBit", "ClearBits", "SetBit", "SetBits", "SetRowAttrs", "SetRowFromRoaring", "SetColumnAttrs":
 			continue
 		case "Count", "TopN":
 			return true
@@ -0,0 +1,56 @@
+// Copyright 2017 Pilosa Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pilosa
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Ensure parallel children take about as long as the slowest child and
+// are returned in argument order.
+func TestExecutor_mapChildren_Parallel(t *testing.T) {
+	delays := []time.Duration{100 * time.Millisecond, 300 * time.Millisecond, 200 * time.Millisecond}
+	fn := func(i int) (*Bitmap, error) {
+		time.Sleep(delays[i])
+		return NewBitmap(uint64(i)), nil
+	}
+
+	e := NewExecutor()
+	start := time.Now()
+	bms, err := e.mapChildren(len(delays), true, fn)
+	if err != nil {
+		t.Fatal(err)
+	} else if d := time.Since(start); d >= 500*time.Millisecond {
+		t.Fatalf("expected about the slowest child, took %s", d)
+	}
+	for i, bm := range bms {
+		if bits := bm.Bits(); !reflect.DeepEqual(bits, []uint64{uint64(i)}) {
+			t.Fatalf("%d: unexpected bits: %v", i, bits)
+		}
+	}
+
+	// Children run inline once no map slots are free.
+	e.MaxMapGoroutines = 1
+	release, _ := e.tryAcquireMapSlot()
+	defer release()
+	start = time.Now()
+	if _, err := e.mapChildren(len(delays), true, fn); err != nil {
+		t.Fatal(err)
+	} else if d := time.Since(start); d < 600*time.Millisecond {
+		t.Fatalf("expected sequential children, took %s", d)
+	}
+}
@@ -15,15 +15,30 @@
 package pilosa_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
+	"github.com/gogo/protobuf/proto"
 	"github.com/pilosa/pilosa"
+	"github.com/pilosa/pilosa/internal"
 	"github.com/pilosa/pilosa/pql"
+	"github.com/pilosa/pilosa/roaring"
 )
 
 // Ensure a bitmap query can be executed.
@@ -92,6 +107,17 @@ func TestExecutor_Execute_Bitmap(t *testing.T) {
 	})
 }
 
+// Ensure a query against a missing index returns an error.
+func TestExecutor_Execute_ErrIndexNotFound(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	if _, err := e.Execute(context.Background(), "no_such_index", MustParse(`Count(Bitmap(rowID=1, frame=f))`), nil, nil); err != pilosa.ErrIndexNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 // Ensure a difference query can be executed.
 func TestExecutor_Execute_Difference(t *testing.T) {
 	hldr := MustOpenHolder()
@@ -110,6 +136,96 @@ func TestExecutor_Execute_Difference(t *testing.T) {
 	}
 }
 
+// Ensure a difference of several inputs matches subtracting each input in turn.
+func TestExecutor_Execute_Difference_Multiple(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	for slice := uint64(0); slice < 3; slice++ {
+		base := slice * SliceWidth
+		hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, slice).MustSetBits(10, base+1, base+2, base+3, base+4, base+5, base+6)
+		hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, slice).MustSetBits(11, base+1, base+7)
+		hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, slice).MustSetBits(13, base+3)
+	}
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(12, SliceWidth+2, SliceWidth+3)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 2).MustSetBits(12, (2*SliceWidth)+5)
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	for _, tt := range []struct {
+		q   string
+		exp []uint64
+	}{
+		{q: `Difference(Bitmap(rowID=10, frame=f), Bitmap(rowID=11, frame=f), Bitmap(rowID=12, frame=f))`, exp: []uint64{2, 3, 4, 5, 6, SliceWidth + 4, SliceWidth + 5, SliceWidth + 6, (2 * SliceWidth) + 2, (2 * SliceWidth) + 3, (2 * SliceWidth) + 4, (2 * SliceWidth) + 6}},
+		{q: `Difference(Bitmap(rowID=10, frame=f), Bitmap(rowID=11, frame=f), Bitmap(rowID=12, frame=f), Bitmap(rowID=13, frame=f), Bitmap(rowID=99, frame=f))`, exp: []uint64{2, 4, 5, 6, SliceWidth + 4, SliceWidth + 5, SliceWidth + 6, (2 * SliceWidth) + 2, (2 * SliceWidth) + 4, (2 * SliceWidth) + 6}},
+		{q: `Difference(Bitmap(rowID=10, frame=f), Union(Bitmap(rowID=11, frame=f), Bitmap(rowID=13, frame=f)), Difference(Bitmap(rowID=10, frame=f), Bitmap(rowID=12, frame=f), Bitmap(rowID=13, frame=f)))`, exp: []uint64{SliceWidth + 2, (2 * SliceWidth) + 5}},
+		{q: `Difference(Bitmap(rowID=99, frame=f), Bitmap(rowID=10, frame=f), Bitmap(rowID=11, frame=f))`, exp: []uint64{}},
+	} {
+		optimized, err := e.Execute(context.Background(), "i", MustParse(tt.q), nil, nil)
+		if err != nil {
+			t.Fatalf("%s: %s", tt.q, err)
+		}
+		naive, err := e.Execute(context.Background(), "i", MustParse(tt.q), nil, &pilosa.ExecOptions{NoOptimize: true})
+		if err != nil {
+			t.Fatalf("%s: %s", tt.q, err)
+		}
+
+		if bits := optimized[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, tt.exp) {
+			t.Fatalf("%s: unexpected bits: %+v", tt.q, bits)
+		} else if other := naive[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, other) {
+			t.Fatalf("%s: unexpected naive bits: %+v, expected %+v", tt.q, other, bits)
+		}
+
+		// Counts match the returned bits.
+		if res, err := e.Execute(context.Background(), "i", MustParse(`Count(`+tt.q+`)`), nil, nil); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(res, []interface{}{uint64(len(tt.exp))}) {
+			t.Fatalf("%s: unexpected count: %s", tt.q, spew.Sdump(res))
+		}
+	}
+}
+
+// Ensure a count of a difference matches counting the materialized difference.
+func TestExecutor_Execute_Count_Difference(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	for slice := uint64(0); slice < 3; slice++ {
+		base := slice * SliceWidth
+		hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, slice).MustSetBits(10, base+1, base+2, base+3, base+4, base+5, base+6)
+		hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, slice).MustSetBits(11, base+1, base+7)
+		hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, slice).MustSetBits(13, base+3)
+	}
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(12, SliceWidth+2, SliceWidth+3)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 2).MustSetBits(12, (2*SliceWidth)+5)
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	for _, tt := range []struct {
+		q   string
+		exp uint64
+	}{
+		{q: `Count(Difference(Bitmap(rowID=10, frame=f)))`, exp: 18},
+		{q: `Count(Difference(Bitmap(rowID=10, frame=f), Bitmap(rowID=11, frame=f)))`, exp: 15},
+		{q: `Count(Difference(Bitmap(rowID=11, frame=f), Bitmap(rowID=10, frame=f)))`, exp: 3},
+		{q: `Count(Difference(Bitmap(rowID=10, frame=f), Bitmap(rowID=10, frame=f)))`, exp: 0},
+		{q: `Count(Difference(Bitmap(rowID=10, frame=f), Bitmap(rowID=11, frame=f), Bitmap(rowID=12, frame=f)))`, exp: 12},
+		{q: `Count(Difference(Bitmap(rowID=10, frame=f), Union(Bitmap(rowID=11, frame=f), Bitmap(rowID=13, frame=f)), Difference(Bitmap(rowID=10, frame=f), Bitmap(rowID=12, frame=f), Bitmap(rowID=13, frame=f))))`, exp: 2},
+		{q: `Count(Difference(Bitmap(rowID=99, frame=f), Bitmap(rowID=10, frame=f)))`, exp: 0},
+	} {
+		optimized, err := e.Execute(context.Background(), "i", MustParse(tt.q), nil, nil)
+		if err != nil {
+			t.Fatalf("%s: %s", tt.q, err)
+		}
+		naive, err := e.Execute(context.Background(), "i", MustParse(tt.q), nil, &pilosa.ExecOptions{NoOptimize: true})
+		if err != nil {
+			t.Fatalf("%s: %s", tt.q, err)
+		}
+
+		if optimized[0] != tt.exp {
+			t.Fatalf("%s: unexpected count: %v", tt.q, optimized[0])
+		} else if naive[0] != optimized[0] {
+			t.Fatalf("%s: unexpected naive count: %v, expected %v", tt.q, naive[0], optimized[0])
+		}
+	}
+}
+
 // Ensure an empty difference query behaves properly.
 func TestExecutor_Execute_Empty_Difference(t *testing.T) {
 	hldr := MustOpenHolder()
@@ -172,305 +288,431 @@ func TestExecutor_Execute_Union(t *testing.T) {
 	}
 }
 
-// Ensure an empty union query behaves properly.
-func TestExecutor_Execute_Empty_Union(t *testing.T) {
+// Ensure nested children evaluated concurrently are folded like sequential ones.
+func TestExecutor_Execute_ParallelChildren(t *testing.T) {
 	hldr := MustOpenHolder()
 	defer hldr.Close()
-	hldr.MustCreateFragmentIfNotExists("i", "general", pilosa.ViewStandard, 0).MustSetBits(10, 0)
+	hldr.MustCreateFragmentIfNotExists("i", "general", pilosa.ViewStandard, 0).MustSetBits(10, 1, 2, 3)
+	hldr.MustCreateFragmentIfNotExists("i", "general", pilosa.ViewStandard, 0).MustSetBits(11, 2, 3)
+	hldr.MustCreateFragmentIfNotExists("i", "general", pilosa.ViewStandard, 1).MustSetBits(12, SliceWidth+1)
 
 	e := NewExecutor(hldr.Holder, NewCluster(1))
-	if res, err := e.Execute(context.Background(), "i", MustParse(`Union()`), nil, nil); err != nil {
+	e.MaxMapGoroutines = 3
+	q := `Union(Intersect(Bitmap(rowID=10), Bitmap(rowID=11), Bitmap(rowID=10)), Bitmap(rowID=12), Union(Bitmap(rowID=11)))`
+	if res, err := e.Execute(context.Background(), "i", MustParse(q), nil, &pilosa.ExecOptions{ParallelChildren: true}); err != nil {
 		t.Fatal(err)
-	} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, []uint64{}) {
+	} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, []uint64{2, 3, SliceWidth + 1}) {
 		t.Fatalf("unexpected bits: %+v", bits)
 	}
+
+	// Errors from any child fail the call.
+	q = `Union(Bitmap(rowID=10), Bitmap(rowID=10, frame=x))`
+	if _, err := e.Execute(context.Background(), "i", MustParse(q), nil, &pilosa.ExecOptions{ParallelChildren: true}); err != pilosa.ErrFrameNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	}
 }
 
-// Ensure a count query can be executed.
-func TestExecutor_Execute_Count(t *testing.T) {
+// Ensure a frame set on a parent call is the default frame of its children.
+func TestExecutor_Execute_InheritFrame(t *testing.T) {
 	hldr := MustOpenHolder()
 	defer hldr.Close()
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 3)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(10, SliceWidth+1)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(10, SliceWidth+2)
+	hldr.MustCreateFragmentIfNotExists("i", "general", pilosa.ViewStandard, 0).MustSetBits(10, 100)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 1, 2)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(11, 2, 3)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(12, SliceWidth+1)
+	hldr.MustCreateFragmentIfNotExists("i", "g", pilosa.ViewStandard, 0).MustSetBits(10, 4)
 
 	e := NewExecutor(hldr.Holder, NewCluster(1))
-	if res, err := e.Execute(context.Background(), "i", MustParse(`Count(Bitmap(rowID=10, frame=f))`), nil, nil); err != nil {
-		t.Fatal(err)
-	} else if res[0] != uint64(3) {
-		t.Fatalf("unexpected n: %d", res[0])
+	for _, tt := range []struct {
+		q   string
+		exp string
+	}{
+		{q: `Union(Bitmap(rowID=10), Bitmap(rowID=12), frame=f)`, exp: `Union(Bitmap(frame=f, rowID=10), Bitmap(frame=f, rowID=12))`},
+		{q: `Intersect(Bitmap(rowID=10), Bitmap(rowID=11), frame=f)`, exp: `Intersect(Bitmap(frame=f, rowID=10), Bitmap(frame=f, rowID=11))`},
+		{q: `Union(Difference(Bitmap(rowID=10), Bitmap(rowID=11)), Bitmap(rowID=12), frame=f)`, exp: `Union(Difference(Bitmap(frame=f, rowID=10), Bitmap(frame=f, rowID=11)), Bitmap(frame=f, rowID=12))`},
+		{q: `Union(Bitmap(rowID=10), Bitmap(rowID=10, frame=g), frame=f)`, exp: `Union(Bitmap(frame=f, rowID=10), Bitmap(frame=g, rowID=10))`},
+		{q: `Union(Bitmap(rowID=12), Union(Bitmap(rowID=10), frame=g), frame=f)`, exp: `Union(Bitmap(frame=f, rowID=12), Bitmap(frame=g, rowID=10))`},
+		{q: `Count(Union(Bitmap(rowID=10), frame=f))`, exp: `Count(Bitmap(frame=f, rowID=10))`},
+	} {
+		res, err := e.Execute(context.Background(), "i", MustParse(tt.q), nil, nil)
+		if err != nil {
+			t.Fatalf("%s: %s", tt.q, err)
+		}
+		exp, err := e.Execute(context.Background(), "i", MustParse(tt.exp), nil, nil)
+		if err != nil {
+			t.Fatalf("%s: %s", tt.exp, err)
+		}
+		if !reflect.DeepEqual(res, exp) {
+			t.Fatalf("%s: unexpected result: %s, expected %s", tt.q, spew.Sdump(res), spew.Sdump(exp))
+		}
+	}
+
+	// Missing inherited frames are reported like explicit ones.
+	if _, err := e.Execute(context.Background(), "i", MustParse(`Union(Bitmap(rowID=10), frame=x)`), nil, nil); err != pilosa.ErrFrameNotFound {
+		t.Fatalf("unexpected error: %v", err)
 	}
 }
 
-// Ensure a set query can be executed.
-func TestExecutor_Execute_SetBit(t *testing.T) {
+// Ensure bitmap results include their count when requested.
+func TestExecutor_Execute_Union_IncludeCount(t *testing.T) {
 	hldr := MustOpenHolder()
 	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "general", pilosa.ViewStandard, 0).MustSetBits(10, 0, 2)
+	hldr.MustCreateFragmentIfNotExists("i", "general", pilosa.ViewStandard, 1).MustSetBits(10, SliceWidth+1)
+	hldr.MustCreateFragmentIfNotExists("i", "general", pilosa.ViewStandard, 1).MustSetBits(11, SliceWidth+1, SliceWidth+2)
 
 	e := NewExecutor(hldr.Holder, NewCluster(1))
-	f := hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0)
-	if n := f.Row(11).Count(); n != 0 {
-		t.Fatalf("unexpected bitmap count: %d", n)
-	}
-
-	if res, err := e.Execute(context.Background(), "i", MustParse(`SetBit(rowID=11, frame=f, columnID=1)`), nil, nil); err != nil {
+	res, err := e.Execute(context.Background(), "i", MustParse(`Union(Bitmap(rowID=10), Bitmap(rowID=11)) Count(Union(Bitmap(rowID=10), Bitmap(rowID=11)))`), nil, &pilosa.ExecOptions{IncludeCount: true})
+	if err != nil {
 		t.Fatal(err)
-	} else {
-		if !res[0].(bool) {
-			t.Fatalf("expected bit changed")
-		}
 	}
 
-	if n := f.Row(11).Count(); n != 1 {
-		t.Fatalf("unexpected bitmap count: %d", n)
-	}
-	if res, err := e.Execute(context.Background(), "i", MustParse(`SetBit(rowID=11, frame=f, columnID=1)`), nil, nil); err != nil {
-		t.Fatal(err)
-	} else {
-		if res[0].(bool) {
-			t.Fatalf("expected bit unchanged")
-		}
+	result, ok := res[0].(*pilosa.BitmapResult)
+	if !ok {
+		t.Fatalf("unexpected result: %s", spew.Sdump(res[0]))
+	} else if bits := result.Bitmap.Bits(); !reflect.DeepEqual(bits, []uint64{0, 2, SliceWidth + 1, SliceWidth + 2}) {
+		t.Fatalf("unexpected bits: %+v", bits)
+	} else if result.Count != res[1] {
+		t.Fatalf("unexpected count: %d != %v", result.Count, res[1])
 	}
 }
 
-// Ensure a SetRowAttrs() query can be executed.
-func TestExecutor_Execute_SetRowAttrs(t *testing.T) {
+// Ensure bitmap results can be returned as a single roaring bitmap.
+func TestExecutor_Execute_RawRoaring(t *testing.T) {
 	hldr := MustOpenHolder()
 	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 0, 2, 70000)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(10, SliceWidth+1, SliceWidth+200000)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 3).MustSetBits(10, (3*SliceWidth)+5)
 
-	// Create frames.
-	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
-	if _, err := index.CreateFrameIfNotExists("f", pilosa.FrameOptions{}); err != nil {
-		t.Fatal(err)
-	} else if _, err := index.CreateFrameIfNotExists("xxx", pilosa.FrameOptions{}); err != nil {
-		t.Fatal(err)
-	}
-
-	// Set two fields on f/10.
-	// Also set fields on other bitmaps and frames to test isolation.
 	e := NewExecutor(hldr.Holder, NewCluster(1))
-	if _, err := e.Execute(context.Background(), "i", MustParse(`SetRowAttrs(rowID=10, frame=f, foo="bar")`), nil, nil); err != nil {
+	exp, err := e.Execute(context.Background(), "i", MustParse(`Bitmap(rowID=10, frame=f)`), nil, nil)
+	if err != nil {
 		t.Fatal(err)
 	}
-	if _, err := e.Execute(context.Background(), "i", MustParse(`SetRowAttrs(rowID=200, frame=f, YYY=1)`), nil, nil); err != nil {
+	res, err := e.Execute(context.Background(), "i", MustParse(`Bitmap(rowID=10, frame=f)`), nil, &pilosa.ExecOptions{RawRoaring: true})
+	if err != nil {
 		t.Fatal(err)
 	}
-	if _, err := e.Execute(context.Background(), "i", MustParse(`SetRowAttrs(rowID=10, frame=xxx, YYY=1)`), nil, nil); err != nil {
-		t.Fatal(err)
+
+	data, ok := res[0].([]byte)
+	if !ok {
+		t.Fatalf("unexpected result: %s", spew.Sdump(res[0]))
 	}
-	if _, err := e.Execute(context.Background(), "i", MustParse(`SetRowAttrs(rowID=10, frame=f, baz=123, bat=true)`), nil, nil); err != nil {
+	bm := roaring.NewBitmap()
+	if err := bm.UnmarshalBinary(data); err != nil {
 		t.Fatal(err)
+	} else if bits, expBits := bm.Slice(), exp[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, expBits) {
+		t.Fatalf("unexpected bits: %+v != %+v", bits, expBits)
 	}
+}
 
-	f := hldr.Frame("i", "f")
-	if m, err := f.RowAttrStore().Attrs(10); err != nil {
-		t.Fatal(err)
-	} else if !reflect.DeepEqual(m, map[string]interface{}{"foo": "bar", "baz": int64(123), "bat": true}) {
-		t.Fatalf("unexpected bitmap attr: %#v", m)
+// Ensure remote bitmap results are merged before being encoded as roaring.
+func TestExecutor_Execute_Remote_RawRoaring(t *testing.T) {
+	c := NewCluster(2)
+
+	// Create secondary server and update second cluster node.
+	s := NewServer()
+	defer s.Close()
+	c.Nodes[1].Host = s.Host()
+
+	// The remote node returns a decoded bitmap for slice 1.
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		if opt.RawRoaring {
+			t.Fatal("unexpected raw roaring option")
+		}
+		return []interface{}{pilosa.NewBitmap(SliceWidth+5, SliceWidth+70000)}, nil
 	}
-}
 
-// Ensure a TopN() query can be executed.
-func TestExecutor_Execute_TopN(t *testing.T) {
+	// The local node owns slice 0.
 	hldr := MustOpenHolder()
 	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 1, 3)
 
-	// Set bits for rows 0, 10, & 20 across two slices.
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).SetBit(0, 0)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).SetBit(0, 1)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(0, SliceWidth)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(0, SliceWidth+2)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 5).SetBit(0, (5*SliceWidth)+100)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).SetBit(10, 0)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(10, SliceWidth)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(20, SliceWidth)
-	hldr.MustCreateFragmentIfNotExists("i", "other", pilosa.ViewStandard, 0).SetBit(0, 0)
+	e := NewExecutor(hldr.Holder, c)
+	res, err := e.Execute(context.Background(), "i", MustParse(`Bitmap(rowID=10, frame=f)`), []uint64{0, 1}, &pilosa.ExecOptions{RawRoaring: true})
+	if err != nil {
+		t.Fatal(err)
+	}
 
-	// Execute query.
-	e := NewExecutor(hldr.Holder, NewCluster(1))
-	if result, err := e.Execute(context.Background(), "i", MustParse(`TopN(frame=f, n=2)`), nil, nil); err != nil {
+	bm := roaring.NewBitmap()
+	if err := bm.UnmarshalBinary(res[0].([]byte)); err != nil {
 		t.Fatal(err)
-	} else if !reflect.DeepEqual(result[0], []pilosa.Pair{
-		{ID: 0, Count: 5},
-		{ID: 10, Count: 2},
-	}) {
-		t.Fatalf("unexpected result: %s", spew.Sdump(result))
+	} else if bits := bm.Slice(); !reflect.DeepEqual(bits, []uint64{1, 3, SliceWidth + 5, SliceWidth + 70000}) {
+		t.Fatalf("unexpected bits: %+v", bits)
 	}
 }
-func TestExecutor_Execute_TopN_fill(t *testing.T) {
+
+// Ensure optimized set operations return the same results as the original call tree.
+func TestExecutor_Execute_Optimize(t *testing.T) {
 	hldr := MustOpenHolder()
 	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "general", pilosa.ViewStandard, 0).MustSetBits(10, 0, 1, 2, 3)
+	hldr.MustCreateFragmentIfNotExists("i", "general", pilosa.ViewStandard, 0).MustSetBits(11, 1, 2, 4)
+	hldr.MustCreateFragmentIfNotExists("i", "general", pilosa.ViewStandard, 1).MustSetBits(11, SliceWidth+1)
+	hldr.MustCreateFragmentIfNotExists("i", "general", pilosa.ViewStandard, 0).MustSetBits(12, 2, 3, 5)
 
-	// Set bits for rows 0, 10, & 20 across two slices.
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).SetBit(0, 0)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).SetBit(0, 1)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).SetBit(0, 2)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(0, SliceWidth)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(1, SliceWidth+2)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(1, SliceWidth)
-
-	// Execute query.
 	e := NewExecutor(hldr.Holder, NewCluster(1))
-	if result, err := e.Execute(context.Background(), "i", MustParse(`TopN(frame=f, n=1)`), nil, nil); err != nil {
-		t.Fatal(err)
-	} else if !reflect.DeepEqual(result, []interface{}{[]pilosa.Pair{
-		{ID: 0, Count: 4},
-	}}) {
-		t.Fatalf("unexpected result: %s", spew.Sdump(result))
+	for _, tt := range []struct {
+		q   string
+		opt string
+	}{
+		{
+			q:   `Union(Union(Bitmap(rowID=10), Bitmap(rowID=11)), Bitmap(rowID=12))`,
+			opt: `Union(Bitmap(rowID=10), Bitmap(rowID=11), Bitmap(rowID=12))`,
+		},
+		{
+			q:   `Intersect(Bitmap(rowID=10), Intersect(Bitmap(rowID=11), Bitmap(rowID=12)))`,
+			opt: `Intersect(Bitmap(rowID=10), Bitmap(rowID=11), Bitmap(rowID=12))`,
+		},
+		{
+			q:   `Difference(Difference(Bitmap(rowID=10), Bitmap(rowID=11)), Bitmap(rowID=12))`,
+			opt: `Difference(Bitmap(rowID=10), Bitmap(rowID=11), Bitmap(rowID=12))`,
+		},
+		{
+			q:   `Difference(Bitmap(rowID=10), Union())`,
+			opt: `Difference(Bitmap(rowID=10))`,
+		},
+		{
+			q:   `Intersect(Union(Union(Bitmap(rowID=10), Union()), Bitmap(rowID=12)), Bitmap(rowID=11))`,
+			opt: `Intersect(Union(Bitmap(rowID=10), Bitmap(rowID=12)), Bitmap(rowID=11))`,
+		},
+	} {
+		q := MustParse(tt.q)
+		if other := pilosa.OptimizeCall(q.Calls[0]); other.String() != MustParse(tt.opt).Calls[0].String() {
+			t.Fatalf("%s: unexpected optimized call: %s", tt.q, other)
+		} else if callNodeN(other) >= callNodeN(q.Calls[0]) {
+			t.Fatalf("%s: expected fewer nodes: %s", tt.q, other)
+		}
+
+		exp, err := e.Execute(context.Background(), "i", q, nil, &pilosa.ExecOptions{NoOptimize: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := e.Execute(context.Background(), "i", q, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, exp[0].(*pilosa.Bitmap).Bits()) {
+			t.Fatalf("%s: unexpected bits: %+v", tt.q, bits)
+		}
 	}
 }
 
-// Ensure
-func TestExecutor_Execute_TopN_fill_small(t *testing.T) {
+// callNodeN returns the number of calls in the tree rooted at c.
+func callNodeN(c *pql.Call) int {
+	n := 1
+	for _, child := range c.Children {
+		n += callNodeN(child)
+	}
+	return n
+}
+
+// Ensure column attributes are attached to union results if requested.
+func TestExecutor_Execute_Union_AttachColumnAttrs(t *testing.T) {
 	hldr := MustOpenHolder()
 	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "general", pilosa.ViewStandard, 0).MustSetBits(10, 0, 2)
+	hldr.MustCreateFragmentIfNotExists("i", "general", pilosa.ViewStandard, 1).MustSetBits(11, SliceWidth+2)
 
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).SetBit(0, 0)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(0, SliceWidth)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 2).SetBit(0, 2*SliceWidth)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 3).SetBit(0, 3*SliceWidth)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 4).SetBit(0, 4*SliceWidth)
+	idx := hldr.Index("i")
+	if err := idx.ColumnAttrStore().SetAttrs(2, map[string]interface{}{"x": "a"}); err != nil {
+		t.Fatal(err)
+	} else if err := idx.ColumnAttrStore().SetAttrs(SliceWidth+2, map[string]interface{}{"x": "b"}); err != nil {
+		t.Fatal(err)
+	}
 
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).SetBit(1, 0)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).SetBit(1, 1)
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	q := MustParse(`Union(Bitmap(rowID=10), Bitmap(rowID=11))`)
+	if res, err := e.Execute(context.Background(), "i", q, nil, &pilosa.ExecOptions{AttachColumnAttrs: true}); err != nil {
+		t.Fatal(err)
+	} else if a := res[0].(*pilosa.Bitmap).ColumnAttrSets; !reflect.DeepEqual(a, []*pilosa.ColumnAttrSet{
+		{ID: 2, Attrs: map[string]interface{}{"x": "a"}},
+		{ID: SliceWidth + 2, Attrs: map[string]interface{}{"x": "b"}},
+	}) {
+		t.Fatalf("unexpected column attrs: %s", spew.Sdump(a))
+	}
 
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(2, SliceWidth)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(2, SliceWidth+1)
+	// Attributes should not be attached by default.
+	if res, err := e.Execute(context.Background(), "i", q, nil, nil); err != nil {
+		t.Fatal(err)
+	} else if a := res[0].(*pilosa.Bitmap).ColumnAttrSets; a != nil {
+		t.Fatalf("unexpected column attrs: %s", spew.Sdump(a))
+	}
 
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 2).SetBit(3, 2*SliceWidth)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 2).SetBit(3, 2*SliceWidth+1)
+	// Results over the column limit should return an error.
+	e.MaxColumnAttrsN = 2
+	if _, err := e.Execute(context.Background(), "i", q, nil, &pilosa.ExecOptions{AttachColumnAttrs: true}); err != pilosa.ErrTooManyColumnAttrs {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
 
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 3).SetBit(4, 3*SliceWidth)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 3).SetBit(4, 3*SliceWidth+1)
+// Ensure an empty union query behaves properly.
+func TestExecutor_Execute_Empty_Union(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "general", pilosa.ViewStandard, 0).MustSetBits(10, 0)
 
-	// Execute query.
 	e := NewExecutor(hldr.Holder, NewCluster(1))
-	if result, err := e.Execute(context.Background(), "i", MustParse(`TopN(frame=f, n=1)`), nil, nil); err != nil {
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Union()`), nil, nil); err != nil {
 		t.Fatal(err)
-	} else if !reflect.DeepEqual(result, []interface{}{[]pilosa.Pair{
-		{ID: 0, Count: 5},
-	}}) {
-		t.Fatalf("unexpected result: %s", spew.Sdump(result))
+	} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, []uint64{}) {
+		t.Fatalf("unexpected bits: %+v", bits)
 	}
 }
 
-// Ensure a TopN() query with a source bitmap can be executed.
-func TestExecutor_Execute_TopN_Src(t *testing.T) {
+// Ensure a count query can be executed.
+func TestExecutor_Execute_Count(t *testing.T) {
 	hldr := MustOpenHolder()
 	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 3)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(10, SliceWidth+1)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(10, SliceWidth+2)
 
-	// Set bits for rows 0, 10, & 20 across two slices.
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).SetBit(0, 0)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).SetBit(0, 1)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(0, SliceWidth)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(10, SliceWidth)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(10, SliceWidth+1)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(20, SliceWidth)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(20, SliceWidth+1)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(20, SliceWidth+2)
-
-	// Create an intersecting row.
-	hldr.MustCreateFragmentIfNotExists("i", "other", pilosa.ViewStandard, 1).SetBit(100, SliceWidth)
-	hldr.MustCreateFragmentIfNotExists("i", "other", pilosa.ViewStandard, 1).SetBit(100, SliceWidth+1)
-	hldr.MustCreateFragmentIfNotExists("i", "other", pilosa.ViewStandard, 1).SetBit(100, SliceWidth+2)
-
-	// Execute query.
 	e := NewExecutor(hldr.Holder, NewCluster(1))
-	if result, err := e.Execute(context.Background(), "i", MustParse(`TopN(Bitmap(rowID=100, frame=other), frame=f, n=3)`), nil, nil); err != nil {
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Count(Bitmap(rowID=10, frame=f))`), nil, nil); err != nil {
 		t.Fatal(err)
-	} else if !reflect.DeepEqual(result, []interface{}{[]pilosa.Pair{
-		{ID: 20, Count: 3},
-		{ID: 10, Count: 2},
-		{ID: 0, Count: 1},
-	}}) {
-		t.Fatalf("unexpected result: %s", spew.Sdump(result))
+	} else if res[0] != uint64(3) {
+		t.Fatalf("unexpected n: %d", res[0])
 	}
 }
 
-//Ensure TopN handles Attribute filters
-func TestExecutor_Execute_TopN_Attr(t *testing.T) {
-	//
+// Ensure a count query can return the number of columns in its frames.
+func TestExecutor_Execute_Count_IncludeUniverse(t *testing.T) {
 	hldr := MustOpenHolder()
 	defer hldr.Close()
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).SetBit(0, 0)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).SetBit(0, 1)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(10, SliceWidth)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 1, 3)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(11, 3, 5, 70000)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(12, SliceWidth+1)
+	hldr.MustCreateFragmentIfNotExists("i", "g", pilosa.ViewStandard, 0).MustSetBits(1, 3, 6)
+	hldr.MustCreateFragmentIfNotExists("i", "h", pilosa.ViewStandard, 0).MustSetBits(1, 7)
 
-	if err := hldr.Frame("i", "f").RowAttrStore().SetAttrs(10, map[string]interface{}{"category": int64(123)}); err != nil {
-		t.Fatal(err)
-	}
 	e := NewExecutor(hldr.Holder, NewCluster(1))
-	if result, err := e.Execute(context.Background(), "i", MustParse(`TopN(frame="f", n=1, field="category", filters=[123])`), nil, nil); err != nil {
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Count(Bitmap(rowID=10, frame=f)) Count(Intersect(Bitmap(rowID=10, frame=f), Bitmap(rowID=1, frame=g)))`), nil, &pilosa.ExecOptions{IncludeUniverse: true}); err != nil {
 		t.Fatal(err)
-	} else if !reflect.DeepEqual(result, []interface{}{[]pilosa.Pair{
-		{ID: 10, Count: 1},
-	}}) {
-		t.Fatalf("unexpected result: %s", spew.Sdump(result))
+	} else if !reflect.DeepEqual(res, []interface{}{
+		&pilosa.CountResult{Count: 2, Universe: 5},
+		&pilosa.CountResult{Count: 1, Universe: 6},
+	}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res))
 	}
 
+	// The universe matches a count of every row in the frame.
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Count(Union(Bitmap(rowID=10, frame=f), Bitmap(rowID=11, frame=f), Bitmap(rowID=12, frame=f))) CountColumns(frames=["f"]) Count(Bitmap(rowID=10, frame=f))`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{uint64(5), uint64(5), uint64(2)}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res))
+	}
 }
 
-//Ensure TopN handles Attribute filters with source bitmap
-func TestExecutor_Execute_TopN_Attr_Src(t *testing.T) {
-	//
-	hldr := MustOpenHolder()
-	defer hldr.Close()
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).SetBit(0, 0)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).SetBit(0, 1)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(10, SliceWidth)
+// Ensure result checksums only depend on the merged result.
+func TestExecutor_Execute_Checksum(t *testing.T) {
+	// Open two holders with the same bits set in different orders.
+	bits := [][2]uint64{{10, 1}, {10, SliceWidth + 2}, {10, (3 * SliceWidth) + 3}, {11, 1}, {11, 2}, {12, SliceWidth + 5}}
+	var executors []*Executor
+	for i := 0; i < 2; i++ {
+		hldr := MustOpenHolder()
+		defer hldr.Close()
+		hldr.MustCreateFrameIfNotExists("i", "f")
 
-	if err := hldr.Frame("i", "f").RowAttrStore().SetAttrs(10, map[string]interface{}{"category": uint64(123)}); err != nil {
-		t.Fatal(err)
+		e := NewExecutor(hldr.Holder, NewCluster(1))
+		for j := range bits {
+			bit := bits[j]
+			if i == 1 {
+				bit = bits[len(bits)-j-1]
+			}
+			if _, err := e.Execute(context.Background(), "i", MustParse(fmt.Sprintf(`SetBit(frame=f, rowID=%d, columnID=%d)`, bit[0], bit[1])), nil, nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+		executors = append(executors, e)
 	}
-	e := NewExecutor(hldr.Holder, NewCluster(1))
-	if result, err := e.Execute(context.Background(), "i", MustParse(`TopN(Bitmap(rowID=10,frame=f),frame="f", n=1, field="category", filters=[123])`), nil, nil); err != nil {
-		t.Fatal(err)
-	} else if !reflect.DeepEqual(result, []interface{}{[]pilosa.Pair{
-		{ID: 10, Count: 1},
-	}}) {
-		t.Fatalf("unexpected result: %s", spew.Sdump(result))
+
+	q := `Union(Bitmap(rowID=10, frame=f), Bitmap(rowID=12, frame=f)) TopN(frame=f, ids=[10,11,12]) Count(Bitmap(rowID=10, frame=f))`
+	opt := &pilosa.ExecOptions{Checksum: true}
+	checksums := func(e *Executor) []string {
+		res, err := e.Execute(context.Background(), "i", MustParse(q), nil, opt)
+		if err != nil {
+			t.Fatal(err)
+		} else if res[2] != uint64(3) {
+			t.Fatalf("unexpected count: %v", res[2])
+		}
+		a := make([]string, 2)
+		for i := range a {
+			r, ok := res[i].(*pilosa.ChecksumResult)
+			if !ok || r.Checksum == "" {
+				t.Fatalf("unexpected result: %s", spew.Sdump(res[i]))
+			}
+			a[i] = r.Checksum
+		}
+		return a
+	}
+
+	exp := checksums(executors[0])
+	if sums := checksums(executors[0]); !reflect.DeepEqual(sums, exp) {
+		t.Fatalf("checksums changed between runs: %v != %v", sums, exp)
+	} else if sums := checksums(executors[1]); !reflect.DeepEqual(sums, exp) {
+		t.Fatalf("checksums differ between holders: %v != %v", sums, exp)
 	}
 
+	// A single changed bit changes both checksums.
+	if _, err := executors[1].Execute(context.Background(), "i", MustParse(`SetBit(frame=f, rowID=12, columnID=7)`), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if sums := checksums(executors[1]); sums[0] == exp[0] || sums[1] == exp[1] {
+		t.Fatalf("expected checksums to change: %v", sums)
+	}
 }
 
-// Ensure a range query can be executed.
-func TestExecutor_Execute_Range(t *testing.T) {
+// Ensure a page query returns the total count and the requested columns.
+func TestExecutor_Execute_Page(t *testing.T) {
 	hldr := MustOpenHolder()
 	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 3, 5, 7)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(10, SliceWidth+1, SliceWidth+2)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 3).MustSetBits(10, (3*SliceWidth)+4)
 
-	// Create index.
-	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
-
-	// Create frame.
-	f, err := index.CreateFrameIfNotExists("f", pilosa.FrameOptions{})
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	res, err := e.Execute(context.Background(), "i", MustParse(`Count(Bitmap(rowID=10, frame=f)) Bitmap(rowID=10, frame=f)`), nil, nil)
 	if err != nil {
 		t.Fatal(err)
-	} else if err := f.SetTimeQuantum(pilosa.TimeQuantum("YMDH")); err != nil {
-		t.Fatal(err)
 	}
+	count, bits := res[0].(uint64), res[1].(*pilosa.Bitmap).Bits()
 
-	// Set bits.
-	f.MustSetBit(pilosa.ViewStandard, 1, 2, MustParseTimePtr("1999-12-31 00:00"))
-	f.MustSetBit(pilosa.ViewStandard, 1, 3, MustParseTimePtr("2000-01-01 00:00"))
-	f.MustSetBit(pilosa.ViewStandard, 1, 4, MustParseTimePtr("2000-01-02 00:00"))
-	f.MustSetBit(pilosa.ViewStandard, 1, 5, MustParseTimePtr("2000-02-01 00:00"))
-	f.MustSetBit(pilosa.ViewStandard, 1, 6, MustParseTimePtr("2001-01-01 00:00"))
-	f.MustSetBit(pilosa.ViewStandard, 1, 7, MustParseTimePtr("2002-01-01 02:00"))
+	for _, tt := range []struct {
+		offset, limit int
+	}{
+		{0, 2},
+		{2, 3},
+		{4, 10},
+		{6, 1},
+		{10, 2},
+		{0, 0},
+	} {
+		exp := []uint64{}
+		if tt.offset < len(bits) {
+			exp = bits[tt.offset:]
+			if len(exp) > tt.limit {
+				exp = exp[:tt.limit]
+			}
+		}
 
-	f.MustSetBit(pilosa.ViewStandard, 1, 2, MustParseTimePtr("1999-12-30 00:00"))  // too early
-	f.MustSetBit(pilosa.ViewStandard, 1, 2, MustParseTimePtr("2002-02-01 00:00"))  // too late
-	f.MustSetBit(pilosa.ViewStandard, 10, 2, MustParseTimePtr("2001-01-01 00:00")) // different row
+		q := fmt.Sprintf(`Page(Bitmap(rowID=10, frame=f), offset=%d, limit=%d)`, tt.offset, tt.limit)
+		if res, err := e.Execute(context.Background(), "i", MustParse(q), nil, nil); err != nil {
+			t.Fatal(err)
+		} else if page := res[0].(*pilosa.PageResult); page.Count != count {
+			t.Fatalf("%s: unexpected count: %d", q, page.Count)
+		} else if !reflect.DeepEqual(page.Columns, exp) {
+			t.Fatalf("%s: unexpected columns: %v, expected %v", q, page.Columns, exp)
+		}
+	}
 
-	e := NewExecutor(hldr.Holder, NewCluster(1))
-	if res, err := e.Execute(context.Background(), "i", MustParse(`Range(rowID=1, frame=f, start="1999-12-31T00:00", end="2002-01-01T03:00")`), nil, nil); err != nil {
-		t.Fatal(err)
-	} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, []uint64{2, 3, 4, 5, 6, 7}) {
-		t.Fatalf("unexpected bits: %+v", bits)
+	if _, err := e.Execute(context.Background(), "i", MustParse(`Page(Bitmap(rowID=10, frame=f), offset=2)`), nil, nil); err == nil || err.Error() != "Page() limit required" {
+		t.Fatalf("unexpected error: %v", err)
 	}
 }
 
-// Ensure a remote query can return a bitmap.
-func TestExecutor_Execute_Remote_Bitmap(t *testing.T) {
+// Ensure a page query merges remote pages before applying the offset.
+func TestExecutor_Execute_Remote_Page(t *testing.T) {
 	c := NewCluster(2)
 
 	// Create secondary server and update second cluster node.
@@ -478,161 +720,361 @@ func TestExecutor_Execute_Remote_Bitmap(t *testing.T) {
 	defer s.Close()
 	c.Nodes[1].Host = s.Host()
 
-	// Mock secondary server's executor to verify arguments and return a bitmap.
+	// The remote node returns its unpaged columns.
 	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
-		if index != "i" {
-			t.Fatalf("unexpected index: %s", index)
-		} else if query.String() != `Bitmap(frame="f", rowID=10)` {
+		if query.String() != `Page(Bitmap(frame="f", rowID=10), limit=2, offset=1)` {
 			t.Fatalf("unexpected query: %s", query.String())
-		} else if !reflect.DeepEqual(slices, []uint64{1}) {
-			t.Fatalf("unexpected slices: %+v", slices)
+		} else if !opt.Remote {
+			t.Fatal("expected remote option")
 		}
-
-		// Set bits in slice 0 & 2.
-		bm := pilosa.NewBitmap(
-			(0*SliceWidth)+1,
-			(0*SliceWidth)+2,
-			(2*SliceWidth)+4,
-		)
-		return []interface{}{bm}, nil
+		return []interface{}{&pilosa.PageResult{Count: 4, Columns: []uint64{SliceWidth + 1, SliceWidth + 2, SliceWidth + 3}}}, nil
 	}
 
-	// Create local executor data.
-	// The local node owns slice 1.
+	// The local node owns slice 0.
 	hldr := MustOpenHolder()
 	defer hldr.Close()
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(10, (1*SliceWidth)+1)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 2, 4)
 
 	e := NewExecutor(hldr.Holder, c)
-	if res, err := e.Execute(context.Background(), "i", MustParse(`Bitmap(rowID=10, frame=f)`), nil, nil); err != nil {
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Page(Bitmap(rowID=10, frame=f), offset=1, limit=2)`), []uint64{0, 1}, nil); err != nil {
 		t.Fatal(err)
-	} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, []uint64{1, 2, 2*SliceWidth + 4}) {
-		t.Fatalf("unexpected bits: %+v", bits)
+	} else if !reflect.DeepEqual(res, []interface{}{&pilosa.PageResult{Count: 6, Columns: []uint64{4, SliceWidth + 1}}}) {
+		t.Fatalf("unexpected result: %s", spew.Sdump(res))
 	}
 }
 
-// Ensure a remote query can return a count.
-func TestExecutor_Execute_Remote_Count(t *testing.T) {
-	c := NewCluster(2)
+// Ensure a rows query returns exactly the rows overlapping the filter.
+func TestExecutor_Execute_Rows(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(1, 1, 2)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(2, 3)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(5, 2)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(3, SliceWidth+1)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(4, SliceWidth+2)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(5, SliceWidth+2)
+	hldr.MustCreateFragmentIfNotExists("i", "g", pilosa.ViewStandard, 0).MustSetBits(100, 2, 4)
+	hldr.MustCreateFragmentIfNotExists("i", "g", pilosa.ViewStandard, 1).MustSetBits(100, SliceWidth+2)
 
-	// Create secondary server and update second cluster node.
-	s := NewServer()
-	defer s.Close()
-	c.Nodes[1].Host = s.Host()
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	for _, tt := range []struct {
+		q      string
+		rowIDs []uint64
+	}{
+		{q: `Rows(frame=f)`, rowIDs: []uint64{1, 2, 3, 4, 5}},
+		{q: `Rows(frame=f, n=2)`, rowIDs: []uint64{1, 2}},
+		{q: `Rows(Bitmap(rowID=100, frame=g), frame=f)`, rowIDs: []uint64{1, 4, 5}},
+		{q: `Rows(Bitmap(rowID=100, frame=g), frame=f, n=2)`, rowIDs: []uint64{1, 4}},
+		{q: `Rows(Bitmap(rowID=101, frame=g), frame=f)`, rowIDs: []uint64{}},
+		{q: `Rows(frame=h)`, rowIDs: []uint64{}},
+	} {
+		opt := &pilosa.ExecOptions{TreatMissingAsEmpty: true}
+		if res, err := e.Execute(context.Background(), "i", MustParse(tt.q), nil, opt); err != nil {
+			t.Fatalf("%s: %s", tt.q, err)
+		} else if !reflect.DeepEqual(res[0], tt.rowIDs) {
+			t.Fatalf("%s: unexpected rows: %v", tt.q, res[0])
+		}
+	}
 
-	// Mock secondary server's executor to return a count.
-	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
-		return []interface{}{uint64(10)}, nil
+	if _, err := e.Execute(context.Background(), "i", MustParse(`Rows(Bitmap(rowID=1, frame=f), Bitmap(rowID=2, frame=f), frame=f)`), nil, nil); err == nil || err.Error() != "Rows() only accepts a single bitmap input" {
+		t.Fatalf("unexpected error: %v", err)
 	}
+}
 
-	// Create local executor data. The local node owns slice 1.
+// Ensure rows from remote slices are merged with local rows.
+func TestExecutor_Execute_Remote_Rows(t *testing.T) {
+	for _, format := range []string{pilosa.RemoteFormatProtobuf, pilosa.RemoteFormatJSON} {
+		c := NewCluster(2)
+
+		// Create secondary server and update second cluster node.
+		s := NewServer()
+		defer s.Close()
+		c.Nodes[1].Host = s.Host()
+
+		s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+			if query.String() != `Rows(frame="f", n=3)` {
+				t.Fatalf("unexpected query: %s", query.String())
+			}
+			return []interface{}{[]uint64{2, 4, 6}}, nil
+		}
+
+		// The local node owns slice 0.
+		hldr := MustOpenHolder()
+		defer hldr.Close()
+		hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(1, 1)
+		hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(2, 1)
+
+		e := NewExecutor(hldr.Holder, c)
+		e.RemoteFormat = format
+		if res, err := e.Execute(context.Background(), "i", MustParse(`Rows(frame=f, n=3)`), []uint64{0, 1}, nil); err != nil {
+			t.Fatalf("%s: %s", format, err)
+		} else if !reflect.DeepEqual(res, []interface{}{[]uint64{1, 2, 4}}) {
+			t.Fatalf("%s: unexpected result: %s", format, spew.Sdump(res))
+		}
+	}
+}
+
+// Ensure a ColumnDegree query counts the rows of each filtered column.
+func TestExecutor_Execute_ColumnDegree(t *testing.T) {
 	hldr := MustOpenHolder()
 	defer hldr.Close()
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 2).MustSetBits(10, (2*SliceWidth)+1)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 2).MustSetBits(10, (2*SliceWidth)+2)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(1, 1, 2, 3)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(2, 2, 3, 5)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(3, 3)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(3, SliceWidth+1)
+	hldr.MustCreateFragmentIfNotExists("i", "g", pilosa.ViewStandard, 0).MustSetBits(0, 1, 2, 3, 4)
+	hldr.MustCreateFragmentIfNotExists("i", "g", pilosa.ViewStandard, 1).MustSetBits(0, SliceWidth+1)
 
-	e := NewExecutor(hldr.Holder, c)
-	if res, err := e.Execute(context.Background(), "i", MustParse(`Count(Bitmap(rowID=10, frame=f))`), nil, nil); err != nil {
-		t.Fatal(err)
-	} else if res[0] != uint64(12) {
-		t.Fatalf("unexpected n: %d", res[0])
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	for _, tt := range []struct {
+		q   string
+		exp []pilosa.Pair
+	}{
+		{q: `ColumnDegree(Bitmap(rowID=0, frame=g), frame=f, n=10)`, exp: []pilosa.Pair{{ID: 3, Count: 3}, {ID: 2, Count: 2}, {ID: 1, Count: 1}, {ID: SliceWidth + 1, Count: 1}, {ID: 4, Count: 0}}},
+		{q: `ColumnDegree(Bitmap(rowID=0, frame=g), frame=f, n=2)`, exp: []pilosa.Pair{{ID: 3, Count: 3}, {ID: 2, Count: 2}}},
+		{q: `ColumnDegree(Bitmap(rowID=0, frame=g), frame=f, offset=2, n=2)`, exp: []pilosa.Pair{{ID: 1, Count: 1}, {ID: SliceWidth + 1, Count: 1}}},
+		{q: `ColumnDegree(Bitmap(rowID=0, frame=g), frame=f, offset=10, n=2)`, exp: []pilosa.Pair{}},
+		{q: `ColumnDegree(Bitmap(rowID=2, frame=f), frame=f, n=10)`, exp: []pilosa.Pair{{ID: 3, Count: 3}, {ID: 2, Count: 2}, {ID: 5, Count: 1}}},
+	} {
+		if res, err := e.Execute(context.Background(), "i", MustParse(tt.q), nil, nil); err != nil {
+			t.Fatalf("%s: %s", tt.q, err)
+		} else if !reflect.DeepEqual(res, []interface{}{tt.exp}) {
+			t.Fatalf("%s: unexpected result: %s", tt.q, spew.Sdump(res))
+		}
+	}
+
+	// Bounding arguments are required.
+	if _, err := e.Execute(context.Background(), "i", MustParse(`ColumnDegree(Bitmap(rowID=0, frame=g), frame=f)`), nil, nil); err == nil || !strings.Contains(err.Error(), "ColumnDegree() n required") {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, err := e.Execute(context.Background(), "i", MustParse(`ColumnDegree(frame=f, n=10)`), nil, nil); err == nil || !strings.Contains(err.Error(), "ColumnDegree() requires a single filter bitmap") {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, err := e.Execute(context.Background(), "i", MustParse(`ColumnDegree(Bitmap(rowID=0, frame=g), frame=f, offset=1, n=9223372036854775807)`), nil, nil); err == nil || !strings.Contains(err.Error(), "ColumnDegree() offset + n exceeds 9223372036854775807") {
+		t.Fatalf("unexpected error: %v", err)
 	}
 }
 
-// Ensure a remote query can set bits on multiple nodes.
-func TestExecutor_Execute_Remote_SetBit(t *testing.T) {
+// Ensure a ColumnDegree query pages the merged results of remote nodes.
+func TestExecutor_Execute_Remote_ColumnDegree(t *testing.T) {
 	c := NewCluster(2)
-	c.ReplicaN = 2
 
 	// Create secondary server and update second cluster node.
 	s := NewServer()
 	defer s.Close()
 	c.Nodes[1].Host = s.Host()
 
-	// Mock secondary server's executor to verify arguments.
-	var remoteCalled bool
+	// The remote node returns its unpaged pairs.
 	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
-		if index != `i` {
-			t.Fatalf("unexpected index: %s", index)
-		} else if query.String() != `SetBit(columnID=2, frame="f", rowID=10)` {
+		if query.String() != `ColumnDegree(Bitmap(frame="g", rowID=0), frame="f", n=2, offset=1)` {
 			t.Fatalf("unexpected query: %s", query.String())
 		}
-		remoteCalled = true
-		return []interface{}{nil}, nil
+		return []interface{}{[]pilosa.Pair{{ID: SliceWidth + 1, Count: 5}, {ID: SliceWidth + 2, Count: 1}}}, nil
 	}
 
-	// Create local executor data.
+	// The local node owns slice 0.
 	hldr := MustOpenHolder()
 	defer hldr.Close()
-
-	// Create frame.
-	if _, err := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{}).CreateFrame("f", pilosa.FrameOptions{}); err != nil {
-		t.Fatal(err)
-	}
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(1, 1, 2)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(2, 2)
+	hldr.MustCreateFragmentIfNotExists("i", "g", pilosa.ViewStandard, 0).MustSetBits(0, 1, 2)
 
 	e := NewExecutor(hldr.Holder, c)
-	if _, err := e.Execute(context.Background(), "i", MustParse(`SetBit(rowID=10, frame=f, columnID=2)`), nil, nil); err != nil {
+	if res, err := e.Execute(context.Background(), "i", MustParse(`ColumnDegree(Bitmap(rowID=0, frame=g), frame=f, offset=1, n=2)`), []uint64{0, 1}, nil); err != nil {
 		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{[]pilosa.Pair{{ID: 2, Count: 2}, {ID: 1, Count: 1}}}) {
+		t.Fatalf("unexpected result: %s", spew.Sdump(res))
 	}
+}
 
-	// Verify that one bit is set on both node's holder.
-	if n := hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).Row(10).Count(); n != 1 {
-		t.Fatalf("unexpected local count: %d", n)
+// Ensure a TopColumns query counts the given rows of each column.
+func TestExecutor_Execute_TopColumns(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(1, 1, 2, 3)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(2, 2, 3, 5)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(3, 3)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(3, SliceWidth+1)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(4, SliceWidth+1, SliceWidth+2)
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	for _, tt := range []struct {
+		q   string
+		exp []pilosa.Pair
+	}{
+		{q: `TopColumns(frame=f, ids=[1,2,3], n=10)`, exp: []pilosa.Pair{{ID: 3, Count: 3}, {ID: 2, Count: 2}, {ID: 1, Count: 1}, {ID: 5, Count: 1}, {ID: SliceWidth + 1, Count: 1}}},
+		{q: `TopColumns(frame=f, ids=[1,2,3], n=3)`, exp: []pilosa.Pair{{ID: 3, Count: 3}, {ID: 2, Count: 2}, {ID: 1, Count: 1}}},
+		{q: `TopColumns(frame=f, ids=[3,4], n=2)`, exp: []pilosa.Pair{{ID: SliceWidth + 1, Count: 2}, {ID: 3, Count: 1}}},
+		{q: `TopColumns(frame=f, ids=[100], n=2)`, exp: []pilosa.Pair{}},
+	} {
+		if res, err := e.Execute(context.Background(), "i", MustParse(tt.q), nil, nil); err != nil {
+			t.Fatalf("%s: %s", tt.q, err)
+		} else if !reflect.DeepEqual(res, []interface{}{tt.exp}) {
+			t.Fatalf("%s: unexpected result: %s", tt.q, spew.Sdump(res))
+		}
 	}
-	if !remoteCalled {
-		t.Fatalf("expected remote execution")
+
+	for _, tt := range []struct {
+		q   string
+		err string
+	}{
+		{q: `TopColumns(frame=f, ids=[1,2])`, err: `TopColumns() n required`},
+		{q: `TopColumns(frame=f, n=2)`, err: `TopColumns() ids required`},
+		{q: `TopColumns(frame=f, ids=[1], n=-1)`, err: `TopColumns() n exceeds 9223372036854775807`},
+		{q: `TopColumns(Bitmap(rowID=1, frame=f), frame=f, ids=[1], n=2)`, err: `TopColumns() does not accept bitmap inputs`},
+		{q: `TopColumns(frame=x, ids=[1], n=2)`, err: pilosa.ErrFrameNotFound.Error()},
+	} {
+		if _, err := e.Execute(context.Background(), "i", MustParse(tt.q), nil, nil); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Fatalf("%s: unexpected error: %v", tt.q, err)
+		}
 	}
 }
 
-// Ensure a remote query can set bits on multiple nodes.
-func TestExecutor_Execute_Remote_SetBit_With_Timestamp(t *testing.T) {
+// Ensure a TopColumns query merges and trims the pairs of remote nodes.
+func TestExecutor_Execute_Remote_TopColumns(t *testing.T) {
 	c := NewCluster(2)
-	c.ReplicaN = 2
 
 	// Create secondary server and update second cluster node.
 	s := NewServer()
 	defer s.Close()
 	c.Nodes[1].Host = s.Host()
 
-	// Mock secondary server's executor to verify arguments.
-	var remoteCalled bool
+	// The remote node returns the pairs of slice 1.
 	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
-		if index != `i` {
-			t.Fatalf("unexpected index: %s", index)
-		} else if query.String() != `SetBit(columnID=2, frame="f", rowID=10, timestamp="2016-12-11T10:09")` {
+		if query.String() != `TopColumns(frame="f", ids=[1,2], n=2)` {
 			t.Fatalf("unexpected query: %s", query.String())
 		}
-		remoteCalled = true
-		return []interface{}{nil}, nil
+		return []interface{}{[]pilosa.Pair{{ID: SliceWidth + 1, Count: 2}, {ID: SliceWidth + 2, Count: 1}}}, nil
 	}
 
-	// Create local executor data.
+	// The local node owns slice 0.
 	hldr := MustOpenHolder()
 	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(1, 1, 2)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(2, 2)
 
-	// Create frame.
-	if f, err := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{}).CreateFrame("f", pilosa.FrameOptions{}); err != nil {
+	e := NewExecutor(hldr.Holder, c)
+	if res, err := e.Execute(context.Background(), "i", MustParse(`TopColumns(frame=f, ids=[1,2], n=2)`), []uint64{0, 1}, nil); err != nil {
 		t.Fatal(err)
-	} else if err := f.SetTimeQuantum("Y"); err != nil {
+	} else if !reflect.DeepEqual(res, []interface{}{[]pilosa.Pair{{ID: 2, Count: 2}, {ID: SliceWidth + 1, Count: 2}}}) {
+		t.Fatalf("unexpected result: %s", spew.Sdump(res))
+	}
+}
+
+// Ensure a MaxColumn() query returns the highest column set across slices.
+func TestExecutor_Execute_MaxColumn(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 3, SliceWidth-1)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 2).MustSetBits(20, (2*SliceWidth)+5)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 2).MustSetBits(1, (2*SliceWidth)+4)
+	hldr.MustCreateFragmentIfNotExists("i", "g", pilosa.ViewStandard, 0).MustSetBits(10, 7)
+	hldr.MustCreateFragmentIfNotExists("i", "g", pilosa.ViewStandard, 3).MustSetBits(1, (3*SliceWidth)+2)
+	hldr.MustCreateFragmentIfNotExists("i", "g", pilosa.ViewStandard, 3).MustClearBits(1, (3*SliceWidth)+2)
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	for _, tt := range []struct {
+		q   string
+		exp *pilosa.ColumnResult
+	}{
+		{q: `MaxColumn(frame=f)`, exp: &pilosa.ColumnResult{Column: (2 * SliceWidth) + 5}},
+		{q: `MaxColumn(frame=g)`, exp: &pilosa.ColumnResult{Column: 7}},
+		{q: `MaxColumn()`, exp: &pilosa.ColumnResult{Column: (2 * SliceWidth) + 5}},
+	} {
+		if res, err := e.Execute(context.Background(), "i", MustParse(tt.q), nil, nil); err != nil {
+			t.Fatalf("%s: %s", tt.q, err)
+		} else if !reflect.DeepEqual(res, []interface{}{tt.exp}) {
+			t.Fatalf("%s: unexpected result: %s", tt.q, spew.Sdump(res))
+		}
+	}
+
+	// Ensure columns set after a query are found.
+	if _, err := e.Execute(context.Background(), "i", MustParse(fmt.Sprintf(`SetBit(frame=g, rowID=2, columnID=%d)`, (3*SliceWidth)+1)), nil, nil); err != nil {
 		t.Fatal(err)
+	} else if res, err := e.Execute(context.Background(), "i", MustParse(`MaxColumn()`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{&pilosa.ColumnResult{Column: (3 * SliceWidth) + 1}}) {
+		t.Fatalf("unexpected result: %s", spew.Sdump(res))
 	}
 
-	e := NewExecutor(hldr.Holder, c)
-	if _, err := e.Execute(context.Background(), "i", MustParse(`SetBit(rowID=10, frame=f, columnID=2, timestamp="2016-12-11T10:09")`), nil, nil); err != nil {
+	// Ensure bitmap inputs are rejected.
+	if _, err := e.Execute(context.Background(), "i", MustParse(`MaxColumn(Bitmap(rowID=10, frame=f))`), nil, nil); err == nil || err.Error() != "MaxColumn() does not accept bitmap inputs" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure MaxColumn() and MinColumn() queries against an index without columns
+// are flagged empty.
+func TestExecutor_Execute_MaxColumn_Empty(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	hldr.MustCreateIndexIfNotExists("j", pilosa.IndexOptions{})
+	if _, err := index.CreateFrame("f", pilosa.FrameOptions{}); err != nil {
 		t.Fatal(err)
 	}
 
-	// Verify that one bit is set on both node's holder.
-	if n := hldr.MustCreateFragmentIfNotExists("i", "f", "standard_2016", 0).Row(10).Count(); n != 1 {
-		t.Fatalf("unexpected local count: %d", n)
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	for _, tt := range []struct {
+		index string
+		q     string
+	}{
+		{index: "i", q: `MaxColumn(frame=f)`},
+		{index: "i", q: `MaxColumn()`},
+		{index: "j", q: `MaxColumn()`},
+		{index: "i", q: `MinColumn(frame=f)`},
+		{index: "j", q: `MinColumn()`},
+	} {
+		if res, err := e.Execute(context.Background(), tt.index, MustParse(tt.q), nil, nil); err != nil {
+			t.Fatalf("%s: %s", tt.q, err)
+		} else if !reflect.DeepEqual(res, []interface{}{&pilosa.ColumnResult{Empty: true}}) {
+			t.Fatalf("%s: unexpected result: %s", tt.q, spew.Sdump(res))
+		}
 	}
-	if !remoteCalled {
-		t.Fatalf("expected remote execution")
+}
+
+// Ensure a MinColumn() query returns the lowest column set across slices.
+func TestExecutor_Execute_MinColumn(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 3)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustClearBits(10, 3)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 2).MustSetBits(20, (2*SliceWidth)+5)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 2).MustSetBits(1, (2*SliceWidth)+9)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 3).MustSetBits(0, 3*SliceWidth)
+	hldr.MustCreateFragmentIfNotExists("i", "g", pilosa.ViewStandard, 1).MustSetBits(10, SliceWidth+2, SliceWidth+7)
+	hldr.MustCreateFragmentIfNotExists("i", "h", pilosa.ViewStandard, 0).MustSetBits(4, 0)
+	hldr.MustCreateFragmentIfNotExists("i", "h", pilosa.ViewStandard, 1).MustSetBits(4, SliceWidth+1)
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	for _, tt := range []struct {
+		q   string
+		exp *pilosa.ColumnResult
+	}{
+		{q: `MinColumn(frame=f)`, exp: &pilosa.ColumnResult{Column: (2 * SliceWidth) + 5}},
+		{q: `MinColumn(frame=g)`, exp: &pilosa.ColumnResult{Column: SliceWidth + 2}},
+		{q: `MinColumn(frame=h)`, exp: &pilosa.ColumnResult{Column: 0}},
+		{q: `MinColumn()`, exp: &pilosa.ColumnResult{Column: 0}},
+		{q: `MinColumn(frames=["f", "g"])`, exp: &pilosa.ColumnResult{Column: SliceWidth + 2}},
+	} {
+		if res, err := e.Execute(context.Background(), "i", MustParse(tt.q), nil, nil); err != nil {
+			t.Fatalf("%s: %s", tt.q, err)
+		} else if !reflect.DeepEqual(res, []interface{}{tt.exp}) {
+			t.Fatalf("%s: unexpected result: %s", tt.q, spew.Sdump(res))
+		}
+	}
+
+	// Ensure an empty frame is not reported as column zero.
+	hldr.MustCreateFragmentIfNotExists("i", "h", pilosa.ViewStandard, 0).MustClearBits(4, 0)
+	hldr.MustCreateFragmentIfNotExists("i", "h", pilosa.ViewStandard, 1).MustClearBits(4, SliceWidth+1)
+	if res, err := e.Execute(context.Background(), "i", MustParse(`MinColumn(frame=h) MaxColumn(frame=h)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{&pilosa.ColumnResult{Empty: true}, &pilosa.ColumnResult{Empty: true}}) {
+		t.Fatalf("unexpected result: %s", spew.Sdump(res))
 	}
 }
 
-// Ensure a remote query can return a top-n query.
-func TestExecutor_Execute_Remote_TopN(t *testing.T) {
+// Ensure MaxColumn() & MinColumn() queries are reduced with remote results and
+// list the index's frames for remote nodes.
+func TestExecutor_Execute_Remote_MaxColumn(t *testing.T) {
 	c := NewCluster(2)
 
 	// Create secondary server and update second cluster node.
@@ -640,54 +1082,4820 @@ func TestExecutor_Execute_Remote_TopN(t *testing.T) {
 	defer s.Close()
 	c.Nodes[1].Host = s.Host()
 
-	// Mock secondary server's executor to verify arguments and return a bitmap.
-	var remoteExecN int
 	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
-		if index != "i" {
-			t.Fatalf("unexpected index: %s", index)
-		} else if !reflect.DeepEqual(slices, []uint64{1, 3}) {
-			t.Fatalf("unexpected slices: %+v", slices)
-		}
-
-		// Query should be executed twice. Once to get the top bitmaps for the
-		// slices and a second time to get the counts for a set of bitmaps.
-		switch remoteExecN {
-		case 0:
-			if query.String() != `TopN(frame="f", n=3)` {
-				t.Fatalf("unexpected query(0): %s", query.String())
-			}
-		case 1:
-			if query.String() != `TopN(frame="f", ids=[0,10,30], n=3)` {
-				t.Fatalf("unexpected query(1): %s", query.String())
-			}
+		switch query.String() {
+		case `MaxColumn(frames=["f","g"])`:
+			return []interface{}{&pilosa.ColumnResult{Column: SliceWidth + 9}}, nil
+		case `MaxColumn(frame="g")`:
+			return []interface{}{&pilosa.ColumnResult{Empty: true}}, nil
+		case `MinColumn(frame="f")`:
+			return []interface{}{&pilosa.ColumnResult{Column: SliceWidth + 1}}, nil
 		default:
-			t.Fatalf("too many remote exec calls")
+			t.Fatalf("unexpected query: %s", query.String())
+			return nil, nil
 		}
-		remoteExecN++
-
-		// Return pair counts.
-		return []interface{}{[]pilosa.Pair{
-			{ID: 0, Count: 5},
-			{ID: 10, Count: 2},
-			{ID: 30, Count: 2},
-		}}, nil
 	}
 
-	// Create local executor data on slice 2 & 4.
+	// The local node owns slice 0.
 	hldr := MustOpenHolder()
 	defer hldr.Close()
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 2).MustSetBits(30, (2*SliceWidth)+1)
-	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 4).MustSetBits(30, (4*SliceWidth)+2)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(1, 2)
+	hldr.MustCreateFragmentIfNotExists("i", "g", pilosa.ViewStandard, 0).MustSetBits(1, 5)
 
 	e := NewExecutor(hldr.Holder, c)
-	if res, err := e.Execute(context.Background(), "i", MustParse(`TopN(frame=f, n=3)`), nil, nil); err != nil {
+	if res, err := e.Execute(context.Background(), "i", MustParse(`MaxColumn() MaxColumn(frame=g) MinColumn(frame=f)`), []uint64{0, 1}, nil); err != nil {
 		t.Fatal(err)
-	} else if !reflect.DeepEqual(res, []interface{}{[]pilosa.Pair{
-		{ID: 0, Count: 5},
-		{ID: 30, Count: 4},
-		{ID: 10, Count: 2},
+	} else if !reflect.DeepEqual(res, []interface{}{&pilosa.ColumnResult{Column: SliceWidth + 9}, &pilosa.ColumnResult{Column: 5}, &pilosa.ColumnResult{Column: 2}}) {
+		t.Fatalf("unexpected result: %s", spew.Sdump(res))
+	}
+}
+
+// Ensure a count of an inverse bitmap query reads from inverse slices.
+func TestExecutor_Execute_Count_Inverse(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	if _, err := index.CreateFrame("f", pilosa.FrameOptions{InverseEnabled: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+
+	// Set bits so there are more inverse slices than standard slices.
+	if _, err := e.Execute(context.Background(), "i", MustParse(``+
+		fmt.Sprintf("SetBit(frame=f, rowID=%d, columnID=%d)\n", 10, 3)+
+		fmt.Sprintf("SetBit(frame=f, rowID=%d, columnID=%d)\n", (2*SliceWidth)+5, 3)+
+		fmt.Sprintf("SetBit(frame=f, rowID=%d, columnID=%d)\n", (2*SliceWidth)+5, 4),
+	), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Count(Bitmap(columnID=3, frame=f)) Count(Bitmap(rowID=10, frame=f))`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{uint64(2), uint64(1)}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res))
+	}
+}
+
+// Ensure read results are cached at the coordinator and invalidated by writes.
+func TestExecutor_Execute_QueryCache(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 3)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(10, SliceWidth+1)
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	e.QueryCache = pilosa.NewQueryCache(10)
+
+	// Execute the same query twice. The second should be served from the cache.
+	for i := 0; i < 2; i++ {
+		if res, err := e.Execute(context.Background(), "i", MustParse(`Count(Bitmap(rowID=10, frame=f))`), nil, nil); err != nil {
+			t.Fatal(err)
+		} else if res[0] != uint64(2) {
+			t.Fatalf("unexpected n: %d", res[0])
+		}
+	}
+	if stats := e.QueryCache.Stats(); stats != (pilosa.QueryCacheStats{Hits: 1, Misses: 1}) {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	// Setting a bit should invalidate the cached count.
+	if _, err := e.Execute(context.Background(), "i", MustParse(`SetBit(rowID=10, frame=f, columnID=4)`), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Count(Bitmap(rowID=10, frame=f))`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if res[0] != uint64(3) {
+		t.Fatalf("unexpected n after write: %d", res[0])
+	}
+	if stats := e.QueryCache.Stats(); stats != (pilosa.QueryCacheStats{Hits: 1, Misses: 2}) {
+		t.Fatalf("unexpected stats after write: %+v", stats)
+	}
+}
+
+// Ensure a read which overlaps an index write is not cached as current.
+func TestExecutor_Execute_QueryCache_ConcurrentWrite(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 3)
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	e.QueryCache = pilosa.NewQueryCache(10)
+
+	// Write column attributes while the first read is executing.
+	var written bool
+	if err := e.RegisterAggregation("WriteCount", pilosa.Aggregation{
+		Map: func(ctx context.Context, index string, c *pql.Call, slice uint64, inputs []*pilosa.Bitmap) (interface{}, error) {
+			if !written {
+				written = true
+				if _, err := e.Execute(ctx, "i", MustParse(`SetColumnAttrs(id=3, x=1)`), nil, nil); err != nil {
+					return nil, err
+				}
+			}
+			return inputs[0].Count(), nil
+		},
+		Reduce: func(prev, v interface{}) interface{} {
+			n, _ := prev.(uint64)
+			return n + v.(uint64)
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The overlapping read is not cached so the second read is also a miss.
+	for i := 0; i < 2; i++ {
+		if _, err := e.Execute(context.Background(), "i", MustParse(`WriteCount(Bitmap(rowID=10, frame=f))`), []uint64{0}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if stats := e.QueryCache.Stats(); stats != (pilosa.QueryCacheStats{Hits: 0, Misses: 2}) {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	// Reads without an overlapping write are cached.
+	if _, err := e.Execute(context.Background(), "i", MustParse(`WriteCount(Bitmap(rowID=10, frame=f))`), []uint64{0}, nil); err != nil {
+		t.Fatal(err)
+	} else if stats := e.QueryCache.Stats(); stats != (pilosa.QueryCacheStats{Hits: 1, Misses: 2}) {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+// Ensure a read which overlaps a write to its frame is not cached as current.
+func TestExecutor_Execute_QueryCache_ConcurrentFrameWrite(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "a", pilosa.ViewStandard, 0).MustSetBits(10, 3)
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	e.QueryCache = pilosa.NewQueryCache(10)
+
+	// Set a bit in frame "a" after the first read counts its input.
+	var written bool
+	if err := e.RegisterAggregation("WriteCount", pilosa.Aggregation{
+		Map: func(ctx context.Context, index string, c *pql.Call, slice uint64, inputs []*pilosa.Bitmap) (interface{}, error) {
+			n := inputs[0].Count()
+			if !written {
+				written = true
+				if _, err := e.Execute(ctx, "i", MustParse(`SetBit(rowID=10, frame=a, columnID=4)`), nil, nil); err != nil {
+					return nil, err
+				}
+			}
+			return n, nil
+		},
+		Reduce: func(prev, v interface{}) interface{} {
+			n, _ := prev.(uint64)
+			return n + v.(uint64)
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first read returns the count from before the write.
+	q := `WriteCount(Bitmap(rowID=10, frame=a))`
+	if res, err := e.Execute(context.Background(), "i", MustParse(q), []uint64{0}, nil); err != nil {
+		t.Fatal(err)
+	} else if res[0] != uint64(1) {
+		t.Fatalf("unexpected n: %d", res[0])
+	}
+
+	// The second read must not be served the pre-write result.
+	if res, err := e.Execute(context.Background(), "i", MustParse(q), []uint64{0}, nil); err != nil {
+		t.Fatal(err)
+	} else if res[0] != uint64(2) {
+		t.Fatalf("unexpected n after write: %d", res[0])
+	} else if stats := e.QueryCache.Stats(); stats != (pilosa.QueryCacheStats{Hits: 0, Misses: 2}) {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+// Ensure writes only invalidate cached results which read from the written frame.
+func TestExecutor_Execute_QueryCache_FrameInvalidation(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "a", pilosa.ViewStandard, 0).MustSetBits(10, 3)
+	hldr.MustCreateFragmentIfNotExists("i", "b", pilosa.ViewStandard, 0).MustSetBits(10, 3)
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	e.QueryCache = pilosa.NewQueryCache(10)
+
+	// Populate cache with a query for each frame.
+	for _, q := range []string{`Count(Bitmap(rowID=10, frame=a))`, `Count(Bitmap(rowID=10, frame=b))`} {
+		if _, err := e.Execute(context.Background(), "i", MustParse(q), nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Write to frame "a".
+	if _, err := e.Execute(context.Background(), "i", MustParse(`SetBit(rowID=10, frame=a, columnID=4)`), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Query over frame "b" should still be cached.
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Count(Bitmap(rowID=10, frame=b))`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if res[0] != uint64(1) {
+		t.Fatalf("unexpected n: %d", res[0])
+	} else if stats := e.QueryCache.Stats(); stats != (pilosa.QueryCacheStats{Hits: 1, Misses: 2}) {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	// Query over frame "a" should be invalidated.
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Count(Bitmap(rowID=10, frame=a))`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if res[0] != uint64(2) {
+		t.Fatalf("unexpected n: %d", res[0])
+	} else if stats := e.QueryCache.Stats(); stats != (pilosa.QueryCacheStats{Hits: 1, Misses: 3}) {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	// A query referencing both frames is invalidated by a write to either.
+	q := `Union(Bitmap(rowID=10, frame=a), Bitmap(rowID=10, frame=b))`
+	if _, err := e.Execute(context.Background(), "i", MustParse(q), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if _, err := e.Execute(context.Background(), "i", MustParse(`SetBit(rowID=10, frame=b, columnID=5)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if res, err := e.Execute(context.Background(), "i", MustParse(q), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, []uint64{3, 4, 5}) {
+		t.Fatalf("unexpected bits: %+v", bits)
+	}
+}
+
+// Ensure a set query can be executed.
+func TestExecutor_Execute_SetBit(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	f := hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0)
+	if n := f.Row(11).Count(); n != 0 {
+		t.Fatalf("unexpected bitmap count: %d", n)
+	}
+
+	if res, err := e.Execute(context.Background(), "i", MustParse(`SetBit(rowID=11, frame=f, columnID=1)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else {
+		if !res[0].(bool) {
+			t.Fatalf("expected bit changed")
+		}
+	}
+
+	if n := f.Row(11).Count(); n != 1 {
+		t.Fatalf("unexpected bitmap count: %d", n)
+	}
+	if res, err := e.Execute(context.Background(), "i", MustParse(`SetBit(rowID=11, frame=f, columnID=1)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else {
+		if res[0].(bool) {
+			t.Fatalf("expected bit unchanged")
+		}
+	}
+}
+
+// Ensure a ClearBit() query with a timestamp clears the matching time views.
+func TestExecutor_Execute_ClearBit_Timestamp(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	f, err := index.CreateFrameIfNotExists("f", pilosa.FrameOptions{})
+	if err != nil {
+		t.Fatal(err)
+	} else if err := f.SetTimeQuantum(pilosa.TimeQuantum("YMD")); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	if _, err := e.Execute(context.Background(), "i", MustParse(``+
+		`SetBit(rowID=1, frame=f, columnID=2, timestamp="2000-01-02T00:00") `+
+		`SetBit(rowID=1, frame=f, columnID=2, timestamp="2001-03-04T00:00")`,
+	), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if res, err := e.Execute(context.Background(), "i", MustParse(`ClearBit(rowID=1, frame=f, columnID=2, timestamp="2000-01-02T00:00")`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{true}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res))
+	}
+
+	// Verify the bit is cleared from the time views of the timestamp only.
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Range(rowID=1, frame=f, start="2000-01-01T00:00", end="2000-12-31T00:00")`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if bits := res[0].(*pilosa.Bitmap).Bits(); len(bits) != 0 {
+		t.Fatalf("unexpected bits: %+v", bits)
+	}
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Range(rowID=1, frame=f, start="2001-03-04T00:00", end="2001-03-05T00:00")`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, []uint64{2}) {
+		t.Fatalf("unexpected bits: %+v", bits)
+	}
+}
+
+// Ensure a ClearBits() query clears the same bits as individual ClearBit() queries.
+func TestExecutor_Execute_ClearBits(t *testing.T) {
+	bits := [][2]uint64{{10, 1}, {10, 2}, {20, SliceWidth + 1}, {(2 * SliceWidth) + 3, 4}}
+
+	// Open two holders with the same bits set.
+	var executors []*Executor
+	for i := 0; i < 2; i++ {
+		hldr := MustOpenHolder()
+		defer hldr.Close()
+		index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+		if _, err := index.CreateFrame("f", pilosa.FrameOptions{InverseEnabled: true}); err != nil {
+			t.Fatal(err)
+		}
+
+		e := NewExecutor(hldr.Holder, NewCluster(1))
+		for _, bit := range bits {
+			if _, err := e.Execute(context.Background(), "i", MustParse(fmt.Sprintf(`SetBit(frame=f, rowID=%d, columnID=%d)`, bit[0], bit[1])), nil, nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+		executors = append(executors, e)
+	}
+
+	// Clear bits individually on the first holder.
+	q := ``
+	for _, bit := range [][2]uint64{{10, 2}, {20, SliceWidth + 1}, {30, 5}, {(2 * SliceWidth) + 3, 4}} {
+		q += fmt.Sprintf("ClearBit(frame=f, rowID=%d, columnID=%d)\n", bit[0], bit[1])
+	}
+	if _, err := executors[0].Execute(context.Background(), "i", MustParse(q), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Clear bits in bulk on the second holder. One bit was not set.
+	if res, err := executors[1].Execute(context.Background(), "i", MustParse(fmt.Sprintf(`ClearBits(frame=f, rowIDs=[10,20,30,%d], columnIDs=[2,%d,5,4])`, (2*SliceWidth)+3, SliceWidth+1)), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{uint64(3)}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res))
+	}
+
+	// Verify both views match.
+	for _, call := range []string{`Bitmap(frame=f, rowID=10)`, `Bitmap(frame=f, rowID=20)`, `Bitmap(frame=f, columnID=1)`, `Bitmap(frame=f, columnID=4)`} {
+		var results [][]uint64
+		for _, e := range executors {
+			res, err := e.Execute(context.Background(), "i", MustParse(call), nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			results = append(results, res[0].(*pilosa.Bitmap).Bits())
+		}
+		if !reflect.DeepEqual(results[0], results[1]) {
+			t.Fatalf("%s: mismatch: %v != %v", call, results[0], results[1])
+		}
+	}
+	if res, err := executors[1].Execute(context.Background(), "i", MustParse(`Count(Bitmap(frame=f, rowID=10)) Count(Bitmap(frame=f, columnID=4))`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{uint64(1), uint64(0)}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res))
+	}
+}
+
+// Ensure a SetRowFromRoaring() query sets the row to the columns of the bitmap.
+func TestExecutor_Execute_SetRowFromRoaring(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	if _, err := index.CreateFrame("f", pilosa.FrameOptions{InverseEnabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 5)
+
+	src := roaring.NewBitmap(1, 2, 70000, SliceWidth+1, SliceWidth+200000, (3*SliceWidth)+5)
+	var buf bytes.Buffer
+	if _, err := src.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	if _, err := e.Execute(context.Background(), "i", MustParse(fmt.Sprintf(`SetRowFromRoaring(frame=f, rowID=10, data=%q)`, base64.StdEncoding.EncodeToString(buf.Bytes()))), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Existing columns are kept and the inverse view is updated.
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Bitmap(frame=f, rowID=10) Bitmap(frame=f, columnID=70000)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if bits, exp := res[0].(*pilosa.Bitmap).Bits(), roaring.NewBitmap(append(src.Slice(), 5)...).Slice(); !reflect.DeepEqual(bits, exp) {
+		t.Fatalf("unexpected bits: %+v != %+v", bits, exp)
+	} else if bits := res[1].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, []uint64{10}) {
+		t.Fatalf("unexpected inverse bits: %+v", bits)
+	}
+
+	// Invalid data is rejected.
+	for _, tt := range []struct {
+		data string
+		err  string
+	}{
+		{data: "!", err: "decoding SetRowFromRoaring() data: illegal base64 data at input byte 0"},
+		{data: base64.StdEncoding.EncodeToString(buf.Bytes()[:20]), err: "invalid SetRowFromRoaring() roaring data: key headers out of bounds: n=5, len=20"},
+	} {
+		if _, err := e.Execute(context.Background(), "i", MustParse(fmt.Sprintf(`SetRowFromRoaring(frame=f, rowID=11, data=%q)`, tt.data)), nil, nil); err == nil || err.Error() != tt.err {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+// Ensure a SetRowFromRoaring() query forwards the columns of remote slices.
+func TestExecutor_Execute_Remote_SetRowFromRoaring(t *testing.T) {
+	c := NewCluster(2)
+
+	// Create secondary server and update second cluster node.
+	s := NewServer()
+	defer s.Close()
+	c.Nodes[1].Host = s.Host()
+
+	var remoteExecN int
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		call := query.Calls[0]
+		if !opt.Remote {
+			t.Fatal("expected remote execution")
+		} else if call.Name != "SetRowFromRoaring" || call.Args["frame"] != "f" || call.Args["rowID"] != int64(10) || call.Args["view"] != pilosa.ViewStandard {
+			t.Fatalf("unexpected query: %s", query.String())
+		}
+
+		data, err := base64.StdEncoding.DecodeString(call.Args["data"].(string))
+		if err != nil {
+			t.Fatal(err)
+		}
+		bm := roaring.NewBitmap()
+		if err := bm.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		} else if bits := bm.Slice(); !reflect.DeepEqual(bits, []uint64{SliceWidth + 1, (3 * SliceWidth) + 5}) {
+			t.Fatalf("unexpected remote bits: %+v", bits)
+		}
+		remoteExecN++
+		return []interface{}{nil}, nil
+	}
+
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFrameIfNotExists("i", "f")
+
+	var buf bytes.Buffer
+	if _, err := roaring.NewBitmap(1, 2, SliceWidth+1, (3*SliceWidth)+5).WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewExecutor(hldr.Holder, c)
+	if _, err := e.Execute(context.Background(), "i", MustParse(fmt.Sprintf(`SetRowFromRoaring(frame=f, rowID=10, data=%q)`, base64.StdEncoding.EncodeToString(buf.Bytes()))), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if remoteExecN != 1 {
+		t.Fatalf("unexpected remote exec count: %d", remoteExecN)
+	} else if bits := hldr.Fragment("i", "f", pilosa.ViewStandard, 0).Row(10).Bits(); !reflect.DeepEqual(bits, []uint64{1, 2}) {
+		t.Fatalf("unexpected local bits: %+v", bits)
+	}
+}
+
+// Ensure a ClearMatching() query only clears the row's columns in the filter.
+func TestExecutor_Execute_ClearMatching(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	if _, err := index.CreateFrame("f", pilosa.FrameOptions{InverseEnabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	tf, err := index.CreateFrame("t", pilosa.FrameOptions{})
+	if err != nil {
+		t.Fatal(err)
+	} else if err := tf.SetTimeQuantum(pilosa.TimeQuantum("YMD")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Set bits through the executor so inverse views are updated.
+	// Columns 1, SliceWidth+1 & SliceWidth+2 were created before 2000.
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	q := "SetBit(frame=f, rowID=20, columnID=1)\n"
+	for _, col := range []uint64{1, 2, 3, SliceWidth + 1, SliceWidth + 2} {
+		q += fmt.Sprintf("SetBit(frame=f, rowID=10, columnID=%d)\n", col)
+	}
+	if _, err := e.Execute(context.Background(), "i", MustParse(q), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	tf.MustSetBit(pilosa.ViewStandard, 1, 1, MustParseTimePtr("1999-01-01 00:00"))
+	tf.MustSetBit(pilosa.ViewStandard, 1, 2, MustParseTimePtr("2001-01-01 00:00"))
+	tf.MustSetBit(pilosa.ViewStandard, 1, 50, MustParseTimePtr("1999-06-01 00:00"))
+	tf.MustSetBit(pilosa.ViewStandard, 1, SliceWidth+1, MustParseTimePtr("1998-01-01 00:00"))
+	tf.MustSetBit(pilosa.ViewStandard, 1, SliceWidth+2, MustParseTimePtr("1999-12-31 00:00"))
+
+	if res, err := e.Execute(context.Background(), "i", MustParse(`ClearMatching(Range(rowID=1, frame=t, start="1990-01-01T00:00", end="2000-01-01T00:00"), frame=f, rowID=10)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{uint64(3)}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res))
+	}
+
+	// Other rows and columns outside the filter are unaffected.
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Bitmap(rowID=10, frame=f) Bitmap(rowID=20, frame=f) Bitmap(columnID=1, frame=f)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, []uint64{2, 3}) {
+		t.Fatalf("unexpected row bits: %+v", bits)
+	} else if bits := res[1].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, []uint64{1}) {
+		t.Fatalf("unexpected other row bits: %+v", bits)
+	} else if bits := res[2].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, []uint64{20}) {
+		t.Fatalf("unexpected inverse bits: %+v", bits)
+	}
+
+	// The filter is required.
+	if _, err := e.Execute(context.Background(), "i", MustParse(`ClearMatching(frame=f, rowID=10)`), nil, nil); err == nil || err.Error() != "ClearMatching() requires a single filter bitmap" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure a ClearBits() query forwards a single batch per remote node.
+func TestExecutor_Execute_Remote_ClearBits(t *testing.T) {
+	c := NewCluster(2)
+
+	// Create secondary server and update second cluster node.
+	s := NewServer()
+	defer s.Close()
+	c.Nodes[1].Host = s.Host()
+
+	var remoteExecN int
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		if !opt.Remote {
+			t.Fatal("expected remote execution")
+		} else if exp := fmt.Sprintf(`ClearBits(columnIDs=[%d,%d], frame="f", rowIDs=[10,20], view="standard")`, SliceWidth+1, (3*SliceWidth)+1); query.String() != exp {
+			t.Fatalf("unexpected query: %s", query.String())
+		}
+		remoteExecN++
+		return []interface{}{uint64(2)}, nil
+	}
+
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 1)
+
+	e := NewExecutor(hldr.Holder, c)
+	if res, err := e.Execute(context.Background(), "i", MustParse(fmt.Sprintf(`ClearBits(frame=f, rowIDs=[10,10,20], columnIDs=[1,%d,%d])`, SliceWidth+1, (3*SliceWidth)+1)), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{uint64(3)}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res))
+	} else if remoteExecN != 1 {
+		t.Fatalf("unexpected remote exec count: %d", remoteExecN)
+	}
+}
+
+// Ensure a SetBits() query sets bits on remote nodes in a single batch.
+func TestExecutor_Execute_Remote_SetBits(t *testing.T) {
+	c := NewCluster(2)
+
+	// Create secondary server and update second cluster node.
+	s := NewServer()
+	defer s.Close()
+	c.Nodes[1].Host = s.Host()
+
+	var remoteExecN int
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		if exp := fmt.Sprintf(`SetBits(columnIDs=[%d,%d], frame="f", rowIDs=[10,20], view="standard")`, SliceWidth+1, (3*SliceWidth)+1); query.String() != exp {
+			t.Fatalf("unexpected query: %s", query.String())
+		}
+		remoteExecN++
+		return []interface{}{uint64(2)}, nil
+	}
+
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 1)
+
+	// One local bit is already set.
+	e := NewExecutor(hldr.Holder, c)
+	if res, err := e.Execute(context.Background(), "i", MustParse(fmt.Sprintf(`SetBits(frame=f, rowIDs=[10,10,20,10], columnIDs=[1,%d,%d,2])`, SliceWidth+1, (3*SliceWidth)+1)), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{uint64(3)}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res))
+	} else if remoteExecN != 1 {
+		t.Fatalf("unexpected remote exec count: %d", remoteExecN)
+	} else if bits := hldr.Fragment("i", "f", pilosa.ViewStandard, 0).Row(10).Bits(); !reflect.DeepEqual(bits, []uint64{1, 2}) {
+		t.Fatalf("unexpected bits: %v", bits)
+	}
+}
+
+// Ensure a stream of bits is ingested in batches and matches the input.
+func TestExecutor_IngestBits(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	if _, err := index.CreateFrame("f", pilosa.FrameOptions{InverseEnabled: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Generate random bits across several slices, including duplicates.
+	rnd := rand.New(rand.NewSource(0))
+	exp := make(map[uint64]map[uint64]struct{})
+	var buf []byte
+	for i := 0; i < 50000; i++ {
+		rowID, columnID := uint64(rnd.Intn(20)), uint64(rnd.Int63n(4*SliceWidth))
+		if exp[rowID] == nil {
+			exp[rowID] = make(map[uint64]struct{})
+		}
+		exp[rowID][columnID] = struct{}{}
+		buf = pilosa.AppendIngestBit(buf, rowID, columnID)
+	}
+	var setN int
+	for _, columns := range exp {
+		setN += len(columns)
+	}
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	e.IngestBatchN = 1000
+	if n, err := e.IngestBits(context.Background(), "i", "f", bytes.NewReader(buf)); err != nil {
+		t.Fatal(err)
+	} else if n != uint64(setN) {
+		t.Fatalf("unexpected changed count: %d, expected %d", n, setN)
+	}
+
+	// Verify every row against the authoritative set.
+	for rowID, columns := range exp {
+		res, err := e.Execute(context.Background(), "i", MustParse(fmt.Sprintf(`Bitmap(frame=f, rowID=%d)`, rowID)), nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bits := res[0].(*pilosa.Bitmap).Bits()
+		if len(bits) != len(columns) {
+			t.Fatalf("row %d: unexpected bit count: %d, expected %d", rowID, len(bits), len(columns))
+		}
+		for _, columnID := range bits {
+			if _, ok := columns[columnID]; !ok {
+				t.Fatalf("row %d: unexpected column: %d", rowID, columnID)
+			}
+		}
+	}
+
+	// The inverse view is written too.
+	for columnID := range exp[0] {
+		res, err := e.Execute(context.Background(), "i", MustParse(fmt.Sprintf(`Bitmap(frame=f, columnID=%d)`, columnID)), nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		} else if bits := res[0].(*pilosa.Bitmap).Bits(); len(bits) == 0 || bits[0] != 0 {
+			t.Fatalf("column %d: unexpected rows: %v", columnID, bits)
+		}
+		break
+	}
+
+	// Reingesting changes nothing.
+	if n, err := e.IngestBits(context.Background(), "i", "f", bytes.NewReader(buf)); err != nil {
+		t.Fatal(err)
+	} else if n != 0 {
+		t.Fatalf("unexpected changed count: %d", n)
+	}
+
+	// A pair cut short is reported.
+	if _, err := e.IngestBits(context.Background(), "i", "f", bytes.NewReader(pilosa.AppendIngestBit(nil, 1, 1000)[:1])); err == nil || err.Error() != "reading ingest column: "+io.ErrUnexpectedEOF.Error() {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure a SetBit() query can report changes for each view.
+func TestExecutor_Execute_SetBit_ChangedByView(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	if _, err := index.CreateFrame("f", pilosa.FrameOptions{InverseEnabled: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	opt := &pilosa.ExecOptions{ChangedByView: true}
+
+	// Set the bit in the standard view only.
+	if res, err := e.Execute(context.Background(), "i", MustParse(`SetBit(rowID=11, frame=f, columnID=1, view="standard")`), nil, opt); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{pilosa.SetBitResult{Standard: true}}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res))
+	}
+
+	// Setting across all views should only change the inverse view.
+	if res, err := e.Execute(context.Background(), "i", MustParse(`SetBit(rowID=11, frame=f, columnID=1)`), nil, opt); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{pilosa.SetBitResult{Inverse: true}}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res))
+	}
+
+	// Default options should return a single bool.
+	if res, err := e.Execute(context.Background(), "i", MustParse(`SetBit(rowID=11, frame=f, columnID=1)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{false}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res))
+	}
+}
+
+// Ensure writes to a slice without owners fail unless explicitly allowed.
+func TestExecutor_Execute_SetBit_Unowned(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFrameIfNotExists("i", "f")
+
+	// Remove every node so that no slice has an owner.
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	e.Cluster.Nodes = nil
+	e.Cluster.Hasher = &ConstHasher{}
+
+	for _, q := range []string{
+		`SetBit(rowID=11, frame=f, columnID=1)`,
+		`ClearBit(rowID=11, frame=f, columnID=1)`,
+	} {
+		if _, err := e.Execute(context.Background(), "i", MustParse(q), nil, nil); err != pilosa.ErrSliceUnowned {
+			t.Fatalf("%s: unexpected error: %v", q, err)
+		}
+	}
+
+	// Dropped writes are reported as unchanged if allowed.
+	e.AllowUnownedWrites = true
+	if res, err := e.Execute(context.Background(), "i", MustParse(`SetBit(rowID=11, frame=f, columnID=1)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{false}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res))
+	}
+}
+
+// Ensure batched writes to a slice without owners fail unless explicitly allowed.
+func TestExecutor_Execute_SetBits_Unowned(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFrameIfNotExists("i", "f")
+
+	var buf bytes.Buffer
+	if _, err := roaring.NewBitmap(1, SliceWidth+1).WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// Remove every node so that no slice has an owner.
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	e.Cluster.Nodes = nil
+	e.Cluster.Hasher = &ConstHasher{}
+
+	for _, q := range []string{
+		`SetBits(frame=f, rowIDs=[11,11], columnIDs=[1,2])`,
+		`ClearBits(frame=f, rowIDs=[11,11], columnIDs=[1,2])`,
+		fmt.Sprintf(`SetRowFromRoaring(frame=f, rowID=10, data=%q)`, base64.StdEncoding.EncodeToString(buf.Bytes())),
+	} {
+		if _, err := e.Execute(context.Background(), "i", MustParse(q), nil, nil); err != pilosa.ErrSliceUnowned {
+			t.Fatalf("%s: unexpected error: %v", q, err)
+		}
+	}
+
+	// Dropped writes are reported as unchanged if allowed.
+	e.AllowUnownedWrites = true
+	if res, err := e.Execute(context.Background(), "i", MustParse(`SetBits(frame=f, rowIDs=[11,11], columnIDs=[1,2])`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{uint64(0)}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res))
+	}
+}
+
+// Ensure SetBit() & ClearBit() only write the standard view if SkipInverse is set.
+func TestExecutor_Execute_SetBit_SkipInverse(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	if _, err := index.CreateFrame("f", pilosa.FrameOptions{InverseEnabled: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	opt := &pilosa.ExecOptions{SkipInverse: true}
+
+	// Only the standard view is written.
+	if res, err := e.Execute(context.Background(), "i", MustParse(`SetBit(rowID=11, frame=f, columnID=1)`), nil, &pilosa.ExecOptions{SkipInverse: true, ChangedByView: true}); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{pilosa.SetBitResult{Standard: true}}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res))
+	}
+	if bits := hldr.Fragment("i", "f", pilosa.ViewStandard, 0).Row(11).Bits(); !reflect.DeepEqual(bits, []uint64{1}) {
+		t.Fatalf("unexpected standard bits: %+v", bits)
+	} else if frag := hldr.Fragment("i", "f", pilosa.ViewInverse, 0); frag != nil && frag.Row(1).Count() != 0 {
+		t.Fatalf("unexpected inverse bits: %+v", frag.Row(1).Bits())
+	}
+
+	// Both views are written by default.
+	if _, err := e.Execute(context.Background(), "i", MustParse(`SetBit(rowID=12, frame=f, columnID=1)`), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if bits := hldr.Fragment("i", "f", pilosa.ViewInverse, 0).Row(1).Bits(); !reflect.DeepEqual(bits, []uint64{12}) {
+		t.Fatalf("unexpected inverse bits: %+v", bits)
+	}
+
+	// Clearing only touches the standard view.
+	if res, err := e.Execute(context.Background(), "i", MustParse(`ClearBit(rowID=12, frame=f, columnID=1)`), nil, opt); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{true}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res))
+	}
+	if bits := hldr.Fragment("i", "f", pilosa.ViewStandard, 0).Row(12).Bits(); len(bits) != 0 {
+		t.Fatalf("unexpected standard bits: %+v", bits)
+	} else if bits := hldr.Fragment("i", "f", pilosa.ViewInverse, 0).Row(1).Bits(); !reflect.DeepEqual(bits, []uint64{12}) {
+		t.Fatalf("unexpected inverse bits: %+v", bits)
+	}
+
+	// Calls targeting the inverse view are still applied.
+	if _, err := e.Execute(context.Background(), "i", MustParse(`SetBit(rowID=13, frame=f, columnID=1, view="inverse")`), nil, opt); err != nil {
+		t.Fatal(err)
+	}
+	if bits := hldr.Fragment("i", "f", pilosa.ViewInverse, 0).Row(1).Bits(); !reflect.DeepEqual(bits, []uint64{12, 13}) {
+		t.Fatalf("unexpected inverse bits: %+v", bits)
+	}
+}
+
+// Ensure a duplicate forwarded SetBit() returns the original result without reapplying.
+func TestExecutor_Execute_SetBit_Duplicate(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0)
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	opt := &pilosa.ExecOptions{Remote: true, OperationID: "op1"}
+	if res, err := e.Execute(context.Background(), "i", MustParse(`SetBit(rowID=10, frame=f, columnID=1)`), nil, opt); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{true}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res))
+	}
+
+	// Clear the bit with a separate operation.
+	if res, err := e.Execute(context.Background(), "i", MustParse(`ClearBit(rowID=10, frame=f, columnID=1)`), nil, &pilosa.ExecOptions{Remote: true}); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{true}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res))
+	}
+
+	// Resending the original operation returns its result and is not reapplied.
+	if res, err := e.Execute(context.Background(), "i", MustParse(`SetBit(rowID=10, frame=f, columnID=1)`), nil, opt); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{true}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res))
+	}
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Bitmap(rowID=10, frame=f)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if bits := res[0].(*pilosa.Bitmap).Bits(); len(bits) != 0 {
+		t.Fatalf("unexpected bits: %+v", bits)
+	}
+}
+
+// Ensure forwarded SetBit() calls are sent with an operation id.
+func TestExecutor_Execute_Remote_SetBit_OperationID(t *testing.T) {
+	c := NewCluster(2)
+
+	var ids []string
+	s := NewServer()
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		ids = append(ids, opt.OperationID)
+		return []interface{}{true}, nil
+	}
+	defer s.Close()
+	c.Nodes[1].Host = s.Host()
+
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0)
+
+	// Slice 1 is owned by the remote node.
+	e := NewExecutor(hldr.Holder, c)
+	q := fmt.Sprintf(`SetBit(rowID=10, frame=f, columnID=%d)`, SliceWidth+1)
+	for i := 0; i < 2; i++ {
+		if _, err := e.Execute(context.Background(), "i", MustParse(q), nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(ids) != 2 || ids[0] == "" || ids[1] == "" || ids[0] == ids[1] {
+		t.Fatalf("unexpected operation ids: %v", ids)
+	}
+}
+
+// Ensure each forwarded SetBits() batch is sent with its own operation id.
+func TestExecutor_Execute_Remote_SetBits_OperationID(t *testing.T) {
+	c := NewCluster(2)
+
+	var mu sync.Mutex
+	var ids []string
+	s := NewServer()
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		ids = append(ids, opt.OperationID)
+		return []interface{}{uint64(1)}, nil
+	}
+	defer s.Close()
+	c.Nodes[1].Host = s.Host()
+
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	if _, err := index.CreateFrame("f", pilosa.FrameOptions{InverseEnabled: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Slice 1 of both views is owned by the remote node so it receives a
+	// batch for each view.
+	e := NewExecutor(hldr.Holder, c)
+	q := fmt.Sprintf(`SetBits(frame=f, rowIDs=[%d], columnIDs=[%d])`, SliceWidth+1, SliceWidth+1)
+	if _, err := e.Execute(context.Background(), "i", MustParse(q), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids[0] == "" || ids[1] == "" || ids[0] == ids[1] {
+		t.Fatalf("unexpected operation ids: %v", ids)
+	}
+}
+
+// Ensure SetBits() & ClearBits() only write the standard view if SkipInverse is set.
+func TestExecutor_Execute_SetBits_SkipInverse(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	if _, err := index.CreateFrame("f", pilosa.FrameOptions{InverseEnabled: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	opt := &pilosa.ExecOptions{SkipInverse: true}
+
+	// Only the standard view is written.
+	if _, err := e.Execute(context.Background(), "i", MustParse(`SetBits(frame=f, rowIDs=[11,11], columnIDs=[1,2])`), nil, opt); err != nil {
+		t.Fatal(err)
+	}
+	if bits := hldr.Fragment("i", "f", pilosa.ViewStandard, 0).Row(11).Bits(); !reflect.DeepEqual(bits, []uint64{1, 2}) {
+		t.Fatalf("unexpected standard bits: %+v", bits)
+	} else if frag := hldr.Fragment("i", "f", pilosa.ViewInverse, 0); frag != nil && frag.Row(1).Count() != 0 {
+		t.Fatalf("unexpected inverse bits: %+v", frag.Row(1).Bits())
+	}
+
+	// Both views are written by default.
+	if _, err := e.Execute(context.Background(), "i", MustParse(`SetBits(frame=f, rowIDs=[12,12], columnIDs=[1,2])`), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if bits := hldr.Fragment("i", "f", pilosa.ViewInverse, 0).Row(1).Bits(); !reflect.DeepEqual(bits, []uint64{12}) {
+		t.Fatalf("unexpected inverse bits: %+v", bits)
+	}
+
+	// Clearing only touches the standard view.
+	if _, err := e.Execute(context.Background(), "i", MustParse(`ClearBits(frame=f, rowIDs=[12,12], columnIDs=[1,2])`), nil, opt); err != nil {
+		t.Fatal(err)
+	}
+	if bits := hldr.Fragment("i", "f", pilosa.ViewStandard, 0).Row(12).Bits(); len(bits) != 0 {
+		t.Fatalf("unexpected standard bits: %+v", bits)
+	} else if bits := hldr.Fragment("i", "f", pilosa.ViewInverse, 0).Row(1).Bits(); !reflect.DeepEqual(bits, []uint64{12}) {
+		t.Fatalf("unexpected inverse bits: %+v", bits)
+	}
+}
+
+// Ensure SkipInverse is forwarded to remote nodes.
+func TestExecutor_Execute_Remote_SetBit_SkipInverse(t *testing.T) {
+	c := NewCluster(2)
+
+	var skipped []bool
+	s := NewServer()
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		skipped = append(skipped, opt.SkipInverse)
+		return []interface{}{true}, nil
+	}
+	defer s.Close()
+	c.Nodes[1].Host = s.Host()
+
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	if _, err := index.CreateFrame("f", pilosa.FrameOptions{InverseEnabled: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Slice 1 is owned by the remote node.
+	e := NewExecutor(hldr.Holder, c)
+	for _, format := range []string{pilosa.RemoteFormatProtobuf, pilosa.RemoteFormatJSON} {
+		skipped = nil
+		e.RemoteFormat = format
+		if _, err := e.Execute(context.Background(), "i", MustParse(fmt.Sprintf(`SetBit(rowID=10, frame=f, columnID=%d)`, SliceWidth+1)), nil, &pilosa.ExecOptions{SkipInverse: true}); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(skipped, []bool{true}) {
+			t.Fatalf("%s: unexpected forwarded options: %v", format, skipped)
+		}
+	}
+}
+
+// Ensure a SetRowAttrs() query can be executed.
+func TestExecutor_Execute_SetRowAttrs(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	// Create frames.
+	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	if _, err := index.CreateFrameIfNotExists("f", pilosa.FrameOptions{}); err != nil {
+		t.Fatal(err)
+	} else if _, err := index.CreateFrameIfNotExists("xxx", pilosa.FrameOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Set two fields on f/10.
+	// Also set fields on other bitmaps and frames to test isolation.
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	if _, err := e.Execute(context.Background(), "i", MustParse(`SetRowAttrs(rowID=10, frame=f, foo="bar")`), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Execute(context.Background(), "i", MustParse(`SetRowAttrs(rowID=200, frame=f, YYY=1)`), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Execute(context.Background(), "i", MustParse(`SetRowAttrs(rowID=10, frame=xxx, YYY=1)`), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Execute(context.Background(), "i", MustParse(`SetRowAttrs(rowID=10, frame=f, baz=123, bat=true)`), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	f := hldr.Frame("i", "f")
+	if m, err := f.RowAttrStore().Attrs(10); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(m, map[string]interface{}{"foo": "bar", "baz": int64(123), "bat": true}) {
+		t.Fatalf("unexpected bitmap attr: %#v", m)
+	}
+}
+
+// Ensure a SetColumnAttrs() query resolves the column id consistently.
+func TestExecutor_Execute_SetColumnAttrs(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	idx := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	for _, tt := range []struct {
+		q     string
+		id    uint64
+		attrs map[string]interface{}
+		err   string
+	}{
+		// Legacy id.
+		{q: `SetColumnAttrs(id=1, x=1)`, id: 1, attrs: map[string]interface{}{"x": int64(1)}},
+		// Column label.
+		{q: `SetColumnAttrs(columnID=2, x=2)`, id: 2, attrs: map[string]interface{}{"x": int64(2)}},
+		// Id is preferred if both are present.
+		{q: `SetColumnAttrs(id=3, columnID=4, x=3)`, id: 3, attrs: map[string]interface{}{"columnID": int64(4), "x": int64(3)}},
+		// Explicit column label overrides id.
+		{q: `SetColumnAttrs(id=5, columnID=6, columnLabel="columnID", x=4)`, id: 6, attrs: map[string]interface{}{"id": int64(5), "x": int64(4)}},
+		// Neither present.
+		{q: `SetColumnAttrs(x=5)`, err: `SetColumnAttrs() column id required: expected "id" or "columnID"`},
+		// Explicit label missing.
+		{q: `SetColumnAttrs(id=7, columnLabel="columnID", x=6)`, err: `SetColumnAttrs() column id required: expected "columnID"`},
+		// Explicit label unknown.
+		{q: `SetColumnAttrs(id=8, columnLabel="foo", x=7)`, err: `SetColumnAttrs() invalid column id "columnLabel": unknown label: foo`},
+		// Invalid id falls back to the column label.
+		{q: `SetColumnAttrs(id="bad", columnID=9, x=9)`, id: 9, attrs: map[string]interface{}{"id": "bad", "x": int64(9)}},
+		// Invalid id.
+		{q: `SetColumnAttrs(id="bad", x=8)`, err: `SetColumnAttrs() invalid column id "id": could not convert bad of type string to uint64 in Calll.UintArg`},
+	} {
+		if _, err := e.Execute(context.Background(), "i", MustParse(tt.q), nil, nil); tt.err != "" {
+			if _, ok := err.(*pilosa.ColumnIDError); !ok || err.Error() != tt.err {
+				t.Fatalf("%s: unexpected error: %v", tt.q, err)
+			}
+			continue
+		} else if err != nil {
+			t.Fatalf("%s: %s", tt.q, err)
+		}
+
+		if m, err := idx.ColumnAttrStore().Attrs(tt.id); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(m, tt.attrs) {
+			t.Fatalf("%s: unexpected attrs: %#v", tt.q, m)
+		}
+	}
+}
+
+// Ensure multiple SetColumnAttrs() calls are merged and forwarded once per node.
+func TestExecutor_Execute_SetColumnAttrs_Bulk(t *testing.T) {
+	const q = `SetColumnAttrs(id=1, x=1) SetColumnAttrs(columnID=2, y="a") SetColumnAttrs(id=1, x=2, z=true)`
+
+	// Execute each call individually as the expected result.
+	hldr0 := MustOpenHolder()
+	defer hldr0.Close()
+	idx0 := hldr0.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	e0 := NewExecutor(hldr0.Holder, NewCluster(1))
+	for _, call := range MustParse(q).Calls {
+		if _, err := e0.Execute(context.Background(), "i", &pql.Query{Calls: []*pql.Call{call}}, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Create secondary server which records forwarded requests.
+	var requests []*pql.Query
+	s := NewServer()
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		requests = append(requests, query)
+		return make([]interface{}, len(query.Calls)), nil
+	}
+	defer s.Close()
+
+	c := NewCluster(2)
+	c.Nodes[1].Host = s.Host()
+
+	hldr1 := MustOpenHolder()
+	defer hldr1.Close()
+	idx1 := hldr1.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	e1 := NewExecutor(hldr1.Holder, c)
+	if res, err := e1.Execute(context.Background(), "i", MustParse(q), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{nil, nil, nil}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res))
+	} else if len(requests) != 1 || len(requests[0].Calls) != 3 {
+		t.Fatalf("unexpected requests: %s", spew.Sdump(requests))
+	}
+
+	// Verify bulk attributes match individual calls.
+	for _, id := range []uint64{1, 2} {
+		exp, err := idx0.ColumnAttrStore().Attrs(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m, err := idx1.ColumnAttrStore().Attrs(id); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(m, exp) {
+			t.Fatalf("unexpected attrs(%d): %#v != %#v", id, m, exp)
+		}
+	}
+}
+
+// Ensure a TopN() query can be executed.
+func TestExecutor_Execute_TopN(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	// Set bits for rows 0, 10, & 20 across two slices.
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).SetBit(0, 0)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).SetBit(0, 1)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(0, SliceWidth)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(0, SliceWidth+2)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 5).SetBit(0, (5*SliceWidth)+100)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).SetBit(10, 0)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(10, SliceWidth)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(20, SliceWidth)
+	hldr.MustCreateFragmentIfNotExists("i", "other", pilosa.ViewStandard, 0).SetBit(0, 0)
+
+	// Execute query.
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	if result, err := e.Execute(context.Background(), "i", MustParse(`TopN(frame=f, n=2)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(result[0], []pilosa.Pair{
+		{ID: 0, Count: 5},
+		{ID: 10, Count: 2},
+	}) {
+		t.Fatalf("unexpected result: %s", spew.Sdump(result))
+	}
+}
+
+// Ensure a TopN() query on the inverse view ranks columns by their number of rows.
+func TestExecutor_Execute_TopN_Inverse(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	if _, err := index.CreateFrame("f", pilosa.FrameOptions{InverseEnabled: true}); err != nil {
+		t.Fatal(err)
+	} else if _, err := index.CreateFrame("g", pilosa.FrameOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Rows are spread across two inverse slices.
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	q := ``
+	for _, bit := range [][2]uint64{{1, 1}, {1, 2}, {1, 3}, {2, 2}, {2, 3}, {3, 3}, {SliceWidth + 1, 2}, {SliceWidth + 1, 3}} {
+		q += fmt.Sprintf("SetBit(frame=f, rowID=%d, columnID=%d)\n", bit[0], bit[1])
+	}
+	if _, err := e.Execute(context.Background(), "i", MustParse(q), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if result, err := e.Execute(context.Background(), "i", MustParse(`TopN(frame=f, view="inverse", n=2)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(result[0], []pilosa.Pair{
+		{ID: 3, Count: 4},
+		{ID: 2, Count: 3},
+	}) {
+		t.Fatalf("unexpected result: %s", spew.Sdump(result))
+	}
+
+	// Inputs filter the rows counted for each column.
+	if result, err := e.Execute(context.Background(), "i", MustParse(`TopN(Bitmap(frame=f, columnID=2), frame=f, view="inverse", ids=[1,3])`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(result[0], []pilosa.Pair{
+		{ID: 3, Count: 3},
+		{ID: 1, Count: 1},
+	}) {
+		t.Fatalf("unexpected filtered result: %s", spew.Sdump(result))
+	}
+
+	for _, tt := range []struct {
+		q   string
+		err string
+	}{
+		{q: `TopN(frame=g, view="inverse", n=2)`, err: pilosa.ErrFrameInverseDisabled.Error()},
+		{q: `TopN(frame=f, view="other", n=2)`, err: "invalid TopN() view: other"},
+		{q: `TopN(frame=f, view="inverse", field="x", filters=["a"])`, err: "TopN() attribute filters require the standard view"},
+	} {
+		if _, err := e.Execute(context.Background(), "i", MustParse(tt.q), nil, nil); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Fatalf("%s: unexpected error: %v", tt.q, err)
+		}
+	}
+}
+
+func TestExecutor_Execute_TopN_fill(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	// Set bits for rows 0, 10, & 20 across two slices.
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).SetBit(0, 0)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).SetBit(0, 1)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).SetBit(0, 2)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(0, SliceWidth)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(1, SliceWidth+2)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(1, SliceWidth)
+
+	// Execute query.
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	if result, err := e.Execute(context.Background(), "i", MustParse(`TopN(frame=f, n=1)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(result, []interface{}{[]pilosa.Pair{
+		{ID: 0, Count: 4},
+	}}) {
+		t.Fatalf("unexpected result: %s", spew.Sdump(result))
+	}
+}
+
+// Ensure a bounded TopN() reduction returns the same results as full accumulation.
+func TestExecutor_Execute_TopN_ReduceMargin(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	// Rows 18-20 are set in every slice while rows 1-3 are only set in a
+	// single slice but rank first within it, so merging must drop some rows.
+	for slice := uint64(0); slice < 3; slice++ {
+		frag := hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, slice)
+		for rowID, n := range map[uint64]uint64{18: 39, 19: 40, 20: 41, slice + 1: 45 + slice} {
+			for i := uint64(0); i < n; i++ {
+				frag.MustSetBits(rowID, slice*SliceWidth+i)
+			}
+		}
+	}
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	for _, q := range []string{
+		`TopN(frame=f, n=1)`,
+		`TopN(frame=f, n=3)`,
+		`TopN(frame=f, n=5)`,
+		`TopN(frame=f, n=3, ids=[1,2,3,4,5,6,7])`,
+	} {
+		e.TopNReduceMargin = 0
+		exp, err := e.Execute(context.Background(), "i", MustParse(q), nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		e.TopNReduceMargin = 1
+		if res, err := e.Execute(context.Background(), "i", MustParse(q), nil, nil); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(res, exp) {
+			t.Fatalf("%s: unexpected results: %s != %s", q, spew.Sdump(res), spew.Sdump(exp))
+		}
+	}
+}
+
+// Ensure TopN() only counts columns matching a column attribute filter.
+func TestExecutor_Execute_TopN_ColumnFilter(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 1, 2, 3)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(20, 1, 4, 5)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(20, SliceWidth+1)
+
+	// Tag columns by country.
+	idx := hldr.Index("i")
+	for id, country := range map[uint64]string{1: "US", 2: "US", 3: "US", 4: "CA", 5: "CA", SliceWidth + 1: "CA"} {
+		if err := idx.ColumnAttrStore().SetAttrs(id, map[string]interface{}{"country": country}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	if result, err := e.Execute(context.Background(), "i", MustParse(`TopN(frame=f, n=2)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(result[0], []pilosa.Pair{{ID: 20, Count: 4}, {ID: 10, Count: 3}}) {
+		t.Fatalf("unexpected result: %s", spew.Sdump(result))
+	}
+
+	if result, err := e.Execute(context.Background(), "i", MustParse(`TopN(frame=f, n=2, columnField="country", columnFilters=["US"])`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(result[0], []pilosa.Pair{{ID: 10, Count: 3}, {ID: 20, Count: 1}}) {
+		t.Fatalf("unexpected result: %s", spew.Sdump(result))
+	}
+
+	// The filter is intersected with any input bitmap.
+	if result, err := e.Execute(context.Background(), "i", MustParse(`TopN(Bitmap(rowID=20, frame=f), frame=f, n=2, columnField="country", columnFilters=["CA"])`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(result[0], []pilosa.Pair{{ID: 20, Count: 3}}) {
+		t.Fatalf("unexpected result: %s", spew.Sdump(result))
+	}
+
+	// Scanning more attribute sets than the limit returns an error.
+	e.MaxColumnAttrFilterN = 2
+	if _, err := e.Execute(context.Background(), "i", MustParse(`TopN(frame=f, n=2, columnField="country", columnFilters=["US"])`), nil, nil); err != pilosa.ErrTooManyFilterColumns {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure TopN() column filters match list-valued column attributes by element.
+func TestExecutor_Execute_TopN_ColumnFilter_List(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 1, 2, 3)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(20, 3)
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	if _, err := e.Execute(context.Background(), "i", MustParse(`SetColumnAttrs(id=1, tags=["a","b"]) SetColumnAttrs(id=2, tags="a") SetColumnAttrs(id=3, tags=["c"])`), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for q, exp := range map[string][]pilosa.Pair{
+		`TopN(frame=f, n=2, columnField="tags", columnFilters=["a"])`:     {{ID: 10, Count: 2}},
+		`TopN(frame=f, n=2, columnField="tags", columnFilters=["b","c"])`: {{ID: 10, Count: 2}, {ID: 20, Count: 1}},
+		`TopN(frame=f, n=2, columnField="tags", columnFilters=[["a"]])`:   {},
+	} {
+		if result, err := e.Execute(context.Background(), "i", MustParse(q), nil, nil); err != nil {
+			t.Fatalf("%s: %s", q, err)
+		} else if !reflect.DeepEqual(result, []interface{}{exp}) {
+			t.Fatalf("%s: unexpected result: %s", q, spew.Sdump(result))
+		}
+	}
+}
+
+// Ensure
+func TestExecutor_Execute_TopN_fill_small(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).SetBit(0, 0)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(0, SliceWidth)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 2).SetBit(0, 2*SliceWidth)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 3).SetBit(0, 3*SliceWidth)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 4).SetBit(0, 4*SliceWidth)
+
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).SetBit(1, 0)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).SetBit(1, 1)
+
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(2, SliceWidth)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(2, SliceWidth+1)
+
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 2).SetBit(3, 2*SliceWidth)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 2).SetBit(3, 2*SliceWidth+1)
+
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 3).SetBit(4, 3*SliceWidth)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 3).SetBit(4, 3*SliceWidth+1)
+
+	// Execute query.
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	if result, err := e.Execute(context.Background(), "i", MustParse(`TopN(frame=f, n=1)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(result, []interface{}{[]pilosa.Pair{
+		{ID: 0, Count: 5},
+	}}) {
+		t.Fatalf("unexpected result: %s", spew.Sdump(result))
+	}
+}
+
+// Ensure a TopN() query drops rows below the global minimum count.
+func TestExecutor_Execute_TopN_MinCount(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	// Row 20 is above the local threshold on each slice but has a merged count below minCount.
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(0, 0, 1, 2)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 0, 1)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(20, 0)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(0, SliceWidth)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(10, SliceWidth)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(20, SliceWidth)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).RecalculateCache()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).RecalculateCache()
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	for _, tt := range []struct {
+		q   string
+		exp []pilosa.Pair
+	}{
+		{q: `TopN(frame=f, threshold=1, minCount=3)`, exp: []pilosa.Pair{{ID: 0, Count: 4}, {ID: 10, Count: 3}}},
+		{q: `TopN(frame=f, threshold=1, minCount=3, n=1)`, exp: []pilosa.Pair{{ID: 0, Count: 4}}},
+		{q: `TopN(frame=f, threshold=1, minCount=5)`, exp: []pilosa.Pair{}},
+		{q: `TopN(frame=f, threshold=1, ids=[10,20], minCount=3)`, exp: []pilosa.Pair{{ID: 10, Count: 3}}},
+	} {
+		if res, err := e.Execute(context.Background(), "i", MustParse(tt.q), nil, nil); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(res, []interface{}{tt.exp}) {
+			t.Fatalf("%s: unexpected results: %s", tt.q, spew.Sdump(res))
+		}
+	}
+}
+
+// Ensure a TopN() query with a source bitmap can be executed.
+func TestExecutor_Execute_TopN_Src(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	// Set bits for rows 0, 10, & 20 across two slices.
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).SetBit(0, 0)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).SetBit(0, 1)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(0, SliceWidth)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(10, SliceWidth)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(10, SliceWidth+1)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(20, SliceWidth)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(20, SliceWidth+1)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(20, SliceWidth+2)
+
+	// Create an intersecting row.
+	hldr.MustCreateFragmentIfNotExists("i", "other", pilosa.ViewStandard, 1).SetBit(100, SliceWidth)
+	hldr.MustCreateFragmentIfNotExists("i", "other", pilosa.ViewStandard, 1).SetBit(100, SliceWidth+1)
+	hldr.MustCreateFragmentIfNotExists("i", "other", pilosa.ViewStandard, 1).SetBit(100, SliceWidth+2)
+
+	// Execute query.
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	if result, err := e.Execute(context.Background(), "i", MustParse(`TopN(Bitmap(rowID=100, frame=other), frame=f, n=3)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(result, []interface{}{[]pilosa.Pair{
+		{ID: 20, Count: 3},
+		{ID: 10, Count: 2},
+		{ID: 0, Count: 1},
+	}}) {
+		t.Fatalf("unexpected result: %s", spew.Sdump(result))
+	}
+}
+
+// Ensure a TopN() query with multiple source bitmaps ranks within their intersection.
+func TestExecutor_Execute_TopN_MultiSrc(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	// Set bits for rows 0, 10, & 20 across two slices.
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).SetBit(0, 0)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).SetBit(0, 1)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(0, SliceWidth)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(10, SliceWidth)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(10, SliceWidth+1)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(20, SliceWidth)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(20, SliceWidth+1)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(20, SliceWidth+2)
+
+	// Create two overlapping filter rows.
+	hldr.MustCreateFragmentIfNotExists("i", "other", pilosa.ViewStandard, 0).SetBit(100, 0)
+	hldr.MustCreateFragmentIfNotExists("i", "other", pilosa.ViewStandard, 1).SetBit(100, SliceWidth)
+	hldr.MustCreateFragmentIfNotExists("i", "other", pilosa.ViewStandard, 1).SetBit(100, SliceWidth+1)
+	hldr.MustCreateFragmentIfNotExists("i", "other", pilosa.ViewStandard, 1).SetBit(100, SliceWidth+2)
+	hldr.MustCreateFragmentIfNotExists("i", "other", pilosa.ViewStandard, 0).SetBit(200, 1)
+	hldr.MustCreateFragmentIfNotExists("i", "other", pilosa.ViewStandard, 1).SetBit(200, SliceWidth+1)
+	hldr.MustCreateFragmentIfNotExists("i", "other", pilosa.ViewStandard, 1).SetBit(200, SliceWidth+2)
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	expected, err := e.Execute(context.Background(), "i", MustParse(`TopN(Intersect(Bitmap(rowID=100, frame=other), Bitmap(rowID=200, frame=other)), frame=f, n=3)`), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result, err := e.Execute(context.Background(), "i", MustParse(`TopN(Bitmap(rowID=100, frame=other), Bitmap(rowID=200, frame=other), frame=f, n=3)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("unexpected result: %s", spew.Sdump(result))
+	} else if !reflect.DeepEqual(result, []interface{}{[]pilosa.Pair{
+		{ID: 20, Count: 2},
+		{ID: 10, Count: 1},
+	}}) {
+		t.Fatalf("unexpected result: %s", spew.Sdump(result))
+	}
+}
+
+// Ensure TopN handles Attribute filters
+func TestExecutor_Execute_TopN_Attr(t *testing.T) {
+	//
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).SetBit(0, 0)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).SetBit(0, 1)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(10, SliceWidth)
+
+	if err := hldr.Frame("i", "f").RowAttrStore().SetAttrs(10, map[string]interface{}{"category": int64(123)}); err != nil {
+		t.Fatal(err)
+	}
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	if result, err := e.Execute(context.Background(), "i", MustParse(`TopN(frame="f", n=1, field="category", filters=[123])`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(result, []interface{}{[]pilosa.Pair{
+		{ID: 10, Count: 1},
+	}}) {
+		t.Fatalf("unexpected result: %s", spew.Sdump(result))
+	}
+
+}
+
+// Ensure TopN handles Attribute filters with source bitmap
+func TestExecutor_Execute_TopN_Attr_Src(t *testing.T) {
+	//
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).SetBit(0, 0)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).SetBit(0, 1)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).SetBit(10, SliceWidth)
+
+	if err := hldr.Frame("i", "f").RowAttrStore().SetAttrs(10, map[string]interface{}{"category": uint64(123)}); err != nil {
+		t.Fatal(err)
+	}
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	if result, err := e.Execute(context.Background(), "i", MustParse(`TopN(Bitmap(rowID=10,frame=f),frame="f", n=1, field="category", filters=[123])`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(result, []interface{}{[]pilosa.Pair{
+		{ID: 10, Count: 1},
+	}}) {
+		t.Fatalf("unexpected result: %s", spew.Sdump(result))
+	}
+
+}
+
+// Ensure TopN() attribute filters match list-valued row attributes by element.
+func TestExecutor_Execute_TopN_Attr_List(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 1, 2)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(20, 1)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(30, 1)
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	if _, err := e.Execute(context.Background(), "i", MustParse(`SetRowAttrs(frame=f, rowID=10, tags=["a","b"]) SetRowAttrs(frame=f, rowID=20, tags="a") SetRowAttrs(frame=f, rowID=30, tags=["c"])`), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for q, exp := range map[string][]pilosa.Pair{
+		`TopN(frame=f, n=2, field="tags", filters=["a"])`:     {{ID: 10, Count: 2}, {ID: 20, Count: 1}},
+		`TopN(frame=f, n=2, field="tags", filters=["b","c"])`: {{ID: 10, Count: 2}, {ID: 30, Count: 1}},
+		`TopN(frame=f, n=2, field="tags", filters=[["a"]])`:   {},
+	} {
+		if result, err := e.Execute(context.Background(), "i", MustParse(q), nil, nil); err != nil {
+			t.Fatalf("%s: %s", q, err)
+		} else if !reflect.DeepEqual(result, []interface{}{exp}) {
+			t.Fatalf("%s: unexpected result: %s", q, spew.Sdump(result))
+		}
+	}
+}
+
+// Ensure TopN() skips remaining slices once the top rows are decided.
+func TestExecutor_Execute_TopN_EarlyTermination(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	// Row 1 dominates the first slices. Later slices only hold small rows.
+	for slice := uint64(0); slice < 2; slice++ {
+		for i := uint64(0); i < 50; i++ {
+			hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, slice).MustSetBits(1, (slice*SliceWidth)+i)
+		}
+		for i := uint64(0); i < 5; i++ {
+			hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, slice).MustSetBits(2, (slice*SliceWidth)+i)
+		}
+	}
+	for slice := uint64(2); slice < 8; slice++ {
+		hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, slice).MustSetBits(2, slice*SliceWidth)
+		hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, slice).MustSetBits(3, slice*SliceWidth)
+	}
+
+	execute := func(batchN int, q string, opt *pilosa.ExecOptions) ([]interface{}, int) {
+		e := NewExecutor(hldr.Holder, NewCluster(1))
+		e.TopNEarlyTerminationBatchN = batchN
+		res, stats, err := e.ExecuteWithStats(context.Background(), "i", MustParse(q), nil, opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res, len(stats.Calls[0].LocalSlices)
+	}
+
+	// The first pass stops after the first batch.
+	opt := &pilosa.ExecOptions{ApproximateTopN: true}
+	full, fullN := execute(0, `TopN(frame=f, n=1)`, opt)
+	early, earlyN := execute(2, `TopN(frame=f, n=1)`, opt)
+	if !reflect.DeepEqual(early, []interface{}{[]pilosa.Pair{{ID: 1, Count: 100}}}) {
+		t.Fatalf("unexpected result: %s", spew.Sdump(early))
+	} else if !reflect.DeepEqual(early, full) {
+		t.Fatalf("unexpected result: %s != %s", spew.Sdump(early), spew.Sdump(full))
+	} else if fullN != 8 || earlyN != 2 {
+		t.Fatalf("unexpected slices visited: full=%d, early=%d", fullN, earlyN)
+	}
+
+	// Exact counts for the decided rows are refetched from all slices.
+	full, _ = execute(0, `TopN(frame=f, n=2)`, nil)
+	early, _ = execute(2, `TopN(frame=f, n=2)`, nil)
+	if !reflect.DeepEqual(early, []interface{}{[]pilosa.Pair{{ID: 1, Count: 100}, {ID: 2, Count: 16}}}) {
+		t.Fatalf("unexpected result: %s", spew.Sdump(early))
+	} else if !reflect.DeepEqual(early, full) {
+		t.Fatalf("unexpected result: %s != %s", spew.Sdump(early), spew.Sdump(full))
+	}
+
+	// Slices are not skipped while rows could still be overtaken.
+	if _, n := execute(2, `TopN(frame=f, n=3)`, opt); n != 6 {
+		t.Fatalf("unexpected slices visited: %d", n)
+	}
+}
+
+// Ensure TopN() early termination only skips locally owned slices.
+func TestExecutor_Execute_TopN_EarlyTermination_Remote(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	// Even slices are owned by the local node. Row 1 dominates the first two.
+	c := NewCluster(2)
+	for slice := uint64(0); slice < 16; slice += 2 {
+		if !c.OwnsFragment(c.Nodes[0].Host, "i", slice) {
+			t.Fatalf("slice %d not owned by local node", slice)
+		}
+	}
+	for _, slice := range []uint64{0, 2} {
+		for i := uint64(0); i < 50; i++ {
+			hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, slice).MustSetBits(1, (slice*SliceWidth)+i)
+		}
+	}
+	for slice := uint64(4); slice < 16; slice += 2 {
+		hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, slice).MustSetBits(2, slice*SliceWidth)
+	}
+
+	// The remote node owns the odd slices and returns a small row for each.
+	var remoteSlices []uint64
+	s := NewServer()
+	defer s.Close()
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		remoteSlices = append(remoteSlices, slices...)
+		return []interface{}{[]pilosa.Pair{{ID: 3, Count: uint64(len(slices))}}}, nil
+	}
+	c.Nodes[1].Host = s.Host()
+
+	e := NewExecutor(hldr.Holder, c)
+	e.TopNEarlyTerminationBatchN = 2
+	slices := []uint64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+	res, stats, err := e.ExecuteWithStats(context.Background(), "i", MustParse(`TopN(frame=f, n=1)`), slices, &pilosa.ExecOptions{ApproximateTopN: true})
+	if err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{[]pilosa.Pair{{ID: 1, Count: 100}}}) {
+		t.Fatalf("unexpected result: %s", spew.Sdump(res))
+	}
+
+	// Every remote slice is visited with the first batch of local slices.
+	if !reflect.DeepEqual(remoteSlices, []uint64{1, 3, 5, 7, 9, 11, 13, 15}) {
+		t.Fatalf("unexpected remote slices: %v", remoteSlices)
+	} else if local := stats.Calls[0].LocalSlices; !reflect.DeepEqual(local, []uint64{0, 2}) {
+		t.Fatalf("unexpected local slices: %v", local)
+	}
+}
+
+// Ensure a shift query can carry columns across slice boundaries.
+func TestExecutor_Execute_Shift(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 5, SliceWidth-2, SliceWidth-1)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(10, SliceWidth+1, (2*SliceWidth)-1)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(11, SliceWidth)
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	for _, tt := range []struct {
+		q   string
+		exp []uint64
+	}{
+		{q: `Shift(Bitmap(rowID=10, frame=f), n=2)`, exp: []uint64{7, SliceWidth, SliceWidth + 1, SliceWidth + 3, (2 * SliceWidth) + 1}},
+		{q: `Shift(Bitmap(rowID=10, frame=f), n=-6)`, exp: []uint64{SliceWidth - 8, SliceWidth - 7, SliceWidth - 5, (2 * SliceWidth) - 7}},
+		{q: `Shift(Shift(Bitmap(rowID=10, frame=f), n=2), n=-2)`, exp: []uint64{5, SliceWidth - 2, SliceWidth - 1, SliceWidth + 1, (2 * SliceWidth) - 1}},
+		{q: `Union(Shift(Bitmap(rowID=10, frame=f), n=1), Bitmap(rowID=11, frame=f))`, exp: []uint64{6, SliceWidth - 1, SliceWidth, SliceWidth + 2, 2 * SliceWidth}},
+	} {
+		if res, err := e.Execute(context.Background(), "i", MustParse(tt.q), nil, nil); err != nil {
+			t.Fatalf("%s: %s", tt.q, err)
+		} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, tt.exp) {
+			t.Fatalf("%s: unexpected bits: %+v", tt.q, bits)
+		}
+	}
+
+	// Counts include columns carried into other slices.
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Count(Shift(Bitmap(rowID=10, frame=f), n=2))`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{uint64(5)}) {
+		t.Fatalf("unexpected result: %s", spew.Sdump(res))
+	}
+
+	// Shifted columns cannot be combined before being counted slice by slice.
+	for _, q := range []string{
+		fmt.Sprintf(`Count(Union(Shift(Bitmap(rowID=10, frame=f), n=%d), Bitmap(rowID=10, frame=f)))`, SliceWidth),
+		`Count(Shift(Union(Shift(Bitmap(rowID=10, frame=f), n=1), Bitmap(rowID=11, frame=f)), n=1))`,
+	} {
+		if _, err := e.Execute(context.Background(), "i", MustParse(q), nil, nil); err == nil || err.Error() != "Shift() must be the direct input of Count()" {
+			t.Fatalf("%s: unexpected error: %v", q, err)
+		}
+	}
+	if _, err := e.Execute(context.Background(), "i", MustParse(`Page(Union(Shift(Bitmap(rowID=10, frame=f), n=1), Bitmap(rowID=11, frame=f)), limit=2)`), nil, nil); err == nil || err.Error() != "Shift() must be the direct input of Page()" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Count(Shift(Shift(Bitmap(rowID=10, frame=f), n=2), n=-2))`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{uint64(5)}) {
+		t.Fatalf("unexpected result: %s", spew.Sdump(res))
+	}
+
+	// Shifted columns cannot be intersected slice by slice.
+	if _, err := e.Execute(context.Background(), "i", MustParse(`Intersect(Union(Shift(Bitmap(rowID=10, frame=f), n=1)), Bitmap(rowID=11, frame=f))`), nil, &pilosa.ExecOptions{NoOptimize: true}); err == nil || err.Error() != "Shift() cannot be nested in Intersect()" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := e.Execute(context.Background(), "i", MustParse(`Shift(Bitmap(rowID=10, frame=f))`), nil, nil); err == nil || err.Error() != "Shift() n required" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure a UnionRows query matches the nested Union of its frames.
+func TestExecutor_Execute_UnionRows(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 1, 2)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(10, SliceWidth+1)
+	hldr.MustCreateFragmentIfNotExists("i", "g", pilosa.ViewStandard, 0).MustSetBits(10, 2, 3)
+	hldr.MustCreateFragmentIfNotExists("i", "g", pilosa.ViewStandard, 2).MustSetBits(10, (2*SliceWidth)+4)
+	hldr.MustCreateFragmentIfNotExists("i", "h", pilosa.ViewStandard, 0).MustSetBits(11, 5)
+	hldr.MustCreateFragmentIfNotExists("i", "h", pilosa.ViewStandard, 1).MustSetBits(10, SliceWidth+7)
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	for _, tt := range []struct {
+		q   string
+		exp string
+	}{
+		{q: `UnionRows(frames=["f"], rowID=10)`, exp: `Union(Bitmap(frame=f, rowID=10))`},
+		{q: `UnionRows(frames=["f","g"], rowID=10)`, exp: `Union(Bitmap(frame=f, rowID=10), Bitmap(frame=g, rowID=10))`},
+		{q: `UnionRows(frames=["f","g","h"], rowID=10)`, exp: `Union(Bitmap(frame=f, rowID=10), Bitmap(frame=g, rowID=10), Bitmap(frame=h, rowID=10))`},
+		{q: `UnionRows(frames=["g","h"], rowID=11)`, exp: `Union(Bitmap(frame=g, rowID=11), Bitmap(frame=h, rowID=11))`},
+	} {
+		res, err := e.Execute(context.Background(), "i", MustParse(tt.q), nil, nil)
+		if err != nil {
+			t.Fatalf("%s: %s", tt.q, err)
+		}
+		exp, err := e.Execute(context.Background(), "i", MustParse(tt.exp), nil, nil)
+		if err != nil {
+			t.Fatalf("%s: %s", tt.exp, err)
+		}
+		if bits, expBits := res[0].(*pilosa.Bitmap).Bits(), exp[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, expBits) {
+			t.Fatalf("%s: unexpected bits: %+v, expected %+v", tt.q, bits, expBits)
+		}
+	}
+
+	// Frames must read the row with the same label.
+	if _, err := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{}).CreateFrameIfNotExists("x", pilosa.FrameOptions{RowLabel: "xid"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Execute(context.Background(), "i", MustParse(`UnionRows(frames=["f","x"], rowID=10)`), nil, nil); err == nil || err.Error() != `UnionRows() frames must share a row label: f uses "rowID", x uses "xid"` {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := e.Execute(context.Background(), "i", MustParse(`UnionRows(frames=["f"])`), nil, nil); err == nil || err.Error() != "UnionRows() row field 'rowID' required" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := e.Execute(context.Background(), "i", MustParse(`UnionRows(rowID=10)`), nil, nil); err == nil || err.Error() != "UnionRows() frames required" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure a row range matches the union of its rows.
+func TestExecutor_Execute_RowRange(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 1, 2)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(11, 2, 3)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(12, SliceWidth+1)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 2).MustSetBits(13, (2*SliceWidth)+4)
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	for _, tt := range []struct {
+		q   string
+		exp string
+	}{
+		{q: `RowRange(frame=f, from=10, to=10)`, exp: `Union(Bitmap(frame=f, rowID=10))`},
+		{q: `RowRange(frame=f, from=10, to=12)`, exp: `Union(Bitmap(frame=f, rowID=10), Bitmap(frame=f, rowID=11), Bitmap(frame=f, rowID=12))`},
+		{q: `RowRange(frame=f, from=11, to=20)`, exp: `Union(Bitmap(frame=f, rowID=11), Bitmap(frame=f, rowID=12), Bitmap(frame=f, rowID=13))`},
+		{q: `RowRange(frame=f, from=14, to=20)`, exp: `Union()`},
+	} {
+		res, err := e.Execute(context.Background(), "i", MustParse(tt.q), nil, nil)
+		if err != nil {
+			t.Fatalf("%s: %s", tt.q, err)
+		}
+		exp, err := e.Execute(context.Background(), "i", MustParse(tt.exp), nil, nil)
+		if err != nil {
+			t.Fatalf("%s: %s", tt.exp, err)
+		}
+		if bits, expBits := res[0].(*pilosa.Bitmap).Bits(), exp[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, expBits) {
+			t.Fatalf("%s: unexpected bits: %+v, expected %+v", tt.q, bits, expBits)
+		}
+	}
+
+	e.MaxRowRangeN = 3
+	for _, tt := range []struct {
+		q   string
+		err string
+	}{
+		{q: `RowRange(frame=f, from=10, to=13)`, err: `RowRange() exceeds the limit of 3 rows`},
+		{q: `RowRange(frame=f, from=0, to=9223372036854775807)`, err: `RowRange() exceeds the limit of 3 rows`},
+		{q: `RowRange(frame=f, from=12, to=10)`, err: `invalid RowRange() bounds: from 12 is after to 10`},
+		{q: `RowRange(frame=f, to=10)`, err: `RowRange() from required`},
+		{q: `RowRange(frame=f, from=10)`, err: `RowRange() to required`},
+		{q: `RowRange(frame=x, from=10, to=12)`, err: pilosa.ErrFrameNotFound.Error()},
+	} {
+		if _, err := e.Execute(context.Background(), "i", MustParse(tt.q), nil, nil); err == nil || err.Error() != tt.err {
+			t.Fatalf("%s: unexpected error: %v", tt.q, err)
+		}
+	}
+}
+
+// Ensure queries nested deeper than MaxCallDepth are rejected before execution.
+func TestExecutor_Execute_MaxCallDepth(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 1, 2)
+
+	// nest wraps a bitmap call in n-1 Union() calls.
+	nest := func(n int) string {
+		return strings.Repeat("Union(", n-1) + "Bitmap(frame=f, rowID=10)" + strings.Repeat(")", n-1)
+	}
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	e.MaxCallDepth = 8
+
+	// Execute a query at the limit.
+	if res, err := e.Execute(context.Background(), "i", MustParse(nest(8)), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, []uint64{1, 2}) {
+		t.Fatalf("unexpected bits: %+v", bits)
+	}
+
+	// Reject a query one level past the limit when executed or validated.
+	q := MustParse(nest(9))
+	if _, err := e.Execute(context.Background(), "i", q, nil, nil); err == nil || err.Error() != `Union() call exceeds the maximum nesting depth of 8` {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := e.Validate(context.Background(), "i", q); err == nil || err.Error() != `Union() call exceeds the maximum nesting depth of 8` {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+}
+
+// Ensure a range query can be executed.
+func TestExecutor_Execute_Range(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	// Create index.
+	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+
+	// Create frame.
+	f, err := index.CreateFrameIfNotExists("f", pilosa.FrameOptions{})
+	if err != nil {
+		t.Fatal(err)
+	} else if err := f.SetTimeQuantum(pilosa.TimeQuantum("YMDH")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Set bits.
+	f.MustSetBit(pilosa.ViewStandard, 1, 2, MustParseTimePtr("1999-12-31 00:00"))
+	f.MustSetBit(pilosa.ViewStandard, 1, 3, MustParseTimePtr("2000-01-01 00:00"))
+	f.MustSetBit(pilosa.ViewStandard, 1, 4, MustParseTimePtr("2000-01-02 00:00"))
+	f.MustSetBit(pilosa.ViewStandard, 1, 5, MustParseTimePtr("2000-02-01 00:00"))
+	f.MustSetBit(pilosa.ViewStandard, 1, 6, MustParseTimePtr("2001-01-01 00:00"))
+	f.MustSetBit(pilosa.ViewStandard, 1, 7, MustParseTimePtr("2002-01-01 02:00"))
+
+	f.MustSetBit(pilosa.ViewStandard, 1, 2, MustParseTimePtr("1999-12-30 00:00"))  // too early
+	f.MustSetBit(pilosa.ViewStandard, 1, 2, MustParseTimePtr("2002-02-01 00:00"))  // too late
+	f.MustSetBit(pilosa.ViewStandard, 10, 2, MustParseTimePtr("2001-01-01 00:00")) // different row
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Range(rowID=1, frame=f, start="1999-12-31T00:00", end="2002-01-01T03:00")`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, []uint64{2, 3, 4, 5, 6, 7}) {
+		t.Fatalf("unexpected bits: %+v", bits)
+	}
+}
+
+// Ensure a Range() query with relative times reads the views relative to the executor's clock.
+func TestExecutor_Execute_Range_Relative(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	f, err := index.CreateFrameIfNotExists("f", pilosa.FrameOptions{})
+	if err != nil {
+		t.Fatal(err)
+	} else if err := f.SetTimeQuantum(pilosa.TimeQuantum("YMDH")); err != nil {
+		t.Fatal(err)
+	}
+
+	f.MustSetBit(pilosa.ViewStandard, 1, 2, MustParseTimePtr("2017-03-03 14:00")) // too early
+	f.MustSetBit(pilosa.ViewStandard, 1, 3, MustParseTimePtr("2017-03-03 15:00"))
+	f.MustSetBit(pilosa.ViewStandard, 1, 4, MustParseTimePtr("2017-03-07 00:00"))
+	f.MustSetBit(pilosa.ViewStandard, 1, 5, MustParseTimePtr("2017-03-10 14:00"))
+	f.MustSetBit(pilosa.ViewStandard, 1, 6, MustParseTimePtr("2017-03-10 15:00")) // too late for "now"
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	e.Now = func() time.Time { return time.Date(2017, 3, 10, 15, 0, 0, 0, time.UTC) }
+	for _, tt := range []struct {
+		q   string
+		exp []uint64
+	}{
+		{q: `Range(rowID=1, frame=f, start="-7d", end="now")`, exp: []uint64{3, 4, 5}},
+		{q: `Range(rowID=1, frame=f, start="now-1w", end="now+1h")`, exp: []uint64{3, 4, 5, 6}},
+		{q: `Range(rowID=1, frame=f, start="-1h", end="2017-03-11T00:00")`, exp: []uint64{5, 6}},
+		{q: `Range(rowID=1, frame=f, start="2017-03-03T00:00", end="-3d")`, exp: []uint64{2, 3, 4}},
+	} {
+		if res, err := e.Execute(context.Background(), "i", MustParse(tt.q), nil, nil); err != nil {
+			t.Fatalf("%s: %s", tt.q, err)
+		} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, tt.exp) {
+			t.Fatalf("%s: unexpected bits: %+v", tt.q, bits)
+		}
+	}
+
+	// Values outside the relative grammar are parsed as absolute times.
+	if _, err := e.Execute(context.Background(), "i", MustParse(`Range(rowID=1, frame=f, start="-7x", end="now")`), nil, nil); err == nil || err.Error() != "cannot parse Range() start time" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure relative Range() times are sent to remote nodes as absolute times.
+func TestExecutor_Execute_Remote_Range_Relative(t *testing.T) {
+	c := NewCluster(2)
+
+	s := NewServer()
+	defer s.Close()
+	c.Nodes[1].Host = s.Host()
+
+	var queries []string
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		queries = append(queries, query.String())
+		return []interface{}{uint64(1)}, nil
+	}
+
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	if f, err := index.CreateFrameIfNotExists("f", pilosa.FrameOptions{}); err != nil {
+		t.Fatal(err)
+	} else if err := f.SetTimeQuantum(pilosa.TimeQuantum("YMDH")); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewExecutor(hldr.Holder, c)
+	e.Now = func() time.Time { return time.Date(2017, 3, 10, 15, 0, 0, 0, time.UTC) }
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Count(Range(rowID=1, frame=f, start="-2h", end="now"))`), []uint64{0, 1}, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{uint64(1)}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res))
+	} else if !reflect.DeepEqual(queries, []string{`Count(Range(end="2017-03-10T15:00", frame="f", rowID=1, start="2017-03-10T13:00"))`}) {
+		t.Fatalf("unexpected remote queries: %v", queries)
+	}
+}
+
+// Ensure a Range() query with an explicit view only reads subframes of that view.
+func TestExecutor_Execute_Range_View(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	f, err := index.CreateFrame("f", pilosa.FrameOptions{InverseEnabled: true})
+	if err != nil {
+		t.Fatal(err)
+	} else if err := f.SetTimeQuantum(pilosa.TimeQuantum("YMD")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := index.CreateFrame("g", pilosa.FrameOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	f.MustSetBit(pilosa.ViewStandard, 1, 2, MustParseTimePtr("2000-01-02 00:00"))
+	f.MustSetBit(pilosa.ViewInverse, 1, 3, MustParseTimePtr("2000-01-02 00:00"))
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	for _, tt := range []struct {
+		q    string
+		bits []uint64
+		err  string
+	}{
+		{q: `Range(rowID=1, frame=f, start="2000-01-01T00:00", end="2000-01-03T00:00")`, bits: []uint64{2}},
+		{q: `Range(rowID=1, frame=f, view="standard", start="2000-01-01T00:00", end="2000-01-03T00:00")`, bits: []uint64{2}},
+		{q: `Range(rowID=1, frame=f, view="inverse", start="2000-01-01T00:00", end="2000-01-03T00:00")`, bits: []uint64{3}},
+		{q: `Range(rowID=1, frame=f, view="foo", start="2000-01-01T00:00", end="2000-01-03T00:00")`, err: `invalid Range() view: foo`},
+		{q: `Range(rowID=1, frame=g, view="inverse", start="2000-01-01T00:00", end="2000-01-03T00:00")`, err: pilosa.ErrFrameInverseDisabled.Error()},
+	} {
+		res, err := e.Execute(context.Background(), "i", MustParse(tt.q), nil, nil)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Fatalf("%s: unexpected error: %v", tt.q, err)
+			}
+			continue
+		} else if err != nil {
+			t.Fatalf("%s: %s", tt.q, err)
+		}
+		if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, tt.bits) {
+			t.Fatalf("%s: unexpected bits: %+v", tt.q, bits)
+		}
+	}
+}
+
+// Ensure a Range() query on a frame without a time quantum is rejected.
+func TestExecutor_Execute_Range_NoTimeQuantum(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(1, 2)
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	q := `Count(Range(rowID=1, frame=f, start="2000-01-01T00:00", end="2000-01-03T00:00"))`
+	if _, err := e.Execute(context.Background(), "i", MustParse(q), nil, nil); err == nil || err.Error() != `Range() requires a time quantum on frame f` {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Empty results may be requested instead.
+	opt := &pilosa.ExecOptions{EmptyRangeWithoutQuantum: true}
+	if res, err := e.Execute(context.Background(), "i", MustParse(q), nil, opt); err != nil {
+		t.Fatal(err)
+	} else if res[0] != uint64(0) {
+		t.Fatalf("unexpected n: %v", res[0])
+	}
+}
+
+// Ensure a Range() query expanding to more views than the limit is rejected.
+func TestExecutor_Execute_Range_MaxRangeViews(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	f, err := index.CreateFrameIfNotExists("f", pilosa.FrameOptions{})
+	if err != nil {
+		t.Fatal(err)
+	} else if err := f.SetTimeQuantum(pilosa.TimeQuantum("YMDH")); err != nil {
+		t.Fatal(err)
+	}
+	f.MustSetBit(pilosa.ViewStandard, 1, 2, MustParseTimePtr("2000-06-01 05:00"))
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	e.MaxRangeViews = 100
+
+	// A narrow range is under the limit.
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Range(rowID=1, frame=f, start="2000-06-01T00:00", end="2000-06-01T10:00")`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, []uint64{2}) {
+		t.Fatalf("unexpected bits: %+v", bits)
+	}
+
+	// A wide hourly range is over the limit.
+	q := MustParse(`Count(Range(rowID=1, frame=f, start="2000-01-01T01:00", end="2001-12-31T23:00"))`)
+	if _, err := e.Execute(context.Background(), "i", q, nil, nil); err == nil || !strings.Contains(err.Error(), "exceeding the limit of 100") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The limit is disabled when zero.
+	e.MaxRangeViews = 0
+	if res, err := e.Execute(context.Background(), "i", q, nil, nil); err != nil {
+		t.Fatal(err)
+	} else if res[0] != uint64(1) {
+		t.Fatalf("unexpected count: %v", res[0])
+	}
+}
+
+// Ensure a count of a range query matches the number of bits in the range.
+func TestExecutor_Execute_Count_Range(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	f, err := index.CreateFrameIfNotExists("f", pilosa.FrameOptions{})
+	if err != nil {
+		t.Fatal(err)
+	} else if err := f.SetTimeQuantum(pilosa.TimeQuantum("YMDH")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Columns 2 & SliceWidth+2 are set in several views.
+	f.MustSetBit(pilosa.ViewStandard, 1, 2, MustParseTimePtr("1999-12-30 00:00"))
+	f.MustSetBit(pilosa.ViewStandard, 1, 2, MustParseTimePtr("1999-12-31 00:00"))
+	f.MustSetBit(pilosa.ViewStandard, 1, 2, MustParseTimePtr("2000-01-01 00:00"))
+	f.MustSetBit(pilosa.ViewStandard, 1, 3, MustParseTimePtr("2000-01-01 00:00"))
+	f.MustSetBit(pilosa.ViewStandard, 1, 4, MustParseTimePtr("2000-01-02 05:00"))
+	f.MustSetBit(pilosa.ViewStandard, 1, SliceWidth+2, MustParseTimePtr("2000-02-01 00:00"))
+	f.MustSetBit(pilosa.ViewStandard, 1, SliceWidth+2, MustParseTimePtr("2001-01-01 00:00"))
+	f.MustSetBit(pilosa.ViewStandard, 10, 5, MustParseTimePtr("2000-01-01 00:00")) // different row
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	for _, window := range [][2]string{
+		{"1999-12-31T00:00", "1999-12-31T01:00"},
+		{"1999-12-30T00:00", "2000-01-02T00:00"},
+		{"1999-01-01T00:00", "2002-01-01T00:00"},
+		{"2000-01-02T05:00", "2000-03-01T00:00"},
+		{"2003-01-01T00:00", "2004-01-01T00:00"},
+	} {
+		rng := fmt.Sprintf(`Range(rowID=1, frame=f, start="%s", end="%s")`, window[0], window[1])
+		res, err := e.Execute(context.Background(), "i", MustParse(rng+" Count("+rng+")"), nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exp, n := uint64(len(res[0].(*pilosa.Bitmap).Bits())), res[1].(uint64); n != exp {
+			t.Fatalf("%s: unexpected count: %d, expected %d", rng, n, exp)
+		}
+	}
+}
+
+// Ensure a Count() over a missing frame fails unless missing frames are treated as empty.
+func TestExecutor_Execute_Count_MissingFrame(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 1, 2)
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	for _, q := range []string{
+		`Count(Bitmap(rowID=10, frame=x))`,
+		`Count(Range(rowID=10, frame=x, start="2000-01-01T00:00", end="2000-01-02T00:00"))`,
+		`Count(Union(Bitmap(rowID=10, frame=f), Bitmap(rowID=10, frame=x)))`,
+	} {
+		if _, err := e.Execute(context.Background(), "i", MustParse(q), nil, nil); err != pilosa.ErrFrameNotFound {
+			t.Fatalf("%s: unexpected error: %v", q, err)
+		}
+	}
+
+	opt := &pilosa.ExecOptions{TreatMissingAsEmpty: true}
+	for _, tt := range []struct {
+		q string
+		n uint64
+	}{
+		{q: `Count(Bitmap(rowID=10, frame=x))`, n: 0},
+		{q: `Count(Range(rowID=10, frame=x, start="2000-01-01T00:00", end="2000-01-02T00:00"))`, n: 0},
+		{q: `Count(Union(Bitmap(rowID=10, frame=f), Bitmap(rowID=10, frame=x)))`, n: 2},
+	} {
+		if res, err := e.Execute(context.Background(), "i", MustParse(tt.q), nil, opt); err != nil {
+			t.Fatalf("%s: %s", tt.q, err)
+		} else if res[0] != tt.n {
+			t.Fatalf("%s: unexpected n: %d", tt.q, res[0])
+		}
+	}
+}
+
+// Ensure read calls against an empty index return empty results of their type.
+func TestExecutor_Execute_EmptyIndex(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	f, err := index.CreateFrame("f", pilosa.FrameOptions{InverseEnabled: true})
+	if err != nil {
+		t.Fatal(err)
+	} else if err := f.SetTimeQuantum("YMD"); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	for _, q := range []string{
+		`Bitmap(rowID=10, frame=f)`,
+		`Bitmap(columnID=10, frame=f)`,
+		`Union(Bitmap(rowID=10, frame=f), Bitmap(rowID=11, frame=f))`,
+		`Intersect(Bitmap(rowID=10, frame=f), Bitmap(rowID=11, frame=f))`,
+		`Difference(Bitmap(rowID=10, frame=f), Bitmap(rowID=11, frame=f))`,
+		`Range(rowID=10, frame=f, start="2000-01-01T00:00", end="2000-01-03T00:00")`,
+	} {
+		if res, err := e.Execute(context.Background(), "i", MustParse(q), nil, nil); err != nil {
+			t.Fatalf("%s: %s", q, err)
+		} else if bits := res[0].(*pilosa.Bitmap).Bits(); len(bits) != 0 {
+			t.Fatalf("%s: unexpected bits: %+v", q, bits)
+		}
+	}
+
+	for _, q := range []string{
+		`Count(Bitmap(rowID=10, frame=f))`,
+		`Count(Range(rowID=10, frame=f, start="2000-01-01T00:00", end="2000-01-03T00:00"))`,
+	} {
+		if res, err := e.Execute(context.Background(), "i", MustParse(q), nil, nil); err != nil {
+			t.Fatalf("%s: %s", q, err)
+		} else if !reflect.DeepEqual(res, []interface{}{uint64(0)}) {
+			t.Fatalf("%s: unexpected result: %s", q, spew.Sdump(res))
+		}
+	}
+
+	for _, q := range []string{
+		`TopN(frame=f, n=2)`,
+		`TopN(Bitmap(rowID=10, frame=f), frame=f, n=2)`,
+		`TopN(frame=f, ids=[10,11])`,
+	} {
+		if res, err := e.Execute(context.Background(), "i", MustParse(q), nil, nil); err != nil {
+			t.Fatalf("%s: %s", q, err)
+		} else if !reflect.DeepEqual(res, []interface{}{[]pilosa.Pair{}}) {
+			t.Fatalf("%s: unexpected result: %s", q, spew.Sdump(res))
+		}
+	}
+}
+
+// Ensure calls without a frame use the index's default frame.
+func TestExecutor_Execute_IndexDefaultFrame(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	if _, err := hldr.CreateIndex("i", pilosa.IndexOptions{DefaultFrame: "events"}); err != nil {
+		t.Fatal(err)
+	}
+	hldr.MustCreateFragmentIfNotExists("i", "events", pilosa.ViewStandard, 0).MustSetBits(10, 1, 2)
+	hldr.MustCreateFragmentIfNotExists("i", pilosa.DefaultFrame, pilosa.ViewStandard, 0).MustSetBits(10, 3)
+	hldr.MustCreateFragmentIfNotExists("j", pilosa.DefaultFrame, pilosa.ViewStandard, 0).MustSetBits(10, 4)
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Bitmap(rowID=10)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, []uint64{1, 2}) {
+		t.Fatalf("unexpected bits: %+v", bits)
+	}
+
+	if res, err := e.Execute(context.Background(), "i", MustParse(`TopN(n=1)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res[0], []pilosa.Pair{{ID: 10, Count: 2}}) {
+		t.Fatalf("unexpected pairs: %s", spew.Sdump(res))
+	}
+
+	// Indexes without a default frame use the global default.
+	if res, err := e.Execute(context.Background(), "j", MustParse(`Bitmap(rowID=10)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, []uint64{4}) {
+		t.Fatalf("unexpected bits: %+v", bits)
+	}
+}
+
+// Ensure string row & column keys are translated to ids.
+func TestExecutor_Execute_KeyTranslator(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	if _, err := index.CreateFrame("f", pilosa.FrameOptions{InverseEnabled: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	e.KeyTranslator = MapKeyTranslator{"f/eng": 10, "f/ops": 11, "/alice": 1, "/bob": SliceWidth + 2}
+
+	if _, err := e.Execute(context.Background(), "i", MustParse(`SetBit(rowID="eng", frame=f, columnID="alice") SetBit(rowID="eng", frame=f, columnID="bob") SetBit(rowID=11, frame=f, columnID=3)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if bits := hldr.Fragment("i", "f", pilosa.ViewStandard, 0).Row(10).Bits(); !reflect.DeepEqual(bits, []uint64{1}) {
+		t.Fatalf("unexpected bits: %+v", bits)
+	}
+
+	// Read rows & columns by key or by id.
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Bitmap(rowID="eng", frame=f)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, []uint64{1, SliceWidth + 2}) {
+		t.Fatalf("unexpected bits: %+v", bits)
+	}
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Count(Union(Bitmap(rowID="eng", frame=f), Bitmap(rowID="ops", frame=f)))`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{uint64(3)}) {
+		t.Fatalf("unexpected result: %s", spew.Sdump(res))
+	}
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Bitmap(columnID="bob", frame=f)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, []uint64{10}) {
+		t.Fatalf("unexpected bits: %+v", bits)
+	}
+
+	// Clear a bit by key.
+	if _, err := e.Execute(context.Background(), "i", MustParse(`ClearBit(rowID="eng", frame=f, columnID="alice")`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if bits := hldr.Fragment("i", "f", pilosa.ViewStandard, 0).Row(10).Bits(); len(bits) != 0 {
+		t.Fatalf("unexpected bits: %+v", bits)
+	}
+
+	// Unknown keys return the translator's error.
+	if _, err := e.Execute(context.Background(), "i", MustParse(`Bitmap(rowID="sales", frame=f)`), nil, nil); err == nil || err.Error() != `Bitmap() cannot translate rowID key "sales": key not found` {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure result ids can be translated back to keys.
+func TestExecutor_Execute_TranslateKeys(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	if _, err := index.CreateFrame("f", pilosa.FrameOptions{InverseEnabled: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	e.KeyTranslator = MapKeyTranslator{"f/eng": 10, "f/ops": 11, "/alice": 1, "/bob": SliceWidth + 2}
+	if _, err := e.Execute(context.Background(), "i", MustParse(`SetBit(rowID="eng", frame=f, columnID="alice") SetBit(rowID="eng", frame=f, columnID="bob") SetBit(rowID="ops", frame=f, columnID="bob")`), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	opt := &pilosa.ExecOptions{TranslateKeys: true}
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Bitmap(rowID="eng", frame=f)`), nil, opt); err != nil {
+		t.Fatal(err)
+	} else if keys := res[0].(*pilosa.Bitmap).Keys; !reflect.DeepEqual(keys, []string{"alice", "bob"}) {
+		t.Fatalf("unexpected keys: %+v", keys)
+	}
+
+	// Inverse results contain row ids.
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Bitmap(columnID="bob", frame=f)`), nil, opt); err != nil {
+		t.Fatal(err)
+	} else if keys := res[0].(*pilosa.Bitmap).Keys; !reflect.DeepEqual(keys, []string{"eng", "ops"}) {
+		t.Fatalf("unexpected keys: %+v", keys)
+	}
+
+	if res, err := e.Execute(context.Background(), "i", MustParse(`TopN(frame=f, n=2)`), nil, opt); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res[0], []pilosa.Pair{{ID: 10, Key: "eng", Count: 2}, {ID: 11, Key: "ops", Count: 1}}) {
+		t.Fatalf("unexpected pairs: %s", spew.Sdump(res))
+	}
+
+	// Keys are not attached unless requested.
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Bitmap(rowID="eng", frame=f)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if keys := res[0].(*pilosa.Bitmap).Keys; keys != nil {
+		t.Fatalf("unexpected keys: %+v", keys)
+	}
+
+	// Results over the limit are not translated.
+	e.MaxTranslateIDsN = 1
+	if _, err := e.Execute(context.Background(), "i", MustParse(`Bitmap(rowID="eng", frame=f)`), nil, opt); err != pilosa.ErrTooManyTranslateIDs {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A translator is required.
+	e.KeyTranslator = nil
+	if _, err := e.Execute(context.Background(), "i", MustParse(`Bitmap(rowID=10, frame=f)`), nil, opt); err != pilosa.ErrKeyTranslatorRequired {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure all missing frames are reported before any call is executed.
+func TestExecutor_Execute_MissingFrames(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0)
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	_, err := e.Execute(context.Background(), "i", MustParse(`SetBit(rowID=10, frame=f, columnID=1) Bitmap(rowID=10, frame=y) Count(Union(Bitmap(rowID=10, frame=x), Bitmap(rowID=10, frame=y)))`), nil, nil)
+	if err, ok := err.(*pilosa.FramesNotFoundError); !ok {
+		t.Fatalf("unexpected error: %v", err)
+	} else if !reflect.DeepEqual(err.Frames, []string{"x", "y"}) {
+		t.Fatalf("unexpected frames: %v", err.Frames)
+	} else if err.Error() != "frames not found: x, y" {
+		t.Fatalf("unexpected error message: %s", err)
+	}
+
+	// The mutation before the missing frames is not applied.
+	if bits := hldr.Fragment("i", "f", pilosa.ViewStandard, 0).Row(10).Bits(); len(bits) != 0 {
+		t.Fatalf("unexpected bits: %+v", bits)
+	}
+
+	// A single missing frame returns ErrFrameNotFound.
+	if _, err := e.Execute(context.Background(), "i", MustParse(`SetBit(rowID=10, frame=f, columnID=1) SetBit(rowID=10, frame=x, columnID=1)`), nil, nil); err != pilosa.ErrFrameNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	} else if bits := hldr.Fragment("i", "f", pilosa.ViewStandard, 0).Row(10).Bits(); len(bits) != 0 {
+		t.Fatalf("unexpected bits: %+v", bits)
+	}
+}
+
+// Ensure list-valued row attributes can be set and read back.
+func TestExecutor_Execute_SetRowAttrs_List(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 1)
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+
+	// Set using both the bulk and the single call paths.
+	if _, err := e.Execute(context.Background(), "i", MustParse(`SetRowAttrs(rowID=10, frame=f, tags=["a","b"])`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if _, err := e.Execute(context.Background(), "i", MustParse(`SetRowAttrs(rowID=10, frame=f, matrix=[[1,2],[3]]) Count(Bitmap(rowID=10, frame=f))`), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Bitmap(rowID=10, frame=f)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if attrs := res[0].(*pilosa.Bitmap).Attrs; !reflect.DeepEqual(attrs, map[string]interface{}{
+		"tags":   []interface{}{"a", "b"},
+		"matrix": []interface{}{[]interface{}{int64(1), int64(2)}, []interface{}{int64(3)}},
+	}) {
+		t.Fatalf("unexpected attrs: %#v", attrs)
+	}
+
+	// Mixed-type lists are rejected without writing any attributes.
+	for _, q := range []string{
+		`SetRowAttrs(rowID=11, frame=f, x=1, tags=["a",1])`,
+		`SetRowAttrs(rowID=11, frame=f, x=1, tags=[["a"],"b"]) Count(Bitmap(rowID=10, frame=f))`,
+	} {
+		if _, err := e.Execute(context.Background(), "i", MustParse(q), nil, nil); err == nil || !strings.Contains(err.Error(), "mixed types") {
+			t.Fatalf("%s: unexpected error: %v", q, err)
+		}
+	}
+	if attrs, err := hldr.Frame("i", "f").RowAttrStore().Attrs(11); err != nil {
+		t.Fatal(err)
+	} else if len(attrs) != 0 {
+		t.Fatalf("unexpected attrs: %#v", attrs)
+	}
+}
+
+// Ensure oversized row attributes are rejected without writing any attributes.
+func TestExecutor_Execute_SetRowAttrs_MaxSize(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFrameIfNotExists("i", "f")
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	e.MaxRowAttrSize = 8
+	e.MaxRowAttrsSize = 20
+
+	// Values within the limits are written by the single and the bulk paths.
+	if _, err := e.Execute(context.Background(), "i", MustParse(`SetRowAttrs(rowID=10, frame=f, a="wxyzwxyz", b=1)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if _, err := e.Execute(context.Background(), "i", MustParse(`SetRowAttrs(rowID=11, frame=f, a="wxyzwxyz") SetRowAttrs(rowID=11, frame=f, tags=["a","b"])`), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		q   string
+		err string
+	}{
+		{q: `SetRowAttrs(rowID=12, frame=f, a="vwxyzwxyz")`, err: `SetRowAttrs() attribute a exceeds the maximum size of 8 bytes`},
+		{q: `SetRowAttrs(rowID=12, frame=f, tags=["abcde","fghi"])`, err: `SetRowAttrs() attribute tags exceeds the maximum size of 8 bytes`},
+		{q: `SetRowAttrs(rowID=12, frame=f, a="wxyzwxyz", b=1, c=true)`, err: `SetRowAttrs() attributes of row 12 exceed the maximum size of 20 bytes`},
+		{q: `SetRowAttrs(rowID=12, frame=f, abc="wxyzwxyz") SetRowAttrs(rowID=12, frame=f, bcd="wxyzwxyz")`, err: `SetRowAttrs() attributes of row 12 exceed the maximum size of 20 bytes`},
+		{q: `SetRowAttrs(rowID=13, frame=f, a=1) SetRowAttrs(rowID=12, frame=f, a="vwxyzwxyz")`, err: `SetRowAttrs() attribute a exceeds the maximum size of 8 bytes`},
+	} {
+		if _, err := e.Execute(context.Background(), "i", MustParse(tt.q), nil, nil); err == nil || err.Error() != tt.err {
+			t.Fatalf("%s: unexpected error: %v", tt.q, err)
+		}
+	}
+
+	for _, rowID := range []uint64{12, 13} {
+		if attrs, err := hldr.Frame("i", "f").RowAttrStore().Attrs(rowID); err != nil {
+			t.Fatal(err)
+		} else if len(attrs) != 0 {
+			t.Fatalf("unexpected attrs for row %d: %#v", rowID, attrs)
+		}
+	}
+}
+
+// Ensure the first failed attribute forward cancels the other remote nodes.
+func TestExecutor_Execute_Remote_BulkSetAttrs_Cancel(t *testing.T) {
+	for _, q := range []string{
+		`SetRowAttrs(rowID=10, frame=f, foo="bar") SetRowAttrs(rowID=11, frame=f, foo="baz")`,
+		`SetColumnAttrs(id=10, foo="bar") SetColumnAttrs(id=11, foo="baz")`,
+	} {
+		c := NewCluster(3)
+
+		// The first remote node fails immediately.
+		failing := NewServer()
+		failing.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+			return nil, errors.New("marker")
+		}
+		defer failing.Close()
+		c.Nodes[1].Host = failing.Host()
+
+		// The second remote node blocks until its request is cancelled.
+		cancelled := make(chan struct{})
+		done := make(chan struct{})
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ioutil.ReadAll(r.Body)
+			select {
+			case <-r.Context().Done():
+				close(cancelled)
+			case <-done:
+			}
+		}))
+		defer slow.Close()
+		defer close(done)
+		c.Nodes[2].Host = MustParseURLHost(slow.URL)
+
+		hldr := MustOpenHolder()
+		defer hldr.Close()
+		if _, err := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{}).CreateFrameIfNotExists("f", pilosa.FrameOptions{}); err != nil {
+			t.Fatal(err)
+		}
+
+		e := NewExecutor(hldr.Holder, c)
+		if _, err := e.Execute(context.Background(), "i", MustParse(q), nil, nil); err == nil || !strings.Contains(err.Error(), "marker") {
+			t.Fatalf("%s: unexpected error: %v", q, err)
+		}
+
+		select {
+		case <-cancelled:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s: expected remote request to be cancelled", q)
+		}
+	}
+}
+
+// Ensure a forwarded query returns an error if the executor's host is not in the cluster.
+func TestExecutor_Execute_Remote_ErrUnknownHost(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 1)
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	e.Host = "no_such_host"
+	if _, err := e.Execute(context.Background(), "i", MustParse(`Count(Bitmap(rowID=10, frame=f))`), []uint64{0}, &pilosa.ExecOptions{Remote: true}); err == nil || err.Error() != `executor host not found in cluster: no_such_host` {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure a count of a range query over overlapping views counts each column once.
+func TestExecutor_Execute_Count_Range_Overlapping(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	index := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{})
+	f, err := index.CreateFrameIfNotExists("f", pilosa.FrameOptions{})
+	if err != nil {
+		t.Fatal(err)
+	} else if err := f.SetTimeQuantum(pilosa.TimeQuantum("H")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each hour overlaps the others, with both array & bitmap containers.
+	var even, low []uint64
+	for i := uint64(0); i < 10000; i++ {
+		if i%2 == 0 {
+			even = append(even, i)
+		}
+		if i < 5000 {
+			low = append(low, i)
+		}
+	}
+	hldr.MustCreateFragmentIfNotExists("i", "f", "standard_2000010100", 0).MustSetBits(1, even...)
+	hldr.MustCreateFragmentIfNotExists("i", "f", "standard_2000010101", 0).MustSetBits(1, low...)
+	hldr.MustCreateFragmentIfNotExists("i", "f", "standard_2000010102", 0).MustSetBits(1, 1, 9999, 70000)
+	hldr.MustCreateFragmentIfNotExists("i", "f", "standard_2000010102", 1).MustSetBits(1, SliceWidth+1)
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	rng := `Range(rowID=1, frame=f, start="2000-01-01T00:00", end="2000-01-01T03:00")`
+	if res, err := e.Execute(context.Background(), "i", MustParse(rng+" Count("+rng+")"), []uint64{0, 1}, nil); err != nil {
+		t.Fatal(err)
+	} else if exp := uint64(len(res[0].(*pilosa.Bitmap).Bits())); exp != 7503 {
+		t.Fatalf("unexpected bits: %d", exp)
+	} else if n := res[1].(uint64); n != exp {
+		t.Fatalf("unexpected count: %d, expected %d", n, exp)
+	}
+}
+
+// Ensure a remote query can return a bitmap.
+func TestExecutor_Execute_Remote_Bitmap(t *testing.T) {
+	c := NewCluster(2)
+
+	// Create secondary server and update second cluster node.
+	s := NewServer()
+	defer s.Close()
+	c.Nodes[1].Host = s.Host()
+
+	// Mock secondary server's executor to verify arguments and return a bitmap.
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		if index != "i" {
+			t.Fatalf("unexpected index: %s", index)
+		} else if query.String() != `Bitmap(frame="f", rowID=10)` {
+			t.Fatalf("unexpected query: %s", query.String())
+		} else if !reflect.DeepEqual(slices, []uint64{1}) {
+			t.Fatalf("unexpected slices: %+v", slices)
+		}
+
+		// Set bits in slice 0 & 2.
+		bm := pilosa.NewBitmap(
+			(0*SliceWidth)+1,
+			(0*SliceWidth)+2,
+			(2*SliceWidth)+4,
+		)
+		return []interface{}{bm}, nil
+	}
+
+	// Create local executor data.
+	// The local node owns slice 1.
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(10, (1*SliceWidth)+1)
+
+	e := NewExecutor(hldr.Holder, c)
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Bitmap(rowID=10, frame=f)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, []uint64{1, 2, 2*SliceWidth + 4}) {
+		t.Fatalf("unexpected bits: %+v", bits)
+	}
+}
+
+// Ensure a remote query is forwarded with the coordinator's deadline.
+func TestExecutor_Execute_Remote_Deadline(t *testing.T) {
+	deadline := time.Now().Add(time.Minute)
+
+	// Verify the remote node executes with the forwarded deadline.
+	var header string
+	h := NewHandler()
+	h.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		if d, ok := ctx.Deadline(); !ok {
+			t.Fatal("expected deadline")
+		} else if !d.Equal(deadline) {
+			t.Fatalf("unexpected deadline: %s, expected %s", d, deadline)
+		}
+		return []interface{}{pilosa.NewBitmap()}, nil
+	}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get(pilosa.QueryDeadlineHeader)
+		h.ServeHTTP(w, r)
+	}))
+	defer s.Close()
+
+	c := NewCluster(2)
+	c.Nodes[1].Host = MustParseURLHost(s.URL)
+
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 1)
+
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	e := NewExecutor(hldr.Holder, c)
+	if _, err := e.Execute(ctx, "i", MustParse(`Bitmap(rowID=10, frame=f)`), []uint64{1}, nil); err != nil {
+		t.Fatal(err)
+	} else if d, err := time.Parse(time.RFC3339Nano, header); err != nil {
+		t.Fatalf("unexpected deadline header %q: %s", header, err)
+	} else if !d.Equal(deadline) {
+		t.Fatalf("unexpected deadline header: %s, expected %s", d, deadline)
+	}
+}
+
+// Ensure malformed remote responses return an error instead of panicking.
+func TestExecutor_Execute_Remote_MalformedResponse(t *testing.T) {
+	marshal := func(pb *internal.QueryResponse) []byte {
+		buf, err := proto.Marshal(pb)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return buf
+	}
+	full := marshal(&internal.QueryResponse{Results: []*internal.QueryResult{{Bitmap: &internal.Bitmap{Bits: []uint64{SliceWidth + 1}}}}})
+
+	for _, tt := range []struct {
+		name string
+		body []byte
+		err  string
+	}{
+		{name: "Truncated", body: full[:len(full)-1], err: "unexpected EOF"},
+		{name: "Fewer", body: marshal(&internal.QueryResponse{}), err: "unexpected result count: 0, expected 1"},
+		{name: "Extra", body: marshal(&internal.QueryResponse{Results: []*internal.QueryResult{{}, {}}}), err: "unexpected result count: 2, expected 1"},
+		{name: "NoBitmap", body: marshal(&internal.QueryResponse{Results: []*internal.QueryResult{{N: 1}}}), err: "invalid Bitmap() result: bitmap missing"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/x-protobuf")
+				w.Write(tt.body)
+			}))
+			defer s.Close()
+
+			c := NewCluster(2)
+			c.Nodes[1].Host = MustParseURLHost(s.URL)
+
+			hldr := MustOpenHolder()
+			defer hldr.Close()
+			if _, err := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{}).CreateFrameIfNotExists("f", pilosa.FrameOptions{}); err != nil {
+				t.Fatal(err)
+			}
+
+			e := NewExecutor(hldr.Holder, c)
+			_, err := e.Execute(context.Background(), "i", MustParse(`Bitmap(rowID=10, frame=f)`), []uint64{1}, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+
+	// A well-formed response decodes.
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(full)
+	}))
+	defer s.Close()
+	c := NewCluster(2)
+	c.Nodes[1].Host = MustParseURLHost(s.URL)
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	if _, err := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{}).CreateFrameIfNotExists("f", pilosa.FrameOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := NewExecutor(hldr.Holder, c).Execute(context.Background(), "i", MustParse(`Bitmap(rowID=10, frame=f)`), []uint64{1}, nil); err != nil {
+		t.Fatal(err)
+	} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, []uint64{SliceWidth + 1}) {
+		t.Fatalf("unexpected bits: %+v", bits)
+	}
+}
+
+// Ensure all slices are sent to a pinned node regardless of ownership.
+func TestExecutor_Execute_Remote_PinNode(t *testing.T) {
+	c := NewCluster(2)
+
+	// Create secondary server and update second cluster node.
+	s := NewServer()
+	defer s.Close()
+	c.Nodes[1].Host = s.Host()
+
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		if !reflect.DeepEqual(slices, []uint64{0, 1, 2, 3}) {
+			t.Fatalf("unexpected slices: %+v", slices)
+		}
+		return []interface{}{uint64(10)}, nil
+	}
+
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 1)
+
+	e := NewExecutor(hldr.Holder, c)
+	res, stats, err := e.ExecuteWithStats(context.Background(), "i", MustParse(`Count(Bitmap(rowID=10, frame=f))`), []uint64{0, 1, 2, 3}, &pilosa.ExecOptions{PinNode: s.Host()})
+	if err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{uint64(10)}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res))
+	} else if cs := stats.Calls[0]; len(cs.LocalSlices) != 0 || !reflect.DeepEqual(cs.RemoteSlices, map[string][]uint64{s.Host(): {0, 1, 2, 3}}) {
+		t.Fatalf("unexpected stats: %s", spew.Sdump(cs))
+	}
+
+	// The pinned host must be in the cluster.
+	if _, err := e.Execute(context.Background(), "i", MustParse(`Count(Bitmap(rowID=10, frame=f))`), nil, &pilosa.ExecOptions{PinNode: "no-such-host"}); err == nil || err.Error() != "pinned node not found in cluster: no-such-host" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure a remote query can return a count.
+func TestExecutor_Execute_Remote_Count(t *testing.T) {
+	c := NewCluster(2)
+
+	// Create secondary server and update second cluster node.
+	s := NewServer()
+	defer s.Close()
+	c.Nodes[1].Host = s.Host()
+
+	// Mock secondary server's executor to return a count.
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		return []interface{}{uint64(10)}, nil
+	}
+
+	// Create local executor data. The local node owns slice 1.
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 2).MustSetBits(10, (2*SliceWidth)+1)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 2).MustSetBits(10, (2*SliceWidth)+2)
+
+	e := NewExecutor(hldr.Holder, c)
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Count(Bitmap(rowID=10, frame=f))`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if res[0] != uint64(12) {
+		t.Fatalf("unexpected n: %d", res[0])
+	}
+}
+
+// Ensure the universe of a count query includes remote slices.
+func TestExecutor_Execute_Remote_Count_IncludeUniverse(t *testing.T) {
+	c := NewCluster(2)
+
+	// Create secondary server and update second cluster node.
+	s := NewServer()
+	defer s.Close()
+	c.Nodes[1].Host = s.Host()
+
+	var remoteQueries []string
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		remoteQueries = append(remoteQueries, query.String())
+		if query.Calls[0].Name == "CountColumns" {
+			return []interface{}{uint64(20)}, nil
+		}
+		return []interface{}{uint64(10)}, nil
+	}
+
+	// The local node owns slice 0.
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 1, 2)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(11, 2, 3)
+
+	e := NewExecutor(hldr.Holder, c)
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Count(Bitmap(rowID=10, frame=f))`), []uint64{0, 1}, &pilosa.ExecOptions{IncludeUniverse: true}); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{&pilosa.CountResult{Count: 12, Universe: 23}}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res))
+	} else if !reflect.DeepEqual(remoteQueries, []string{`Count(Bitmap(frame="f", rowID=10))`, `CountColumns(frames=["f"])`}) {
+		t.Fatalf("unexpected remote queries: %v", remoteQueries)
+	}
+}
+
+// Ensure a remote query can set bits on multiple nodes.
+func TestExecutor_Execute_Remote_SetBit(t *testing.T) {
+	c := NewCluster(2)
+	c.ReplicaN = 2
+
+	// Create secondary server and update second cluster node.
+	s := NewServer()
+	defer s.Close()
+	c.Nodes[1].Host = s.Host()
+
+	// Mock secondary server's executor to verify arguments.
+	var remoteCalled bool
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		if index != `i` {
+			t.Fatalf("unexpected index: %s", index)
+		} else if query.String() != `SetBit(columnID=2, frame="f", rowID=10)` {
+			t.Fatalf("unexpected query: %s", query.String())
+		}
+		remoteCalled = true
+		return []interface{}{nil}, nil
+	}
+
+	// Create local executor data.
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	// Create frame.
+	if _, err := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{}).CreateFrame("f", pilosa.FrameOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewExecutor(hldr.Holder, c)
+	if _, err := e.Execute(context.Background(), "i", MustParse(`SetBit(rowID=10, frame=f, columnID=2)`), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Verify that one bit is set on both node's holder.
+	if n := hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).Row(10).Count(); n != 1 {
+		t.Fatalf("unexpected local count: %d", n)
+	}
+	if !remoteCalled {
+		t.Fatalf("expected remote execution")
+	}
+}
+
+// Ensure a remote query can set bits on multiple nodes.
+func TestExecutor_Execute_Remote_SetBit_With_Timestamp(t *testing.T) {
+	c := NewCluster(2)
+	c.ReplicaN = 2
+
+	// Create secondary server and update second cluster node.
+	s := NewServer()
+	defer s.Close()
+	c.Nodes[1].Host = s.Host()
+
+	// Mock secondary server's executor to verify arguments.
+	var remoteCalled bool
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		if index != `i` {
+			t.Fatalf("unexpected index: %s", index)
+		} else if query.String() != `SetBit(columnID=2, frame="f", rowID=10, timestamp="2016-12-11T10:09")` {
+			t.Fatalf("unexpected query: %s", query.String())
+		}
+		remoteCalled = true
+		return []interface{}{nil}, nil
+	}
+
+	// Create local executor data.
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	// Create frame.
+	if f, err := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{}).CreateFrame("f", pilosa.FrameOptions{}); err != nil {
+		t.Fatal(err)
+	} else if err := f.SetTimeQuantum("Y"); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewExecutor(hldr.Holder, c)
+	if _, err := e.Execute(context.Background(), "i", MustParse(`SetBit(rowID=10, frame=f, columnID=2, timestamp="2016-12-11T10:09")`), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Verify that one bit is set on both node's holder.
+	if n := hldr.MustCreateFragmentIfNotExists("i", "f", "standard_2016", 0).Row(10).Count(); n != 1 {
+		t.Fatalf("unexpected local count: %d", n)
+	}
+	if !remoteCalled {
+		t.Fatalf("expected remote execution")
+	}
+}
+
+// Ensure a remote query can return a top-n query.
+func TestExecutor_Execute_Remote_TopN(t *testing.T) {
+	c := NewCluster(2)
+
+	// Create secondary server and update second cluster node.
+	s := NewServer()
+	defer s.Close()
+	c.Nodes[1].Host = s.Host()
+
+	// Mock secondary server's executor to verify arguments and return a bitmap.
+	var remoteExecN int
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		if index != "i" {
+			t.Fatalf("unexpected index: %s", index)
+		} else if !reflect.DeepEqual(slices, []uint64{1, 3}) {
+			t.Fatalf("unexpected slices: %+v", slices)
+		}
+
+		// Query should be executed twice. Once to get the top bitmaps for the
+		// slices and a second time to get the counts for a set of bitmaps.
+		switch remoteExecN {
+		case 0:
+			if query.String() != `TopN(frame="f", n=3)` {
+				t.Fatalf("unexpected query(0): %s", query.String())
+			}
+		case 1:
+			if query.String() != `TopN(frame="f", ids=[0,10,30], n=3)` {
+				t.Fatalf("unexpected query(1): %s", query.String())
+			}
+		default:
+			t.Fatalf("too many remote exec calls")
+		}
+		remoteExecN++
+
+		// Return pair counts.
+		return []interface{}{[]pilosa.Pair{
+			{ID: 0, Count: 5},
+			{ID: 10, Count: 2},
+			{ID: 30, Count: 2},
+		}}, nil
+	}
+
+	// Create local executor data on slice 2 & 4.
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 2).MustSetBits(30, (2*SliceWidth)+1)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 4).MustSetBits(30, (4*SliceWidth)+2)
+
+	e := NewExecutor(hldr.Holder, c)
+	if res, err := e.Execute(context.Background(), "i", MustParse(`TopN(frame=f, n=3)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{[]pilosa.Pair{
+		{ID: 0, Count: 5},
+		{ID: 30, Count: 4},
+		{ID: 10, Count: 2},
+	}}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res))
+	}
+}
+
+// Ensure a query can be executed on a single node and all result types are decoded.
+func TestExecutor_ExecuteOnNode(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		if index != "i" {
+			t.Fatalf("unexpected index: %s", index)
+		} else if !reflect.DeepEqual(slices, []uint64{2}) {
+			t.Fatalf("unexpected slices: %+v", slices)
+		} else if !opt.Remote {
+			t.Fatal("expected remote execution")
+		}
+		return []interface{}{
+			pilosa.NewBitmap(1, 2),
+			uint64(3),
+			[]pilosa.Pair{{ID: 1, Count: 2}},
+			pilosa.NewBitmap(),
+			true,
+			nil,
+			nil,
+		}, nil
+	}
+
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	res, err := e.ExecuteOnNode(context.Background(), &pilosa.Node{Host: s.Host()}, "i", MustParse(``+
+		`Bitmap(rowID=1, frame=f) `+
+		`Count(Bitmap(rowID=1, frame=f)) `+
+		`TopN(frame=f, n=1) Union() `+
+		`SetBit(rowID=1, frame=f, columnID=1) `+
+		`SetRowAttrs(rowID=1, frame=f, x=1) `+
+		`SetColumnAttrs(id=1, x=1)`,
+	), []uint64{2}, nil)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(res) != 7 {
+		t.Fatalf("unexpected result count: %d", len(res))
+	}
+
+	if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, []uint64{1, 2}) {
+		t.Fatalf("unexpected bits: %+v", bits)
+	} else if !reflect.DeepEqual(res[1:3], []interface{}{uint64(3), []pilosa.Pair{{ID: 1, Count: 2}}}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res[1:3]))
+	} else if bits := res[3].(*pilosa.Bitmap).Bits(); len(bits) != 0 {
+		t.Fatalf("unexpected union bits: %+v", bits)
+	} else if !reflect.DeepEqual(res[4:], []interface{}{true, nil, nil}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res[4:]))
+	}
+}
+
+// Ensure remote errors are classified as transport or query errors.
+func TestExecutor_ExecuteOnNode_Errors(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+
+	t.Run("Status", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "marker", http.StatusInternalServerError)
+		}))
+		defer s.Close()
+
+		_, err := e.ExecuteOnNode(context.Background(), &pilosa.Node{Host: MustParseURLHost(s.URL)}, "i", MustParse(`Count(Bitmap(rowID=1))`), nil, nil)
+		if err, ok := err.(*pilosa.RemoteTransportError); !ok {
+			t.Fatalf("unexpected error: %#v", err)
+		} else if !strings.Contains(err.Error(), "code=500") {
+			t.Fatalf("unexpected error message: %s", err)
+		}
+	})
+
+	t.Run("Unreachable", func(t *testing.T) {
+		s := httptest.NewServer(http.NotFoundHandler())
+		host := MustParseURLHost(s.URL)
+		s.Close()
+
+		_, err := e.ExecuteOnNode(context.Background(), &pilosa.Node{Host: host}, "i", MustParse(`Count(Bitmap(rowID=1))`), nil, nil)
+		if _, ok := err.(*pilosa.RemoteTransportError); !ok {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("Query", func(t *testing.T) {
+		s := NewServer()
+		defer s.Close()
+		s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+			return nil, pilosa.ErrFrameNotFound
+		}
+
+		_, err := e.ExecuteOnNode(context.Background(), &pilosa.Node{Host: s.Host()}, "i", MustParse(`Count(Bitmap(rowID=1))`), nil, nil)
+		if err, ok := err.(*pilosa.RemoteQueryError); !ok {
+			t.Fatalf("unexpected error: %#v", err)
+		} else if err.Error() != pilosa.ErrFrameNotFound.Error() {
+			t.Fatalf("unexpected error message: %s", err)
+		}
+	})
+}
+
+// Ensure the slices visited by each call are reported.
+func TestExecutor_ExecuteWithStats(t *testing.T) {
+	c := NewCluster(2)
+
+	// Create secondary server and update second cluster node.
+	s := NewServer()
+	defer s.Close()
+	c.Nodes[1].Host = s.Host()
+
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		return []interface{}{uint64(10)}, nil
+	}
+
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 1)
+
+	e := NewExecutor(hldr.Holder, c)
+	res, stats, err := e.ExecuteWithStats(context.Background(), "i", MustParse(`Count(Bitmap(rowID=10, frame=f)) SetColumnAttrs(id=1, x=1)`), []uint64{4, 0, 3, 2}, nil)
+	if err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{uint64(11), nil}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res))
+	}
+
+	if len(stats.Calls) != 2 {
+		t.Fatalf("unexpected call stats: %s", spew.Sdump(stats))
+	} else if cs := stats.Calls[0]; !reflect.DeepEqual(cs.LocalSlices, []uint64{0, 2, 4}) {
+		t.Fatalf("unexpected local slices: %+v", cs.LocalSlices)
+	} else if !reflect.DeepEqual(cs.RemoteSlices, map[string][]uint64{s.Host(): {3}}) {
+		t.Fatalf("unexpected remote slices: %+v", cs.RemoteSlices)
+	} else if !reflect.DeepEqual(cs.SliceHosts, map[uint64]string{0: c.Nodes[0].Host, 2: c.Nodes[0].Host, 3: s.Host(), 4: c.Nodes[0].Host}) {
+		t.Fatalf("unexpected slice hosts: %+v", cs.SliceHosts)
+	} else if cs := stats.Calls[1]; len(cs.LocalSlices) != 0 || len(cs.RemoteSlices) != 0 {
+		t.Fatalf("unexpected SetColumnAttrs() stats: %s", spew.Sdump(cs))
+	}
+}
+
+// Ensure the result sizes of each call are recorded in the stats.
+func TestExecutor_ExecuteWithStats_ResultN(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(0, 0, 1)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(0, SliceWidth, SliceWidth+2)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 0)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(10, SliceWidth)
+	hldr.MustCreateFragmentIfNotExists("i", "g", pilosa.ViewStandard, 0).MustSetBits(30, 2)
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	res, stats, err := e.ExecuteWithStats(context.Background(), "i", MustParse(`Union(Bitmap(rowID=0, frame=f), Bitmap(rowID=30, frame=g)) TopN(frame=f, n=2)`), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(stats.Calls) != 2 {
+		t.Fatalf("unexpected call stats: %s", spew.Sdump(stats))
+	}
+
+	// Union() returns 3 columns from slice 0 and 2 from slice 1.
+	if n := res[0].(*pilosa.Bitmap).Count(); n != 5 {
+		t.Fatalf("unexpected union count: %d", n)
+	} else if cs := stats.Calls[0]; cs.ResultN != 5 || cs.MaxSliceResultN != 3 {
+		t.Fatalf("unexpected union stats: %s", spew.Sdump(cs))
+	}
+
+	// TopN() returns two pairs and each slice ranks two rows.
+	if n := len(res[1].([]pilosa.Pair)); n != 2 {
+		t.Fatalf("unexpected pair count: %d", n)
+	} else if cs := stats.Calls[1]; cs.ResultN != 2 || cs.MaxSliceResultN != 2 {
+		t.Fatalf("unexpected TopN() stats: %s", spew.Sdump(cs))
+	}
+}
+
+// Ensure slices without a fragment are counted separately from empty rows.
+func TestExecutor_ExecuteWithStats_MissingSliceN(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(0, 1)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(5, SliceWidth+1)
+	hldr.MustCreateFragmentIfNotExists("i", "g", pilosa.ViewStandard, 2).MustSetBits(0, (2*SliceWidth)+1)
+
+	// Slice 1 has an empty row and slice 2 has no fragment for frame f.
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	res, stats, err := e.ExecuteWithStats(context.Background(), "i", MustParse(`Bitmap(rowID=0, frame=f) Bitmap(rowID=0, frame=g)`), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, []uint64{1}) {
+		t.Fatalf("unexpected bits: %+v", bits)
+	} else if n := stats.Calls[0].MissingSliceN; n != 1 {
+		t.Fatalf("unexpected missing slices for f: %d", n)
+	} else if n := stats.Calls[1].MissingSliceN; n != 2 {
+		t.Fatalf("unexpected missing slices for g: %d", n)
+	}
+}
+
+// Ensure conditions which do not fail a query are returned as warnings.
+func TestExecutor_ExecuteWithWarnings(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	// Row 1 dominates the first slices so TopN() is decided early.
+	for slice := uint64(0); slice < 4; slice++ {
+		n := uint64(1)
+		if slice < 2 {
+			n = 50
+		}
+		for i := uint64(0); i < n; i++ {
+			hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, slice).MustSetBits(1, (slice*SliceWidth)+i)
+		}
+	}
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	e.TopNEarlyTerminationBatchN = 2
+
+	// A missing frame read as empty is reported for its call.
+	opt := &pilosa.ExecOptions{TreatMissingAsEmpty: true}
+	if res, err := e.ExecuteWithWarnings(context.Background(), "i", MustParse(`Count(Bitmap(rowID=1, frame=f)) Count(Bitmap(rowID=1, frame=x))`), nil, opt); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res.Results, []interface{}{uint64(102), uint64(0)}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res.Results))
+	} else if !reflect.DeepEqual(res.Warnings, []pilosa.Warning{{
+		Code:    pilosa.WarningMissingFrame,
+		Message: "frame not found, read as empty: x",
+		Call:    1,
+		Context: map[string]interface{}{"frame": "x"},
+	}}) {
+		t.Fatalf("unexpected warnings: %s", spew.Sdump(res.Warnings))
+	}
+
+	// Skipped slices are reported for a partially ranked TopN().
+	if res, err := e.ExecuteWithWarnings(context.Background(), "i", MustParse(`TopN(frame=f, n=1)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res.Results, []interface{}{[]pilosa.Pair{{ID: 1, Count: 102}}}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res.Results))
+	} else if !reflect.DeepEqual(res.Warnings, []pilosa.Warning{{
+		Code:    pilosa.WarningTopNSlicesSkipped,
+		Message: "TopN() ranked rows from 2 of 4 slices",
+		Context: map[string]interface{}{"slices": 4, "skippedSlices": 2},
+	}}) {
+		t.Fatalf("unexpected warnings: %s", spew.Sdump(res.Warnings))
+	}
+
+	// Queries without warnings return none.
+	if res, err := e.ExecuteWithWarnings(context.Background(), "i", MustParse(`Count(Bitmap(rowID=1, frame=f))`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if res.Warnings != nil {
+		t.Fatalf("unexpected warnings: %s", spew.Sdump(res.Warnings))
+	}
+}
+
+// Ensure results with warnings are not cached.
+func TestExecutor_ExecuteWithWarnings_QueryCache(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	for slice := uint64(0); slice < 4; slice++ {
+		hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, slice).MustSetBits(1, slice*SliceWidth)
+	}
+	for i := uint64(1); i < 10; i++ {
+		hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(1, i)
+	}
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	e.TopNEarlyTerminationBatchN = 1
+	e.QueryCache = pilosa.NewQueryCache(10)
+	for i := 0; i < 2; i++ {
+		if res, err := e.ExecuteWithWarnings(context.Background(), "i", MustParse(`TopN(frame=f, n=1)`), nil, nil); err != nil {
+			t.Fatal(err)
+		} else if len(res.Warnings) != 1 {
+			t.Fatalf("%d. unexpected warnings: %s", i, spew.Sdump(res.Warnings))
+		}
+	}
+}
+
+// Ensure local slices which fail are skipped and reported if AllowPartial is set.
+func TestExecutor_Execute_AllowPartial(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	for slice := uint64(0); slice < 3; slice++ {
+		hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, slice).MustSetBits(10, (slice*SliceWidth)+1, (slice*SliceWidth)+2)
+	}
+
+	// Slice 1 fails as if its fragment were corrupted.
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	if err := e.RegisterAggregation("CountChecked", pilosa.Aggregation{
+		Map: func(ctx context.Context, index string, c *pql.Call, slice uint64, inputs []*pilosa.Bitmap) (interface{}, error) {
+			if slice == 1 {
+				return nil, errors.New("corrupt fragment")
+			}
+			return inputs[0].Count(), nil
+		},
+		Reduce: func(prev, v interface{}) interface{} {
+			n, _ := prev.(uint64)
+			return n + v.(uint64)
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	q := MustParse(`CountChecked(Bitmap(rowID=10, frame=f)) Count(Bitmap(rowID=10, frame=f))`)
+
+	// The error fails the query by default.
+	if _, err := e.Execute(context.Background(), "i", q, nil, nil); err == nil || err.Error() != "corrupt fragment" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The remaining slices are reduced and the failed slice is reported.
+	if res, err := e.ExecuteWithWarnings(context.Background(), "i", q, nil, &pilosa.ExecOptions{AllowPartial: true}); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res.Results, []interface{}{uint64(4), uint64(6)}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res.Results))
+	} else if !reflect.DeepEqual(res.Warnings, []pilosa.Warning{{
+		Code:    pilosa.WarningSlicesFailed,
+		Message: "1 of 3 local slices failed and were skipped",
+		Context: map[string]interface{}{"slices": []uint64{1}},
+	}}) {
+		t.Fatalf("unexpected warnings: %s", spew.Sdump(res.Warnings))
+	}
+}
+
+// Ensure LocalOnly executors never request slices from remote nodes.
+func TestExecutor_Execute_LocalOnly(t *testing.T) {
+	c := NewCluster(2)
+
+	// Create secondary server and update second cluster node.
+	s := NewServer()
+	defer s.Close()
+	c.Nodes[1].Host = s.Host()
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		t.Errorf("unexpected remote query: %s", query)
+		return []interface{}{uint64(0)}, nil
+	}
+
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	for slice := uint64(0); slice < 4; slice++ {
+		hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, slice).MustSetBits(10, (slice*SliceWidth)+1)
+	}
+
+	e := NewExecutor(hldr.Holder, c)
+	e.LocalOnly = true
+	q := MustParse(`Count(Bitmap(rowID=10, frame=f))`)
+
+	// Every slice is owned by the local node.
+	c.Hasher = NewConstHasher(0)
+	if res, stats, err := e.ExecuteWithStats(context.Background(), "i", q, nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{uint64(4)}) {
+		t.Fatalf("unexpected results: %v", res)
+	} else if cs := stats.Calls[0]; !reflect.DeepEqual(cs.LocalSlices, []uint64{0, 1, 2, 3}) || len(cs.RemoteSlices) != 0 {
+		t.Fatalf("unexpected stats: %s", spew.Sdump(cs))
+	}
+
+	// Slices owned by another node fail instead of being forwarded.
+	c.Hasher = NewConstHasher(1)
+	if _, err := e.Execute(context.Background(), "i", q, nil, nil); err == nil || err.Error() != `slice 0 is not owned by the local node: host0` {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure identical read calls are executed once when DedupCalls is set.
+func TestExecutor_Execute_DedupCalls(t *testing.T) {
+	c := NewCluster(2)
+
+	// Create secondary server and update second cluster node.
+	s := NewServer()
+	defer s.Close()
+	c.Nodes[1].Host = s.Host()
+
+	// The remote node counts its requests.
+	var remoteN int32
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		atomic.AddInt32(&remoteN, 1)
+		return []interface{}{uint64(10)}, nil
+	}
+
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 1, 2)
+
+	e := NewExecutor(hldr.Holder, c)
+	q := MustParse(`Count(Bitmap(rowID=10, frame=f)) Count(Bitmap(frame=f, rowID=10))`)
+	slices := []uint64{0, 1, 2, 3}
+
+	// Without the option both calls fan out.
+	if res, err := e.Execute(context.Background(), "i", q, slices, nil); err != nil {
+		t.Fatal(err)
+	} else if n := atomic.LoadInt32(&remoteN); n != 2 {
+		t.Fatalf("unexpected remote requests: %d", n)
+	} else if res[0] != res[1] {
+		t.Fatalf("unexpected results: %v", res)
+	}
+
+	atomic.StoreInt32(&remoteN, 0)
+	if res, err := e.Execute(context.Background(), "i", q, slices, &pilosa.ExecOptions{DedupCalls: true}); err != nil {
+		t.Fatal(err)
+	} else if n := atomic.LoadInt32(&remoteN); n != 1 {
+		t.Fatalf("unexpected remote requests: %d", n)
+	} else if !reflect.DeepEqual(res, []interface{}{uint64(12), uint64(12)}) {
+		t.Fatalf("unexpected results: %v", res)
+	}
+}
+
+// Ensure calls after a mutation are not deduplicated with earlier calls.
+func TestExecutor_Execute_DedupCalls_Mutation(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 1, 2)
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	q := MustParse(`Count(Bitmap(rowID=10, frame=f)) SetBit(frame=f, rowID=10, columnID=3) Count(Bitmap(rowID=10, frame=f)) Count(Bitmap(rowID=10, frame=f))`)
+	if res, err := e.Execute(context.Background(), "i", q, nil, &pilosa.ExecOptions{DedupCalls: true}); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{uint64(2), true, uint64(3), uint64(3)}) {
+		t.Fatalf("unexpected results: %v", res)
+	}
+}
+
+// Ensure queries can be restricted to an allowlist or skip a denylist of slices.
+func TestExecutor_Execute_ExcludeSlices(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	for slice := uint64(0); slice < 4; slice++ {
+		hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, slice).MustSetBits(10, (slice*SliceWidth)+1)
+	}
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	q := MustParse(`Bitmap(rowID=10, frame=f)`)
+	for _, tt := range []struct {
+		slices  []uint64
+		exclude []uint64
+		exp     []uint64
+	}{
+		{slices: []uint64{2}, exp: []uint64{(2 * SliceWidth) + 1}},
+		{exclude: []uint64{1}, exp: []uint64{1, (2 * SliceWidth) + 1, (3 * SliceWidth) + 1}},
+		{slices: []uint64{1, 2}, exclude: []uint64{1, 3}, exp: []uint64{(2 * SliceWidth) + 1}},
+		{slices: []uint64{1}, exclude: []uint64{1}, exp: []uint64{}},
+	} {
+		res, stats, err := e.ExecuteWithStats(context.Background(), "i", q, tt.slices, &pilosa.ExecOptions{ExcludeSlices: tt.exclude})
+		if err != nil {
+			t.Fatal(err)
+		} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, tt.exp) {
+			t.Fatalf("%v/%v: unexpected bits: %+v", tt.slices, tt.exclude, bits)
+		}
+
+		// Excluded slices are never visited.
+		for _, slice := range tt.exclude {
+			if _, ok := stats.Calls[0].SliceHosts[slice]; ok {
+				t.Fatalf("%v/%v: excluded slice visited: %d", tt.slices, tt.exclude, slice)
+			}
+		}
+	}
+}
+
+// Ensure OnSliceDone reports each slice once with the node which served it.
+func TestExecutor_Execute_OnSliceDone(t *testing.T) {
+	c := NewCluster(2)
+
+	// Create secondary server and update second cluster node.
+	s := NewServer()
+	defer s.Close()
+	c.Nodes[1].Host = s.Host()
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		return []interface{}{uint64(len(slices))}, nil
+	}
+
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFrameIfNotExists("i", "f")
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	hosts := make(map[uint64]string)
+	wg.Add(4)
+	opt := &pilosa.ExecOptions{OnSliceDone: func(slice uint64, node string) {
+		defer wg.Done()
+		mu.Lock()
+		defer mu.Unlock()
+		if _, ok := hosts[slice]; ok {
+			t.Errorf("slice reported twice: %d", slice)
+		}
+		hosts[slice] = node
+	}}
+
+	e := NewExecutor(hldr.Holder, c)
+	_, stats, err := e.ExecuteWithStats(context.Background(), "i", MustParse(`Count(Bitmap(rowID=10, frame=f))`), []uint64{0, 1, 2, 3}, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !reflect.DeepEqual(hosts, stats.Calls[0].SliceHosts) {
+		t.Fatalf("unexpected slice hosts: %+v, expected %+v", hosts, stats.Calls[0].SliceHosts)
+	}
+}
+
+// Ensure a call fails once merging its results exceeds the reduce timeout.
+func TestExecutor_Execute_ReduceTimeout(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 1, 2)
+
+	// Slices map instantly but every merge is slow.
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	if err := e.RegisterAggregation("SlowCount", pilosa.Aggregation{
+		Map: func(ctx context.Context, index string, c *pql.Call, slice uint64, inputs []*pilosa.Bitmap) (interface{}, error) {
+			return inputs[0].Count(), nil
+		},
+		Reduce: func(prev, v interface{}) interface{} {
+			time.Sleep(20 * time.Millisecond)
+			n, _ := prev.(uint64)
+			return n + v.(uint64)
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	q := MustParse(`SlowCount(Bitmap(rowID=10, frame=f))`)
+
+	e.ReduceTimeout = time.Millisecond
+	if _, err := e.Execute(context.Background(), "i", q, nil, nil); err != pilosa.ErrReduceTimeout {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Calls within the timeout are unaffected.
+	e.ReduceTimeout = time.Minute
+	if res, err := e.Execute(context.Background(), "i", q, nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{uint64(2)}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res))
+	}
+}
+
+// Ensure a query fails once it reads more fragments than its budget.
+func TestExecutor_Execute_MaxFragmentReads(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	for slice := uint64(0); slice < 4; slice++ {
+		hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, slice).MustSetBits(10, (slice*SliceWidth)+1)
+		hldr.MustCreateFragmentIfNotExists("i", "g", pilosa.ViewStandard, slice).MustSetBits(10, (slice*SliceWidth)+2)
+	}
+
+	// Each call reads one fragment per slice and the budget covers the query.
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	q := MustParse(`Count(Union(Bitmap(rowID=10, frame=f), Bitmap(rowID=10, frame=g))) Count(Bitmap(rowID=10, frame=f))`)
+	if res, err := e.Execute(context.Background(), "i", q, nil, &pilosa.ExecOptions{MaxFragmentReads: 12}); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{uint64(8), uint64(4)}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res))
+	}
+
+	for _, n := range []int{11, 7, 1} {
+		if _, err := e.Execute(context.Background(), "i", q, nil, &pilosa.ExecOptions{MaxFragmentReads: n}); err != pilosa.ErrFragmentReadBudgetExceeded {
+			t.Fatalf("%d: unexpected error: %v", n, err)
+		}
+	}
+}
+
+// Ensure the fragment read budget is forwarded to remote nodes.
+func TestExecutor_Execute_Remote_MaxFragmentReads(t *testing.T) {
+	c := NewCluster(2)
+
+	// Create secondary server and update second cluster node.
+	s := NewServer()
+	defer s.Close()
+	c.Nodes[1].Host = s.Host()
+
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		if opt.MaxFragmentReads != 5 {
+			t.Fatalf("unexpected budget: %d", opt.MaxFragmentReads)
+		}
+		return []interface{}{uint64(1)}, nil
+	}
+
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 1)
+
+	for _, format := range []string{pilosa.RemoteFormatProtobuf, pilosa.RemoteFormatJSON} {
+		e := NewExecutor(hldr.Holder, c)
+		e.RemoteFormat = format
+		if res, err := e.Execute(context.Background(), "i", MustParse(`Count(Bitmap(rowID=10, frame=f))`), []uint64{0, 1}, &pilosa.ExecOptions{MaxFragmentReads: 5}); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(res, []interface{}{uint64(2)}) {
+			t.Fatalf("%s: unexpected results: %s", format, spew.Sdump(res))
+		}
+	}
+}
+
+// Ensure an approximate TopN() query skips refetching exact counts.
+func TestExecutor_Execute_Remote_TopN_Approximate(t *testing.T) {
+	c := NewCluster(2)
+
+	// Create secondary server and update second cluster node.
+	s := NewServer()
+	defer s.Close()
+	c.Nodes[1].Host = s.Host()
+
+	var remoteExecN int
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		if query.String() != `TopN(frame="f", n=2)` {
+			t.Fatalf("unexpected query: %s", query.String())
+		}
+		remoteExecN++
+		return []interface{}{[]pilosa.Pair{
+			{ID: 0, Count: 5},
+			{ID: 10, Count: 2},
+		}}, nil
+	}
+
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 2).MustSetBits(30, (2*SliceWidth)+1, (2*SliceWidth)+2, (2*SliceWidth)+3)
+
+	e := NewExecutor(hldr.Holder, c)
+	if res, err := e.Execute(context.Background(), "i", MustParse(`TopN(frame=f, n=2)`), []uint64{1, 2}, &pilosa.ExecOptions{ApproximateTopN: true}); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{[]pilosa.Pair{
+		{ID: 0, Count: 5},
+		{ID: 30, Count: 3},
 	}}) {
 		t.Fatalf("unexpected results: %s", spew.Sdump(res))
+	} else if remoteExecN != 1 {
+		t.Fatalf("unexpected remote exec count: %d", remoteExecN)
+	}
+}
+
+// Ensure large remote TopN() results are streamed and match the batch response.
+func TestExecutor_Execute_Remote_TopN_Stream(t *testing.T) {
+	// Generate a large set of pairs.
+	pairs := make([]pilosa.Pair, 50000)
+	for i := range pairs {
+		pairs[i] = pilosa.Pair{ID: uint64(i), Count: uint64(len(pairs) - i)}
+	}
+
+	h := NewHandler()
+	h.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		return []interface{}{pairs}, nil
+	}
+
+	// Execute against a server with streaming enabled and disabled.
+	var results [][]interface{}
+	for _, stream := range []bool{true, false} {
+		var streamed bool
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !stream {
+				r.Header.Del(pilosa.StreamPairsHeader)
+			}
+			h.ServeHTTP(w, r)
+			streamed = w.Header().Get(pilosa.StreamPairsHeader) != ""
+		}))
+
+		c := NewCluster(2)
+		c.Nodes[1].Host = MustParseURLHost(s.URL)
+
+		hldr := MustOpenHolder()
+		if _, err := hldr.MustCreateIndexIfNotExists("i", pilosa.IndexOptions{}).CreateFrameIfNotExists("f", pilosa.FrameOptions{}); err != nil {
+			t.Fatal(err)
+		}
+
+		e := NewExecutor(hldr.Holder, c)
+		res, err := e.Execute(context.Background(), "i", MustParse(`TopN(frame=f)`), []uint64{1}, nil)
+		hldr.Close()
+		s.Close()
+		if err != nil {
+			t.Fatal(err)
+		} else if streamed != stream {
+			t.Fatalf("unexpected streamed (stream=%v): %v", stream, streamed)
+		}
+		results = append(results, res)
+	}
+
+	if !reflect.DeepEqual(results[0], []interface{}{pairs}) {
+		t.Fatalf("unexpected streamed results: n=%d", len(results[0][0].([]pilosa.Pair)))
+	} else if !reflect.DeepEqual(results[0], results[1]) {
+		t.Fatal("streamed results do not match batch results")
+	}
+}
+
+// Ensure slices are only retried on replicas after transport errors.
+func TestExecutor_Execute_Remote_Retry(t *testing.T) {
+	for _, transport := range []bool{true, false} {
+		c := NewCluster(3)
+		c.ReplicaN = 2
+
+		// The primary either fails to execute the query or is unreachable.
+		primary := NewServer()
+		primary.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+			return nil, pilosa.ErrFrameNotFound
+		}
+		defer primary.Close()
+		c.Nodes[1].Host = primary.Host()
+		if transport {
+			primary.Close()
+		}
+
+		var replicaExecN int
+		replica := NewServer()
+		replica.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+			replicaExecN++
+			return []interface{}{uint64(1)}, nil
+		}
+		defer replica.Close()
+		c.Nodes[2].Host = replica.Host()
+
+		hldr := MustOpenHolder()
+		defer hldr.Close()
+
+		// Slice 1 is owned by the primary & replica but not the local node.
+		if nodes := c.FragmentNodes("i", 1); !reflect.DeepEqual(nodes, []*pilosa.Node{c.Nodes[1], c.Nodes[2]}) {
+			t.Fatalf("unexpected fragment nodes: %s", spew.Sdump(nodes))
+		}
+
+		e := NewExecutor(hldr.Holder, c)
+		res, err := e.Execute(context.Background(), "i", MustParse(`Count(Bitmap(rowID=1, frame=f))`), []uint64{1}, nil)
+		if transport {
+			if err != nil {
+				t.Fatal(err)
+			} else if !reflect.DeepEqual(res, []interface{}{uint64(1)}) {
+				t.Fatalf("unexpected results: %s", spew.Sdump(res))
+			} else if replicaExecN != 1 {
+				t.Fatalf("unexpected replica exec count: %d", replicaExecN)
+			}
+		} else {
+			if _, ok := err.(*pilosa.RemoteQueryError); !ok {
+				t.Fatalf("unexpected error: %#v", err)
+			} else if replicaExecN != 0 {
+				t.Fatalf("unexpected replica exec count: %d", replicaExecN)
+			}
+		}
+	}
+}
+
+// Ensure slices are resplit away from a node which failed earlier in the query.
+func TestExecutor_Execute_Remote_FailedNode(t *testing.T) {
+	c := NewCluster(3)
+	c.ReplicaN = 2
+
+	// The primary is unavailable for every request.
+	var primaryN int64
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&primaryN, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+	c.Nodes[1].Host = MustParseURLHost(primary.URL)
+
+	replica := NewServer()
+	replica.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		return []interface{}{uint64(len(slices))}, nil
+	}
+	defer replica.Close()
+	c.Nodes[2].Host = replica.Host()
+
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	// Slice 1 is owned by the primary & replica but not the local node.
+	if nodes := c.FragmentNodes("i", 1); !reflect.DeepEqual(nodes, []*pilosa.Node{c.Nodes[1], c.Nodes[2]}) {
+		t.Fatalf("unexpected fragment nodes: %s", spew.Sdump(nodes))
+	}
+
+	e := NewExecutor(hldr.Holder, c)
+	res, stats, err := e.ExecuteWithStats(context.Background(), "i", MustParse(`Count(Bitmap(rowID=1, frame=f)) Count(Bitmap(rowID=2, frame=f))`), []uint64{1}, nil)
+	if err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{uint64(1), uint64(1)}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res))
+	} else if n := atomic.LoadInt64(&primaryN); n != 1 {
+		t.Fatalf("unexpected primary request count: %d", n)
+	}
+
+	// Neither call should report a slice as read by the failed primary.
+	for i, cs := range stats.Calls {
+		if _, ok := cs.RemoteSlices[c.Nodes[1].Host]; ok {
+			t.Fatalf("call %d: slices read by failed node: %s", i, spew.Sdump(cs.RemoteSlices))
+		} else if host := cs.SliceHosts[1]; host != c.Nodes[2].Host {
+			t.Fatalf("call %d: unexpected slice host: %s", i, host)
+		}
+	}
+}
+
+// Ensure a remote request which does not respond in time is retried on a replica.
+func TestExecutor_Execute_Remote_RequestTimeout(t *testing.T) {
+	c := NewCluster(3)
+	c.ReplicaN = 2
+
+	// The primary never responds until the test completes.
+	var primaryN int64
+	done := make(chan struct{})
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&primaryN, 1)
+		<-done
+	}))
+	defer primary.Close()
+	defer close(done)
+	c.Nodes[1].Host = MustParseURLHost(primary.URL)
+
+	replica := NewServer()
+	replica.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		return []interface{}{uint64(1)}, nil
+	}
+	defer replica.Close()
+	c.Nodes[2].Host = replica.Host()
+
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	// Slice 1 is owned by the primary & replica but not the local node.
+	e := NewExecutor(hldr.Holder, c)
+	e.RemoteRequestTimeout = 50 * time.Millisecond
+	if res, err := e.Execute(context.Background(), "i", MustParse(`Count(Bitmap(rowID=1, frame=f))`), []uint64{1}, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{uint64(1)}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res))
+	} else if n := atomic.LoadInt64(&primaryN); n != 1 {
+		t.Fatalf("unexpected primary request count: %d", n)
+	}
+}
+
+// Ensure a node which failed recently is skipped until the timeout expires.
+func TestExecutor_Execute_Remote_NodeDown(t *testing.T) {
+	c := NewCluster(3)
+	c.ReplicaN = 2
+
+	// The primary drops every connection without responding.
+	var primaryN, replicaN int64
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&primaryN, 1)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn.Close()
+	}))
+	defer primary.Close()
+	c.Nodes[1].Host = MustParseURLHost(primary.URL)
+
+	replica := NewServer()
+	replica.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		atomic.AddInt64(&replicaN, 1)
+		return []interface{}{uint64(1)}, nil
+	}
+	defer replica.Close()
+	c.Nodes[2].Host = replica.Host()
+
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	// Slice 1 is owned by the primary & replica but not the local node.
+	if nodes := c.FragmentNodes("i", 1); !reflect.DeepEqual(nodes, []*pilosa.Node{c.Nodes[1], c.Nodes[2]}) {
+		t.Fatalf("unexpected fragment nodes: %s", spew.Sdump(nodes))
+	}
+
+	e := NewExecutor(hldr.Holder, c)
+	e.NodeDownTimeout = 100 * time.Millisecond
+	for i, exp := range []struct{ primaryN, replicaN int64 }{
+		{1, 1}, // primary fails & is marked down
+		{1, 2}, // primary is skipped
+		{2, 3}, // primary is retried after the timeout
+	} {
+		if i == 2 {
+			time.Sleep(e.NodeDownTimeout)
+		}
+
+		if res, err := e.Execute(context.Background(), "i", MustParse(`Count(Bitmap(rowID=1, frame=f))`), []uint64{1}, nil); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(res, []interface{}{uint64(1)}) {
+			t.Fatalf("unexpected results: %s", spew.Sdump(res))
+		} else if p, r := atomic.LoadInt64(&primaryN), atomic.LoadInt64(&replicaN); p != exp.primaryN || r != exp.replicaN {
+			t.Fatalf("%d. unexpected exec counts: primary=%d, replica=%d", i, p, r)
+		}
+	}
+}
+
+// Ensure repeated failures open a node's circuit and a successful probe closes it.
+func TestExecutor_Execute_Remote_CircuitBreaker(t *testing.T) {
+	c := NewCluster(3)
+	c.ReplicaN = 2
+
+	// The primary drops every connection while failing.
+	var primaryN, replicaN, failing int64 = 0, 0, 1
+	h := NewHandler()
+	h.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		return []interface{}{uint64(1)}, nil
+	}
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&primaryN, 1)
+		if atomic.LoadInt64(&failing) == 0 {
+			h.ServeHTTP(w, r)
+			return
+		}
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn.Close()
+	}))
+	defer primary.Close()
+	c.Nodes[1].Host = MustParseURLHost(primary.URL)
+
+	replica := NewServer()
+	replica.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		atomic.AddInt64(&replicaN, 1)
+		return []interface{}{uint64(1)}, nil
+	}
+	defer replica.Close()
+	c.Nodes[2].Host = replica.Host()
+
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	e := NewExecutor(hldr.Holder, c)
+	e.NodeFailureThreshold = 2
+	e.NodeCircuitCooldown = 100 * time.Millisecond
+	for i, step := range []struct {
+		wait, fail         bool
+		primaryN, replicaN int64
+	}{
+		{primaryN: 1, replicaN: 1, fail: true},             // first failure
+		{primaryN: 2, replicaN: 2, fail: true},             // second failure opens the circuit
+		{primaryN: 2, replicaN: 3, fail: true},             // primary is skipped
+		{primaryN: 3, replicaN: 3, wait: true},             // probe succeeds and closes the circuit
+		{primaryN: 4, replicaN: 3},                         // primary is used
+		{primaryN: 5, replicaN: 4, fail: true},             // first failure
+		{primaryN: 6, replicaN: 5, fail: true},             // second failure reopens the circuit
+		{primaryN: 7, replicaN: 6, fail: true, wait: true}, // probe fails and reopens the circuit
+		{primaryN: 7, replicaN: 7, fail: true},             // primary is skipped
+	} {
+		if step.wait {
+			time.Sleep(e.NodeCircuitCooldown)
+		}
+		if step.fail {
+			atomic.StoreInt64(&failing, 1)
+		} else {
+			atomic.StoreInt64(&failing, 0)
+		}
+
+		if res, err := e.Execute(context.Background(), "i", MustParse(`Count(Bitmap(rowID=1, frame=f))`), []uint64{1}, nil); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(res, []interface{}{uint64(1)}) {
+			t.Fatalf("unexpected results: %s", spew.Sdump(res))
+		} else if p, r := atomic.LoadInt64(&primaryN), atomic.LoadInt64(&replicaN); p != step.primaryN || r != step.replicaN {
+			t.Fatalf("%d. unexpected exec counts: primary=%d, replica=%d", i, p, r)
+		}
+	}
+
+	// Open circuits fail immediately when no replica is available.
+	if _, err := e.ExecuteOnNode(context.Background(), c.Nodes[1], "i", MustParse(`Count(Bitmap(rowID=1, frame=f))`), []uint64{1}, nil); err == nil || !strings.Contains(err.Error(), pilosa.ErrCircuitOpen.Error()) {
+		t.Fatalf("unexpected error: %v", err)
+	} else if p := atomic.LoadInt64(&primaryN); p != 7 {
+		t.Fatalf("unexpected primary exec count: %d", p)
+	}
+}
+
+// Ensure a probe canceled by the caller does not leave the circuit open.
+func TestExecutor_Execute_Remote_CircuitBreaker_CanceledProbe(t *testing.T) {
+	c := NewCluster(2)
+
+	// The node drops connections, blocks until canceled or succeeds.
+	const (
+		modeFail = iota
+		modeBlock
+		modeOK
+	)
+	var mode int64 = modeFail
+	h := NewHandler()
+	h.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		return []interface{}{uint64(1)}, nil
+	}
+	done := make(chan struct{})
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.LoadInt64(&mode) {
+		case modeFail:
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Fatal(err)
+			}
+			conn.Close()
+		case modeBlock:
+			select {
+			case <-r.Context().Done():
+			case <-done:
+			}
+		default:
+			h.ServeHTTP(w, r)
+		}
+	}))
+	defer s.Close()
+	defer close(done)
+	c.Nodes[1].Host = MustParseURLHost(s.URL)
+
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	e := NewExecutor(hldr.Holder, c)
+	e.NodeFailureThreshold = 1
+	e.NodeCircuitCooldown = 50 * time.Millisecond
+	q := MustParse(`Count(Bitmap(rowID=1, frame=f))`)
+
+	// A failure opens the circuit.
+	if _, err := e.ExecuteOnNode(context.Background(), c.Nodes[1], "i", q, []uint64{1}, nil); err == nil {
+		t.Fatal("expected error")
+	}
+
+	// The probe is canceled by the caller once the cooldown elapses.
+	time.Sleep(e.NodeCircuitCooldown)
+	atomic.StoreInt64(&mode, modeBlock)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := e.ExecuteOnNode(ctx, c.Nodes[1], "i", q, []uint64{1}, nil); err == nil {
+		t.Fatal("expected error")
+	}
+
+	// The next request probes the node again and closes the circuit.
+	atomic.StoreInt64(&mode, modeOK)
+	for i := 0; i < 2; i++ {
+		if res, err := e.ExecuteOnNode(context.Background(), c.Nodes[1], "i", q, []uint64{1}, nil); err != nil {
+			t.Fatalf("%d. %s", i, err)
+		} else if !reflect.DeepEqual(res, []interface{}{uint64(1)}) {
+			t.Fatalf("unexpected results: %s", spew.Sdump(res))
+		}
+	}
+}
+
+// Ensure a remote node's slices are split across requests when over the limit.
+func TestExecutor_Execute_Remote_MaxSlicesPerRequest(t *testing.T) {
+	c := NewCluster(2)
+
+	// Create secondary server which counts each slice as 10 and records requests.
+	var requests [][]uint64
+	s := NewServer()
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		requests = append(requests, slices)
+		return []interface{}{uint64(len(slices) * 10)}, nil
+	}
+	defer s.Close()
+	c.Nodes[1].Host = s.Host()
+
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	// Slices 1, 3, 5 & 7 are owned by the remote node.
+	e := NewExecutor(hldr.Holder, c)
+	q := MustParse(`Count(Bitmap(rowID=1, frame=f))`)
+	exp, err := e.Execute(context.Background(), "i", q, []uint64{1, 3, 5, 7}, nil)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(requests) != 1 {
+		t.Fatalf("unexpected request count: %d", len(requests))
+	}
+
+	requests = nil
+	e.MaxSlicesPerRequest = 3
+	if res, err := e.Execute(context.Background(), "i", q, []uint64{1, 3, 5, 7}, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, exp) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res))
+	} else if !reflect.DeepEqual(requests, [][]uint64{{1, 3, 5}, {7}}) {
+		t.Fatalf("unexpected requests: %v", requests)
+	}
+}
+
+// Ensure mapper goroutines exit when the reducer returns before all responses.
+func TestExecutor_Execute_Remote_NoMapperLeak(t *testing.T) {
+	c := NewCluster(3)
+
+	// Create a server which fails immediately.
+	s1 := NewServer()
+	s1.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		return nil, pilosa.ErrFrameNotFound
+	}
+	defer s1.Close()
+	c.Nodes[1].Host = s1.Host()
+
+	// Create a server which responds after the query has failed.
+	s2 := NewServer()
+	s2.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		time.Sleep(100 * time.Millisecond)
+		return []interface{}{uint64(1)}, nil
+	}
+	defer s2.Close()
+	c.Nodes[2].Host = s2.Host()
+
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	// Find a slice owned by each remote node.
+	var slices []uint64
+	for _, node := range c.Nodes[1:] {
+		for slice := uint64(0); ; slice++ {
+			if c.FragmentNodes("i", slice)[0] == node {
+				slices = append(slices, slice)
+				break
+			}
+		}
+	}
+
+	e := NewExecutor(hldr.Holder, c)
+	baseline := runtime.NumGoroutine()
+	if _, err := e.Execute(context.Background(), "i", MustParse(`Count(Bitmap(rowID=1, frame=f))`), slices, nil); err == nil || err.Error() != pilosa.ErrFrameNotFound.Error() {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Wait for the slow mapper to complete and connections to close.
+	for i := 0; ; i++ {
+		http.DefaultTransport.(*http.Transport).CloseIdleConnections()
+		if n := runtime.NumGoroutine(); n <= baseline {
+			break
+		} else if i == 100 {
+			t.Fatalf("goroutine leak: %d > %d", n, baseline)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Ensure queries over an index's concurrency limit are rejected or queued.
+func TestExecutor_Execute_MaxConcurrentQueries(t *testing.T) {
+	for _, reject := range []bool{true, false} {
+		t.Run(fmt.Sprintf("reject=%v", reject), func(t *testing.T) {
+			c := NewCluster(2)
+
+			// Create secondary server which blocks until released.
+			s := NewServer()
+			defer s.Close()
+			c.Nodes[1].Host = s.Host()
+
+			started, release := make(chan struct{}, 2), make(chan struct{})
+			s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+				started <- struct{}{}
+				<-release
+				return []interface{}{uint64(0)}, nil
+			}
+
+			hldr := MustOpenHolder()
+			defer hldr.Close()
+			hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(10, SliceWidth+1)
+
+			e := NewExecutor(hldr.Holder, c)
+			e.MaxConcurrentQueries = 1
+			e.RejectTooManyQueries = reject
+
+			// Start first query and wait until it reaches the remote node.
+			errs := make(chan error, 2)
+			go func() {
+				_, err := e.Execute(context.Background(), "i", MustParse(`Count(Bitmap(rowID=10, frame=f))`), nil, nil)
+				errs <- err
+			}()
+			<-started
+
+			if reject {
+				// The second query should be rejected immediately.
+				if _, err := e.Execute(context.Background(), "i", MustParse(`Count(Bitmap(rowID=10, frame=f))`), nil, nil); err != pilosa.ErrTooManyQueries {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			} else {
+				// The second query should wait until the first completes.
+				go func() {
+					_, err := e.Execute(context.Background(), "i", MustParse(`Count(Bitmap(rowID=10, frame=f))`), nil, nil)
+					errs <- err
+				}()
+				select {
+				case <-started:
+					t.Fatal("expected second query to be queued")
+				case <-time.After(100 * time.Millisecond):
+				}
+			}
+
+			// Queries against other indexes are not limited.
+			if _, err := e.Execute(context.Background(), "j", MustParse(`SetRowAttrs(rowID=10, frame=f, foo="bar")`), nil, nil); err != pilosa.ErrFrameNotFound {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// Release remote and wait for all started queries to finish.
+			close(release)
+			n := 2
+			if reject {
+				n = 1
+			}
+			for i := 0; i < n; i++ {
+				if err := <-errs; err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// Ensure frame access is authorized before any call in a query is executed.
+func TestExecutor_Execute_Authorizer(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 1)
+	hldr.MustCreateFragmentIfNotExists("i", "g", pilosa.ViewStandard, 0).MustSetBits(10, 2)
+
+	type tenantKey struct{}
+	var authorized []string
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	e.Authorizer = AuthorizerFunc(func(ctx context.Context, index, frame, op string) error {
+		if tenant := ctx.Value(tenantKey{}); tenant != "acme" {
+			t.Fatalf("unexpected tenant: %v", tenant)
+		}
+		authorized = append(authorized, frame+":"+op)
+		if frame == "g" {
+			return errors.New("frame g is restricted")
+		}
+		return nil
+	})
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+
+	// Access to the allowed frame succeeds.
+	if res, err := e.Execute(ctx, "i", MustParse(`Bitmap(rowID=10, frame=f)`), nil, nil); err != nil {
+		t.Fatal(err)
+	} else if bits := res[0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, []uint64{1}) {
+		t.Fatalf("unexpected bits: %+v", bits)
+	}
+
+	// A denied frame fails the whole query without applying earlier calls.
+	authorized = nil
+	_, err := e.Execute(ctx, "i", MustParse(`SetBit(rowID=11, frame=f, columnID=3) Count(Bitmap(rowID=10, frame=g))`), nil, nil)
+	if err, ok := err.(*pilosa.AuthorizationError); !ok {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err.Frame != "g" || err.Op != pilosa.AuthorizeRead {
+		t.Fatalf("unexpected error: %+v", err)
+	} else if err.Error() != `not authorized to read frame "g" in index "i": frame g is restricted` {
+		t.Fatalf("unexpected error message: %s", err)
+	}
+	if !reflect.DeepEqual(authorized, []string{"f:write", "g:read"}) {
+		t.Fatalf("unexpected authorizations: %v", authorized)
+	}
+	if bits := hldr.Fragment("i", "f", pilosa.ViewStandard, 0).Row(11).Bits(); len(bits) != 0 {
+		t.Fatalf("unexpected bits: %+v", bits)
+	}
+}
+
+// Ensure a batch of queries returns independent results and errors.
+func TestExecutor_ExecuteBatch(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 1, 2)
+	hldr.MustCreateFragmentIfNotExists("j", "f", pilosa.ViewStandard, 1).MustSetBits(20, SliceWidth+1)
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	results, errs := e.ExecuteBatch(context.Background(), []pilosa.QueryRequest{
+		{Index: "i", Query: `Count(Bitmap(rowID=10, frame=f))`},
+		{Index: "j", Query: `Bitmap(rowID=20, frame=f)`},
+		{Index: "i", Query: `Count(Bitmap(rowID=10, frame=no_such_frame))`},
+		{Index: "j", Query: `Bitmap(`},
+		{Index: "", Query: `Count(Bitmap(rowID=10, frame=f))`},
+		{Index: "j", Query: `Count(Bitmap(rowID=20, frame=f))`},
+	})
+
+	if len(results) != 6 || len(errs) != 6 {
+		t.Fatalf("unexpected lengths: results=%d, errs=%d", len(results), len(errs))
+	}
+
+	// Successful requests.
+	if errs[0] != nil {
+		t.Fatal(errs[0])
+	} else if !reflect.DeepEqual(results[0], []interface{}{uint64(2)}) {
+		t.Fatalf("unexpected result(0): %s", spew.Sdump(results[0]))
+	}
+	if errs[1] != nil {
+		t.Fatal(errs[1])
+	} else if bits := results[1][0].(*pilosa.Bitmap).Bits(); !reflect.DeepEqual(bits, []uint64{SliceWidth + 1}) {
+		t.Fatalf("unexpected bits(1): %+v", bits)
+	}
+	if errs[5] != nil {
+		t.Fatal(errs[5])
+	} else if !reflect.DeepEqual(results[5], []interface{}{uint64(1)}) {
+		t.Fatalf("unexpected result(5): %s", spew.Sdump(results[5]))
+	}
+
+	// Failed requests.
+	if errs[2] != pilosa.ErrFrameNotFound {
+		t.Fatalf("unexpected error(2): %v", errs[2])
+	} else if results[2] != nil {
+		t.Fatalf("unexpected result(2): %s", spew.Sdump(results[2]))
+	}
+	if errs[3] == nil || !strings.Contains(errs[3].Error(), "expected") {
+		t.Fatalf("unexpected error(3): %v", errs[3])
+	}
+	if errs[4] != pilosa.ErrIndexRequired {
+		t.Fatalf("unexpected error(4): %v", errs[4])
+	}
+}
+
+// Ensure a query can be validated without being executed.
+func TestExecutor_Validate(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	f := hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0)
+
+	t.Run("OK", func(t *testing.T) {
+		if err := e.Validate(context.Background(), "i", MustParse(`SetBit(rowID=11, frame=f, columnID=1) Count(Bitmap(rowID=11, frame=f))`)); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("ErrFrameNotFound", func(t *testing.T) {
+		err := e.Validate(context.Background(), "i", MustParse(`SetBit(rowID=11, frame=f, columnID=1) SetBit(rowID=11, frame=x, columnID=2)`))
+		if verr, ok := err.(*pilosa.ValidationError); !ok {
+			t.Fatalf("unexpected error: %#v", err)
+		} else if verr.Err != pilosa.ErrFrameNotFound {
+			t.Fatalf("unexpected error: %s", verr.Err)
+		} else if verr.Call.Args["frame"] != "x" {
+			t.Fatalf("unexpected call: %s", verr.Call)
+		}
+	})
+
+	t.Run("InvalidTimestamp", func(t *testing.T) {
+		err := e.Validate(context.Background(), "i", MustParse(`SetBit(rowID=11, frame=f, columnID=1) SetBit(rowID=11, frame=f, columnID=2, timestamp="2017-13-01T00:00")`))
+		if verr, ok := err.(*pilosa.ValidationError); !ok {
+			t.Fatalf("unexpected error: %#v", err)
+		} else if verr.Err.Error() != `invalid date: 2017-13-01T00:00` {
+			t.Fatalf("unexpected error: %s", verr.Err)
+		}
+	})
+
+	t.Run("NestedCall", func(t *testing.T) {
+		err := e.Validate(context.Background(), "i", MustParse(`Count(Union(Bitmap(rowID=1, frame=f), SetBit(rowID=11, frame=f, columnID=1)))`))
+		if verr, ok := err.(*pilosa.ValidationError); !ok {
+			t.Fatalf("unexpected error: %#v", err)
+		} else if verr.Call.Name != "SetBit" {
+			t.Fatalf("unexpected call: %s", verr.Call)
+		}
+	})
+
+	// Validation must not write any data.
+	if n := f.Row(11).Count(); n != 0 {
+		t.Fatalf("unexpected bitmap count: %d", n)
+	}
+}
+
+// Ensure a query's cost can be estimated without being executed.
+func TestExecutor_EstimateCost(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 1)
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 3).MustSetBits(10, (3*SliceWidth)+1)
+	hldr.MustCreateFragmentIfNotExists("j", "f", pilosa.ViewStandard, 0).MustSetBits(10, 1, 2)
+	hldr.MustCreateFragmentIfNotExists("j", "f", pilosa.ViewStandard, 0).MustSetBits(11, 1)
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	q := MustParse(`Union(Bitmap(rowID=10, frame=f), Bitmap(rowID=11, frame=f))`)
+
+	// A union over more slices costs more.
+	many, err := e.EstimateCost(context.Background(), "i", q, nil)
+	if err != nil {
+		t.Fatal(err)
+	} else if many.Calls[0].SliceN != 4 {
+		t.Fatalf("unexpected slice count: %d", many.Calls[0].SliceN)
+	}
+	few, err := e.EstimateCost(context.Background(), "j", q, nil)
+	if err != nil {
+		t.Fatal(err)
+	} else if few.Calls[0].SliceN != 1 {
+		t.Fatalf("unexpected slice count: %d", few.Calls[0].SliceN)
+	}
+	if many.Total <= few.Total {
+		t.Fatalf("expected higher cost over more slices: %d <= %d", many.Total, few.Total)
+	}
+
+	// Explicit slices restrict the estimate.
+	if cost, err := e.EstimateCost(context.Background(), "i", q, []uint64{0}); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(cost, few) {
+		t.Fatalf("unexpected cost: %s", spew.Sdump(cost))
+	}
+
+	// TopN() includes ranked rows and the total sums each call.
+	if cost, err := e.EstimateCost(context.Background(), "j", MustParse(`TopN(frame=f, n=1) SetBit(rowID=1, frame=f, columnID=1)`), nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(cost, &pilosa.QueryCost{
+		Calls: []pilosa.CallCost{{SliceN: 1, Cost: 2}, {SliceN: 1, Cost: 1}},
+		Total: 3,
+	}) {
+		t.Fatalf("unexpected cost: %s", spew.Sdump(cost))
+	}
+
+	// Invalid queries are rejected.
+	if _, err := e.EstimateCost(context.Background(), "i", MustParse(`Bitmap(rowID=10, frame=x)`), nil); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+// Ensure Explain reports the columns matched by every call in a query's tree.
+func TestExecutor_Explain(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	for slice := uint64(0); slice < 4; slice++ {
+		base := slice * SliceWidth
+		hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, slice).MustSetBits(1, base+1, base+2, base+3)
+		hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, slice).MustSetBits(2, base+3, base+4)
+		hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, slice).MustSetBits(3, base+2, base+3, base+4, base+5)
+		hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, slice).MustSetBits(4, base+3)
+	}
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	q := MustParse(`Count(Intersect(Union(Bitmap(rowID=1, frame=f), Bitmap(rowID=2, frame=f)), Difference(Bitmap(rowID=3, frame=f), Bitmap(rowID=4, frame=f))))`)
+	explain, err := e.Explain(context.Background(), "i", q, nil)
+	if err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(explain.Slices, []uint64{0, 1, 2, 3}) {
+		t.Fatalf("unexpected slices: %v", explain.Slices)
+	} else if ce := explain.Calls[0]; ce.Call != `Count()` || ce.Count != 0 || len(ce.Children) != 1 {
+		t.Fatalf("unexpected Count() trace: %s", spew.Sdump(ce))
+	}
+
+	// Each child's count matches counting the child on its own.
+	var walk func(c *pql.Call, ce *pilosa.CallExplain)
+	walk = func(c *pql.Call, ce *pilosa.CallExplain) {
+		res, err := e.Execute(context.Background(), "i", &pql.Query{Calls: []*pql.Call{{Name: "Count", Children: []*pql.Call{c}}}}, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		} else if res[0].(uint64) != ce.Count {
+			t.Fatalf("%s: unexpected count: %d, expected %d", c, ce.Count, res[0])
+		} else if len(ce.Children) != len(c.Children) {
+			t.Fatalf("%s: unexpected children: %s", c, spew.Sdump(ce))
+		}
+		for i := range c.Children {
+			walk(c.Children[i], ce.Children[i])
+		}
+	}
+	walk(q.Calls[0].Children[0], explain.Calls[0].Children[0])
+
+	// Intersect() of {1,2,3,4} and {2,4,5} in each slice.
+	if ce := explain.Calls[0].Children[0]; ce.Call != `Intersect()` || ce.Count != 8 {
+		t.Fatalf("unexpected Intersect() trace: %s", spew.Sdump(ce))
+	}
+
+	// Larger slice sets are sampled and mutations are rejected.
+	e.MaxExplainSlices = 2
+	if explain, err := e.Explain(context.Background(), "i", q, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(explain.Slices, []uint64{0, 2}) {
+		t.Fatalf("unexpected sampled slices: %v", explain.Slices)
+	} else if n := explain.Calls[0].Children[0].Count; n != 4 {
+		t.Fatalf("unexpected sampled count: %d", n)
+	}
+	if _, err := e.Explain(context.Background(), "i", MustParse(`SetBit(frame=f, rowID=1, columnID=1)`), nil); err == nil || err.Error() != `cannot explain SetBit() call` {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure Warm() loads only the fragments read by a query on their owners.
+func TestExecutor_Warm(t *testing.T) {
+	c := NewCluster(2)
+	s := NewServer()
+	defer s.Close()
+	c.Nodes[1].Host = s.Host()
+
+	// Both nodes hold the same fragments and record the fragments warmed.
+	var holders []*Holder
+	var stats []*CountStatsClient
+	for i := 0; i < 2; i++ {
+		hldr := MustOpenHolder()
+		defer hldr.Close()
+		st := NewCountStatsClient("warmN")
+		hldr.Stats = st
+		for slice := uint64(0); slice < 2; slice++ {
+			hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, slice).MustSetBits(10, (slice*SliceWidth)+1)
+			hldr.MustCreateFragmentIfNotExists("i", "g", pilosa.ViewStandard, slice).MustSetBits(10, (slice*SliceWidth)+1)
+		}
+		holders = append(holders, hldr)
+		stats = append(stats, st)
+	}
+
+	// Execute forwarded queries with a second executor.
+	var remoteQueries []string
+	remote := NewExecutor(holders[1].Holder, c)
+	remote.Host = c.Nodes[1].Host
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		remoteQueries = append(remoteQueries, query.String())
+		return remote.Execute(ctx, index, query, slices, opt)
+	}
+
+	// Mutations are neither warmed nor applied.
+	e := NewExecutor(holders[0].Holder, c)
+	if err := e.Warm(context.Background(), "i", MustParse(`Count(Bitmap(rowID=10, frame=f)) SetBit(rowID=20, frame=g, columnID=1)`), nil); err != nil {
+		t.Fatal(err)
+	}
+	if n := stats[0].Counts(); !reflect.DeepEqual(n, map[string]int64{"index:i,frame:f,slice:standard,slice:0": 1}) {
+		t.Fatalf("unexpected local warms: %v", n)
+	} else if n := stats[1].Counts(); !reflect.DeepEqual(n, map[string]int64{"index:i,frame:f,slice:standard,slice:1": 1}) {
+		t.Fatalf("unexpected remote warms: %v", n)
+	} else if !reflect.DeepEqual(remoteQueries, []string{`Warm(frames=["f"])`}) {
+		t.Fatalf("unexpected remote queries: %v", remoteQueries)
+	} else if holders[0].Fragment("i", "g", pilosa.ViewStandard, 0).Row(20).Count() != 0 {
+		t.Fatal("unexpected mutation")
+	}
+}
+
+// Ensure query stats are recorded with the caller's tags and the tags are not forwarded.
+func TestExecutor_Execute_Tags(t *testing.T) {
+	c := NewCluster(2)
+	s := NewServer()
+	defer s.Close()
+	c.Nodes[1].Host = s.Host()
+
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		if opt.Tags != nil {
+			t.Fatalf("unexpected remote tags: %v", opt.Tags)
+		} else if query.String() != `Count(Bitmap(frame="f", rowID=10))` {
+			t.Fatalf("unexpected query: %s", query.String())
+		}
+		return []interface{}{uint64(1)}, nil
+	}
+
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	st := NewCountStatsClient("queryN")
+	hldr.Stats = st
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 1)
+
+	e := NewExecutor(hldr.Holder, c)
+	opt := &pilosa.ExecOptions{Tags: map[string]string{"source": "dashboard", "team": "ops"}}
+	if _, err := e.Execute(context.Background(), "i", MustParse(`Count(Bitmap(rowID=10, frame=f))`), []uint64{0, 1}, opt); err != nil {
+		t.Fatal(err)
+	} else if _, err := e.Execute(context.Background(), "i", MustParse(`Count(Bitmap(rowID=10, frame=f))`), []uint64{0}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if n := st.Counts(); !reflect.DeepEqual(n, map[string]int64{"index:i,source:dashboard,team:ops": 1, "index:i": 1}) {
+		t.Fatalf("unexpected counts: %v", n)
+	}
+}
+
+// Ensure a registered aggregation executes across local and remote slices.
+func TestExecutor_Execute_Aggregation(t *testing.T) {
+	// Weights are held outside of the index.
+	weights := map[uint64]float64{1: 0.5, 2: 2, SliceWidth + 1: 4, SliceWidth + 3: 0.25}
+	sumWeighted := pilosa.Aggregation{
+		Map: func(ctx context.Context, index string, c *pql.Call, slice uint64, inputs []*pilosa.Bitmap) (interface{}, error) {
+			if len(inputs) != 1 {
+				return nil, errors.New("SumWeighted() requires a single bitmap input")
+			}
+			var sum float64
+			for _, col := range inputs[0].Bits() {
+				sum += weights[col]
+			}
+			return sum, nil
+		},
+		Reduce: func(prev, v interface{}) interface{} {
+			sum, _ := prev.(float64)
+			return sum + v.(float64)
+		},
+	}
+
+	c := NewCluster(2)
+	s := NewServer()
+	defer s.Close()
+	c.Nodes[1].Host = s.Host()
+
+	// Both nodes hold the same data so the result does not depend on slice ownership.
+	var holders []*Holder
+	for i := 0; i < 2; i++ {
+		hldr := MustOpenHolder()
+		defer hldr.Close()
+		hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 1, 2, 3)
+		hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 1).MustSetBits(10, SliceWidth+1, SliceWidth+3)
+		holders = append(holders, hldr)
+	}
+
+	// Execute forwarded queries with a second executor.
+	var remoteN int
+	remote := NewExecutor(holders[1].Holder, c)
+	remote.Host = c.Nodes[1].Host
+	if err := remote.RegisterAggregation("SumWeighted", sumWeighted); err != nil {
+		t.Fatal(err)
+	}
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		if query.Calls[0].Name == "SumWeighted" {
+			remoteN++
+		}
+		return remote.Execute(ctx, index, query, slices, opt)
+	}
+
+	e := NewExecutor(holders[0].Holder, c)
+	if err := e.RegisterAggregation("SumWeighted", sumWeighted); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := e.Execute(context.Background(), "i", MustParse(`SumWeighted(Bitmap(rowID=10, frame=f)) Count(Bitmap(rowID=10, frame=f))`), []uint64{0, 1}, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(res, []interface{}{float64(6.75), uint64(5)}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res))
+	} else if remoteN != 1 {
+		t.Fatalf("unexpected remote request count: %d", remoteN)
+	}
+
+	// Built-in and duplicate names are rejected.
+	if err := e.RegisterAggregation("Count", sumWeighted); err == nil || err.Error() != "cannot register built-in call: Count" {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := e.RegisterAggregation("SumWeighted", sumWeighted); err == nil || err.Error() != "aggregation already registered: SumWeighted" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Unregistered calls are still unknown.
+	if _, err := e.Execute(context.Background(), "i", MustParse(`SumOther(Bitmap(rowID=10, frame=f))`), nil, nil); err == nil || !strings.Contains(err.Error(), "unknown call: SumOther") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure every result type decodes the same from JSON & protobuf responses.
+func TestExecutor_Execute_Remote_RemoteFormat(t *testing.T) {
+	sumColumns := pilosa.Aggregation{
+		Map: func(ctx context.Context, index string, c *pql.Call, slice uint64, inputs []*pilosa.Bitmap) (interface{}, error) {
+			var sum float64
+			for _, col := range inputs[0].Bits() {
+				sum += float64(col)
+			}
+			return sum, nil
+		},
+		Reduce: func(prev, v interface{}) interface{} {
+			sum, _ := prev.(float64)
+			return sum + v.(float64)
+		},
+	}
+
+	c := NewCluster(2)
+	s := NewServer()
+	defer s.Close()
+	c.Nodes[1].Host = s.Host()
+
+	var holders []*Holder
+	for i := 0; i < 2; i++ {
+		hldr := MustOpenHolder()
+		defer hldr.Close()
+		hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 3).MustSetBits(10, (3*SliceWidth)+1, (3*SliceWidth)+2, (3*SliceWidth)+3)
+		hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 3).MustSetBits(11, (3*SliceWidth)+1)
+		holders = append(holders, hldr)
+	}
+
+	// Execute forwarded queries with a second executor.
+	remote := NewExecutor(holders[1].Holder, c)
+	remote.Host = c.Nodes[1].Host
+	if err := remote.RegisterAggregation("SumColumns", sumColumns); err != nil {
+		t.Fatal(err)
+	}
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		if !opt.Remote {
+			t.Fatal("expected remote request")
+		}
+		return remote.Execute(ctx, index, query, slices, opt)
+	}
+
+	// Slice 3 is owned by the remote node.
+	exec := func(format, q string) []interface{} {
+		e := NewExecutor(holders[0].Holder, c)
+		e.RemoteFormat = format
+		if err := e.RegisterAggregation("SumColumns", sumColumns); err != nil {
+			t.Fatal(err)
+		}
+		res, err := e.Execute(context.Background(), "i", MustParse(q), []uint64{3}, nil)
+		if err != nil {
+			t.Fatalf("%s: %s: %s", format, q, err)
+		}
+		return res
+	}
+
+	for _, tt := range []struct {
+		q   string
+		exp []interface{}
+	}{
+		{q: `Count(Bitmap(rowID=10, frame=f))`, exp: []interface{}{uint64(3)}},
+		{q: `TopN(frame=f, n=2)`, exp: []interface{}{[]pilosa.Pair{{ID: 10, Count: 3}, {ID: 11, Count: 1}}}},
+		{q: `Page(Bitmap(rowID=10, frame=f), limit=2)`, exp: []interface{}{&pilosa.PageResult{Count: 3, Columns: []uint64{(3 * SliceWidth) + 1, (3 * SliceWidth) + 2}}}},
+		{q: `Page(Bitmap(rowID=12, frame=f), limit=2)`, exp: []interface{}{&pilosa.PageResult{Count: 0, Columns: []uint64{}}}},
+		{q: `SumColumns(Bitmap(rowID=11, frame=f))`, exp: []interface{}{float64((3 * SliceWidth) + 1)}},
+		{q: fmt.Sprintf(`SetBit(rowID=20, frame=f, columnID=%d) ClearBit(rowID=20, frame=f, columnID=%d)`, (3*SliceWidth)+5, (3*SliceWidth)+5), exp: []interface{}{true, true}},
+		{q: fmt.Sprintf(`SetBit(rowID=20, frame=f, columnID=%d) ClearBits(frame=f, rowIDs=[20], columnIDs=[%d])`, (3*SliceWidth)+6, (3*SliceWidth)+6), exp: []interface{}{true, uint64(1)}},
+		{q: `SetRowAttrs(rowID=10, frame=f, x=1)`, exp: []interface{}{nil}},
+	} {
+		// Mutations are reverted by each query so both formats see the same data.
+		pbRes := exec(pilosa.RemoteFormatProtobuf, tt.q)
+		jsonRes := exec(pilosa.RemoteFormatJSON, tt.q)
+		if !reflect.DeepEqual(pbRes, tt.exp) {
+			t.Fatalf("%s: unexpected protobuf results: %s", tt.q, spew.Sdump(pbRes))
+		} else if !reflect.DeepEqual(jsonRes, pbRes) {
+			t.Fatalf("%s: unexpected json results: %s, expected %s", tt.q, spew.Sdump(jsonRes), spew.Sdump(pbRes))
+		}
+	}
+
+	// Bitmaps hold the same columns. Bitmap internals are compared by bits.
+	pbBM := exec(pilosa.RemoteFormatProtobuf, `Bitmap(rowID=10, frame=f)`)[0].(*pilosa.Bitmap)
+	jsonBM := exec(pilosa.RemoteFormatJSON, `Bitmap(rowID=10, frame=f)`)[0].(*pilosa.Bitmap)
+	if !reflect.DeepEqual(jsonBM.Bits(), pbBM.Bits()) || !reflect.DeepEqual(jsonBM.Attrs, pbBM.Attrs) {
+		t.Fatalf("unexpected json bitmap: %s, expected %s", spew.Sdump(jsonBM), spew.Sdump(pbBM))
+	}
+
+	// Remote errors are returned from both formats. Only the coordinator
+	// registers SumOther().
+	for _, format := range []string{pilosa.RemoteFormatProtobuf, pilosa.RemoteFormatJSON} {
+		e := NewExecutor(holders[0].Holder, c)
+		e.RemoteFormat = format
+		if err := e.RegisterAggregation("SumOther", sumColumns); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := e.Execute(context.Background(), "i", MustParse(`SumOther(Bitmap(rowID=10, frame=f))`), []uint64{3}, nil); err == nil || !strings.Contains(err.Error(), "SumOther") {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+	}
+
+	// Unknown formats are rejected.
+	e := NewExecutor(holders[0].Holder, c)
+	e.RemoteFormat = "xml"
+	if _, err := e.Execute(context.Background(), "i", MustParse(`Count(Bitmap(rowID=10, frame=f))`), []uint64{3}, nil); err == nil || err.Error() != "unknown remote format: xml" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure local map goroutines are bounded across all calls of a query.
+func TestExecutor_Execute_MaxMapGoroutines(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	for slice := uint64(0); slice < 16; slice++ {
+		hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, slice).MustSetBits(10, (slice*SliceWidth)+1)
+	}
+
+	// Track the number of slices mapped at once.
+	var n, maxN int64
+	var hold int32
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	e.MaxMapGoroutines = 3
+	if err := e.RegisterAggregation("Wait", pilosa.Aggregation{
+		Map: func(ctx context.Context, index string, c *pql.Call, slice uint64, inputs []*pilosa.Bitmap) (interface{}, error) {
+			v := atomic.AddInt64(&n, 1)
+			defer atomic.AddInt64(&n, -1)
+			for {
+				old := atomic.LoadInt64(&maxN)
+				if v <= old || atomic.CompareAndSwapInt64(&maxN, old, v) {
+					break
+				}
+			}
+
+			// Hold the slot until the query is canceled, if set.
+			if atomic.LoadInt32(&hold) == 1 {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+			time.Sleep(time.Millisecond)
+			return uint64(1), nil
+		},
+		Reduce: func(prev, v interface{}) interface{} {
+			n, _ := prev.(uint64)
+			return n + v.(uint64)
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	q := strings.Repeat(`Wait(Bitmap(rowID=10, frame=f))`, 8)
+	results, errs := e.ExecuteBatch(context.Background(), []pilosa.QueryRequest{{Index: "i", Query: q}, {Index: "i", Query: q}})
+	for i := range results {
+		if errs[i] != nil {
+			t.Fatal(errs[i])
+		} else if len(results[i]) != 8 {
+			t.Fatalf("unexpected result count: %d", len(results[i]))
+		}
+		for _, v := range results[i] {
+			if v != uint64(16) {
+				t.Fatalf("unexpected result: %v", v)
+			}
+		}
+	}
+	if maxN > 3 {
+		t.Fatalf("unexpected concurrent map goroutines: %d", maxN)
+	}
+
+	// Waiting for a slot stops once the context is done.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	atomic.StoreInt32(&hold, 1)
+	if _, err := e.Execute(ctx, "i", MustParse(`Wait(Bitmap(rowID=10, frame=f))`), nil, nil); err != context.DeadlineExceeded {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure a snapshot query does not observe writes made while it executes.
+func TestExecutor_Execute_Snapshot(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	for slice := uint64(0); slice < 4; slice++ {
+		hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, slice).MustSetBits(10, (slice*SliceWidth)+1)
+	}
+
+	// Write a new bit to each slice while the query executes.
+	var col uint64 = 100
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	if err := e.RegisterAggregation("Write", pilosa.Aggregation{
+		Map: func(ctx context.Context, index string, c *pql.Call, slice uint64, inputs []*pilosa.Bitmap) (interface{}, error) {
+			if _, err := hldr.Fragment("i", "f", pilosa.ViewStandard, slice).SetBit(10, (slice*SliceWidth)+atomic.AddUint64(&col, 1)); err != nil {
+				return nil, err
+			}
+			return nil, nil
+		},
+		Reduce: func(prev, v interface{}) interface{} { return nil },
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	q := MustParse(`Count(Bitmap(rowID=10, frame=f)) Write(Bitmap(rowID=10, frame=f)) Count(Bitmap(rowID=10, frame=f))`)
+	if res, err := e.Execute(context.Background(), "i", q, nil, &pilosa.ExecOptions{Snapshot: true}); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual([]interface{}{res[0], res[2]}, []interface{}{uint64(4), uint64(4)}) {
+		t.Fatalf("unexpected snapshot results: %s", spew.Sdump(res))
+	}
+
+	// Without a snapshot the writes are observed.
+	if res, err := e.Execute(context.Background(), "i", q, nil, nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual([]interface{}{res[0], res[2]}, []interface{}{uint64(8), uint64(12)}) {
+		t.Fatalf("unexpected results: %s", spew.Sdump(res))
+	}
+
+	// Concurrent writes do not change counts within a snapshot query.
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := uint64(0); ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			slice := i % 4
+			if _, err := hldr.Fragment("i", "f", pilosa.ViewStandard, slice).SetBit(10, (slice*SliceWidth)+1000+i); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		res, err := e.Execute(context.Background(), "i", MustParse(`Count(Bitmap(rowID=10, frame=f)) Count(Bitmap(rowID=10, frame=f))`), nil, &pilosa.ExecOptions{Snapshot: true})
+		if err != nil {
+			t.Fatal(err)
+		} else if res[0] != res[1] {
+			t.Fatalf("inconsistent snapshot counts: %d != %d", res[0], res[1])
+		}
+	}
+	close(done)
+	wg.Wait()
+}
+
+// Ensure concurrent snapshot queries over several frames do not deadlock.
+func TestExecutor_Execute_Snapshot_Concurrent(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	for slice := uint64(0); slice < 64; slice++ {
+		hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, slice).MustSetBits(10, (slice*SliceWidth)+1)
+		hldr.MustCreateFragmentIfNotExists("i", "g", pilosa.ViewStandard, slice).MustSetBits(10, (slice*SliceWidth)+1)
+	}
+
+	e := NewExecutor(hldr.Holder, NewCluster(1))
+	q := `Count(Bitmap(rowID=10, frame=f)) Count(Bitmap(rowID=10, frame=g))`
+	errs := make(chan error, 16)
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			for j := 0; j < 100; j++ {
+				if _, err := e.Execute(context.Background(), "i", MustParse(q), nil, &pilosa.ExecOptions{Snapshot: true}); err != nil {
+					errs <- err
+					return
+				}
+			}
+			errs <- nil
+		}()
+	}
+
+	timeout := time.After(30 * time.Second)
+	for i := 0; i < cap(errs); i++ {
+		select {
+		case err := <-errs:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-timeout:
+			t.Fatal("snapshot queries deadlocked")
+		}
 	}
 }
 
@@ -706,6 +5914,83 @@ func NewExecutor(holder *pilosa.Holder, cluster *pilosa.Cluster) *Executor {
 	return e
 }
 
+// AuthorizerFunc implements pilosa.Authorizer with a function.
+type AuthorizerFunc func(ctx context.Context, index, frame, op string) error
+
+// Authorize calls fn.
+func (fn AuthorizerFunc) Authorize(ctx context.Context, index, frame, op string) error {
+	return fn(ctx, index, frame, op)
+}
+
+// MapKeyTranslator implements pilosa.KeyTranslator with ids by "frame/key".
+type MapKeyTranslator map[string]uint64
+
+// TranslateKey returns the id for key in frame.
+func (m MapKeyTranslator) TranslateKey(index, frame, key string) (uint64, error) {
+	id, ok := m[frame+"/"+key]
+	if !ok {
+		return 0, errors.New("key not found")
+	}
+	return id, nil
+}
+
+// TranslateID returns the key for id in frame.
+func (m MapKeyTranslator) TranslateID(index, frame string, id uint64) (string, error) {
+	for k, v := range m {
+		if v == id && strings.HasPrefix(k, frame+"/") {
+			return strings.TrimPrefix(k, frame+"/"), nil
+		}
+	}
+	return "", errors.New("id not found")
+}
+
+// CountStatsClient is a pilosa.StatsClient which records the total of a
+// counter by the tags of the client it is counted on.
+type CountStatsClient struct {
+	name   string
+	tags   []string
+	mu     *sync.Mutex
+	counts map[string]int64
+}
+
+// NewCountStatsClient returns a client which records counts of name.
+func NewCountStatsClient(name string) *CountStatsClient {
+	return &CountStatsClient{name: name, mu: &sync.Mutex{}, counts: make(map[string]int64)}
+}
+
+// Counts returns a copy of the recorded counts by comma-delimited tags.
+func (c *CountStatsClient) Counts() map[string]int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m := make(map[string]int64, len(c.counts))
+	for k, v := range c.counts {
+		m[k] = v
+	}
+	return m
+}
+
+func (c *CountStatsClient) Tags() []string { return c.tags }
+
+func (c *CountStatsClient) WithTags(tags ...string) pilosa.StatsClient {
+	other := *c
+	other.tags = append(append([]string{}, c.tags...), tags...)
+	return &other
+}
+
+func (c *CountStatsClient) Count(name string, value int64) {
+	if name != c.name {
+		return
+	}
+	c.mu.Lock()
+	c.counts[strings.Join(c.tags, ",")] += value
+	c.mu.Unlock()
+}
+
+func (c *CountStatsClient) Gauge(name string, value float64)        {}
+func (c *CountStatsClient) Histogram(name string, value float64)    {}
+func (c *CountStatsClient) Set(name string, value string)           {}
+func (c *CountStatsClient) Timing(name string, value time.Duration) {}
+
 // MustParse parses s into a PQL query. Panic on error.
 func MustParse(s string) *pql.Query {
 	q, err := pql.NewParser(strings.NewReader(s)).Parse()
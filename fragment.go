@@ -79,7 +79,8 @@ type Fragment struct {
 	file        *os.File
 	storage     *roaring.Bitmap
 	storageData []byte
-	opN         int // number of ops since snapshot
+	opN         int    // number of ops since snapshot
+	generation  uint64 // number of changes to storage
 
 	// Cache for row counts.
 	cacheType string // passed in by frame
@@ -97,6 +98,10 @@ type Fragment struct {
 	// so that they can be mmapped and heap utilization can be kept low.
 	MaxOpN int
 
+	// Maximum number of recently read rows to cache. If zero then every
+	// touched row is cached until the next snapshot.
+	RowCacheSize int
+
 	// Writer used for out-of-band log entries.
 	LogOutput io.Writer
 
@@ -227,12 +232,42 @@ func (f *Fragment) openStorage() error {
 
 	// Attach the file to the bitmap to act as a write-ahead log.
 	f.storage.OpWriter = f.file
-	f.rowCache = &SimpleCache{make(map[uint64]*Bitmap)}
+	f.rowCache = f.newRowCache()
 
 	return nil
 
 }
 
+// newRowCache returns a row cache based on the fragment's row cache size.
+func (f *Fragment) newRowCache() BitmapCache {
+	if f.RowCacheSize > 0 {
+		return NewLRUBitmapCache(f.RowCacheSize)
+	}
+	return &SimpleCache{make(map[uint64]*Bitmap)}
+}
+
+// RowCacheStats returns the row cache hits & misses since the storage was
+// last opened. Returns zeros if the fragment has no row cache size.
+func (f *Fragment) RowCacheStats() (hitN, missN uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if c, ok := f.rowCache.(*LRUBitmapCache); ok {
+		return c.Stats()
+	}
+	return 0, 0
+}
+
+// writableRow returns the row for a bit change that has already been
+// written to storage. Bounded caches share rows with readers so the row is
+// evicted and reread instead of being updated in place.
+func (f *Fragment) writableRow(rowID uint64) *Bitmap {
+	if f.RowCacheSize > 0 {
+		f.rowCache.Remove(rowID)
+		return f.row(rowID, false, false)
+	}
+	return f.row(rowID, true, true)
+}
+
 // openCache initializes the cache from row ids persisted to disk.
 func (f *Fragment) openCache() error {
 	// Determine cache type from frame name.
@@ -366,6 +401,54 @@ func (f *Fragment) row(rowID uint64, checkRowCache bool, updateRowCache bool) *B
 	return bm
 }
 
+// Generation returns the number of changes made to the fragment's storage.
+func (f *Fragment) Generation() uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.generation
+}
+
+// ReadSnapshot returns a read-only copy of the fragment's current storage.
+// The copy is held in memory, so it costs as much as the fragment's data.
+func (f *Fragment) ReadSnapshot() *FragmentSnapshot {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.readSnapshot()
+}
+
+func (f *Fragment) readSnapshot() *FragmentSnapshot {
+	return &FragmentSnapshot{
+		slice:      f.slice,
+		generation: f.generation,
+		storage:    f.storage.Clone(),
+	}
+}
+
+// FragmentSnapshot represents the storage of a fragment at a generation.
+// It is unaffected by later changes to the fragment.
+type FragmentSnapshot struct {
+	slice      uint64
+	generation uint64
+	storage    *roaring.Bitmap
+}
+
+// Generation returns the fragment generation the snapshot was taken at.
+func (s *FragmentSnapshot) Generation() uint64 { return s.generation }
+
+// Row returns a row by ID as of the snapshot.
+func (s *FragmentSnapshot) Row(rowID uint64) *Bitmap {
+	data := s.storage.OffsetRange(s.slice*SliceWidth, rowID*SliceWidth, (rowID+1)*SliceWidth)
+	bm := &Bitmap{
+		segments: []BitmapSegment{{
+			data:     *data.Clone(),
+			slice:    s.slice,
+			writable: false,
+		}},
+	}
+	bm.InvalidateCount()
+	return bm
+}
+
 // SetBit sets a bit for a given column & row within the fragment.
 // This updates both the on-disk storage and the in-cache bitmap.
 func (f *Fragment) SetBit(rowID, columnID uint64) (changed bool, err error) {
@@ -394,6 +477,7 @@ func (f *Fragment) setBit(rowID, columnID uint64) (changed bool, err error) {
 
 	// Invalidate block checksum.
 	delete(f.checksums, int(rowID/HashBlockSize))
+	f.generation++
 
 	// Increment number of operations until snapshot is required.
 	if err := f.incrementOpN(); err != nil {
@@ -401,7 +485,7 @@ func (f *Fragment) setBit(rowID, columnID uint64) (changed bool, err error) {
 	}
 
 	// Get the row from row cache or fragment.storage.
-	bm := f.row(rowID, true, true)
+	bm := f.writableRow(rowID)
 	bm.SetBit(columnID)
 
 	// Update the cache.
@@ -440,6 +524,7 @@ func (f *Fragment) clearBit(rowID, columnID uint64) (changed bool, err error) {
 
 	// Invalidate block checksum.
 	delete(f.checksums, int(rowID/HashBlockSize))
+	f.generation++
 
 	// Increment number of operations until snapshot is required.
 	if err := f.incrementOpN(); err != nil {
@@ -447,7 +532,7 @@ func (f *Fragment) clearBit(rowID, columnID uint64) (changed bool, err error) {
 	}
 
 	// Get the row from cache or fragment.storage.
-	bm := f.row(rowID, true, true)
+	bm := f.writableRow(rowID)
 	bm.ClearBit(columnID)
 
 	// Update the cache.
@@ -487,6 +572,149 @@ func (f *Fragment) ForEachBit(fn func(rowID, columnID uint64) error) error {
 	return err
 }
 
+// Warm advises the kernel to read the fragment's storage into memory ahead
+// of queries. Pages are read in the background so it returns immediately.
+func (f *Fragment) Warm() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.storageData) == 0 {
+		return nil
+	}
+	f.stats.Count("warmN", 1)
+	return madvise(f.storageData, syscall.MADV_WILLNEED)
+}
+
+// MaxColumn returns the highest column id set in the fragment.
+// Returns false if no bits are set.
+func (f *Fragment) MaxColumn() (uint64, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return maxColumnID(f.storage, f.slice)
+}
+
+// MaxColumn returns the highest column id set as of the snapshot.
+// Returns false if no bits are set.
+func (s *FragmentSnapshot) MaxColumn() (uint64, bool) {
+	return maxColumnID(s.storage, s.slice)
+}
+
+// MinColumn returns the lowest column id set in the fragment.
+// Returns false if no bits are set.
+func (f *Fragment) MinColumn() (uint64, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return minColumnID(f.storage, f.slice)
+}
+
+// MinColumn returns the lowest column id set as of the snapshot.
+// Returns false if no bits are set.
+func (s *FragmentSnapshot) MinColumn() (uint64, bool) {
+	return minColumnID(s.storage, s.slice)
+}
+
+// Columns returns the columns set in any row of the fragment.
+func (f *Fragment) Columns() *Bitmap {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return storageColumns(f.storage, f.slice)
+}
+
+// Columns returns the columns set in any row as of the snapshot.
+func (s *FragmentSnapshot) Columns() *Bitmap {
+	return storageColumns(s.storage, s.slice)
+}
+
+// Rows returns the ids of rows with any bit set, in order.
+func (f *Fragment) Rows() []uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return storageRows(f.storage)
+}
+
+// Rows returns the ids of rows with any bit set as of the snapshot, in order.
+func (s *FragmentSnapshot) Rows() []uint64 {
+	return storageRows(s.storage)
+}
+
+// storageRows returns the ids of the non-empty rows in storage. Only the
+// first bit of each row is read before seeking to the next row.
+func storageRows(storage *roaring.Bitmap) []uint64 {
+	var a []uint64
+	itr := storage.Iterator()
+	for v, eof := itr.Next(); !eof; v, eof = itr.Next() {
+		rowID := v / SliceWidth
+		a = append(a, rowID)
+		itr.Seek((rowID + 1) * SliceWidth)
+	}
+	return a
+}
+
+// storageColumns returns the union of every row in the storage of a slice.
+// Each row is unioned as a whole before seeking to the next row.
+func storageColumns(storage *roaring.Bitmap, slice uint64) *Bitmap {
+	data := roaring.NewBitmap()
+	itr := storage.Iterator()
+	for v, eof := itr.Next(); !eof; v, eof = itr.Next() {
+		rowID := v / SliceWidth
+		data = data.Union(storage.OffsetRange(slice*SliceWidth, rowID*SliceWidth, (rowID+1)*SliceWidth))
+		itr.Seek((rowID + 1) * SliceWidth)
+	}
+
+	bm := &Bitmap{
+		segments: []BitmapSegment{{
+			data:     *data,
+			slice:    slice,
+			writable: true,
+		}},
+	}
+	bm.InvalidateCount()
+	return bm
+}
+
+// minColumnID returns the lowest column id set in the storage of a slice.
+// Only the first bit of each row is read, stopping early if the first
+// column of the slice is found.
+func minColumnID(storage *roaring.Bitmap, slice uint64) (uint64, bool) {
+	var min uint64
+	var ok bool
+	itr := storage.Iterator()
+	for v, eof := itr.Next(); !eof; v, eof = itr.Next() {
+		if col := v % SliceWidth; !ok || col < min {
+			min, ok = col, true
+		}
+		if min == 0 {
+			break
+		}
+		itr.Seek(((v / SliceWidth) + 1) * SliceWidth)
+	}
+	if !ok {
+		return 0, false
+	}
+	return (slice * SliceWidth) + min, true
+}
+
+// maxColumnID returns the highest column id set in the storage of a slice.
+// Every row holds its own columns so all bits are scanned, stopping early
+// if the last column of the slice is found.
+func maxColumnID(storage *roaring.Bitmap, slice uint64) (uint64, bool) {
+	var max uint64
+	var ok bool
+	itr := storage.Iterator()
+	for v, eof := itr.Next(); !eof; v, eof = itr.Next() {
+		if col := v % SliceWidth; !ok || col > max {
+			max, ok = col, true
+		}
+		if max == SliceWidth-1 {
+			break
+		}
+	}
+	if !ok {
+		return 0, false
+	}
+	return (slice * SliceWidth) + max, true
+}
+
 // Top returns the top rows from the fragment.
 // If opt.Src is specified then only rows which intersect src are returned.
 // If opt.FilterValues exist then the row attribute specified by field is matched.
@@ -500,12 +728,9 @@ func (f *Fragment) Top(opt TopOptions) ([]Pair, error) {
 	}
 
 	// Create a fast lookup of filter values.
-	var filters map[interface{}]struct{}
+	var filters attrFilterSet
 	if opt.FilterField != "" && len(opt.FilterValues) > 0 {
-		filters = make(map[interface{}]struct{})
-		for _, v := range opt.FilterValues {
-			filters[v] = struct{}{}
-		}
+		filters = newAttrFilterSet(opt.FilterValues)
 	}
 
 	// Use `tanimotoThreshold > 0` to indicate whether or not we are considering Tanimoto.
@@ -549,9 +774,7 @@ func (f *Fragment) Top(opt TopOptions) ([]Pair, error) {
 				return nil, err
 			} else if attr == nil {
 				continue
-			} else if attrValue := attr[opt.FilterField]; attrValue == nil {
-				continue
-			} else if _, ok := filters[attrValue]; !ok {
+			} else if !filters.matches(attr[opt.FilterField]) {
 				continue
 			}
 		}
@@ -973,6 +1196,7 @@ func (f *Fragment) Import(rowIDs, columnIDs []uint64) error {
 		}
 
 		f.cache.Invalidate()
+		f.generation++
 		return nil
 	}(); err != nil {
 		_ = f.closeStorage()
@@ -1244,6 +1468,7 @@ func (f *Fragment) readStorageFromArchive(r io.Reader) error {
 	if err := f.openStorage(); err != nil {
 		return err
 	}
+	f.generation++
 
 	return nil
 }
@@ -21,6 +21,7 @@ import (
 	"math"
 	"os"
 	"reflect"
+	"sync"
 	"testing"
 
 	"github.com/davecgh/go-spew/spew"
@@ -122,6 +123,121 @@ func TestFragment_Snapshot(t *testing.T) {
 	}
 }
 
+// Ensure a read snapshot is unaffected by later changes to the fragment.
+func TestFragment_ReadSnapshot(t *testing.T) {
+	f := MustOpenFragment("i", "f", pilosa.ViewStandard, 0)
+	defer f.Close()
+
+	if _, err := f.SetBit(100, 1); err != nil {
+		t.Fatal(err)
+	} else if _, err := f.SetBit(100, 2); err != nil {
+		t.Fatal(err)
+	}
+	snap := f.ReadSnapshot()
+	if snap.Generation() != f.Generation() {
+		t.Fatalf("unexpected generation: %d, expected %d", snap.Generation(), f.Generation())
+	}
+
+	// Change the fragment after the snapshot.
+	if _, err := f.SetBit(100, 3); err != nil {
+		t.Fatal(err)
+	} else if _, err := f.ClearBit(100, 1); err != nil {
+		t.Fatal(err)
+	} else if _, err := f.SetBit(100, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only changes increment the generation.
+	if n := f.Generation() - snap.Generation(); n != 2 {
+		t.Fatalf("unexpected generation change: %d", n)
+	} else if bits := snap.Row(100).Bits(); !reflect.DeepEqual(bits, []uint64{1, 2}) {
+		t.Fatalf("unexpected snapshot bits: %+v", bits)
+	} else if bits := f.Row(100).Bits(); !reflect.DeepEqual(bits, []uint64{2, 3}) {
+		t.Fatalf("unexpected bits: %+v", bits)
+	}
+}
+
+// Ensure a bounded row cache returns hot rows and is invalidated by writes.
+func TestFragment_RowCache(t *testing.T) {
+	f := NewFragment("i", "f", pilosa.ViewStandard, 0)
+	f.RowCacheSize = 2
+	if err := f.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if _, err := f.SetBit(100, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// The second read of a row hits the cache.
+	bm := f.Row(100)
+	if other := f.Row(100); other != bm {
+		t.Fatal("expected cached row")
+	} else if hitN, missN := f.RowCacheStats(); hitN != 1 || missN != 1 {
+		t.Fatalf("unexpected stats: hits=%d, misses=%d", hitN, missN)
+	}
+
+	// Writes evict the row without changing rows already returned.
+	if _, err := f.SetBit(100, 2); err != nil {
+		t.Fatal(err)
+	} else if bits := bm.Bits(); !reflect.DeepEqual(bits, []uint64{1}) {
+		t.Fatalf("unexpected cached bits: %+v", bits)
+	} else if bits := f.Row(100).Bits(); !reflect.DeepEqual(bits, []uint64{1, 2}) {
+		t.Fatalf("unexpected bits: %+v", bits)
+	}
+	if _, err := f.ClearBit(100, 1); err != nil {
+		t.Fatal(err)
+	} else if bits := f.Row(100).Bits(); !reflect.DeepEqual(bits, []uint64{2}) {
+		t.Fatalf("unexpected bits: %+v", bits)
+	}
+
+	// Least recently read rows are evicted.
+	bm = f.Row(100)
+	f.Row(101)
+	f.Row(102)
+	if f.Row(100) == bm {
+		t.Fatal("expected evicted row")
+	}
+}
+
+// Ensure concurrent reads & writes through a bounded row cache are consistent.
+func TestFragment_RowCache_Concurrent(t *testing.T) {
+	f := NewFragment("i", "f", pilosa.ViewStandard, 0)
+	f.RowCacheSize = 1
+	if err := f.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := uint64(0); i < 1000; i++ {
+			if _, err := f.SetBit(100, i); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+
+	// Row counts never decrease while bits are only being set.
+	var prev uint64
+	for i := 0; i < 1000; i++ {
+		n := f.Row(100).Count()
+		if n < prev {
+			t.Fatalf("row count decreased: %d < %d", n, prev)
+		}
+		prev = n
+	}
+	wg.Wait()
+
+	if n := f.Row(100).Count(); n != 1000 {
+		t.Fatalf("unexpected count: %d", n)
+	}
+}
+
 // Ensure a fragment can iterate over all bits in order.
 func TestFragment_ForEachBit(t *testing.T) {
 	f := MustOpenFragment("i", "f", pilosa.ViewStandard, 0)
@@ -63,6 +63,9 @@ type Frame struct {
 	// Cache size for ranked frames
 	cacheSize uint32
 
+	// Row cache size passed to fragments.
+	rowCacheSize int
+
 	LogOutput io.Writer
 }
 
@@ -439,6 +442,7 @@ func (f *Frame) newView(path, name string) *View {
 	view.RowAttrStore = f.rowAttrStore
 	view.stats = f.stats.WithTags(fmt.Sprintf("slice:%s", name))
 	view.broadcaster = f.broadcaster
+	view.rowCacheSize = f.rowCacheSize
 	return view
 }
 
@@ -44,6 +44,29 @@ import (
 	"github.com/rakyll/statik/fs"
 )
 
+// StreamPairsHeader is the header used to negotiate streaming of TopN()
+// results. A client sets it on a request for a single TopN() call and the
+// server sets it on the response if the pairs are written as a stream of
+// length-delimited messages instead of a single QueryResponse.
+const StreamPairsHeader = "X-Pilosa-Stream-Pairs"
+
+// QueryDeadlineHeader is the header used to forward the deadline of a
+// distributed query to remote nodes. The value is formatted as RFC 3339 with
+// nanoseconds. Requests past their deadline are canceled by the server.
+const QueryDeadlineHeader = "X-Query-Deadline"
+
+// statsExecutor is implemented by executors which report the slices
+// visited by each call.
+type statsExecutor interface {
+	ExecuteWithStats(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *ExecOptions) ([]interface{}, *ExecStats, error)
+}
+
+// cachingExecutor is implemented by executors which cache query results
+// that must be invalidated by writes made outside of query execution.
+type cachingExecutor interface {
+	InvalidateQueryCacheFrame(index, frame string)
+}
+
 // Handler represents an HTTP handler.
 type Handler struct {
 	Holder        *Holder
@@ -187,10 +210,9 @@ func (h *Handler) handlePostQuery(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Build execution options.
-	opt := &ExecOptions{
-		Remote: req.Remote,
-	}
+	// Build execution options. Warnings are collected for the response.
+	opt := req.execOptions()
+	opt.warnings = &warningList{}
 
 	// Parse query string.
 	q, err := pql.NewParser(strings.NewReader(req.Query)).Parse()
@@ -200,24 +222,61 @@ func (h *Handler) handlePostQuery(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Execute the query.
-	results, err := h.Executor.Execute(r.Context(), indexName, q, req.Slices, opt)
-	resp := &QueryResponse{Results: results, Err: err}
+	// Stop executing once the coordinator's deadline has passed.
+	ctx := r.Context()
+	if !req.Deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, req.Deadline)
+		defer cancel()
+	}
+
+	// Execute the query. Record the nodes which served each slice, if
+	// requested and supported by the executor.
+	var results []interface{}
+	var stats *ExecStats
+	if se, ok := h.Executor.(statsExecutor); ok && req.Attribution {
+		results, stats, err = se.ExecuteWithStats(ctx, indexName, q, req.Slices, opt)
+	} else {
+		results, err = h.Executor.Execute(ctx, indexName, q, req.Slices, opt)
+	}
+
+	// Stream TopN() pairs if the client supports it.
+	if err == nil && len(results) == 1 && r.Header.Get(StreamPairsHeader) != "" && strings.Contains(r.Header.Get("Accept"), "application/x-protobuf") {
+		if pairs, ok := results[0].([]Pair); ok {
+			w.Header().Set(StreamPairsHeader, "1")
+			if err := writePairStream(w, pairs); err != nil {
+				h.logger().Printf("write pair stream error: %s", err)
+			}
+			return
+		}
+	}
+
+	resp := &QueryResponse{Results: results, Warnings: opt.warnings.list(), Err: err}
+	if stats != nil {
+		resp.Attribution = make([]map[string][]uint64, len(stats.Calls))
+		for i, cs := range stats.Calls {
+			resp.Attribution[i] = cs.SlicesByHost()
+		}
+	}
 
 	// Fill column attributes if requested.
 	if req.ColumnAttrs {
 		// Consolidate all column ids across all calls.
 		var columnIDs []uint64
 		for _, result := range results {
-			bm, ok := result.(*Bitmap)
-			if !ok {
-				continue
+			if r, ok := result.(*ChecksumResult); ok {
+				result = r.Result
+			}
+			switch result := result.(type) {
+			case *Bitmap:
+				columnIDs = uint64Slice(columnIDs).merge(result.Bits())
+			case *BitmapResult:
+				columnIDs = uint64Slice(columnIDs).merge(result.Bitmap.Bits())
 			}
-			columnIDs = uint64Slice(columnIDs).merge(bm.Bits())
 		}
 
 		// Retrieve column attributes across all calls.
-		columnAttrSets, err := h.readColumnAttrSets(h.Holder.Index(indexName), columnIDs)
+		columnAttrSets, err := readColumnAttrSets(h.Holder.Index(indexName), columnIDs)
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
 			h.writeQueryResponse(w, r, &QueryResponse{Err: err})
@@ -292,7 +351,7 @@ type postIndexRequest struct {
 	Options IndexOptions `json:"options"`
 }
 
-//_postIndexRequest is necessary to avoid recursion while decoding.
+// _postIndexRequest is necessary to avoid recursion while decoding.
 type _postIndexRequest postIndexRequest
 
 // Custom Unmarshal JSON to validate request body when creating a new index.
@@ -785,7 +844,7 @@ type postFrameAttrDiffResponse struct {
 }
 
 // readColumnAttrSets returns a list of column attribute objects by id.
-func (h *Handler) readColumnAttrSets(index *Index, ids []uint64) ([]*ColumnAttrSet, error) {
+func readColumnAttrSets(index *Index, ids []uint64) ([]*ColumnAttrSet, error) {
 	if index == nil {
 		return nil, nil
 	}
@@ -809,12 +868,28 @@ func (h *Handler) readColumnAttrSets(index *Index, ids []uint64) ([]*ColumnAttrS
 
 // readQueryRequest parses an query parameters from r.
 func (h *Handler) readQueryRequest(r *http.Request) (*QueryRequest, error) {
+	var req *QueryRequest
+	var err error
 	switch r.Header.Get("Content-Type") {
 	case "application/x-protobuf":
-		return h.readProtobufQueryRequest(r)
+		req, err = h.readProtobufQueryRequest(r)
 	default:
-		return h.readURLQueryRequest(r)
+		req, err = h.readURLQueryRequest(r)
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Read the deadline from the header if the body did not include one.
+	if s := r.Header.Get(QueryDeadlineHeader); s != "" && req.Deadline.IsZero() {
+		deadline, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, errors.New("invalid query deadline")
+		}
+		req.Deadline = deadline
+	}
+
+	return req, nil
 }
 
 // readProtobufQueryRequest parses query parameters in protobuf from r.
@@ -851,6 +926,28 @@ func (h *Handler) readURLQueryRequest(r *http.Request) (*QueryRequest, error) {
 		return nil, errors.New("invalid slice argument")
 	}
 
+	// Parse list of slices to skip.
+	excludeSlices, err := parseUint64Slice(q.Get("excludeSlices"))
+	if err != nil {
+		return nil, errors.New("invalid excludeSlices argument")
+	}
+
+	// Parse fragment read budget.
+	var maxFragmentReads int
+	if s := q.Get("maxFragmentReads"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 0 {
+			return nil, errors.New("invalid maxFragmentReads argument")
+		}
+		maxFragmentReads = v
+	}
+
+	// Parse stats tags.
+	tags, err := parseQueryTags(q.Get("tags"))
+	if err != nil {
+		return nil, errors.New("invalid tags argument")
+	}
+
 	// Parse time granularity.
 	quantum := TimeQuantum("YMDH")
 	if s := q.Get("time_granularity"); s != "" {
@@ -862,10 +959,30 @@ func (h *Handler) readURLQueryRequest(r *http.Request) (*QueryRequest, error) {
 	}
 
 	return &QueryRequest{
-		Query:       query,
-		Slices:      slices,
-		ColumnAttrs: q.Get("columnAttrs") == "true",
-		Quantum:     quantum,
+		Query:                    query,
+		Slices:                   slices,
+		ExcludeSlices:            excludeSlices,
+		ColumnAttrs:              q.Get("columnAttrs") == "true",
+		Quantum:                  quantum,
+		Remote:                   q.Get("remote") == "true",
+		IncludeCount:             q.Get("includeCount") == "true",
+		IncludeUniverse:          q.Get("includeUniverse") == "true",
+		Checksum:                 q.Get("checksum") == "true",
+		EmptyRangeWithoutQuantum: q.Get("emptyRangeWithoutQuantum") == "true",
+		RawRoaring:               q.Get("rawRoaring") == "true",
+		TranslateKeys:            q.Get("translateKeys") == "true",
+		ChangedByView:            q.Get("changedByView") == "true",
+		OperationID:              q.Get("operationID"),
+		TreatMissingAsEmpty:      q.Get("treatMissingAsEmpty") == "true",
+		Snapshot:                 q.Get("snapshot") == "true",
+		SkipInverse:              q.Get("skipInverse") == "true",
+		AllowPartial:             q.Get("allowPartial") == "true",
+		Attribution:              q.Get("attribution") == "true",
+		PinNode:                  q.Get("pinNode"),
+		MaxFragmentReads:         maxFragmentReads,
+		ParallelChildren:         q.Get("parallelChildren") == "true",
+		DedupCalls:               q.Get("dedupCalls") == "true",
+		Tags:                     tags,
 	}, nil
 }
 
@@ -951,6 +1068,9 @@ func (h *Handler) handlePostImport(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Invalidate cached query results once the import completes.
+	defer h.invalidateQueryCacheFrame(req.Index, req.Frame)
+
 	// Import into fragment.
 	err = f.Import(req.RowIDs, req.ColumnIDs, timestamps)
 	if err != nil {
@@ -1085,6 +1205,9 @@ func (h *Handler) handlePostFragmentData(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Invalidate cached query results once the fragment is written.
+	defer h.invalidateQueryCacheFrame(q.Get("index"), q.Get("frame"))
+
 	// Retrieve view.
 	view, err := f.CreateViewIfNotExists(q.Get("view"))
 	if err != nil {
@@ -1211,6 +1334,9 @@ func (h *Handler) handlePostFrameRestore(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Invalidate cached query results once the restore completes.
+	defer h.invalidateQueryCacheFrame(indexName, frameName)
+
 	// Retrieve list of all views.
 	views, err := client.FrameViews(r.Context(), indexName, frameName)
 	if err != nil {
@@ -1265,6 +1391,14 @@ func (h *Handler) handlePostFrameRestore(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// invalidateQueryCacheFrame marks cached query results which read from a frame
+// as stale, if the executor caches results.
+func (h *Handler) invalidateQueryCacheFrame(index, frame string) {
+	if ce, ok := h.Executor.(cachingExecutor); ok {
+		ce.InvalidateQueryCacheFrame(index, frame)
+	}
+}
+
 // handleGetHosts handles /hosts requests.
 func (h *Handler) handleGetHosts(w http.ResponseWriter, r *http.Request) {
 	if err := json.NewEncoder(w).Encode(h.Cluster.Nodes); err != nil {
@@ -1316,6 +1450,12 @@ type QueryRequest struct {
 	// If empty, all slices are included.
 	Slices []uint64
 
+	// The slices to skip, even if included by Slices.
+	ExcludeSlices []uint64
+
+	// Execute identical read calls once, if true.
+	DedupCalls bool
+
 	// Return column attributes, if true.
 	ColumnAttrs bool
 
@@ -1325,15 +1465,103 @@ type QueryRequest struct {
 	// If true, indicates that query is part of a larger distributed query.
 	// If false, this request is on the originating node.
 	Remote bool
+
+	// Return the column count with bitmap results, if true.
+	IncludeCount bool
+
+	// Return the universe column count with Count() results, if true.
+	IncludeUniverse bool
+
+	// Return a hash of each bitmap & TopN() result, if true.
+	Checksum bool
+
+	// Return empty Range() results for frames without a time quantum, if true.
+	EmptyRangeWithoutQuantum bool
+
+	// Return bitmap results as roaring-encoded bytes, if true.
+	RawRoaring bool
+
+	// Return keys for result ids, if true.
+	TranslateKeys bool
+
+	// Return changes per view for SetBit() calls, if true.
+	ChangedByView bool
+
+	// Identifies a forwarded mutation which should only be applied once.
+	OperationID string
+
+	// Read missing frames as empty instead of failing, if true.
+	TreatMissingAsEmpty bool
+
+	// Time at which the originating query expires, if set.
+	Deadline time.Time
+
+	// Read a copy of the data taken when the query starts, if true.
+	Snapshot bool
+
+	// Only write the standard view of SetBit() & ClearBit() calls, if true.
+	SkipInverse bool
+
+	// Skip local slices which fail instead of failing the query, if true.
+	AllowPartial bool
+
+	// Return the nodes which served each slice, if true.
+	Attribution bool
+
+	// Host of a node to execute all slices on, if set. Used for load testing.
+	PinNode string
+
+	// Maximum number of fragments read on each node, if non-zero.
+	MaxFragmentReads int
+
+	// Evaluate Intersect() & Union() children concurrently, if true.
+	ParallelChildren bool
+
+	// Labels attached to the query's stats.
+	Tags map[string]string
+}
+
+// execOptions returns the execution options specified by the request.
+func (r *QueryRequest) execOptions() *ExecOptions {
+	return &ExecOptions{
+		Remote:                   r.Remote,
+		IncludeCount:             r.IncludeCount,
+		IncludeUniverse:          r.IncludeUniverse,
+		Checksum:                 r.Checksum,
+		EmptyRangeWithoutQuantum: r.EmptyRangeWithoutQuantum,
+		RawRoaring:               r.RawRoaring,
+		TranslateKeys:            r.TranslateKeys,
+		ChangedByView:            r.ChangedByView,
+		OperationID:              r.OperationID,
+		TreatMissingAsEmpty:      r.TreatMissingAsEmpty,
+		Snapshot:                 r.Snapshot,
+		SkipInverse:              r.SkipInverse,
+		AllowPartial:             r.AllowPartial,
+		PinNode:                  r.PinNode,
+		MaxFragmentReads:         r.MaxFragmentReads,
+		ParallelChildren:         r.ParallelChildren,
+		ExcludeSlices:            r.ExcludeSlices,
+		DedupCalls:               r.DedupCalls,
+		Tags:                     r.Tags,
+	}
 }
 
 func decodeQueryRequest(pb *internal.QueryRequest) *QueryRequest {
 	req := &QueryRequest{
-		Query:       pb.Query,
-		Slices:      pb.Slices,
-		ColumnAttrs: pb.ColumnAttrs,
-		Quantum:     TimeQuantum(pb.Quantum),
-		Remote:      pb.Remote,
+		Query:               pb.Query,
+		Slices:              pb.Slices,
+		ColumnAttrs:         pb.ColumnAttrs,
+		Quantum:             TimeQuantum(pb.Quantum),
+		Remote:              pb.Remote,
+		OperationID:         pb.OperationID,
+		TreatMissingAsEmpty: pb.TreatMissingAsEmpty,
+		Snapshot:            pb.Snapshot,
+		SkipInverse:         pb.SkipInverse,
+		MaxFragmentReads:    int(pb.MaxFragmentReads),
+		ParallelChildren:    pb.ParallelChildren,
+	}
+	if pb.Deadline != 0 {
+		req.Deadline = time.Unix(0, pb.Deadline)
 	}
 
 	return req
@@ -1348,6 +1576,13 @@ type QueryResponse struct {
 	// Set of column attribute objects matching IDs returned in Result.
 	ColumnAttrSets []*ColumnAttrSet
 
+	// Slices served by each node, by host, for each call.
+	// Only set if attribution is requested.
+	Attribution []map[string][]uint64
+
+	// Conditions which did not fail the query but may affect its results.
+	Warnings []Warning
+
 	// Error during parsing or execution.
 	Err error
 }
@@ -1355,12 +1590,16 @@ type QueryResponse struct {
 // MarshalJSON marshals QueryResponse into a JSON-encoded byte slice
 func (resp *QueryResponse) MarshalJSON() ([]byte, error) {
 	var output struct {
-		Results        []interface{}    `json:"results,omitempty"`
-		ColumnAttrSets []*ColumnAttrSet `json:"columnAttrs,omitempty"`
-		Err            string           `json:"error,omitempty"`
+		Results        []interface{}         `json:"results,omitempty"`
+		ColumnAttrSets []*ColumnAttrSet      `json:"columnAttrs,omitempty"`
+		Attribution    []map[string][]uint64 `json:"attribution,omitempty"`
+		Warnings       []Warning             `json:"warnings,omitempty"`
+		Err            string                `json:"error,omitempty"`
 	}
 	output.Results = resp.Results
 	output.ColumnAttrSets = resp.ColumnAttrSets
+	output.Attribution = resp.Attribution
+	output.Warnings = resp.Warnings
 
 	if resp.Err != nil {
 		output.Err = resp.Err.Error()
@@ -1377,15 +1616,51 @@ func encodeQueryResponse(resp *QueryResponse) *internal.QueryResponse {
 	for i := range resp.Results {
 		pb.Results[i] = &internal.QueryResult{}
 
-		switch result := resp.Results[i].(type) {
+		// Checksums are encoded alongside the result they cover.
+		result := resp.Results[i]
+		if r, ok := result.(*ChecksumResult); ok {
+			pb.Results[i].Checksum = r.Checksum
+			result = r.Result
+		}
+
+		switch result := result.(type) {
 		case *Bitmap:
 			pb.Results[i].Bitmap = encodeBitmap(result)
+		case *BitmapResult:
+			pb.Results[i].Bitmap = encodeBitmap(result.Bitmap)
+			pb.Results[i].N = result.Count
+		case *PageResult:
+			pb.Results[i].Bitmap = encodeBitmap(NewBitmap(result.Columns...))
+			pb.Results[i].N = result.Count
+		case *CountResult:
+			pb.Results[i].N = result.Count
+			pb.Results[i].Universe = result.Universe
+		case *ColumnResult:
+			bm := NewBitmap()
+			if !result.Empty {
+				bm = NewBitmap(result.Column)
+			}
+			pb.Results[i].Bitmap = encodeBitmap(bm)
 		case []Pair:
 			pb.Results[i].Pairs = encodePairs(result)
+		case []uint64:
+			pb.Results[i].Bitmap = encodeBitmap(NewBitmap(result...))
+		case []byte:
+			pb.Results[i].Value = result
 		case uint64:
 			pb.Results[i].N = result
 		case bool:
 			pb.Results[i].Changed = result
+		case SetBitResult:
+			pb.Results[i].Changed = result.Changed()
+		case nil:
+		default:
+			// Results of registered aggregations are encoded as JSON.
+			buf, err := json.Marshal(result)
+			if err != nil && resp.Err == nil {
+				resp.Err = fmt.Errorf("cannot encode result: %s", err)
+			}
+			pb.Results[i].Value = buf
 		}
 	}
 
@@ -1415,6 +1690,27 @@ func parseUint64Slice(s string) ([]uint64, error) {
 	return a, nil
 }
 
+// parseQueryTags parses a comma-delimited list of "key:value" tags.
+func parseQueryTags(s string) (map[string]string, error) {
+	var m map[string]string
+	for _, str := range strings.Split(s, ",") {
+		// Ignore blanks.
+		if str == "" {
+			continue
+		}
+
+		i := strings.Index(str, ":")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid tag: %q", str)
+		}
+		if m == nil {
+			m = make(map[string]string)
+		}
+		m[str[:i]] = str[i+1:]
+	}
+	return m, nil
+}
+
 // errorString returns the string representation of err.
 func errorString(err error) string {
 	if err == nil {
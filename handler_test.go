@@ -19,6 +19,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -165,6 +166,66 @@ func TestHandler_Query_Args_URL(t *testing.T) {
 	}
 }
 
+// Ensure the handler can return the nodes which served each slice.
+func TestHandler_Query_Attribution_JSON(t *testing.T) {
+	c := NewCluster(2)
+
+	// Create secondary server and update second cluster node.
+	s := NewServer()
+	defer s.Close()
+	c.Nodes[1].Host = s.Host()
+	s.Handler.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		return []interface{}{uint64(10)}, nil
+	}
+
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 1)
+
+	h := NewHandler()
+	h.Handler.Executor = NewExecutor(hldr.Holder, c)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewHTTPRequest("POST", "/index/i/query?slices=0,2,3,4&attribution=true", strings.NewReader("Count(Bitmap(rowID=10, frame=f))")))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d, body=%s", w.Code, w.Body.String())
+	} else if body, exp := w.Body.String(), fmt.Sprintf(`{"results":[11],"attribution":[{%q:[3],%q:[0,2,4]}]}`+"\n", s.Host(), c.Nodes[0].Host); body != exp {
+		t.Fatalf("unexpected body: %q, expected %q", body, exp)
+	}
+
+	// Attribution is omitted unless requested.
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewHTTPRequest("POST", "/index/i/query?slices=0,2,3,4", strings.NewReader("Count(Bitmap(rowID=10, frame=f))")))
+	if body := w.Body.String(); body != `{"results":[11]}`+"\n" {
+		t.Fatalf("unexpected body: %q", body)
+	}
+}
+
+// Ensure the handler returns warnings alongside JSON results.
+func TestHandler_Query_Warnings_JSON(t *testing.T) {
+	hldr := MustOpenHolder()
+	defer hldr.Close()
+	hldr.MustCreateFragmentIfNotExists("i", "f", pilosa.ViewStandard, 0).MustSetBits(10, 1)
+
+	h := NewHandler()
+	h.Handler.Executor = NewExecutor(hldr.Holder, NewCluster(1))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewHTTPRequest("POST", "/index/i/query?treatMissingAsEmpty=true", strings.NewReader("Count(Bitmap(rowID=10, frame=x))")))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d, body=%s", w.Code, w.Body.String())
+	} else if body := w.Body.String(); body != `{"results":[0],"warnings":[{"code":"missing_frame","message":"frame not found, read as empty: x","call":0,"context":{"frame":"x"}}]}`+"\n" {
+		t.Fatalf("unexpected body: %q", body)
+	}
+
+	// Warnings are omitted if none are raised.
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewHTTPRequest("POST", "/index/i/query", strings.NewReader("Count(Bitmap(rowID=10, frame=f))")))
+	if body := w.Body.String(); body != `{"results":[1]}`+"\n" {
+		t.Fatalf("unexpected body: %q", body)
+	}
+}
+
 // Ensure the handler can accept arguments via protobufs.
 func TestHandler_Query_Args_Protobuf(t *testing.T) {
 	h := NewHandler()
@@ -267,6 +328,119 @@ func TestHandler_Query_Bitmap_JSON(t *testing.T) {
 	}
 }
 
+// Ensure the handler can execute a query that returns a bitmap with its count as JSON.
+func TestHandler_Query_Bitmap_IncludeCount_JSON(t *testing.T) {
+	h := NewHandler()
+	h.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		if !opt.IncludeCount {
+			t.Fatal("expected IncludeCount option")
+		}
+		bm := pilosa.NewBitmap(1, 3, 66)
+		return []interface{}{&pilosa.BitmapResult{Bitmap: bm, Count: bm.Count()}}, nil
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewHTTPRequest("POST", "/index/i/query?includeCount=true", strings.NewReader("Bitmap(id=100)")))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	} else if body := w.Body.String(); body != `{"results":[{"attrs":{},"bits":[1,3,66],"count":3}]}`+"\n" {
+		t.Fatalf("unexpected body: %s", body)
+	}
+}
+
+// Ensure the handler passes query tags to the executor.
+func TestHandler_Query_Tags(t *testing.T) {
+	h := NewHandler()
+	h.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		if !reflect.DeepEqual(opt.Tags, map[string]string{"source": "dashboard", "url": "a:b"}) {
+			t.Fatalf("unexpected tags: %v", opt.Tags)
+		}
+		return []interface{}{uint64(1)}, nil
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewHTTPRequest("POST", "/index/i/query?tags=source:dashboard,url:a:b", strings.NewReader("Count(Bitmap(id=100))")))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewHTTPRequest("POST", "/index/i/query?tags=dashboard", strings.NewReader("Count(Bitmap(id=100))")))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	}
+}
+
+// Ensure the handler passes excluded slices to the executor.
+func TestHandler_Query_ExcludeSlices(t *testing.T) {
+	h := NewHandler()
+	h.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		if !reflect.DeepEqual(opt.ExcludeSlices, []uint64{1, 3}) {
+			t.Fatalf("unexpected excluded slices: %v", opt.ExcludeSlices)
+		}
+		return []interface{}{uint64(1)}, nil
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewHTTPRequest("POST", "/index/i/query?excludeSlices=1,3", strings.NewReader("Count(Bitmap(id=100))")))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewHTTPRequest("POST", "/index/i/query?excludeSlices=x", strings.NewReader("Count(Bitmap(id=100))")))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	}
+}
+
+// Ensure the handler can execute a count query that returns its universe as JSON.
+func TestHandler_Query_Count_IncludeUniverse_JSON(t *testing.T) {
+	h := NewHandler()
+	h.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		if !opt.IncludeUniverse {
+			t.Fatal("expected IncludeUniverse option")
+		}
+		return []interface{}{&pilosa.CountResult{Count: 2, Universe: 5}}, nil
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, MustNewHTTPRequest("POST", "/index/i/query?includeUniverse=true", strings.NewReader("Count(Bitmap(id=100))")))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	} else if body := w.Body.String(); body != `{"results":[{"count":2,"universe":5}]}`+"\n" {
+		t.Fatalf("unexpected body: %s", body)
+	}
+}
+
+// Ensure the handler can return result checksums as protobuf.
+func TestHandler_Query_Checksum_Protobuf(t *testing.T) {
+	h := NewHandler()
+	h.Executor.ExecuteFn = func(ctx context.Context, index string, query *pql.Query, slices []uint64, opt *pilosa.ExecOptions) ([]interface{}, error) {
+		if !opt.Checksum {
+			t.Fatal("expected Checksum option")
+		}
+		return []interface{}{&pilosa.ChecksumResult{Result: pilosa.NewBitmap(1, 3), Checksum: "abc"}}, nil
+	}
+
+	w := httptest.NewRecorder()
+	r := MustNewHTTPRequest("POST", "/index/i/query?checksum=true", strings.NewReader("Bitmap(id=100)"))
+	r.Header.Set("Accept", "application/x-protobuf")
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	}
+
+	var resp internal.QueryResponse
+	if err := proto.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	} else if result := resp.Results[0]; result.Checksum != "abc" {
+		t.Fatalf("unexpected checksum: %q", result.Checksum)
+	} else if bits := result.Bitmap.Bits; !reflect.DeepEqual(bits, []uint64{1, 3}) {
+		t.Fatalf("unexpected bits: %v", bits)
+	}
+}
+
 // Ensure the handler can execute a query that returns a bitmap with column attributes as JSON.
 func TestHandler_Query_Bitmap_ColumnAttrs_JSON(t *testing.T) {
 	hldr := NewHolder()
@@ -51,6 +51,10 @@ type Holder struct {
 	// The interval at which the cached row ids are persisted to disk.
 	CacheFlushInterval time.Duration
 
+	// Maximum number of recently read rows cached per fragment.
+	// If zero then fragments cache every touched row until their next snapshot.
+	RowCacheSize int
+
 	LogOutput io.Writer
 }
 
@@ -246,6 +250,7 @@ func (h *Holder) createIndex(name string, opt IndexOptions) (*Index, error) {
 	// Update options.
 	index.SetColumnLabel(opt.ColumnLabel)
 	index.SetTimeQuantum(opt.TimeQuantum)
+	index.SetDefaultFrame(opt.DefaultFrame)
 
 	h.indexes[index.Name()] = index
 
@@ -262,6 +267,7 @@ func (h *Holder) newIndex(path, name string) (*Index, error) {
 	index.LogOutput = h.LogOutput
 	index.stats = h.Stats.WithTags(fmt.Sprintf("index:%s", index.Name()))
 	index.broadcaster = h.Broadcaster
+	index.rowCacheSize = h.RowCacheSize
 	return index, nil
 }
 
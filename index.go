@@ -47,6 +47,10 @@ type Index struct {
 	// Label used for referring to columns in index.
 	columnLabel string
 
+	// Frame used by calls which do not specify one.
+	// If blank then the global DefaultFrame is used.
+	defaultFrame string
+
 	// Frames by name.
 	frames map[string]*Frame
 
@@ -60,6 +64,9 @@ type Index struct {
 	broadcaster Broadcaster
 	stats       StatsClient
 
+	// Row cache size passed to fragments.
+	rowCacheSize int
+
 	LogOutput io.Writer
 }
 
@@ -130,6 +137,44 @@ func (i *Index) ColumnLabel() string {
 	return v
 }
 
+// SetDefaultFrame sets the frame used by calls which do not specify one.
+// Persists to meta file on update.
+func (i *Index) SetDefaultFrame(v string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	// Ignore if no change occurred.
+	if v == "" || i.defaultFrame == v {
+		return nil
+	}
+
+	// Make sure the frame name is valid.
+	if err := ValidateName(v); err != nil {
+		return err
+	}
+
+	// Persist meta data to disk on change.
+	i.defaultFrame = v
+	if err := i.saveMeta(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DefaultFrame returns the frame used by calls which do not specify one.
+// Returns the global DefaultFrame if the index does not set one.
+func (i *Index) DefaultFrame() string {
+	i.mu.Lock()
+	v := i.defaultFrame
+	i.mu.Unlock()
+
+	if v == "" {
+		return DefaultFrame
+	}
+	return v
+}
+
 // Open opens and initializes the index.
 func (i *Index) Open() error {
 	// Ensure the path exists.
@@ -194,6 +239,7 @@ func (i *Index) loadMeta() error {
 	if os.IsNotExist(err) {
 		i.timeQuantum = ""
 		i.columnLabel = DefaultColumnLabel
+		i.defaultFrame = ""
 		return nil
 	} else if err != nil {
 		return err
@@ -206,6 +252,7 @@ func (i *Index) loadMeta() error {
 	// Copy metadata fields.
 	i.timeQuantum = TimeQuantum(pb.TimeQuantum)
 	i.columnLabel = pb.ColumnLabel
+	i.defaultFrame = pb.DefaultFrame
 
 	return nil
 }
@@ -214,8 +261,9 @@ func (i *Index) loadMeta() error {
 func (i *Index) saveMeta() error {
 	// Marshal metadata.
 	buf, err := proto.Marshal(&internal.IndexMeta{
-		TimeQuantum: string(i.timeQuantum),
-		ColumnLabel: i.columnLabel,
+		TimeQuantum:  string(i.timeQuantum),
+		ColumnLabel:  i.columnLabel,
+		DefaultFrame: i.defaultFrame,
 	})
 	if err != nil {
 		return err
@@ -439,6 +487,7 @@ func (i *Index) newFrame(path, name string) (*Frame, error) {
 	f.LogOutput = i.LogOutput
 	f.stats = i.stats.WithTags(fmt.Sprintf("frame:%s", name))
 	f.broadcaster = i.broadcaster
+	f.rowCacheSize = i.rowCacheSize
 	return f, nil
 }
 
@@ -543,8 +592,9 @@ func encodeIndex(d *Index) *internal.Index {
 	return &internal.Index{
 		Name: d.name,
 		Meta: &internal.IndexMeta{
-			ColumnLabel: d.columnLabel,
-			TimeQuantum: string(d.timeQuantum),
+			ColumnLabel:  d.columnLabel,
+			TimeQuantum:  string(d.timeQuantum),
+			DefaultFrame: d.defaultFrame,
 		},
 		MaxSlice: d.MaxSlice(),
 		Frames:   encodeFrames(d.Frames()),
@@ -553,15 +603,17 @@ func encodeIndex(d *Index) *internal.Index {
 
 // IndexOptions represents options to set when initializing an index.
 type IndexOptions struct {
-	ColumnLabel string      `json:"columnLabel,omitempty"`
-	TimeQuantum TimeQuantum `json:"timeQuantum,omitempty"`
+	ColumnLabel  string      `json:"columnLabel,omitempty"`
+	TimeQuantum  TimeQuantum `json:"timeQuantum,omitempty"`
+	DefaultFrame string      `json:"defaultFrame,omitempty"`
 }
 
 // Encode converts o into its internal representation.
 func (o *IndexOptions) Encode() *internal.IndexMeta {
 	return &internal.IndexMeta{
-		ColumnLabel: o.ColumnLabel,
-		TimeQuantum: string(o.TimeQuantum),
+		ColumnLabel:  o.ColumnLabel,
+		TimeQuantum:  string(o.TimeQuantum),
+		DefaultFrame: o.DefaultFrame,
 	}
 }
 
@@ -130,6 +130,36 @@ func TestIndex_SetTimeQuantum(t *testing.T) {
 	}
 }
 
+// Ensure index can set the default frame.
+func TestIndex_SetDefaultFrame(t *testing.T) {
+	index := MustOpenIndex()
+	defer index.Close()
+
+	// Falls back to the global default frame.
+	if f := index.DefaultFrame(); f != pilosa.DefaultFrame {
+		t.Fatalf("unexpected default frame: %s", f)
+	}
+
+	// Set & retrieve default frame.
+	if err := index.SetDefaultFrame("events"); err != nil {
+		t.Fatal(err)
+	} else if f := index.DefaultFrame(); f != "events" {
+		t.Fatalf("unexpected default frame: %s", f)
+	}
+
+	// Reload index and verify that it is persisted.
+	if err := index.Reopen(); err != nil {
+		t.Fatal(err)
+	} else if f := index.DefaultFrame(); f != "events" {
+		t.Fatalf("unexpected default frame (reopen): %s", f)
+	}
+
+	// Invalid frame names are rejected.
+	if err := index.SetDefaultFrame("_bad"); err != pilosa.ErrName {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 // Index represents a test wrapper for pilosa.Index.
 type Index struct {
 	*pilosa.Index
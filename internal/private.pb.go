@@ -60,8 +60,9 @@ var _ = math.Inf
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
 type IndexMeta struct {
-	ColumnLabel string `protobuf:"bytes,1,opt,name=ColumnLabel,proto3" json:"ColumnLabel,omitempty"`
-	TimeQuantum string `protobuf:"bytes,2,opt,name=TimeQuantum,proto3" json:"TimeQuantum,omitempty"`
+	ColumnLabel  string `protobuf:"bytes,1,opt,name=ColumnLabel,proto3" json:"ColumnLabel,omitempty"`
+	TimeQuantum  string `protobuf:"bytes,2,opt,name=TimeQuantum,proto3" json:"TimeQuantum,omitempty"`
+	DefaultFrame string `protobuf:"bytes,3,opt,name=DefaultFrame,proto3" json:"DefaultFrame,omitempty"`
 }
 
 func (m *IndexMeta) Reset()                    { *m = IndexMeta{} }
@@ -327,6 +328,12 @@ func (m *IndexMeta) MarshalTo(dAtA []byte) (int, error) {
 		i = encodeVarintPrivate(dAtA, i, uint64(len(m.TimeQuantum)))
 		i += copy(dAtA[i:], m.TimeQuantum)
 	}
+	if len(m.DefaultFrame) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintPrivate(dAtA, i, uint64(len(m.DefaultFrame)))
+		i += copy(dAtA[i:], m.DefaultFrame)
+	}
 	return i, nil
 }
 
@@ -951,6 +958,10 @@ func (m *IndexMeta) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovPrivate(uint64(l))
 	}
+	l = len(m.DefaultFrame)
+	if l > 0 {
+		n += 1 + l + sovPrivate(uint64(l))
+	}
 	return n
 }
 
@@ -1307,6 +1318,35 @@ func (m *IndexMeta) Unmarshal(dAtA []byte) error {
 			}
 			m.TimeQuantum = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DefaultFrame", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPrivate
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthPrivate
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.DefaultFrame = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipPrivate(dAtA[iNdEx:])
@@ -3422,45 +3462,46 @@ var (
 func init() { proto.RegisterFile("private.proto", fileDescriptorPrivate) }
 
 var fileDescriptorPrivate = []byte{
-	// 640 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x9c, 0x54, 0xc1, 0x4e, 0x14, 0x4d,
-	0x10, 0xfe, 0x67, 0x77, 0x96, 0x7f, 0xa7, 0x08, 0x08, 0x2d, 0x31, 0x23, 0x21, 0x9b, 0x4d, 0x1f,
-	0x04, 0x39, 0x70, 0xc0, 0x8b, 0x51, 0x0f, 0x86, 0x5d, 0x0c, 0x93, 0x08, 0xc6, 0x5e, 0xe2, 0xd1,
-	0xa4, 0x81, 0x8a, 0x4e, 0x98, 0x9d, 0x59, 0xa7, 0x7b, 0x80, 0xf5, 0xe0, 0x73, 0x98, 0x78, 0xf2,
-	0x01, 0x7c, 0x0f, 0x8f, 0x3e, 0x82, 0xc1, 0x17, 0x31, 0x5d, 0xdd, 0x33, 0xb3, 0x0e, 0x22, 0xd1,
-	0x5b, 0xd7, 0x57, 0xd5, 0xf5, 0x7d, 0xfd, 0x4d, 0xd5, 0xc0, 0xc2, 0x24, 0x8f, 0xcf, 0xa4, 0xc6,
-	0xad, 0x49, 0x9e, 0xe9, 0x8c, 0x75, 0xe3, 0x54, 0x63, 0x9e, 0xca, 0x84, 0xbf, 0x80, 0x20, 0x4a,
-	0x4f, 0xf0, 0x62, 0x1f, 0xb5, 0x64, 0x7d, 0x98, 0x1f, 0x64, 0x49, 0x31, 0x4e, 0x9f, 0xcb, 0x23,
-	0x4c, 0x42, 0xaf, 0xef, 0x6d, 0x04, 0x62, 0x16, 0x32, 0x15, 0x87, 0xf1, 0x18, 0x5f, 0x16, 0x32,
-	0xd5, 0xc5, 0x38, 0x6c, 0xd9, 0x8a, 0x19, 0x88, 0x7f, 0xf1, 0x20, 0x78, 0x96, 0xcb, 0x31, 0x52,
-	0xc7, 0x55, 0xe8, 0x8a, 0xec, 0x7c, 0xb6, 0x5d, 0x15, 0xb3, 0x7b, 0xb0, 0x18, 0xa5, 0x67, 0x98,
-	0x2b, 0xdc, 0x4d, 0xe5, 0x51, 0x82, 0x27, 0xd4, 0xae, 0x2b, 0x1a, 0x28, 0x5b, 0x83, 0x60, 0x20,
-	0x8f, 0xdf, 0xe2, 0xe1, 0x74, 0x82, 0x61, 0x9b, 0x9a, 0xd4, 0x40, 0x95, 0x1d, 0xc5, 0xef, 0x31,
-	0xf4, 0xfb, 0xde, 0xc6, 0x82, 0xa8, 0x81, 0xa6, 0xde, 0xce, 0x55, 0xbd, 0x1c, 0x16, 0xa3, 0xf1,
-	0x24, 0xcb, 0xb5, 0x40, 0x35, 0xc9, 0x52, 0x85, 0x6c, 0x09, 0xda, 0xbb, 0x79, 0xee, 0xe4, 0x9a,
-	0x23, 0xff, 0x00, 0x4b, 0x3b, 0x49, 0x76, 0x7c, 0x3a, 0x94, 0x5a, 0x0a, 0x7c, 0x57, 0xa0, 0xd2,
-	0x6c, 0x05, 0x3a, 0x64, 0x9c, 0xab, 0xb3, 0x81, 0x41, 0xe9, 0xf1, 0xce, 0x19, 0x1b, 0x18, 0x94,
-	0xee, 0x93, 0x7a, 0x5f, 0xd8, 0xc0, 0xa0, 0xa3, 0x24, 0x3e, 0xb6, 0xaa, 0x7d, 0x61, 0x03, 0xc6,
-	0xc0, 0x7f, 0x15, 0xe3, 0xb9, 0x93, 0x4a, 0x67, 0x1e, 0xc1, 0xf2, 0x0c, 0xbf, 0x93, 0x79, 0x07,
-	0xe6, 0x44, 0x76, 0x1e, 0x0d, 0x55, 0xe8, 0xf5, 0xdb, 0x1b, 0xbe, 0x70, 0x11, 0x19, 0x42, 0x5f,
-	0xcc, 0xa4, 0x5a, 0x94, 0xaa, 0x01, 0x7e, 0x17, 0x3a, 0xe4, 0x8e, 0x79, 0x65, 0x7d, 0xd7, 0x1c,
-	0xf9, 0x27, 0x0f, 0x96, 0xf7, 0xe5, 0x05, 0xc9, 0x50, 0x15, 0xcd, 0x1e, 0x04, 0x15, 0x48, 0xd5,
-	0xf3, 0xdb, 0x9b, 0x5b, 0xe5, 0xf8, 0x6c, 0x5d, 0xa9, 0xaf, 0x91, 0xdd, 0x54, 0xe7, 0x53, 0x51,
-	0x5f, 0x5e, 0x7d, 0x02, 0x8b, 0xbf, 0x26, 0x8d, 0x86, 0x53, 0x9c, 0x96, 0x4e, 0x9f, 0xe2, 0xd4,
-	0x78, 0x72, 0x26, 0x93, 0xc2, 0xfa, 0xe7, 0x0b, 0x1b, 0x3c, 0x6a, 0x3d, 0xf4, 0xf8, 0x6b, 0x60,
-	0x83, 0x1c, 0xa5, 0x46, 0x6a, 0xb0, 0x8f, 0x4a, 0xc9, 0x37, 0x78, 0xfd, 0x57, 0xb0, 0xce, 0xb6,
-	0x66, 0x9d, 0x5d, 0x83, 0x20, 0x52, 0x6e, 0xb6, 0xe8, 0x4b, 0x74, 0x45, 0x0d, 0xf0, 0x4d, 0x60,
-	0x43, 0x4c, 0x50, 0xa3, 0x5b, 0x87, 0x3f, 0xf4, 0xe7, 0xa3, 0x52, 0xcb, 0xcd, 0xb5, 0x6c, 0x1d,
-	0x7c, 0xb3, 0x09, 0x24, 0x65, 0x7e, 0xfb, 0x76, 0x6d, 0x5d, 0xb5, 0x76, 0x82, 0x0a, 0x78, 0x5c,
-	0x36, 0x75, 0xdb, 0x73, 0xc3, 0x03, 0x7f, 0x33, 0x66, 0x25, 0x55, 0xbb, 0x49, 0x55, 0xed, 0xa3,
-	0xa3, 0x7a, 0x5a, 0xbe, 0xf5, 0x5f, 0xa9, 0xf8, 0xd0, 0xa1, 0x66, 0x5c, 0x0f, 0x4c, 0xd6, 0xde,
-	0xa1, 0xf3, 0xf5, 0x4f, 0x6e, 0xea, 0xf8, 0xec, 0x39, 0xca, 0xbf, 0x6b, 0xd3, 0x70, 0xce, 0xfc,
-	0x64, 0xca, 0xc1, 0x72, 0x1b, 0x56, 0xc5, 0x6c, 0x1d, 0xe6, 0x88, 0x55, 0x85, 0x3e, 0xcd, 0xee,
-	0xad, 0x86, 0x1a, 0xe1, 0xd2, 0x66, 0x9d, 0xdc, 0x90, 0x77, 0xec, 0x3a, 0xd9, 0x88, 0x4b, 0x80,
-	0x83, 0xec, 0x04, 0x47, 0x5a, 0xea, 0x42, 0x19, 0x9d, 0x7b, 0x99, 0xd2, 0xa5, 0x4e, 0x73, 0xa6,
-	0x69, 0xd3, 0x52, 0x57, 0x0e, 0x51, 0xc0, 0xee, 0xc3, 0xff, 0xa4, 0x13, 0x55, 0xd8, 0x6e, 0x32,
-	0x53, 0x42, 0x94, 0x79, 0xfe, 0x18, 0x16, 0x06, 0x49, 0xa1, 0x34, 0xe6, 0x8e, 0x65, 0x13, 0x3a,
-	0x86, 0xb3, 0xdc, 0xb7, 0x95, 0xfa, 0x66, 0x2d, 0x45, 0xd8, 0x92, 0x9d, 0xa5, 0xaf, 0x97, 0x3d,
-	0xef, 0xdb, 0x65, 0xcf, 0xfb, 0x7e, 0xd9, 0xf3, 0x3e, 0xfe, 0xe8, 0xfd, 0x77, 0x34, 0x47, 0xff,
-	0xf8, 0x07, 0x3f, 0x03, 0x00, 0x00, 0xff, 0xff, 0x5a, 0x1c, 0x79, 0x08, 0xf4, 0x05, 0x00, 0x00,
+	// 650 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x55, 0xcd, 0x4e, 0x14, 0x41,
+	0x10, 0x76, 0x76, 0x67, 0x71, 0xb7, 0x10, 0x84, 0x96, 0x98, 0x91, 0x90, 0xcd, 0xa6, 0x0f, 0x82,
+	0x1c, 0x38, 0xe0, 0xc5, 0xa8, 0x07, 0xc3, 0x2e, 0x86, 0x4d, 0x84, 0xc4, 0x5e, 0xe2, 0xd1, 0xa4,
+	0x81, 0x52, 0x27, 0xcc, 0xce, 0xac, 0xd3, 0x3d, 0xc0, 0x7a, 0xf0, 0x39, 0x4c, 0x3c, 0xf9, 0x00,
+	0xbe, 0x87, 0x47, 0x1f, 0xc1, 0xe0, 0x8b, 0x98, 0xae, 0xee, 0xf9, 0x61, 0x50, 0x37, 0x7a, 0xeb,
+	0xfa, 0xaa, 0xba, 0xbf, 0xaf, 0xbe, 0xa9, 0xda, 0x85, 0x85, 0x49, 0x1a, 0x9e, 0x49, 0x8d, 0x5b,
+	0x93, 0x34, 0xd1, 0x09, 0x6b, 0x87, 0xb1, 0xc6, 0x34, 0x96, 0x11, 0x57, 0xd0, 0x19, 0xc6, 0x27,
+	0x78, 0xb1, 0x8f, 0x5a, 0xb2, 0x1e, 0xcc, 0xf7, 0x93, 0x28, 0x1b, 0xc7, 0x2f, 0xe4, 0x11, 0x46,
+	0x81, 0xd7, 0xf3, 0x36, 0x3a, 0xa2, 0x0a, 0x99, 0x8a, 0xc3, 0x70, 0x8c, 0x2f, 0x33, 0x19, 0xeb,
+	0x6c, 0x1c, 0x34, 0x6c, 0x45, 0x05, 0x62, 0x1c, 0x6e, 0x0d, 0xf0, 0x8d, 0xcc, 0x22, 0xfd, 0x3c,
+	0x95, 0x63, 0x0c, 0x9a, 0x54, 0x72, 0x05, 0xe3, 0x5f, 0x3d, 0xe8, 0xd0, 0x89, 0x58, 0x57, 0xa1,
+	0x2d, 0x92, 0xf3, 0x2a, 0x65, 0x11, 0xb3, 0xfb, 0xb0, 0x38, 0x8c, 0xcf, 0x30, 0x55, 0xb8, 0x1b,
+	0xcb, 0xa3, 0x08, 0x4f, 0x88, 0xb2, 0x2d, 0x6a, 0x28, 0x5b, 0x83, 0x4e, 0x5f, 0x1e, 0xbf, 0xc3,
+	0xc3, 0xe9, 0x24, 0xa7, 0x2c, 0x81, 0x22, 0x3b, 0x0a, 0x3f, 0x60, 0xe0, 0xf7, 0xbc, 0x8d, 0x05,
+	0x51, 0x02, 0xf5, 0x9e, 0x5a, 0xd7, 0x7a, 0xe2, 0x1c, 0x16, 0x87, 0xe3, 0x49, 0x92, 0x6a, 0x81,
+	0x6a, 0x92, 0xc4, 0x0a, 0xd9, 0x12, 0x34, 0x77, 0xd3, 0xd4, 0xc9, 0x35, 0x47, 0xfe, 0x11, 0x96,
+	0x76, 0xa2, 0xe4, 0xf8, 0x74, 0x20, 0xb5, 0x14, 0xf8, 0x3e, 0x43, 0xa5, 0xd9, 0x0a, 0xb4, 0xc8,
+	0x5c, 0x57, 0x67, 0x03, 0x83, 0x5a, 0x6b, 0xac, 0x7b, 0x36, 0x30, 0x28, 0xdd, 0x27, 0xf5, 0xbe,
+	0xb0, 0x81, 0x41, 0x47, 0x51, 0x78, 0x6c, 0x55, 0xfb, 0xc2, 0x06, 0x8c, 0x81, 0xff, 0x2a, 0xc4,
+	0x73, 0x27, 0x95, 0xce, 0x7c, 0x08, 0xcb, 0x15, 0x7e, 0x27, 0xf3, 0x2e, 0xcc, 0x89, 0xe4, 0x7c,
+	0x38, 0x50, 0x81, 0xd7, 0x6b, 0x6e, 0xf8, 0xc2, 0x45, 0x64, 0x08, 0x7d, 0x55, 0x93, 0x6a, 0x50,
+	0xaa, 0x04, 0xf8, 0x3d, 0x68, 0x91, 0x3b, 0xa6, 0xcb, 0xf2, 0xae, 0x39, 0xf2, 0xcf, 0x1e, 0x2c,
+	0xef, 0xcb, 0x0b, 0x92, 0xa1, 0x0a, 0x9a, 0x3d, 0xe8, 0x14, 0x20, 0x55, 0xcf, 0x6f, 0x6f, 0x6e,
+	0xe5, 0x23, 0xb6, 0x75, 0xad, 0xbe, 0x44, 0x76, 0x63, 0x9d, 0x4e, 0x45, 0x79, 0x79, 0xf5, 0x29,
+	0x2c, 0x5e, 0x4d, 0x1a, 0x0d, 0xa7, 0x38, 0xcd, 0x9d, 0x3e, 0xc5, 0xa9, 0xf1, 0xe4, 0x4c, 0x46,
+	0x99, 0xf5, 0xcf, 0x17, 0x36, 0x78, 0xdc, 0x78, 0xe4, 0xf1, 0xd7, 0xc0, 0xfa, 0x29, 0x4a, 0x8d,
+	0xf4, 0xc0, 0x3e, 0x2a, 0x25, 0xdf, 0xe2, 0x9f, 0xbf, 0x82, 0x75, 0xb6, 0x51, 0x75, 0x76, 0x0d,
+	0x3a, 0x43, 0xe5, 0x66, 0x8b, 0xbe, 0x44, 0x5b, 0x94, 0x00, 0xdf, 0x04, 0x36, 0xc0, 0x08, 0x35,
+	0xba, 0x95, 0xf9, 0xcb, 0xfb, 0x7c, 0x94, 0x6b, 0x99, 0x5d, 0xcb, 0xd6, 0xc1, 0x37, 0x9b, 0x40,
+	0x52, 0xe6, 0xb7, 0xef, 0x94, 0xd6, 0x15, 0xab, 0x29, 0xa8, 0x80, 0x87, 0xf9, 0xa3, 0x6e, 0x7b,
+	0x66, 0x34, 0xf8, 0x9b, 0x31, 0xcb, 0xa9, 0x9a, 0x75, 0xaa, 0x62, 0x1f, 0x1d, 0xd5, 0xb3, 0xbc,
+	0xd7, 0xff, 0xa5, 0xe2, 0x03, 0x87, 0x9a, 0x71, 0x3d, 0x30, 0x59, 0x7b, 0xc7, 0x3f, 0xa8, 0xea,
+	0x68, 0xcc, 0xd2, 0xf1, 0xc5, 0x73, 0x94, 0xff, 0xf6, 0x4c, 0xcd, 0x39, 0xf3, 0x23, 0x93, 0x0f,
+	0x96, 0xdb, 0xb0, 0x22, 0x66, 0xeb, 0x30, 0x47, 0xac, 0x2a, 0xf0, 0x69, 0x76, 0x6f, 0xd7, 0xd4,
+	0x08, 0x97, 0x36, 0xeb, 0xe4, 0x86, 0xbc, 0x65, 0xd7, 0xc9, 0x46, 0x5c, 0x02, 0x1c, 0x24, 0x27,
+	0x38, 0xd2, 0x52, 0x67, 0xca, 0xe8, 0xdc, 0x4b, 0x94, 0xce, 0x75, 0x9a, 0x33, 0x4d, 0x9b, 0x96,
+	0xba, 0x70, 0x88, 0x02, 0xf6, 0x00, 0x6e, 0x92, 0x4e, 0x54, 0x41, 0xb3, 0xce, 0x4c, 0x09, 0x91,
+	0xe7, 0xf9, 0x13, 0x58, 0xe8, 0x47, 0x99, 0xd2, 0x98, 0x3a, 0x96, 0x4d, 0x68, 0x19, 0xce, 0x7c,
+	0xdf, 0x56, 0xca, 0x9b, 0xa5, 0x14, 0x61, 0x4b, 0x76, 0x96, 0xbe, 0x5d, 0x76, 0xbd, 0xef, 0x97,
+	0x5d, 0xef, 0xc7, 0x65, 0xd7, 0xfb, 0xf4, 0xb3, 0x7b, 0xe3, 0x68, 0x8e, 0xfe, 0x07, 0x1e, 0xfe,
+	0x1a, 0x00, 0xd6, 0xdc, 0x33, 0x3c, 0x18, 0x06, 0x00, 0x00,
 }
@@ -56,6 +56,7 @@ const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 type Bitmap struct {
 	Bits  []uint64 `protobuf:"varint,1,rep,packed,name=Bits" json:"Bits,omitempty"`
 	Attrs []*Attr  `protobuf:"bytes,2,rep,name=Attrs" json:"Attrs,omitempty"`
+	Keys  []string `protobuf:"bytes,3,rep,name=Keys" json:"Keys,omitempty"`
 }
 
 func (m *Bitmap) Reset()                    { *m = Bitmap{} }
@@ -71,8 +72,9 @@ func (m *Bitmap) GetAttrs() []*Attr {
 }
 
 type Pair struct {
-	Key   uint64 `protobuf:"varint,1,opt,name=Key,proto3" json:"Key,omitempty"`
-	Count uint64 `protobuf:"varint,2,opt,name=Count,proto3" json:"Count,omitempty"`
+	Key       uint64 `protobuf:"varint,1,opt,name=Key,proto3" json:"Key,omitempty"`
+	Count     uint64 `protobuf:"varint,2,opt,name=Count,proto3" json:"Count,omitempty"`
+	StringKey string `protobuf:"bytes,3,opt,name=StringKey,proto3" json:"StringKey,omitempty"`
 }
 
 func (m *Pair) Reset()                    { *m = Pair{} }
@@ -115,6 +117,7 @@ type Attr struct {
 	IntValue    int64   `protobuf:"varint,4,opt,name=IntValue,proto3" json:"IntValue,omitempty"`
 	BoolValue   bool    `protobuf:"varint,5,opt,name=BoolValue,proto3" json:"BoolValue,omitempty"`
 	FloatValue  float64 `protobuf:"fixed64,6,opt,name=FloatValue,proto3" json:"FloatValue,omitempty"`
+	ListValue   []*Attr `protobuf:"bytes,7,rep,name=ListValue" json:"ListValue,omitempty"`
 }
 
 func (m *Attr) Reset()                    { *m = Attr{} }
@@ -122,6 +125,13 @@ func (m *Attr) String() string            { return proto.CompactTextString(m) }
 func (*Attr) ProtoMessage()               {}
 func (*Attr) Descriptor() ([]byte, []int) { return fileDescriptorPublic, []int{4} }
 
+func (m *Attr) GetListValue() []*Attr {
+	if m != nil {
+		return m.ListValue
+	}
+	return nil
+}
+
 type AttrMap struct {
 	Attrs []*Attr `protobuf:"bytes,1,rep,name=Attrs" json:"Attrs,omitempty"`
 }
@@ -139,11 +149,18 @@ func (m *AttrMap) GetAttrs() []*Attr {
 }
 
 type QueryRequest struct {
-	Query       string   `protobuf:"bytes,1,opt,name=Query,proto3" json:"Query,omitempty"`
-	Slices      []uint64 `protobuf:"varint,2,rep,packed,name=Slices" json:"Slices,omitempty"`
-	ColumnAttrs bool     `protobuf:"varint,3,opt,name=ColumnAttrs,proto3" json:"ColumnAttrs,omitempty"`
-	Quantum     string   `protobuf:"bytes,4,opt,name=Quantum,proto3" json:"Quantum,omitempty"`
-	Remote      bool     `protobuf:"varint,5,opt,name=Remote,proto3" json:"Remote,omitempty"`
+	Query               string   `protobuf:"bytes,1,opt,name=Query,proto3" json:"Query,omitempty"`
+	Slices              []uint64 `protobuf:"varint,2,rep,packed,name=Slices" json:"Slices,omitempty"`
+	ColumnAttrs         bool     `protobuf:"varint,3,opt,name=ColumnAttrs,proto3" json:"ColumnAttrs,omitempty"`
+	Quantum             string   `protobuf:"bytes,4,opt,name=Quantum,proto3" json:"Quantum,omitempty"`
+	Remote              bool     `protobuf:"varint,5,opt,name=Remote,proto3" json:"Remote,omitempty"`
+	OperationID         string   `protobuf:"bytes,6,opt,name=OperationID,proto3" json:"OperationID,omitempty"`
+	TreatMissingAsEmpty bool     `protobuf:"varint,7,opt,name=TreatMissingAsEmpty,proto3" json:"TreatMissingAsEmpty,omitempty"`
+	Deadline            int64    `protobuf:"varint,8,opt,name=Deadline,proto3" json:"Deadline,omitempty"`
+	Snapshot            bool     `protobuf:"varint,9,opt,name=Snapshot,proto3" json:"Snapshot,omitempty"`
+	SkipInverse         bool     `protobuf:"varint,10,opt,name=SkipInverse,proto3" json:"SkipInverse,omitempty"`
+	MaxFragmentReads    int64    `protobuf:"varint,11,opt,name=MaxFragmentReads,proto3" json:"MaxFragmentReads,omitempty"`
+	ParallelChildren    bool     `protobuf:"varint,12,opt,name=ParallelChildren,proto3" json:"ParallelChildren,omitempty"`
 }
 
 func (m *QueryRequest) Reset()                    { *m = QueryRequest{} }
@@ -177,10 +194,13 @@ func (m *QueryResponse) GetColumnAttrSets() []*ColumnAttrSet {
 }
 
 type QueryResult struct {
-	Bitmap  *Bitmap `protobuf:"bytes,1,opt,name=Bitmap" json:"Bitmap,omitempty"`
-	N       uint64  `protobuf:"varint,2,opt,name=N,proto3" json:"N,omitempty"`
-	Pairs   []*Pair `protobuf:"bytes,3,rep,name=Pairs" json:"Pairs,omitempty"`
-	Changed bool    `protobuf:"varint,4,opt,name=Changed,proto3" json:"Changed,omitempty"`
+	Bitmap   *Bitmap `protobuf:"bytes,1,opt,name=Bitmap" json:"Bitmap,omitempty"`
+	N        uint64  `protobuf:"varint,2,opt,name=N,proto3" json:"N,omitempty"`
+	Pairs    []*Pair `protobuf:"bytes,3,rep,name=Pairs" json:"Pairs,omitempty"`
+	Changed  bool    `protobuf:"varint,4,opt,name=Changed,proto3" json:"Changed,omitempty"`
+	Value    []byte  `protobuf:"bytes,5,opt,name=Value,proto3" json:"Value,omitempty"`
+	Universe uint64  `protobuf:"varint,6,opt,name=Universe,proto3" json:"Universe,omitempty"`
+	Checksum string  `protobuf:"bytes,7,opt,name=Checksum,proto3" json:"Checksum,omitempty"`
 }
 
 func (m *QueryResult) Reset()                    { *m = QueryResult{} }
@@ -272,6 +292,21 @@ func (m *Bitmap) MarshalTo(dAtA []byte) (int, error) {
 			i += n
 		}
 	}
+	if len(m.Keys) > 0 {
+		for _, s := range m.Keys {
+			dAtA[i] = 0x1a
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
 	return i, nil
 }
 
@@ -300,6 +335,12 @@ func (m *Pair) MarshalTo(dAtA []byte) (int, error) {
 		i++
 		i = encodeVarintPublic(dAtA, i, uint64(m.Count))
 	}
+	if len(m.StringKey) > 0 {
+		dAtA[i] = 0x1a
+		i++
+		i = encodeVarintPublic(dAtA, i, uint64(len(m.StringKey)))
+		i += copy(dAtA[i:], m.StringKey)
+	}
 	return i, nil
 }
 
@@ -423,6 +464,18 @@ func (m *Attr) MarshalTo(dAtA []byte) (int, error) {
 		i++
 		i = encodeFixed64Public(dAtA, i, uint64(math.Float64bits(float64(m.FloatValue))))
 	}
+	if len(m.ListValue) > 0 {
+		for _, msg := range m.ListValue {
+			dAtA[i] = 0x3a
+			i++
+			i = encodeVarintPublic(dAtA, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(dAtA[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
 	return i, nil
 }
 
@@ -520,6 +573,62 @@ func (m *QueryRequest) MarshalTo(dAtA []byte) (int, error) {
 		}
 		i++
 	}
+	if len(m.OperationID) > 0 {
+		dAtA[i] = 0x32
+		i++
+		i = encodeVarintPublic(dAtA, i, uint64(len(m.OperationID)))
+		i += copy(dAtA[i:], m.OperationID)
+	}
+	if m.TreatMissingAsEmpty {
+		dAtA[i] = 0x38
+		i++
+		if m.TreatMissingAsEmpty {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if m.Deadline != 0 {
+		dAtA[i] = 0x40
+		i++
+		i = encodeVarintPublic(dAtA, i, uint64(m.Deadline))
+	}
+	if m.Snapshot {
+		dAtA[i] = 0x48
+		i++
+		if m.Snapshot {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if m.SkipInverse {
+		dAtA[i] = 0x50
+		i++
+		if m.SkipInverse {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
+	if m.MaxFragmentReads != 0 {
+		dAtA[i] = 0x58
+		i++
+		i = encodeVarintPublic(dAtA, i, uint64(m.MaxFragmentReads))
+	}
+	if m.ParallelChildren {
+		dAtA[i] = 0x60
+		i++
+		if m.ParallelChildren {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i++
+	}
 	return i, nil
 }
 
@@ -623,6 +732,23 @@ func (m *QueryResult) MarshalTo(dAtA []byte) (int, error) {
 		}
 		i++
 	}
+	if len(m.Value) > 0 {
+		dAtA[i] = 0x2a
+		i++
+		i = encodeVarintPublic(dAtA, i, uint64(len(m.Value)))
+		i += copy(dAtA[i:], m.Value)
+	}
+	if m.Universe != 0 {
+		dAtA[i] = 0x30
+		i++
+		i = encodeVarintPublic(dAtA, i, uint64(m.Universe))
+	}
+	if len(m.Checksum) > 0 {
+		dAtA[i] = 0x3a
+		i++
+		i = encodeVarintPublic(dAtA, i, uint64(len(m.Checksum)))
+		i += copy(dAtA[i:], m.Checksum)
+	}
 	return i, nil
 }
 
@@ -756,6 +882,12 @@ func (m *Bitmap) Size() (n int) {
 			n += 1 + l + sovPublic(uint64(l))
 		}
 	}
+	if len(m.Keys) > 0 {
+		for _, s := range m.Keys {
+			l = len(s)
+			n += 1 + l + sovPublic(uint64(l))
+		}
+	}
 	return n
 }
 
@@ -768,6 +900,10 @@ func (m *Pair) Size() (n int) {
 	if m.Count != 0 {
 		n += 1 + sovPublic(uint64(m.Count))
 	}
+	l = len(m.StringKey)
+	if l > 0 {
+		n += 1 + l + sovPublic(uint64(l))
+	}
 	return n
 }
 
@@ -824,6 +960,12 @@ func (m *Attr) Size() (n int) {
 	if m.FloatValue != 0 {
 		n += 9
 	}
+	if len(m.ListValue) > 0 {
+		for _, e := range m.ListValue {
+			l = e.Size()
+			n += 1 + l + sovPublic(uint64(l))
+		}
+	}
 	return n
 }
 
@@ -863,6 +1005,28 @@ func (m *QueryRequest) Size() (n int) {
 	if m.Remote {
 		n += 2
 	}
+	l = len(m.OperationID)
+	if l > 0 {
+		n += 1 + l + sovPublic(uint64(l))
+	}
+	if m.TreatMissingAsEmpty {
+		n += 2
+	}
+	if m.Deadline != 0 {
+		n += 1 + sovPublic(uint64(m.Deadline))
+	}
+	if m.Snapshot {
+		n += 2
+	}
+	if m.SkipInverse {
+		n += 2
+	}
+	if m.MaxFragmentReads != 0 {
+		n += 1 + sovPublic(uint64(m.MaxFragmentReads))
+	}
+	if m.ParallelChildren {
+		n += 2
+	}
 	return n
 }
 
@@ -907,6 +1071,17 @@ func (m *QueryResult) Size() (n int) {
 	if m.Changed {
 		n += 2
 	}
+	l = len(m.Value)
+	if l > 0 {
+		n += 1 + l + sovPublic(uint64(l))
+	}
+	if m.Universe != 0 {
+		n += 1 + sovPublic(uint64(m.Universe))
+	}
+	l = len(m.Checksum)
+	if l > 0 {
+		n += 1 + l + sovPublic(uint64(l))
+	}
 	return n
 }
 
@@ -1083,6 +1258,35 @@ func (m *Bitmap) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Keys", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPublic
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthPublic
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Keys = append(m.Keys, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipPublic(dAtA[iNdEx:])
@@ -1171,6 +1375,35 @@ func (m *Pair) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StringKey", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPublic
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthPublic
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.StringKey = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipPublic(dAtA[iNdEx:])
@@ -1562,6 +1795,37 @@ func (m *Attr) Unmarshal(dAtA []byte) error {
 			v |= uint64(dAtA[iNdEx-2]) << 48
 			v |= uint64(dAtA[iNdEx-1]) << 56
 			m.FloatValue = float64(math.Float64frombits(v))
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ListValue", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPublic
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthPublic
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ListValue = append(m.ListValue, &Attr{})
+			if err := m.ListValue[len(m.ListValue)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipPublic(dAtA[iNdEx:])
@@ -1853,6 +2117,153 @@ func (m *QueryRequest) Unmarshal(dAtA []byte) error {
 				}
 			}
 			m.Remote = bool(v != 0)
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field OperationID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPublic
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthPublic
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.OperationID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TreatMissingAsEmpty", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPublic
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.TreatMissingAsEmpty = bool(v != 0)
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Deadline", wireType)
+			}
+			m.Deadline = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPublic
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Deadline |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Snapshot", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPublic
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Snapshot = bool(v != 0)
+		case 10:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SkipInverse", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPublic
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.SkipInverse = bool(v != 0)
+		case 11:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxFragmentReads", wireType)
+			}
+			m.MaxFragmentReads = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPublic
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxFragmentReads |= (int64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 12:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ParallelChildren", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPublic
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.ParallelChildren = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipPublic(dAtA[iNdEx:])
@@ -2147,6 +2558,85 @@ func (m *QueryResult) Unmarshal(dAtA []byte) error {
 				}
 			}
 			m.Changed = bool(v != 0)
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPublic
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthPublic
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Value = append(m.Value[:0], dAtA[iNdEx:postIndex]...)
+			if m.Value == nil {
+				m.Value = []byte{}
+			}
+			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Universe", wireType)
+			}
+			m.Universe = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPublic
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Universe |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Checksum", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPublic
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthPublic
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Checksum = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipPublic(dAtA[iNdEx:])
@@ -2589,41 +3079,48 @@ var (
 func init() { proto.RegisterFile("public.proto", fileDescriptorPublic) }
 
 var fileDescriptorPublic = []byte{
-	// 576 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x54, 0x4b, 0x8e, 0xd3, 0x40,
-	0x10, 0xa5, 0x63, 0xe7, 0x57, 0xf9, 0x28, 0x6a, 0xf1, 0xb1, 0x10, 0x8a, 0x2c, 0x8b, 0x85, 0x57,
-	0x19, 0x69, 0x38, 0x00, 0xc2, 0x49, 0x46, 0xb2, 0x10, 0x23, 0xa6, 0x33, 0xb0, 0xf7, 0xcc, 0xb4,
-	0x06, 0x4b, 0xfe, 0xd1, 0xdd, 0x16, 0xe4, 0x00, 0xec, 0x91, 0xd8, 0x70, 0x03, 0x38, 0x0a, 0x4b,
-	0x8e, 0x80, 0xc2, 0x45, 0x50, 0x75, 0xbb, 0x63, 0x0f, 0x0b, 0xc4, 0xae, 0xdf, 0xab, 0xae, 0x76,
-	0xbd, 0x7a, 0x55, 0x86, 0x69, 0x55, 0x5f, 0x65, 0xe9, 0xf5, 0xaa, 0x12, 0xa5, 0x2a, 0xe9, 0x28,
-	0x2d, 0x14, 0x17, 0x45, 0x92, 0x05, 0x11, 0x0c, 0xa2, 0x54, 0xe5, 0x49, 0x45, 0x29, 0xb8, 0x51,
-	0xaa, 0xa4, 0x47, 0x7c, 0x27, 0x74, 0x99, 0x3e, 0xd3, 0xa7, 0xd0, 0x7f, 0xa1, 0x94, 0x90, 0x5e,
-	0xcf, 0x77, 0xc2, 0xc9, 0xe9, 0x7c, 0x65, 0xf3, 0x56, 0x48, 0x33, 0x13, 0x0c, 0x56, 0xe0, 0xbe,
-	0x4e, 0x52, 0x41, 0x17, 0xe0, 0xbc, 0xe4, 0x7b, 0x8f, 0xf8, 0x24, 0x74, 0x19, 0x1e, 0xe9, 0x7d,
-	0xe8, 0xaf, 0xcb, 0xba, 0x50, 0x5e, 0x4f, 0x73, 0x06, 0x04, 0x6f, 0xc0, 0x89, 0x52, 0x85, 0x41,
-	0x56, 0x7e, 0x88, 0x37, 0x4d, 0x82, 0x01, 0xf4, 0x31, 0x8c, 0xd6, 0x65, 0x56, 0xe7, 0x45, 0xbc,
-	0x69, 0xb2, 0x8e, 0x98, 0x3e, 0x81, 0xf1, 0x65, 0x9a, 0x73, 0xa9, 0x92, 0xbc, 0xf2, 0x1c, 0x9f,
-	0x84, 0x0e, 0x6b, 0x89, 0x60, 0x0b, 0x33, 0x73, 0x13, 0xab, 0xda, 0x71, 0x45, 0xe7, 0xd0, 0x3b,
-	0xbe, 0xde, 0x8b, 0x37, 0xff, 0xa9, 0xe6, 0x3b, 0x01, 0x17, 0x4f, 0x5d, 0x39, 0x63, 0x23, 0x87,
-	0x82, 0x7b, 0xb9, 0xaf, 0x78, 0x53, 0x97, 0x3e, 0x53, 0x1f, 0x26, 0x3b, 0x25, 0xd2, 0xe2, 0xf6,
-	0x6d, 0x92, 0xd5, 0x5c, 0x57, 0x35, 0x66, 0x5d, 0x0a, 0x15, 0xc5, 0x85, 0x32, 0x61, 0x57, 0x17,
-	0x7d, 0xc4, 0xa8, 0x28, 0x2a, 0xcb, 0xcc, 0x04, 0xfb, 0x3e, 0x09, 0x47, 0xac, 0x25, 0xe8, 0x12,
-	0xe0, 0x2c, 0x2b, 0x93, 0x26, 0x77, 0xe0, 0x93, 0x90, 0xb0, 0x0e, 0x13, 0x9c, 0xc0, 0x10, 0x2b,
-	0x7d, 0x95, 0x54, 0xad, 0x36, 0xf2, 0x2f, 0x6d, 0x9f, 0x09, 0x4c, 0x2f, 0x6a, 0x2e, 0xf6, 0x8c,
-	0xbf, 0xaf, 0xb9, 0xd4, 0x1e, 0x68, 0xdc, 0xa8, 0x34, 0x80, 0x3e, 0x84, 0xc1, 0x2e, 0x4b, 0xaf,
-	0xb9, 0xe9, 0x94, 0xcb, 0x1a, 0x84, 0x5a, 0xdb, 0x0e, 0x4b, 0xad, 0x75, 0xc4, 0xba, 0x14, 0xf5,
-	0x60, 0x78, 0x51, 0x27, 0x85, 0xaa, 0x73, 0x2d, 0x75, 0xcc, 0x2c, 0xc4, 0x37, 0x19, 0xcf, 0x4b,
-	0x65, 0x65, 0x36, 0x28, 0xf8, 0x42, 0x60, 0xd6, 0x94, 0x24, 0xab, 0xb2, 0x90, 0x1c, 0xfb, 0xbe,
-	0x15, 0xc2, 0xf6, 0x7d, 0x2b, 0x04, 0x3d, 0x81, 0x21, 0xe3, 0xb2, 0xce, 0x94, 0xb5, 0xee, 0x41,
-	0x2b, 0xcf, 0xe6, 0xd6, 0x99, 0x62, 0xf6, 0x16, 0x7d, 0x0e, 0xf3, 0x3b, 0xa3, 0x80, 0xb5, 0x62,
-	0xde, 0xa3, 0x36, 0xef, 0x4e, 0x9c, 0xfd, 0x75, 0x3d, 0xf8, 0x44, 0x60, 0xd2, 0x79, 0x99, 0x86,
-	0x76, 0x4d, 0x74, 0x59, 0x93, 0xd3, 0x45, 0xfb, 0x90, 0xe1, 0x99, 0x5d, 0xa3, 0x29, 0x90, 0xf3,
-	0x66, 0x40, 0xc8, 0x39, 0xda, 0x82, 0xab, 0x61, 0xbf, 0xdf, 0xb1, 0x05, 0x69, 0x66, 0x82, 0xd8,
-	0xb5, 0xf5, 0xbb, 0xa4, 0xb8, 0xe5, 0x37, 0xba, 0x6b, 0x23, 0x66, 0x61, 0xf0, 0x8d, 0xc0, 0x2c,
-	0xce, 0xab, 0x52, 0xa8, 0x8e, 0x63, 0x71, 0x71, 0xc3, 0x3f, 0x5a, 0xc7, 0x34, 0x40, 0xf6, 0x4c,
-	0x24, 0xb9, 0x19, 0xcd, 0x31, 0x33, 0x00, 0x59, 0xed, 0x9c, 0x76, 0xca, 0x65, 0x06, 0x68, 0x27,
-	0x70, 0xd5, 0xa4, 0xe7, 0x1a, 0x77, 0x0d, 0xc2, 0x59, 0xb4, 0x9b, 0x26, 0xbd, 0xbe, 0x0e, 0xb5,
-	0x04, 0xce, 0xe2, 0x71, 0xd5, 0xa4, 0x37, 0xf0, 0x9d, 0xd0, 0x61, 0x1d, 0x26, 0x5a, 0xfc, 0x38,
-	0x2c, 0xc9, 0xcf, 0xc3, 0x92, 0xfc, 0x3a, 0x2c, 0xc9, 0xd7, 0xdf, 0xcb, 0x7b, 0x57, 0x03, 0xfd,
-	0xaf, 0x79, 0xf6, 0x27, 0x00, 0x00, 0xff, 0xff, 0x48, 0x20, 0x4b, 0xe7, 0x7b, 0x04, 0x00, 0x00,
+	// 684 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x54, 0xcb, 0x6e, 0xdb, 0x38,
+	0x14, 0x1d, 0x5a, 0xf2, 0x43, 0xd7, 0x4e, 0x10, 0x70, 0x5e, 0xc2, 0x60, 0x60, 0x08, 0xc2, 0x2c,
+	0xb4, 0x18, 0x24, 0x45, 0xfa, 0x01, 0x45, 0x1c, 0x3b, 0x80, 0x91, 0x47, 0x1b, 0x3a, 0xcd, 0x9e,
+	0x49, 0x88, 0x84, 0x80, 0x5e, 0x25, 0x29, 0xb4, 0xfe, 0x8e, 0x6e, 0x0a, 0xb4, 0xfb, 0xfe, 0x4a,
+	0x97, 0x5d, 0x75, 0x5d, 0xa4, 0x3f, 0x52, 0x5c, 0x52, 0xb2, 0x94, 0x22, 0x28, 0xba, 0xe3, 0x39,
+	0x97, 0x3c, 0xd2, 0x3d, 0xf7, 0x90, 0x30, 0x29, 0xab, 0xab, 0x54, 0x5e, 0xef, 0x96, 0xaa, 0x30,
+	0x05, 0x1d, 0xc9, 0xdc, 0x08, 0x95, 0xf3, 0x34, 0xbe, 0x84, 0xc1, 0x4c, 0x9a, 0x8c, 0x97, 0x94,
+	0x82, 0x3f, 0x93, 0x46, 0x87, 0x24, 0xf2, 0x12, 0x9f, 0xd9, 0x35, 0xfd, 0x0f, 0xfa, 0x07, 0xc6,
+	0x28, 0x1d, 0xf6, 0x22, 0x2f, 0x19, 0xef, 0x6f, 0xef, 0x36, 0xe7, 0x76, 0x91, 0x66, 0xae, 0x88,
+	0x27, 0x8f, 0xc5, 0x5a, 0x87, 0x5e, 0xe4, 0x25, 0x01, 0xb3, 0xeb, 0xf8, 0x04, 0xfc, 0x17, 0x5c,
+	0x2a, 0xba, 0x03, 0xde, 0xb1, 0x58, 0x87, 0x24, 0x22, 0x89, 0xcf, 0x70, 0x49, 0xff, 0x80, 0xfe,
+	0x61, 0x51, 0xe5, 0x26, 0xec, 0x59, 0xce, 0x01, 0xfa, 0x2f, 0x04, 0x2b, 0xa3, 0x64, 0x7e, 0x8b,
+	0xbb, 0xbd, 0x88, 0x24, 0x01, 0x6b, 0x89, 0xf8, 0x25, 0x78, 0x33, 0x69, 0xf0, 0x28, 0x2b, 0x5e,
+	0x2f, 0xe7, 0xb5, 0x9c, 0x03, 0xf4, 0x1f, 0x18, 0x1d, 0x16, 0x69, 0x95, 0xe5, 0xcb, 0x79, 0xad,
+	0xb9, 0xc1, 0x28, 0x7b, 0x21, 0x33, 0xa1, 0x0d, 0xcf, 0x4a, 0x2b, 0xeb, 0xb1, 0x96, 0x88, 0x17,
+	0xb0, 0xe5, 0x76, 0x62, 0x1f, 0x2b, 0x61, 0xe8, 0x36, 0xf4, 0x36, 0xea, 0xbd, 0xe5, 0xfc, 0xd7,
+	0xfa, 0x8f, 0xbf, 0x10, 0xf0, 0x71, 0xd5, 0x6d, 0x36, 0x70, 0xcd, 0x52, 0xf0, 0x2f, 0xd6, 0xa5,
+	0xa8, 0xff, 0xcb, 0xae, 0x69, 0x04, 0x63, 0xd7, 0xd9, 0x25, 0x4f, 0x2b, 0x51, 0x37, 0xdb, 0xa5,
+	0xb0, 0xa3, 0x65, 0x6e, 0x5c, 0xd9, 0xb7, 0x3f, 0xbd, 0xc1, 0xd8, 0xd1, 0xac, 0x28, 0x52, 0x57,
+	0xec, 0x47, 0x24, 0x19, 0xb1, 0x96, 0xa0, 0x53, 0x80, 0xa3, 0xb4, 0xe0, 0xf5, 0xd9, 0x41, 0x44,
+	0x12, 0xc2, 0x3a, 0x0c, 0xfd, 0x1f, 0x82, 0x13, 0xa9, 0xeb, 0xf2, 0xf0, 0xd1, 0xa6, 0xda, 0x0d,
+	0xf1, 0x1e, 0x0c, 0x91, 0x3a, 0xe5, 0x65, 0xeb, 0x04, 0xf9, 0x99, 0x13, 0x1f, 0x7a, 0x30, 0x39,
+	0xaf, 0x84, 0x5a, 0x33, 0xf1, 0xaa, 0x12, 0xda, 0x4e, 0xcc, 0xe2, 0xda, 0x13, 0x07, 0xe8, 0x5f,
+	0x30, 0x58, 0xa5, 0xf2, 0x5a, 0x38, 0x5f, 0x7d, 0x56, 0x23, 0x74, 0xa6, 0x9d, 0x87, 0xb6, 0xce,
+	0x8c, 0x58, 0x97, 0xa2, 0x21, 0x0c, 0xcf, 0x2b, 0x9e, 0x9b, 0x2a, 0xb3, 0xc6, 0x04, 0xac, 0x81,
+	0xa8, 0xc9, 0x44, 0x56, 0x98, 0xc6, 0x94, 0x1a, 0xa1, 0xe6, 0xf3, 0x52, 0x28, 0x6e, 0x64, 0x81,
+	0x01, 0x19, 0x38, 0xb7, 0x3b, 0x14, 0x7d, 0x02, 0xbf, 0x5f, 0x28, 0xc1, 0xcd, 0xa9, 0xd4, 0x5a,
+	0xe6, 0xb7, 0x07, 0x7a, 0x91, 0x95, 0x66, 0x1d, 0x0e, 0xad, 0xcc, 0x63, 0x25, 0x9c, 0xcf, 0x5c,
+	0xf0, 0x9b, 0x54, 0xe6, 0x22, 0x1c, 0xb9, 0xf9, 0x34, 0x18, 0x6b, 0xab, 0x9c, 0x97, 0xfa, 0xae,
+	0x30, 0x61, 0x60, 0x25, 0x36, 0x38, 0x7e, 0x4b, 0x60, 0xab, 0xb6, 0x47, 0x97, 0x45, 0xae, 0x05,
+	0x26, 0x66, 0xa1, 0x54, 0x93, 0x98, 0x85, 0x52, 0x74, 0x0f, 0x86, 0x4c, 0xe8, 0x2a, 0x35, 0x4d,
+	0xe8, 0xfe, 0x6c, 0xad, 0x6e, 0xce, 0x56, 0xa9, 0x61, 0xcd, 0x2e, 0xfa, 0x0c, 0xb6, 0x1f, 0x84,
+	0xd8, 0xdd, 0xc3, 0xf1, 0xfe, 0xdf, 0xed, 0xb9, 0x07, 0x75, 0xf6, 0xc3, 0xf6, 0xf8, 0x3d, 0x81,
+	0x71, 0x47, 0x99, 0x26, 0xcd, 0x93, 0x60, 0x7f, 0x6b, 0xbc, 0xbf, 0xd3, 0x0a, 0x39, 0x9e, 0xd5,
+	0x75, 0x3a, 0x01, 0x72, 0x56, 0x47, 0x9b, 0x9c, 0x61, 0x44, 0xf0, 0xca, 0x37, 0xdf, 0xef, 0x44,
+	0x04, 0x69, 0xe6, 0x8a, 0x38, 0xc1, 0xc3, 0x3b, 0x9e, 0xdf, 0x8a, 0x1b, 0x3b, 0xc1, 0x11, 0x6b,
+	0x20, 0x66, 0xa5, 0x4d, 0xf5, 0x84, 0x39, 0x10, 0x7f, 0x24, 0xb0, 0xb5, 0xcc, 0xca, 0x42, 0x99,
+	0x4e, 0xa6, 0x96, 0xf9, 0x8d, 0x78, 0xd3, 0x64, 0xca, 0x02, 0x64, 0x8f, 0x14, 0xcf, 0xdc, 0x55,
+	0x0b, 0x98, 0x03, 0xc8, 0xda, 0x6c, 0xd9, 0x2c, 0xf9, 0xcc, 0x01, 0x9b, 0x15, 0x7c, 0x3a, 0x74,
+	0xe8, 0xbb, 0xfc, 0x39, 0x84, 0x77, 0xab, 0x79, 0x39, 0x74, 0xd8, 0xb7, 0xa5, 0x96, 0xc0, 0xbb,
+	0xb5, 0x79, 0x3a, 0x74, 0x38, 0x88, 0xbc, 0xc4, 0x63, 0x1d, 0x66, 0xb6, 0xf3, 0xe9, 0x7e, 0x4a,
+	0x3e, 0xdf, 0x4f, 0xc9, 0xd7, 0xfb, 0x29, 0x79, 0xf7, 0x6d, 0xfa, 0xdb, 0xd5, 0xc0, 0xbe, 0xb6,
+	0x4f, 0xbf, 0x0f, 0x00, 0x98, 0xe0, 0xa5, 0x41, 0x7d, 0x05, 0x00, 0x00,
 }
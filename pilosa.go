@@ -44,6 +44,41 @@ var (
 	// ErrFragmentNotFound is returned when a fragment does not exist.
 	ErrFragmentNotFound = errors.New("fragment not found")
 	ErrQueryRequired    = errors.New("query required")
+
+	// ErrTooManyQueries is returned when an index is at its concurrent query limit.
+	ErrTooManyQueries = errors.New("too many concurrent queries")
+
+	// ErrTooManyColumnAttrs is returned when a result has too many columns
+	// to attach column attributes.
+	ErrTooManyColumnAttrs = errors.New("too many columns to attach attributes")
+
+	// ErrTooManyFilterColumns is returned when a column attribute filter
+	// would scan too many columns.
+	ErrTooManyFilterColumns = errors.New("too many columns to filter by attribute")
+
+	// ErrTooManyTranslateIDs is returned when a result has too many ids
+	// to translate back to keys.
+	ErrTooManyTranslateIDs = errors.New("too many ids to translate to keys")
+
+	// ErrCircuitOpen is returned when requests to a node are short-circuited
+	// after repeated failures.
+	ErrCircuitOpen = errors.New("node circuit open")
+
+	// ErrKeyTranslatorRequired is returned when key translation is requested
+	// but the executor has no KeyTranslator.
+	ErrKeyTranslatorRequired = errors.New("key translator required")
+
+	// ErrFragmentReadBudgetExceeded is returned when a query reads more
+	// fragments than allowed by ExecOptions.MaxFragmentReads.
+	ErrFragmentReadBudgetExceeded = errors.New("fragment read budget exceeded")
+
+	// ErrSliceUnowned is returned when a bit is written to a slice which
+	// no node in the cluster owns.
+	ErrSliceUnowned = errors.New("slice has no owning nodes")
+
+	// ErrReduceTimeout is returned when merging the results of a call's
+	// nodes takes longer than Executor.ReduceTimeout.
+	ErrReduceTimeout = errors.New("reduce timeout")
 )
 
 // Regular expression to validate index and frame names.
@@ -37,6 +37,16 @@ func (q *Query) String() string {
 	return strings.Join(a, "\n")
 }
 
+// CanonicalString returns a normalized string representation of the query.
+// Equivalent queries return the same string. See Call.CanonicalString().
+func (q *Query) CanonicalString() string {
+	a := make([]string, len(q.Calls))
+	for i, call := range q.Calls {
+		a[i] = call.CanonicalString()
+	}
+	return strings.Join(a, "\n")
+}
+
 // Call represents a function call in the AST.
 type Call struct {
 	Name     string
@@ -66,8 +76,9 @@ func (c *Call) UintArg(key string) (uint64, bool, error) {
 
 // UintSliceArg reads the value at key from call.Args as a slice of uint64. If
 // the key is not in Call.Args, the value of the returned bool will be false,
-// and the error will be nil. If the value is a slice of int64 it will convert
-// it to []uint64. Otherwise, if it is not a []uint64 it will return an error.
+// and the error will be nil. If the value is a slice of int64, or a parsed list
+// of integers, it will convert it to []uint64. Otherwise, if it is not a
+// []uint64 it will return an error.
 func (c *Call) UintSliceArg(key string) ([]uint64, bool, error) {
 	val, ok := c.Args[key]
 	if !ok {
@@ -83,6 +94,19 @@ func (c *Call) UintSliceArg(key string) ([]uint64, bool, error) {
 			ret[i] = uint64(v)
 		}
 		return ret, true, nil
+	case []interface{}:
+		ret := make([]uint64, len(tval))
+		for i, v := range tval {
+			switch v := v.(type) {
+			case int64:
+				ret[i] = uint64(v)
+			case uint64:
+				ret[i] = v
+			default:
+				return nil, true, fmt.Errorf("unexpected type %T in UintSliceArg, val %v", v, v)
+			}
+		}
+		return ret, true, nil
 	default:
 		return nil, true, fmt.Errorf("unexpected type %T in UintSliceArg, val %v", tval, tval)
 	}
@@ -155,6 +179,8 @@ func (c *Call) String() string {
 			fmt.Fprintf(&buf, "%v=%q", key, v)
 		case []interface{}:
 			fmt.Fprintf(&buf, "%v=%s", key, joinInterfaceSlice(v))
+		case []int64:
+			fmt.Fprintf(&buf, "%v=%s", key, joinInt64Slice(v))
 		case []uint64:
 			fmt.Fprintf(&buf, "%v=%s", key, joinUint64Slice(v))
 		case time.Time:
@@ -170,6 +196,79 @@ func (c *Call) String() string {
 	return buf.String()
 }
 
+// CanonicalString returns a normalized string representation of the call.
+// Arguments are written in key order and integers are written in decimal
+// regardless of their type, so that calls which only differ in argument
+// order or integer representation return the same string.
+func (c *Call) CanonicalString() string {
+	var buf bytes.Buffer
+	buf.WriteString(c.Name)
+	buf.WriteByte('(')
+
+	// Write child list.
+	for i, child := range c.Children {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(child.CanonicalString())
+	}
+
+	// Separate children and args, if necessary.
+	if len(c.Children) > 0 && len(c.Args) > 0 {
+		buf.WriteString(", ")
+	}
+
+	// Write arguments in key order.
+	for i, key := range c.Keys() {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(key)
+		buf.WriteByte('=')
+		buf.WriteString(canonicalValue(c.Args[key]))
+	}
+
+	buf.WriteByte(')')
+	return buf.String()
+}
+
+// canonicalValue returns the normalized string representation of an argument value.
+func canonicalValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return strconv.Quote(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.FormatInt(int64(v), 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float64:
+		// Floats always include a decimal point so they never match an integer.
+		s := strconv.FormatFloat(v, 'f', -1, 64)
+		if !strings.Contains(s, ".") {
+			s += ".0"
+		}
+		return s
+	case time.Time:
+		return strconv.Quote(v.Format(TimeFormat))
+	case []interface{}:
+		a := make([]string, len(v))
+		for i := range v {
+			a[i] = canonicalValue(v[i])
+		}
+		return "[" + strings.Join(a, ",") + "]"
+	case []int64:
+		return joinInt64Slice(v)
+	case []uint64:
+		return joinUint64Slice(v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
 // SupportsInverse indicates that the call may be on an inverse frame.
 func (c *Call) SupportsInverse() bool {
 	if c.Name == "Bitmap" {
@@ -194,11 +293,25 @@ func (c *Call) IsInverse(rowLabel, columnLabel string) bool {
 	return false
 }
 
-// CopyArgs returns a copy of m.
+// CopyArgs returns a copy of m. List values are copied so that they are not
+// shared with m.
 func CopyArgs(m map[string]interface{}) map[string]interface{} {
 	other := make(map[string]interface{}, len(m))
 	for k, v := range m {
-		other[k] = v
+		other[k] = copyValue(v)
+	}
+	return other
+}
+
+// copyValue returns a deep copy of v if it is a list. Otherwise returns v.
+func copyValue(v interface{}) interface{} {
+	a, ok := v.([]interface{})
+	if !ok {
+		return v
+	}
+	other := make([]interface{}, len(a))
+	for i := range a {
+		other[i] = copyValue(a[i])
 	}
 	return other
 }
@@ -209,6 +322,8 @@ func joinInterfaceSlice(a []interface{}) string {
 		switch v := a[i].(type) {
 		case string:
 			other[i] = fmt.Sprintf("%q", v)
+		case []interface{}:
+			other[i] = joinInterfaceSlice(v)
 		default:
 			other[i] = fmt.Sprintf("%v", v)
 		}
@@ -216,6 +331,14 @@ func joinInterfaceSlice(a []interface{}) string {
 	return "[" + strings.Join(other, ",") + "]"
 }
 
+func joinInt64Slice(a []int64) string {
+	other := make([]string, len(a))
+	for i := range a {
+		other[i] = strconv.FormatInt(a[i], 10)
+	}
+	return "[" + strings.Join(other, ",") + "]"
+}
+
 func joinUint64Slice(a []uint64) string {
 	other := make([]string, len(a))
 	for i := range a {
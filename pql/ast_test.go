@@ -28,6 +28,58 @@ func TestCall_String(t *testing.T) {
 			t.Fatalf("unexpected string: %s", s)
 		}
 	})
+
+	t.Run("IntegerSlices", func(t *testing.T) {
+		a := &pql.Call{Name: "TopN", Args: map[string]interface{}{"ids": []int64{1, 2}}}
+		b := &pql.Call{Name: "TopN", Args: map[string]interface{}{"ids": []uint64{1, 2}}}
+		if s := a.String(); s != `TopN(ids=[1,2])` {
+			t.Fatalf("unexpected string: %s", s)
+		} else if s := b.String(); s != `TopN(ids=[1,2])` {
+			t.Fatalf("unexpected string: %s", s)
+		}
+	})
+}
+
+// Ensure equivalent calls have the same canonical string.
+func TestCall_CanonicalString(t *testing.T) {
+	t.Run("Equal", func(t *testing.T) {
+		for _, tt := range [][2]string{
+			{`Bitmap(rowID=1, frame="f")`, `Bitmap(frame="f", rowID=1)`},
+			{`Count(Union(Bitmap(frame=f, rowID=1), Bitmap(rowID=2, frame=f)))`, `Count(Union(Bitmap(rowID=1, frame="f"), Bitmap(frame="f", rowID=2)))`},
+			{`TopN(frame=f, n=2, ids=[1,2])`, `TopN(ids=[1, 2], n=2, frame=f)`},
+		} {
+			a, b := mustParseString(t, tt[0]).CanonicalString(), mustParseString(t, tt[1]).CanonicalString()
+			if a != b {
+				t.Fatalf("canonical strings differ: %s != %s", a, b)
+			}
+		}
+	})
+
+	t.Run("IntegerTypes", func(t *testing.T) {
+		a := &pql.Call{Name: "TopN", Args: map[string]interface{}{"n": int64(2), "ids": []int64{1, 2}}}
+		b := &pql.Call{Name: "TopN", Args: map[string]interface{}{"n": uint64(2), "ids": []uint64{1, 2}}}
+		c := &pql.Call{Name: "TopN", Args: map[string]interface{}{"n": int64(2), "ids": []interface{}{int64(1), int64(2)}}}
+		if a.CanonicalString() != b.CanonicalString() || a.CanonicalString() != c.CanonicalString() {
+			t.Fatalf("canonical strings differ: %s, %s, %s", a.CanonicalString(), b.CanonicalString(), c.CanonicalString())
+		} else if s := a.CanonicalString(); s != `TopN(ids=[1,2], n=2)` {
+			t.Fatalf("unexpected canonical string: %s", s)
+		}
+	})
+
+	t.Run("NotEqual", func(t *testing.T) {
+		for _, tt := range [][2]string{
+			{`Bitmap(rowID=1, frame="f")`, `Bitmap(rowID=2, frame="f")`},
+			{`Bitmap(rowID=1, frame="f")`, `Bitmap(columnID=1, frame="f")`},
+			{`Bitmap(rowID=1, frame="f")`, `Bitmap(rowID="1", frame="f")`},
+			{`Bitmap(rowID=1, frame="f")`, `Bitmap(rowID=1.0, frame="f")`},
+			{`Union(Bitmap(rowID=1), Bitmap(rowID=2))`, `Intersect(Bitmap(rowID=1), Bitmap(rowID=2))`},
+		} {
+			a, b := mustParseString(t, tt[0]).CanonicalString(), mustParseString(t, tt[1]).CanonicalString()
+			if a == b {
+				t.Fatalf("canonical strings match: %s", a)
+			}
+		}
+	})
 }
 
 // Ensure call can be converted into a string.
@@ -95,3 +147,12 @@ func TestCall_IsInverse(t *testing.T) {
 	})
 
 }
+
+// mustParseString parses s into a query. Fails the test on error.
+func mustParseString(t *testing.T, s string) *pql.Query {
+	q, err := pql.ParseString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return q
+}
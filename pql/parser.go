@@ -222,7 +222,8 @@ func (p *Parser) parseArgs() (map[string]interface{}, error) {
 	}
 }
 
-// parseList parses a list of primitives. This is used by the TopN() filters.
+// parseList parses a list of primitives or nested lists. This is used by
+// the TopN() filters and list attribute values.
 func (p *Parser) parseList() ([]interface{}, error) {
 	var values []interface{}
 	for {
@@ -245,6 +246,18 @@ func (p *Parser) parseList() ([]interface{}, error) {
 				return nil, err
 			}
 			values = append(values, v)
+		case FLOAT:
+			v, err := strconv.ParseFloat(lit, 64)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		case LBRACK:
+			v, err := p.parseList()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
 		default:
 			return nil, parseErrorf(pos, "invalid list value: %q", lit)
 		}
@@ -168,4 +168,26 @@ func TestParser_Parse(t *testing.T) {
 			t.Fatalf("unexpected call: %#v", q.Calls[0])
 		}
 	})
+
+	// Parse a nested list argument and write it back out.
+	t.Run("NestedListArgument", func(t *testing.T) {
+		q, err := pql.ParseString(`SetRowAttrs(frame="f", rowID=1, tags=[["a","b"],["c"]], weights=[1.5,2.5])`)
+		if err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(q.Calls[0],
+			&pql.Call{
+				Name: "SetRowAttrs",
+				Args: map[string]interface{}{
+					"frame":   "f",
+					"rowID":   int64(1),
+					"tags":    []interface{}{[]interface{}{"a", "b"}, []interface{}{"c"}},
+					"weights": []interface{}{1.5, 2.5},
+				},
+			},
+		) {
+			t.Fatalf("unexpected call: %#v", q.Calls[0])
+		} else if s := q.String(); s != `SetRowAttrs(frame="f", rowID=1, tags=[["a","b"],["c"]], weights=[1.5,2.5])` {
+			t.Fatalf("unexpected string: %s", s)
+		}
+	})
 }
@@ -407,6 +407,67 @@ func (b *Bitmap) Union(other *Bitmap) *Bitmap {
 	return output
 }
 
+// UnionCount returns the number of bits set in the union of bitmaps without
+// building the union. Containers which only exist in one bitmap are counted
+// directly and overlapping containers are merged into a single buffer.
+func UnionCount(bitmaps []*Bitmap) uint64 {
+	var n uint64
+	var buf []uint64
+	pos := make([]int, len(bitmaps))
+	for {
+		// Find the lowest remaining key and the containers which share it.
+		var key uint64
+		var c *container
+		var overlap bool
+		for i, b := range bitmaps {
+			if pos[i] >= len(b.keys) {
+				continue
+			} else if k := b.keys[pos[i]]; c == nil || k < key {
+				key, c, overlap = k, b.containers[pos[i]], false
+			} else if k == key {
+				overlap = true
+			}
+		}
+		if c == nil {
+			return n
+		} else if !overlap {
+			n += uint64(c.n)
+			for i, b := range bitmaps {
+				if pos[i] < len(b.keys) && b.keys[pos[i]] == key {
+					pos[i]++
+				}
+			}
+			continue
+		}
+
+		// Merge overlapping containers into the buffer.
+		if buf == nil {
+			buf = make([]uint64, bitmapN)
+		} else {
+			for i := range buf {
+				buf[i] = 0
+			}
+		}
+		for i, b := range bitmaps {
+			if pos[i] >= len(b.keys) || b.keys[pos[i]] != key {
+				continue
+			}
+			c := b.containers[pos[i]]
+			if c.isArray() {
+				for _, v := range c.array {
+					buf[v/64] |= 1 << (v % 64)
+				}
+			} else {
+				for j, v := range c.bitmap {
+					buf[j] |= v
+				}
+			}
+			pos[i]++
+		}
+		n += popcntSlice(buf)
+	}
+}
+
 // Difference returns the difference of b and other.
 func (b *Bitmap) Difference(other *Bitmap) *Bitmap {
 	output := &Bitmap{}
@@ -543,8 +604,11 @@ func (b *Bitmap) UnmarshalBinary(data []byte) error {
 		return errors.New("invalid roaring file")
 	}
 
-	// Read key count.
+	// Read key count & verify the key headers and offsets fit in the data.
 	keyN := binary.LittleEndian.Uint32(data[4:8])
+	if headerSize+uint64(keyN)*16 > uint64(len(data)) {
+		return fmt.Errorf("key headers out of bounds: n=%d, len=%d", keyN, len(data))
+	}
 	b.keys = make([]uint64, keyN)
 	b.containers = make([]*container, keyN)
 
@@ -567,8 +631,17 @@ func (b *Bitmap) UnmarshalBinary(data []byte) error {
 			return fmt.Errorf("offset out of bounds: off=%d, len=%d", offset, len(data))
 		}
 
-		// Map byte slice directly to the container data.
+		// Verify the container data is within the bounds of the input data.
 		c := b.containers[i]
+		size := bitmapN * 8
+		if c.n <= ArrayMaxSize {
+			size = c.n * 4
+		}
+		if int(offset)+size > len(data) {
+			return fmt.Errorf("container out of bounds: off=%d, size=%d, len=%d", offset, size, len(data))
+		}
+
+		// Map byte slice directly to the container data.
 		if c.n <= ArrayMaxSize {
 			c.array = (*[0xFFFFFFF]uint32)(unsafe.Pointer(&data[offset]))[:c.n]
 			// TODO: instead of commenting this out, we need to make it a configuration option
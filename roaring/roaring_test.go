@@ -130,6 +130,23 @@ func TestBitmap_Union(t *testing.T) {
 	}
 }
 
+// Ensure the union of several bitmaps can be counted without building it.
+func TestUnionCount(t *testing.T) {
+	bm0 := roaring.NewBitmap(0, 1000001, 1000002, 1000003)
+	bm1 := roaring.NewBitmap(0, 50000, 1000001, 1000002)
+	bm2 := roaring.NewBitmap(3000000)
+	for i := uint64(0); i <= 10000; i += 2 {
+		bm2.Add(i)
+	}
+
+	bitmaps := []*roaring.Bitmap{bm0, bm1, bm2}
+	if n, exp := roaring.UnionCount(bitmaps), bm0.Union(bm1).Union(bm2).Count(); n != exp {
+		t.Fatalf("unexpected n: %d != %d", n, exp)
+	} else if n := roaring.UnionCount(nil); n != 0 {
+		t.Fatalf("unexpected n for no bitmaps: %d", n)
+	}
+}
+
 // Ensure bitmap can return the number of intersecting bits in two bitmaps.
 func TestBitmap_IntersectionCount_ArrayArray(t *testing.T) {
 	bm0 := roaring.NewBitmap(0, 1000001, 1000002, 1000003)
@@ -236,6 +253,27 @@ func testBitmapQuick(t *testing.T, n int, min, max uint64) {
 	})
 }
 
+// Ensure truncated data returns an error instead of mapping past its end.
+func TestBitmap_UnmarshalBinary_Truncated(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := roaring.NewBitmap(1, 2, 100000).WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	for _, tt := range []struct {
+		n   int
+		err string
+	}{
+		{n: 20, err: "key headers out of bounds: n=2, len=20"},
+		{n: len(data) - 2, err: fmt.Sprintf("container out of bounds: off=%d, size=4, len=%d", len(data)-4, len(data)-2)},
+	} {
+		if err := roaring.NewBitmap().UnmarshalBinary(data[:tt.n]); err == nil || err.Error() != tt.err {
+			t.Fatalf("%d: unexpected error: %v", tt.n, err)
+		}
+	}
+}
+
 func TestBitmap_Marshal_Quick_Array1(t *testing.T)  { testBitmapMarshalQuick(t, 1000, 1000, 2000, false) }
 func TestBitmap_Marshal_Quick_Array2(t *testing.T)  { testBitmapMarshalQuick(t, 10000, 0, 1000, false) }
 func TestBitmap_Marshal_Quick_Bitmap1(t *testing.T) { testBitmapMarshalQuick(t, 10000, 0, 10000, false) }
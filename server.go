@@ -266,8 +266,9 @@ func (s *Server) ReceiveMessage(pb proto.Message) error {
 		}
 	case *internal.CreateIndexMessage:
 		opt := IndexOptions{
-			ColumnLabel: obj.Meta.ColumnLabel,
-			TimeQuantum: TimeQuantum(obj.Meta.TimeQuantum),
+			ColumnLabel:  obj.Meta.ColumnLabel,
+			TimeQuantum:  TimeQuantum(obj.Meta.TimeQuantum),
+			DefaultFrame: obj.Meta.DefaultFrame,
 		}
 		_, err := s.Holder.CreateIndex(obj.Index, opt)
 		if err != nil {
@@ -362,8 +363,9 @@ func (s *Server) mergeRemoteStatus(ns *internal.NodeStatus) error {
 	// Create indexes that don't exist.
 	for _, index := range ns.Indexes {
 		opt := IndexOptions{
-			ColumnLabel: index.Meta.ColumnLabel,
-			TimeQuantum: TimeQuantum(index.Meta.TimeQuantum),
+			ColumnLabel:  index.Meta.ColumnLabel,
+			TimeQuantum:  TimeQuantum(index.Meta.TimeQuantum),
+			DefaultFrame: index.Meta.DefaultFrame,
 		}
 		idx, err := s.Holder.CreateIndexIfNotExists(index.Name, opt)
 		if err != nil {
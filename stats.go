@@ -87,10 +87,18 @@ func (c *ExpvarStatsClient) Tags() []string {
 }
 
 // WithTags returns a new client with additional tags appended.
+// Clients with the same tags share the same map.
 func (c *ExpvarStatsClient) WithTags(tags ...string) StatsClient {
-	m := &expvar.Map{}
-	m.Init()
-	c.m.Set(strings.Join(tags, ","), m)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := strings.Join(tags, ",")
+	m, ok := c.m.Get(key).(*expvar.Map)
+	if !ok {
+		m = &expvar.Map{}
+		m.Init()
+		c.m.Set(key, m)
+	}
 
 	return &ExpvarStatsClient{
 		m:    m,
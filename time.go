@@ -17,6 +17,8 @@ package pilosa
 import (
 	"errors"
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -166,6 +168,45 @@ func ViewsByTimeRange(name string, start, end time.Time, q TimeQuantum) []string
 	return results
 }
 
+// relativeTimeRegexp matches relative time expressions such as "now",
+// "-7d" and "now+2h".
+var relativeTimeRegexp = regexp.MustCompile(`^(?:now)?(?:([+-])(\d+)([hdwMy]))?$`)
+
+// ParseRelativeTime returns the time described by the relative expression s,
+// relative to now. Expressions are "now" followed by an optional offset of a
+// sign, a count and a unit of h (hours), d (days), w (weeks), M (months) or
+// y (years). The "now" prefix may be omitted from an offset. Units of a day or
+// more move the calendar date in now's location rather than a fixed number
+// of hours. Returns false if s is not a relative expression.
+func ParseRelativeTime(s string, now time.Time) (time.Time, bool) {
+	m := relativeTimeRegexp.FindStringSubmatch(s)
+	if s == "" || m == nil {
+		return time.Time{}, false
+	} else if m[1] == "" {
+		return now, true
+	}
+
+	n, err := strconv.Atoi(m[2])
+	if err != nil {
+		return time.Time{}, false
+	} else if m[1] == "-" {
+		n = -n
+	}
+
+	switch m[3] {
+	case "h":
+		return now.Add(time.Duration(n) * time.Hour), true
+	case "d":
+		return now.AddDate(0, 0, n), true
+	case "w":
+		return now.AddDate(0, 0, 7*n), true
+	case "M":
+		return now.AddDate(0, n, 0), true
+	default:
+		return now.AddDate(n, 0, 0), true
+	}
+}
+
 func nextYearGTE(t time.Time, end time.Time) bool {
 	next := t.AddDate(1, 0, 0)
 	if next.Year() == end.Year() {
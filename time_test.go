@@ -174,3 +174,39 @@ func MustParseTimeQuantum(v string) pilosa.TimeQuantum {
 	}
 	return q
 }
+
+// Ensure relative time expressions are parsed relative to a time.
+func TestParseRelativeTime(t *testing.T) {
+	now := time.Date(2017, 3, 31, 15, 4, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		s   string
+		exp time.Time
+		ok  bool
+	}{
+		{s: "now", exp: now, ok: true},
+		{s: "-7d", exp: time.Date(2017, 3, 24, 15, 4, 0, 0, time.UTC), ok: true},
+		{s: "now+2h", exp: time.Date(2017, 3, 31, 17, 4, 0, 0, time.UTC), ok: true},
+		{s: "now-1w", exp: time.Date(2017, 3, 24, 15, 4, 0, 0, time.UTC), ok: true},
+		{s: "-1M", exp: time.Date(2017, 3, 3, 15, 4, 0, 0, time.UTC), ok: true},
+		{s: "+1y", exp: time.Date(2018, 3, 31, 15, 4, 0, 0, time.UTC), ok: true},
+		{s: ""},
+		{s: "7d"},
+		{s: "-7x"},
+		{s: "2017-03-31T15:04"},
+	} {
+		if v, ok := pilosa.ParseRelativeTime(tt.s, now); ok != tt.ok {
+			t.Fatalf("%q: unexpected ok: %v", tt.s, ok)
+		} else if !v.Equal(tt.exp) {
+			t.Fatalf("%q: unexpected time: %s", tt.s, v)
+		}
+	}
+
+	// Days move the calendar date across daylight saving changes.
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	if v, _ := pilosa.ParseRelativeTime("-1d", time.Date(2017, 3, 13, 12, 0, 0, 0, loc)); !v.Equal(time.Date(2017, 3, 12, 12, 0, 0, 0, loc)) {
+		t.Fatalf("unexpected time: %s", v)
+	}
+}
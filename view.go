@@ -48,6 +48,9 @@ type View struct {
 
 	cacheSize uint32
 
+	// Row cache size passed to fragments.
+	rowCacheSize int
+
 	// Fragments by slice.
 	cacheType string // passed in by frame
 	fragments map[uint64]*Fragment
@@ -257,6 +260,7 @@ func (v *View) newFragment(path string, slice uint64) *Fragment {
 	frag := NewFragment(path, v.index, v.frame, v.name, slice)
 	frag.cacheType = v.cacheType
 	frag.cacheSize = v.cacheSize
+	frag.RowCacheSize = v.rowCacheSize
 	frag.LogOutput = v.LogOutput
 	frag.stats = v.stats.WithTags(fmt.Sprintf("slice:%d", slice))
 	return frag